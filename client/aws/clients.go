@@ -11,6 +11,8 @@ import (
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
 	"github.com/aws/aws-sdk-go/service/elb"
 	"github.com/aws/aws-sdk-go/service/elb/elbiface"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/aws/aws-sdk-go/service/iam/iamiface"
 	"github.com/aws/aws-sdk-go/service/kms"
@@ -18,6 +20,8 @@ import (
 	"github.com/aws/aws-sdk-go/service/route53"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
 	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/aws/aws-sdk-go/service/sts/stsiface"
 	"github.com/aws/aws-sdk-go/service/support"
@@ -44,10 +48,12 @@ type Clients struct {
 	CloudFormation *cloudformation.CloudFormation
 	EC2            ec2iface.EC2API
 	ELB            elbiface.ELBAPI
+	ELBV2          elbv2iface.ELBV2API
 	IAM            iamiface.IAMAPI
 	KMS            kmsiface.KMSAPI
 	Route53        *route53.Route53
 	S3             s3iface.S3API
+	SSM            ssmiface.SSMAPI
 	STS            stsiface.STSAPI
 	Support        supportiface.SupportAPI
 }
@@ -97,10 +103,12 @@ func newClients(session *session.Session, configs ...*aws.Config) Clients {
 		CloudFormation: cloudformation.New(session, configs...),
 		EC2:            ec2.New(session, configs...),
 		ELB:            elb.New(session, configs...),
+		ELBV2:          elbv2.New(session, configs...),
 		IAM:            iam.New(session, configs...),
 		KMS:            kms.New(session, configs...),
 		Route53:        route53.New(session, configs...),
 		S3:             s3.New(session, configs...),
+		SSM:            ssm.New(session, configs...),
 		STS:            sts.New(session, configs...),
 		Support:        support.New(session, supportConfigs...),
 	}
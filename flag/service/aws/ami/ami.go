@@ -0,0 +1,6 @@
+package ami
+
+type AMI struct {
+	Overrides string
+	Owner     string
+}
@@ -2,25 +2,53 @@ package aws
 
 import (
 	"github.com/giantswarm/aws-operator/flag/service/aws/accesskey"
+	"github.com/giantswarm/aws-operator/flag/service/aws/ami"
+	"github.com/giantswarm/aws-operator/flag/service/aws/changeset"
+	"github.com/giantswarm/aws-operator/flag/service/aws/ebsencryption"
+	"github.com/giantswarm/aws-operator/flag/service/aws/encryptionrekey"
+	"github.com/giantswarm/aws-operator/flag/service/aws/etcdbackup"
+	"github.com/giantswarm/aws-operator/flag/service/aws/ingressnlb"
+	"github.com/giantswarm/aws-operator/flag/service/aws/instancemetadata"
 	"github.com/giantswarm/aws-operator/flag/service/aws/loggingbucket"
+	"github.com/giantswarm/aws-operator/flag/service/aws/recovery"
 	"github.com/giantswarm/aws-operator/flag/service/aws/route53"
+	"github.com/giantswarm/aws-operator/flag/service/aws/stackdrift"
+	"github.com/giantswarm/aws-operator/flag/service/aws/transitgateway"
 	"github.com/giantswarm/aws-operator/flag/service/aws/trustedadvisor"
+	"github.com/giantswarm/aws-operator/flag/service/aws/vaultauth"
+	"github.com/giantswarm/aws-operator/flag/service/aws/vpcendpoints"
+	"github.com/giantswarm/aws-operator/flag/service/aws/vpcflowlogs"
+	"github.com/giantswarm/aws-operator/flag/service/aws/workerrollout"
 )
 
 type AWS struct {
 	AccessKey              accesskey.AccessKey
+	AMI                    ami.AMI
 	AdvancedMonitoringEC2  string
 	AvailabilityZones      string
+	ChangeSet              changeset.ChangeSet
+	EBSEncryption          ebsencryption.EBSEncryption
 	Encrypter              string
+	EncryptionRekey        encryptionrekey.EncryptionRekey
+	EtcdBackup             etcdbackup.EtcdBackup
 	HostAccessKey          accesskey.AccessKey
 	IncludeTags            string
+	IngressNLB             ingressnlb.IngressNLB
+	InstanceMetadata       instancemetadata.InstanceMetadata
 	LoggingBucket          loggingbucket.LoggingBucket
 	PodInfraContainerImage string
 	PubKeyFile             string
 	PublicRouteTables      string
+	Recovery               recovery.Recovery
 	Region                 string
 	Route53                route53.Route53
 	S3AccessLogsExpiration string
+	StackDrift             stackdrift.StackDrift
+	TransitGateway         transitgateway.TransitGateway
 	TrustedAdvisor         trustedadvisor.TrustedAdvisor
 	VaultAddress           string
+	VaultAuth              vaultauth.VaultAuth
+	VPCEndpoints           vpcendpoints.VPCEndpoints
+	VPCFlowLogs            vpcflowlogs.VPCFlowLogs
+	WorkerRollout          workerrollout.WorkerRollout
 }
@@ -0,0 +1,5 @@
+package changeset
+
+type ChangeSet struct {
+	ApprovalRequired string
+}
@@ -0,0 +1,5 @@
+package ebsencryption
+
+type EBSEncryption struct {
+	KeyARN string
+}
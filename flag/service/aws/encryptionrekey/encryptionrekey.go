@@ -0,0 +1,6 @@
+package encryptionrekey
+
+type EncryptionRekey struct {
+	GracePeriod string
+	Interval    string
+}
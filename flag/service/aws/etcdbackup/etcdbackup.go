@@ -0,0 +1,6 @@
+package etcdbackup
+
+type EtcdBackup struct {
+	Interval  string
+	Retention string
+}
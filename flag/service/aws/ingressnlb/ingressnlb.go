@@ -0,0 +1,6 @@
+package ingressnlb
+
+type IngressNLB struct {
+	ProxyProtocol string
+	SourceCIDRs   string
+}
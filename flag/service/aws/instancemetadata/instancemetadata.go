@@ -0,0 +1,5 @@
+package instancemetadata
+
+type InstanceMetadata struct {
+	TokensRequired string
+}
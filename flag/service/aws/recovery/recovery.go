@@ -0,0 +1,7 @@
+package recovery
+
+type Recovery struct {
+	DeleteFailed         string
+	RollbackComplete     string
+	UpdateRollbackFailed string
+}
@@ -0,0 +1,6 @@
+package stackdrift
+
+type StackDrift struct {
+	Interval    string
+	Remediation string
+}
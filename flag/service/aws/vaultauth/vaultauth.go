@@ -0,0 +1,10 @@
+package vaultauth
+
+type VaultAuth struct {
+	AppRoleRoleID       string
+	AppRoleSecretIDPath string
+	KubernetesTokenPath string
+	Method              string
+	Mount               string
+	Role                string
+}
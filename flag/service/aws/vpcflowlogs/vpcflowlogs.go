@@ -0,0 +1,7 @@
+package vpcflowlogs
+
+type VPCFlowLogs struct {
+	Destination            string
+	MaxAggregationInterval string
+	TrafficType            string
+}
@@ -0,0 +1,6 @@
+package workerrollout
+
+type WorkerRollout struct {
+	BatchTimeout string
+	HealthGated  string
+}
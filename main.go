@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"time"
 
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/microkit/command"
@@ -112,13 +113,22 @@ func mainError() error {
 	daemonCommand.PersistentFlags().String(f.Service.AWS.AccessKey.Secret, "", "Secret of the AWS access key for the  account to create guest clusters in.")
 	daemonCommand.PersistentFlags().String(f.Service.AWS.AccessKey.Session, "", "Session token of the AWS access key for the  account to create guest clusters in. (Can be empty)")
 	daemonCommand.PersistentFlags().StringSlice(f.Service.AWS.AvailabilityZones, []string{}, "Availability zones as a slice.")
-	daemonCommand.PersistentFlags().String(f.Service.AWS.Encrypter, "kms", "Encryption backend to use.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.EBSEncryption.KeyARN, "", "ARN of the KMS key the EBS volumes of all guest clusters are encrypted with. Each guest cluster gets its own KMS key in case it is empty.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.Encrypter, "kms", "Encryption backend to use. One of kms, ssm and vault.")
+	daemonCommand.PersistentFlags().Duration(f.Service.AWS.EncryptionRekey.GracePeriod, 24*time.Hour, "Duration the previous encryption key of a re-keyed guest cluster is kept after all nodes got replaced, before its deletion is scheduled.")
+	daemonCommand.PersistentFlags().Duration(f.Service.AWS.EncryptionRekey.Interval, 0, "Age of the encryption key of a guest cluster after which the guest cluster is re-keyed. Re-keying is disabled in case it is 0. Only supported by the kms and ssm encrypter backends.")
 	daemonCommand.PersistentFlags().String(f.Service.AWS.HostAccessKey.ID, "", "ID of the AWS access key for the host cluster account. If empty, guest cluster account is used.")
 	daemonCommand.PersistentFlags().String(f.Service.AWS.HostAccessKey.Secret, "", "Secret of the AWS access key for the host cluster account. If empty, guest cluster account is used.")
 	daemonCommand.PersistentFlags().String(f.Service.AWS.HostAccessKey.Session, "", "Session token of the AWS access key for the host cluster account. If empty, guest cluster token is used.")
 	daemonCommand.PersistentFlags().String(f.Service.AWS.PublicRouteTables, "", "Names of the public route tables in host cluster separated by commas, required for accessing public ELBs from guest nodes.")
 	daemonCommand.PersistentFlags().String(f.Service.AWS.Region, "", "Region for checking for orphan AWS resources.")
 	daemonCommand.PersistentFlags().String(f.Service.AWS.VaultAddress, "", "Server address for Vault encryption.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.VaultAuth.AppRoleRoleID, "", "Role ID used to log in to Vault with the AppRole auth method.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.VaultAuth.AppRoleSecretIDPath, "", "Path of the file containing the secret ID used to log in to Vault with the AppRole auth method, e.g. a key of a mounted Secret. If empty, no secret ID is used.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.VaultAuth.KubernetesTokenPath, "", "Path of the service account token used to log in to Vault with the Kubernetes auth method. If empty, the token of the operator pod is used.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.VaultAuth.Method, "aws", "Auth method used to log in to Vault for Vault encryption. One of aws, kubernetes and approle.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.VaultAuth.Mount, "", "Path the Vault auth method is enabled at. If empty, the name of the auth method is used.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.VaultAuth.Role, "encrypter", "Vault role used to log in with the aws and kubernetes auth methods.")
 
 	daemonCommand.PersistentFlags().String(f.Service.RegistryDomain, "quay.io", "Image registry.")
 
@@ -133,6 +143,11 @@ func mainError() error {
 
 	daemonCommand.PersistentFlags().Bool(f.Service.AWS.AdvancedMonitoringEC2, false, "Advanced EC2 monitoring.")
 
+	daemonCommand.PersistentFlags().String(f.Service.AWS.AMI.Overrides, "", "Comma separated list of region=ami-id pairs pinning the AMI used in a region, e.g. for private, copied or encrypted AMIs.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.AMI.Owner, "595879546273", "Comma separated list of partition=account-id pairs defining the AWS accounts owning the Container Linux AMIs, e.g. aws=595879546273,aws-cn=123456789012. A plain account ID defines the owner of the aws partition. Regions of the aws-cn and aws-us-gov partitions without owner use the AMIs known for the Container Linux release of the version bundle.")
+
+	daemonCommand.PersistentFlags().Bool(f.Service.AWS.ChangeSet.ApprovalRequired, false, "Whether updates of tenant cluster main stacks replacing protected resources like the etcd volume are blocked until their change set is approved.")
+
 	daemonCommand.PersistentFlags().Bool(f.Service.AWS.LoggingBucket.Delete, false, "Should be logging bucket deleted.")
 
 	daemonCommand.PersistentFlags().Bool(f.Service.AWS.Route53.Enabled, true, "Should Route53 be enabled.")
@@ -141,10 +156,35 @@ func mainError() error {
 
 	daemonCommand.PersistentFlags().Bool(f.Service.AWS.IncludeTags, true, "Should resource tags be included (especially for restricted regions, like S3 buckets in China regions).")
 
+	daemonCommand.PersistentFlags().Bool(f.Service.AWS.IngressNLB.ProxyProtocol, true, "Whether the ingress Network Load Balancers of tenant clusters send the client address to the ingress controller using proxy protocol v2.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.IngressNLB.SourceCIDRs, "", "Comma separated list of CIDRs allowed to reach the ingress controller ports of the workers through the ingress Network Load Balancers of tenant clusters, e.g. 0.0.0.0/0. If empty, only clients within the tenant cluster VPC reach them.")
+
+	daemonCommand.PersistentFlags().Bool(f.Service.AWS.InstanceMetadata.TokensRequired, false, "Whether worker instances of tenant clusters only serve the instance metadata service with session tokens, i.e. IMDSv2. The hop limit is 2 so that containers still reach it.")
+
 	daemonCommand.PersistentFlags().Int(f.Service.AWS.S3AccessLogsExpiration, 365, "S3 access logs expiration policy.")
 
+	daemonCommand.PersistentFlags().Duration(f.Service.AWS.EtcdBackup.Interval, time.Hour, "Interval in which masters upload encrypted etcd snapshots to the cluster S3 bucket.")
+	daemonCommand.PersistentFlags().Int(f.Service.AWS.EtcdBackup.Retention, 48, "Number of etcd snapshots kept in the cluster S3 bucket.")
+
+	daemonCommand.PersistentFlags().Bool(f.Service.AWS.Recovery.DeleteFailed, false, "Whether the resources failing the deletion of tenant cluster main stacks in DELETE_FAILED state are retained when deleting them again.")
+	daemonCommand.PersistentFlags().Bool(f.Service.AWS.Recovery.RollbackComplete, false, "Whether tenant cluster main stacks which failed to be created and are in ROLLBACK_COMPLETE state are deleted in order to create them again.")
+	daemonCommand.PersistentFlags().Bool(f.Service.AWS.Recovery.UpdateRollbackFailed, false, "Whether the rollback of tenant cluster main stacks in UPDATE_ROLLBACK_FAILED state is continued while skipping the failed resources.")
+
+	daemonCommand.PersistentFlags().Duration(f.Service.AWS.StackDrift.Interval, time.Hour, "Interval in which drift of the CloudFormation stacks of tenant clusters is detected.")
+	daemonCommand.PersistentFlags().Bool(f.Service.AWS.StackDrift.Remediation, false, "Whether the templates of drifted CloudFormation stacks are re-applied.")
+
+	daemonCommand.PersistentFlags().String(f.Service.AWS.TransitGateway.ID, "", "ID of the Transit Gateway in the host cluster account tenant cluster VPCs are attached to. If empty, tenant cluster VPCs are peered with the host cluster VPC. Only applies to newly created tenant clusters.")
+
 	daemonCommand.PersistentFlags().String(f.Service.AWS.TrustedAdvisor.Enabled, "", "Whether trusted advisor metrics collection is enabled.")
 
+	daemonCommand.PersistentFlags().Bool(f.Service.AWS.VPCEndpoints.Enabled, false, "Whether tenant cluster VPCs get endpoints for S3, EC2, ELB, KMS, STS and ECR so that their nodes reach these services without the NAT gateways.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.VPCFlowLogs.Destination, "", "Destination the flow logs of tenant cluster VPCs are delivered to. Either s3 for the logging bucket of the tenant cluster or cloudwatch for a log group of the tenant cluster. VPC flow logs are disabled in case it is empty.")
+	daemonCommand.PersistentFlags().Int(f.Service.AWS.VPCFlowLogs.MaxAggregationInterval, 600, "Maximum interval in seconds during which packets are aggregated into a VPC flow log record. Either 60 or 600.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.VPCFlowLogs.TrafficType, "ALL", "Type of traffic captured by VPC flow logs. One of ACCEPT, REJECT or ALL.")
+
+	daemonCommand.PersistentFlags().Duration(f.Service.AWS.WorkerRollout.BatchTimeout, 20*time.Minute, "Duration replaced worker instances have to become ready in the tenant cluster before a health gated worker rollout is paused.")
+	daemonCommand.PersistentFlags().Bool(f.Service.AWS.WorkerRollout.HealthGated, false, "Whether outdated worker instances are replaced in batches by the operator once the previous batch is ready in the tenant cluster instead of by the rolling update policy of the ASGs.")
+
 	daemonCommand.PersistentFlags().String(f.Service.Installation.Name, "", "Installation name for tagging AWS resources.")
 	daemonCommand.PersistentFlags().String(f.Service.Installation.Guest.IPAM.Network.CIDR, "", "Guest cluster network segment from which IPAM allocates subnets.")
 	daemonCommand.PersistentFlags().Int(f.Service.Installation.Guest.IPAM.Network.SubnetMaskBits, 24, "Number of bits in guest cluster subnet network mask.")
@@ -5,6 +5,7 @@ import (
 	"sync"
 
 	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elbv2"
 	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/sync/errgroup"
 
@@ -125,6 +126,20 @@ func (e *ELB) collectForAccount(ch chan<- prometheus.Metric, awsClients clientaw
 		return microerror.Mask(err)
 	}
 
+	err = e.collectClassicLoadBalancers(ch, awsClients, account)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	err = e.collectNetworkLoadBalancers(ch, awsClients, account)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (e *ELB) collectClassicLoadBalancers(ch chan<- prometheus.Metric, awsClients clientaws.Clients, account string) error {
 	var loadBalancerNames []*string
 	{
 		i := &elb.DescribeLoadBalancersInput{}
@@ -247,3 +262,113 @@ func (e *ELB) collectForAccount(ch chan<- prometheus.Metric, awsClients clientaw
 
 	return nil
 }
+
+// collectNetworkLoadBalancers emits the number of unhealthy targets of Network
+// Load Balancers, which are not covered by the classic ELB API.
+func (e *ELB) collectNetworkLoadBalancers(ch chan<- prometheus.Metric, awsClients clientaws.Clients, account string) error {
+	loadBalancerNames := map[string]string{}
+	var loadBalancerARNs []*string
+	{
+		i := &elbv2.DescribeLoadBalancersInput{}
+		o, err := awsClients.ELBV2.DescribeLoadBalancers(i)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		for _, d := range o.LoadBalancers {
+			if *d.Type != elbv2.LoadBalancerTypeEnumNetwork {
+				continue
+			}
+			loadBalancerNames[*d.LoadBalancerArn] = *d.LoadBalancerName
+			loadBalancerARNs = append(loadBalancerARNs, d.LoadBalancerArn)
+		}
+
+		if len(loadBalancerARNs) == 0 {
+			return nil
+		}
+	}
+
+	var lbs []loadBalancer
+	var lbARNs []string
+	{
+		arns := loadBalancerARNs
+		for len(arns) > 0 {
+			batchSize := maxELBsInOneDescribeTagsBatch
+			if len(arns) < batchSize {
+				batchSize = len(arns)
+			}
+
+			i := &elbv2.DescribeTagsInput{
+				ResourceArns: arns[0:batchSize],
+			}
+			arns = arns[batchSize:]
+
+			o, err := awsClients.ELBV2.DescribeTags(i)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+
+			for _, d := range o.TagDescriptions {
+				lb := loadBalancer{
+					Name: loadBalancerNames[*d.ResourceArn],
+					Tags: make(map[string]string),
+				}
+
+				for _, t := range d.Tags {
+					lb.Tags[*t.Key] = *t.Value
+				}
+
+				if lb.Tags[tagInstallation] != e.installationName {
+					continue
+				}
+
+				lbs = append(lbs, lb)
+				lbARNs = append(lbARNs, *d.ResourceArn)
+			}
+		}
+	}
+
+	{
+		// Targets are registered with the target groups of a Network Load
+		// Balancer, so their health is checked per target group.
+		for i := range lbs {
+			o, err := awsClients.ELBV2.DescribeTargetGroups(&elbv2.DescribeTargetGroupsInput{
+				LoadBalancerArn: &lbARNs[i],
+			})
+			if err != nil {
+				return microerror.Mask(err)
+			}
+
+			for _, g := range o.TargetGroups {
+				o, err := awsClients.ELBV2.DescribeTargetHealth(&elbv2.DescribeTargetHealthInput{
+					TargetGroupArn: g.TargetGroupArn,
+				})
+				if err != nil {
+					return microerror.Mask(err)
+				}
+
+				for _, d := range o.TargetHealthDescriptions {
+					if *d.TargetHealth.State == elbv2.TargetHealthStateEnumUnhealthy {
+						lbs[i].InstancesOutOfService++
+					}
+				}
+			}
+		}
+	}
+
+	{
+		for _, lb := range lbs {
+			ch <- prometheus.MustNewConstMetric(
+				elbsDesc,
+				prometheus.GaugeValue,
+				lb.InstancesOutOfService,
+				lb.Name,
+				account,
+				lb.Tags[tagCluster],
+				lb.Tags[tagInstallation],
+				lb.Tags[tagOrganization],
+			)
+		}
+	}
+
+	return nil
+}
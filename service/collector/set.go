@@ -92,6 +92,21 @@ func NewSet(config SetConfig) (*Set, error) {
 		}
 	}
 
+	var stackDriftCollector *StackDrift
+	{
+		c := StackDriftConfig{
+			Helper: h,
+			Logger: config.Logger,
+
+			InstallationName: config.InstallationName,
+		}
+
+		stackDriftCollector, err = NewStackDrift(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var trustedAdvisorCollector *TrustedAdvisor
 	{
 		c := TrustedAdvisorConfig{
@@ -127,6 +142,7 @@ func NewSet(config SetConfig) (*Set, error) {
 				asgCollector,
 				ec2InstancesCollector,
 				elbCollector,
+				stackDriftCollector,
 				vpcCollector,
 			},
 			Logger: config.Logger,
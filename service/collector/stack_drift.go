@@ -0,0 +1,106 @@
+package collector
+
+import (
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// conditionTypeDrifted is the type of the status condition the operator's
+	// stackdrift resource sets in case any stack of the cluster drifted.
+	conditionTypeDrifted = "Drifted"
+)
+
+const (
+	subsystemCloudFormation = "cloudformation"
+)
+
+var (
+	stackDriftDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystemCloudFormation, "stacks_drifted"),
+		"Gauge about whether the latest drift detections found drifted resources in the CloudFormation stacks of a cluster.",
+		[]string{
+			labelCluster,
+			labelInstallation,
+		},
+		nil,
+	)
+)
+
+type StackDriftConfig struct {
+	Helper *helper
+	Logger micrologger.Logger
+
+	InstallationName string
+}
+
+// StackDrift exposes the drift of the CloudFormation stacks of all clusters.
+// The drift is detected by the operator's stackdrift resource and reported with
+// the Drifted condition of the AWSConfig CRs, which is where the collector reads
+// it from.
+type StackDrift struct {
+	helper *helper
+	logger micrologger.Logger
+
+	installationName string
+}
+
+func NewStackDrift(config StackDriftConfig) (*StackDrift, error) {
+	if config.Helper == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Helper must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.InstallationName == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.InstallationName must not be empty", config)
+	}
+
+	s := &StackDrift{
+		helper: config.Helper,
+		logger: config.Logger,
+
+		installationName: config.InstallationName,
+	}
+
+	return s, nil
+}
+
+func (s *StackDrift) Collect(ch chan<- prometheus.Metric) error {
+	awsConfigs, err := s.helper.g8sClient.ProviderV1alpha1().AWSConfigs("").List(v1.ListOptions{})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, awsConfig := range awsConfigs.Items {
+		for _, c := range awsConfig.Status.Cluster.Conditions {
+			if c.Type != conditionTypeDrifted {
+				continue
+			}
+
+			var drifted float64
+			if c.Status == v1alpha1.StatusClusterStatusTrue {
+				drifted = 1
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				stackDriftDesc,
+				prometheus.GaugeValue,
+				drifted,
+				awsConfig.Spec.Cluster.ID,
+				s.installationName,
+			)
+		}
+	}
+
+	return nil
+}
+
+func (s *StackDrift) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- stackDriftDesc
+	return nil
+}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"net"
+	"time"
 
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
 	"github.com/giantswarm/apiextensions/pkg/clientset/versioned"
@@ -21,6 +22,7 @@ import (
 	"github.com/giantswarm/aws-operator/service/controller/v21"
 	v21adapter "github.com/giantswarm/aws-operator/service/controller/v21/adapter"
 	v21cloudconfig "github.com/giantswarm/aws-operator/service/controller/v21/cloudconfig"
+	v21vault "github.com/giantswarm/aws-operator/service/controller/v21/encrypter/vault"
 	"github.com/giantswarm/certs"
 	"github.com/giantswarm/legacycerts/legacy"
 	"github.com/giantswarm/microerror"
@@ -39,30 +41,51 @@ type ClusterConfig struct {
 	K8sExtClient apiextensionsclient.Interface
 	Logger       micrologger.Logger
 
-	AccessLogsExpiration       int
-	AdvancedMonitoringEC2      bool
-	APIWhitelist               FrameworkConfigAPIWhitelistConfig
-	DeleteLoggingBucket        bool
-	EncrypterBackend           string
-	GuestAWSConfig             ClusterConfigAWSConfig
-	GuestPrivateSubnetMaskBits int
-	GuestPublicSubnetMaskBits  int
-	GuestSubnetMaskBits        int
-	GuestUpdateEnabled         bool
-	HostAWSConfig              ClusterConfigAWSConfig
-	IgnitionPath               string
-	IncludeTags                bool
-	InstallationName           string
-	IPAMNetworkRange           net.IPNet
-	OIDC                       ClusterConfigOIDC
-	PodInfraContainerImage     string
-	ProjectName                string
-	PubKeyFile                 string
-	PublicRouteTables          string
-	RegistryDomain             string
-	Route53Enabled             bool
-	SSOPublicKey               string
-	VaultAddress               string
+	AccessLogsExpiration        int
+	AdvancedMonitoringEC2       bool
+	AMIOverrides                string
+	AMIOwner                    string
+	APIWhitelist                FrameworkConfigAPIWhitelistConfig
+	ChangeSetApprovalRequired   bool
+	DeleteLoggingBucket         bool
+	EBSEncryptionKeyARN         string
+	EncrypterBackend            string
+	EncryptionRekeyGracePeriod  time.Duration
+	EncryptionRekeyInterval     time.Duration
+	EtcdBackupInterval          time.Duration
+	EtcdBackupRetention         int
+	GuestAWSConfig              ClusterConfigAWSConfig
+	GuestPrivateSubnetMaskBits  int
+	GuestPublicSubnetMaskBits   int
+	GuestSubnetMaskBits         int
+	GuestUpdateEnabled          bool
+	HostAWSConfig               ClusterConfigAWSConfig
+	IgnitionPath                string
+	IncludeTags                 bool
+	IngressNLB                  ClusterConfigIngressNLB
+	InstallationName            string
+	IPAMNetworkRange            net.IPNet
+	MetadataTokensRequired      bool
+	OIDC                        ClusterConfigOIDC
+	PodInfraContainerImage      string
+	ProjectName                 string
+	PubKeyFile                  string
+	PublicRouteTables           string
+	RecoverDeleteFailed         bool
+	RecoverRollbackComplete     bool
+	RecoverUpdateRollbackFailed bool
+	RegistryDomain              string
+	Route53Enabled              bool
+	SSOPublicKey                string
+	StackDriftInterval          time.Duration
+	StackDriftRemediation       bool
+	TransitGatewayID            string
+	VaultAddress                string
+	VaultAuth                   ClusterConfigVaultAuth
+	VPCEndpointsEnabled         bool
+	VPCFlowLogs                 ClusterConfigVPCFlowLogs
+	WorkerRolloutBatchTimeout   time.Duration
+	WorkerRolloutHealthGated    bool
 }
 
 type ClusterConfigAWSConfig struct {
@@ -82,6 +105,26 @@ type ClusterConfigOIDC struct {
 	GroupsClaim   string
 }
 
+type ClusterConfigIngressNLB struct {
+	ProxyProtocol bool
+	SourceCIDRs   string
+}
+
+type ClusterConfigVaultAuth struct {
+	AppRoleRoleID       string
+	AppRoleSecretIDPath string
+	KubernetesTokenPath string
+	Method              string
+	Mount               string
+	Role                string
+}
+
+type ClusterConfigVPCFlowLogs struct {
+	Destination            string
+	MaxAggregationInterval int
+	TrafficType            string
+}
+
 // Whitelist defines guest cluster k8s API whitelisting.
 type FrameworkConfigAPIWhitelistConfig struct {
 	Enabled    bool
@@ -443,8 +486,16 @@ func newClusterResourceSets(config ClusterConfig) ([]*controller.ResourceSet, er
 
 			AccessLogsExpiration:       config.AccessLogsExpiration,
 			AdvancedMonitoringEC2:      config.AdvancedMonitoringEC2,
+			AMIOverrides:               config.AMIOverrides,
+			AMIOwner:                   config.AMIOwner,
+			ChangeSetApprovalRequired:  config.ChangeSetApprovalRequired,
 			DeleteLoggingBucket:        config.DeleteLoggingBucket,
+			EBSEncryptionKeyARN:        config.EBSEncryptionKeyARN,
 			EncrypterBackend:           config.EncrypterBackend,
+			EncryptionRekeyGracePeriod: config.EncryptionRekeyGracePeriod,
+			EncryptionRekeyInterval:    config.EncryptionRekeyInterval,
+			EtcdBackupInterval:         config.EtcdBackupInterval,
+			EtcdBackupRetention:        config.EtcdBackupRetention,
 			GuestAvailabilityZones:     config.GuestAWSConfig.AvailabilityZones,
 			GuestPrivateSubnetMaskBits: config.GuestPrivateSubnetMaskBits,
 			GuestPublicSubnetMaskBits:  config.GuestPublicSubnetMaskBits,
@@ -454,8 +505,13 @@ func newClusterResourceSets(config ClusterConfig) ([]*controller.ResourceSet, er
 			Route53Enabled:             config.Route53Enabled,
 			IgnitionPath:               config.IgnitionPath,
 			IncludeTags:                config.IncludeTags,
-			InstallationName:           config.InstallationName,
-			IPAMNetworkRange:           config.IPAMNetworkRange,
+			IngressNLB: v21adapter.IngressNLB{
+				ProxyProtocol: config.IngressNLB.ProxyProtocol,
+				SourceCIDRs:   config.IngressNLB.SourceCIDRs,
+			},
+			InstallationName:       config.InstallationName,
+			IPAMNetworkRange:       config.IPAMNetworkRange,
+			MetadataTokensRequired: config.MetadataTokensRequired,
 			OIDC: v21cloudconfig.OIDCConfig{
 				ClientID:      config.OIDC.ClientID,
 				IssuerURL:     config.OIDC.IssuerURL,
@@ -466,11 +522,33 @@ func newClusterResourceSets(config ClusterConfig) ([]*controller.ResourceSet, er
 				Enabled:    config.APIWhitelist.Enabled,
 				SubnetList: config.APIWhitelist.SubnetList,
 			},
-			ProjectName:       config.ProjectName,
-			PublicRouteTables: config.PublicRouteTables,
-			RegistryDomain:    config.RegistryDomain,
-			SSOPublicKey:      config.SSOPublicKey,
-			VaultAddress:      config.VaultAddress,
+			ProjectName:                 config.ProjectName,
+			PublicRouteTables:           config.PublicRouteTables,
+			RecoverDeleteFailed:         config.RecoverDeleteFailed,
+			RecoverRollbackComplete:     config.RecoverRollbackComplete,
+			RecoverUpdateRollbackFailed: config.RecoverUpdateRollbackFailed,
+			RegistryDomain:              config.RegistryDomain,
+			SSOPublicKey:                config.SSOPublicKey,
+			StackDriftInterval:          config.StackDriftInterval,
+			StackDriftRemediation:       config.StackDriftRemediation,
+			TransitGatewayID:            config.TransitGatewayID,
+			VaultAddress:                config.VaultAddress,
+			VaultAuth: v21vault.AuthConfig{
+				AppRoleRoleID:       config.VaultAuth.AppRoleRoleID,
+				AppRoleSecretIDPath: config.VaultAuth.AppRoleSecretIDPath,
+				KubernetesTokenPath: config.VaultAuth.KubernetesTokenPath,
+				Method:              config.VaultAuth.Method,
+				Mount:               config.VaultAuth.Mount,
+				Role:                config.VaultAuth.Role,
+			},
+			VPCEndpointsEnabled: config.VPCEndpointsEnabled,
+			VPCFlowLogs: v21adapter.VPCFlowLogs{
+				Destination:            config.VPCFlowLogs.Destination,
+				MaxAggregationInterval: config.VPCFlowLogs.MaxAggregationInterval,
+				TrafficType:            config.VPCFlowLogs.TrafficType,
+			},
+			WorkerRolloutBatchTimeout: config.WorkerRolloutBatchTimeout,
+			WorkerRolloutHealthGated:  config.WorkerRolloutHealthGated,
 		}
 
 		resourceSetV21, err = v21.NewClusterResourceSet(c)
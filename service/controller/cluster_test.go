@@ -3,6 +3,7 @@ package controller
 import (
 	"net"
 	"testing"
+	"time"
 
 	versionedfake "github.com/giantswarm/apiextensions/pkg/clientset/versioned/fake"
 	"github.com/giantswarm/micrologger/microloggertest"
@@ -23,6 +24,8 @@ func newTestClusterConfig() ClusterConfig {
 		Logger:       microloggertest.New(),
 
 		AccessLogsExpiration: 365,
+		EtcdBackupInterval:   time.Hour,
+		EtcdBackupRetention:  48,
 		GuestAWSConfig: ClusterConfigAWSConfig{
 			AccessKeyID:       "guest-key",
 			AccessKeySecret:   "guest-secret",
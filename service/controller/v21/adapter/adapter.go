@@ -31,17 +31,23 @@ import (
 )
 
 type Config struct {
-	APIWhitelist      APIWhitelist
-	CustomObject      v1alpha1.AWSConfig
-	Clients           Clients
-	EncrypterBackend  string
-	GuestAccountID    string
-	HostAccountID     string
-	HostClients       Clients
-	InstallationName  string
-	PublicRouteTables string
-	Route53Enabled    bool
-	StackState        StackState
+	APIWhitelist             APIWhitelist
+	CustomObject             v1alpha1.AWSConfig
+	Clients                  Clients
+	EncrypterBackend         string
+	GuestAccountID           string
+	HostAccountID            string
+	HostClients              Clients
+	IngressNLB               IngressNLB
+	InstallationName         string
+	MetadataTokensRequired   bool
+	PublicRouteTables        string
+	Route53Enabled           bool
+	StackState               StackState
+	TransitGatewayID         string
+	VPCEndpointsEnabled      bool
+	VPCFlowLogs              VPCFlowLogs
+	WorkerRolloutHealthGated bool
 }
 
 type Adapter struct {
@@ -68,6 +74,8 @@ func NewGuest(cfg Config) (Adapter, error) {
 		a.Guest.SecurityGroups.Adapt,
 		a.Guest.Subnets.Adapt,
 		a.Guest.VPC.Adapt,
+		a.Guest.VPCEndpoints.Adapt,
+		a.Guest.VPCFlowLogs.Adapt,
 	}
 
 	for _, h := range hydraters {
@@ -127,6 +135,8 @@ type GuestAdapter struct {
 	SecurityGroups      GuestSecurityGroupsAdapter
 	Subnets             GuestSubnetsAdapter
 	VPC                 GuestVPCAdapter
+	VPCEndpoints        GuestVPCEndpointsAdapter
+	VPCFlowLogs         GuestVPCFlowLogsAdapter
 }
 
 type HostPostAdapter struct {
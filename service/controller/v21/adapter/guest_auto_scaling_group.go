@@ -1,6 +1,7 @@
 package adapter
 
 import (
+	"fmt"
 	"strconv"
 
 	"github.com/giantswarm/microerror"
@@ -8,19 +9,45 @@ import (
 	"github.com/giantswarm/aws-operator/service/controller/v21/key"
 )
 
+// GuestAutoScalingGroupAdapter holds the worker ASG and the ASGs of the node
+// pools of the guest cluster.
 type GuestAutoScalingGroupAdapter struct {
+	GuestAutoScalingGroupAdapterGroup
+	NodePools []GuestAutoScalingGroupAdapterGroup
+}
+
+type GuestAutoScalingGroupAdapterGroup struct {
 	ASGMaxSize             int
 	ASGMinSize             int
 	ASGType                string
+	ClusterAutoscaler      bool
 	ClusterID              string
 	HealthCheckGracePeriod int
-	MaxBatchSize           string
-	MinInstancesInService  string
-	PrivateSubnets         []string
+	InstanceDistribution   GuestAutoScalingGroupAdapterInstanceDistribution
+	// LoadBalancers are the classic Elastic Load Balancers and TargetGroups are
+	// the target groups of the Network Load Balancers the ASG registers its
+	// instances with.
+	LoadBalancers         []string
+	MaxBatchSize          string
+	MinInstancesInService string
+	MixedInstances        bool
+	Name                  string
+	PrivateSubnets        []string
+	// RollingUpdate is false in case the worker instances are replaced by the
+	// operator instead of the rolling update policy of the ASG.
+	RollingUpdate          bool
 	RollingUpdatePauseTime string
+	TargetGroups           []string
 	WorkerAZs              []string
 }
 
+type GuestAutoScalingGroupAdapterInstanceDistribution struct {
+	InstanceTypes                       []string
+	OnDemandBaseCapacity                int
+	OnDemandPercentageAboveBaseCapacity int
+	SpotAllocationStrategy              string
+}
+
 func (a *GuestAutoScalingGroupAdapter) Adapt(cfg Config) error {
 	workers := key.WorkerCount(cfg.CustomObject)
 	if workers <= 0 {
@@ -40,14 +67,71 @@ func (a *GuestAutoScalingGroupAdapter) Adapt(cfg Config) error {
 	a.ClusterID = key.ClusterID(cfg.CustomObject)
 	a.MaxBatchSize = workerCountRatio(workers, asgMaxBatchSizeRatio)
 	a.MinInstancesInService = workerCountRatio(workers, asgMinInstancesRatio)
+
+	// In case the worker nodes are scaled by the cluster autoscaler the worker
+	// ASG is bound by the scaling bounds of the cluster. The desired capacity is
+	// then left to the cluster autoscaler.
+	if cfg.StackState.WorkerMaxSize > 0 {
+		a.ASGMaxSize = cfg.StackState.WorkerMaxSize
+		a.ASGMinSize = cfg.StackState.WorkerMinSize
+		a.ClusterAutoscaler = true
+		a.MaxBatchSize = workerCountRatio(cfg.StackState.WorkerMinSize, asgMaxBatchSizeRatio)
+		a.MinInstancesInService = workerCountRatio(cfg.StackState.WorkerMinSize, asgMinInstancesRatio)
+	}
 	a.HealthCheckGracePeriod = gracePeriodSeconds
+	a.Name = key.KindWorker
+	a.RollingUpdate = !cfg.WorkerRolloutHealthGated
 	a.RollingUpdatePauseTime = rollingUpdatePauseTime
 
+	if key.IngressLoadBalancerType(cfg.CustomObject) == key.LoadBalancerTypeNetwork {
+		for _, p := range ingressPortPairs(cfg.CustomObject) {
+			a.TargetGroups = append(a.TargetGroups, p.TargetGroupResourceName)
+		}
+	} else {
+		a.LoadBalancers = []string{ingressLoadBalancerResourceName}
+	}
+
 	for i, az := range key.StatusAvailabilityZones(cfg.CustomObject) {
 		a.PrivateSubnets = append(a.PrivateSubnets, key.PrivateSubnetName(i))
 		a.WorkerAZs = append(a.WorkerAZs, az.Name)
 	}
 
+	if len(cfg.StackState.WorkerInstanceTypes) > 0 {
+		a.MixedInstances = true
+		a.InstanceDistribution.InstanceTypes = cfg.StackState.WorkerInstanceTypes
+		a.InstanceDistribution.OnDemandBaseCapacity = cfg.StackState.WorkerOnDemandBaseCapacity
+		a.InstanceDistribution.OnDemandPercentageAboveBaseCapacity = 100 - cfg.StackState.WorkerSpotPercentage
+		a.InstanceDistribution.SpotAllocationStrategy = cfg.StackState.WorkerSpotAllocationStrategy
+	}
+
+	for _, p := range cfg.StackState.NodePools {
+		g := GuestAutoScalingGroupAdapterGroup{
+			ASGMaxSize:             p.MaxSize,
+			ASGMinSize:             p.MinSize,
+			ASGType:                key.NodePoolASGType(p.Name),
+			ClusterAutoscaler:      a.ClusterAutoscaler,
+			ClusterID:              a.ClusterID,
+			HealthCheckGracePeriod: gracePeriodSeconds,
+			LoadBalancers:          a.LoadBalancers,
+			MaxBatchSize:           workerCountRatio(p.MinSize, asgMaxBatchSizeRatio),
+			MinInstancesInService:  workerCountRatio(p.MinSize, asgMinInstancesRatio),
+			Name:                   fmt.Sprintf("%s-%s", key.KindWorker, p.Name),
+			PrivateSubnets:         a.PrivateSubnets,
+			RollingUpdate:          a.RollingUpdate,
+			RollingUpdatePauseTime: rollingUpdatePauseTime,
+			TargetGroups:           a.TargetGroups,
+			WorkerAZs:              a.WorkerAZs,
+		}
+
+		// Node pools may be scaled down to zero worker nodes. There cannot be
+		// any instance in service during rolling updates in this case.
+		if p.MinSize == 0 {
+			g.MinInstancesInService = "0"
+		}
+
+		a.NodePools = append(a.NodePools, g)
+	}
+
 	return nil
 }
 
@@ -187,6 +187,230 @@ func TestAdapterAutoScalingGroupRegularFields(t *testing.T) {
 	}
 }
 
+func TestAdapterAutoScalingGroupInstanceDistribution(t *testing.T) {
+	t.Parallel()
+	customObject := v1alpha1.AWSConfig{
+		Spec: v1alpha1.AWSConfigSpec{
+			Cluster: defaultCluster,
+			AWS: v1alpha1.AWSConfigSpecAWS{
+				AZ: "myaz",
+				Workers: []v1alpha1.AWSConfigSpecAWSNode{
+					{},
+				},
+			},
+		},
+		Status: v1alpha1.AWSConfigStatus{
+			AWS: v1alpha1.AWSConfigStatusAWS{
+				AvailabilityZones: []v1alpha1.AWSConfigStatusAWSAvailabilityZone{
+					{
+						Name: "myaz",
+					},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		description                  string
+		stackState                   StackState
+		expectedMixedInstances       bool
+		expectedInstanceDistribution GuestAutoScalingGroupAdapterInstanceDistribution
+	}{
+		{
+			description:            "no instance types, launch on-demand workers",
+			stackState:             StackState{},
+			expectedMixedInstances: false,
+		},
+		{
+			description: "instance types and spot percentage, launch mixed workers",
+			stackState: StackState{
+				WorkerInstanceTypes:          []string{"m5.large", "m4.large"},
+				WorkerOnDemandBaseCapacity:   2,
+				WorkerSpotAllocationStrategy: "lowest-price",
+				WorkerSpotPercentage:         60,
+			},
+			expectedMixedInstances: true,
+			expectedInstanceDistribution: GuestAutoScalingGroupAdapterInstanceDistribution{
+				InstanceTypes:                       []string{"m5.large", "m4.large"},
+				OnDemandBaseCapacity:                2,
+				OnDemandPercentageAboveBaseCapacity: 40,
+				SpotAllocationStrategy:              "lowest-price",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		a := Adapter{}
+		t.Run(tc.description, func(t *testing.T) {
+			cfg := Config{
+				CustomObject: customObject,
+				StackState:   tc.stackState,
+			}
+			err := a.Guest.AutoScalingGroup.Adapt(cfg)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			if a.Guest.AutoScalingGroup.MixedInstances != tc.expectedMixedInstances {
+				t.Errorf("unexpected output, got %t, want %t", a.Guest.AutoScalingGroup.MixedInstances, tc.expectedMixedInstances)
+			}
+			if !reflect.DeepEqual(a.Guest.AutoScalingGroup.InstanceDistribution, tc.expectedInstanceDistribution) {
+				t.Errorf("unexpected output, got %#v, want %#v", a.Guest.AutoScalingGroup.InstanceDistribution, tc.expectedInstanceDistribution)
+			}
+		})
+	}
+}
+
+func TestAdapterAutoScalingGroupClusterAutoscaler(t *testing.T) {
+	t.Parallel()
+	customObject := v1alpha1.AWSConfig{
+		Spec: v1alpha1.AWSConfigSpec{
+			Cluster: defaultCluster,
+			AWS: v1alpha1.AWSConfigSpecAWS{
+				AZ: "myaz",
+				Workers: []v1alpha1.AWSConfigSpecAWSNode{
+					{},
+					{},
+				},
+			},
+		},
+		Status: v1alpha1.AWSConfigStatus{
+			AWS: v1alpha1.AWSConfigStatusAWS{
+				AvailabilityZones: []v1alpha1.AWSConfigStatusAWSAvailabilityZone{
+					{
+						Name: "myaz",
+					},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		description               string
+		stackState                StackState
+		expectedClusterAutoscaler bool
+		expectedASGMaxSize        int
+		expectedASGMinSize        int
+	}{
+		{
+			description:               "no scaling bounds, scale to the number of workers",
+			stackState:                StackState{},
+			expectedClusterAutoscaler: false,
+			expectedASGMaxSize:        3,
+			expectedASGMinSize:        2,
+		},
+		{
+			description: "scaling bounds, scale within the bounds",
+			stackState: StackState{
+				WorkerMaxSize: 10,
+				WorkerMinSize: 3,
+			},
+			expectedClusterAutoscaler: true,
+			expectedASGMaxSize:        10,
+			expectedASGMinSize:        3,
+		},
+	}
+
+	for _, tc := range testCases {
+		a := Adapter{}
+		t.Run(tc.description, func(t *testing.T) {
+			cfg := Config{
+				CustomObject: customObject,
+				StackState:   tc.stackState,
+			}
+			err := a.Guest.AutoScalingGroup.Adapt(cfg)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			if a.Guest.AutoScalingGroup.ClusterAutoscaler != tc.expectedClusterAutoscaler {
+				t.Errorf("unexpected output, got %t, want %t", a.Guest.AutoScalingGroup.ClusterAutoscaler, tc.expectedClusterAutoscaler)
+			}
+			if a.Guest.AutoScalingGroup.ASGMaxSize != tc.expectedASGMaxSize {
+				t.Errorf("unexpected output, got %d, want %d", a.Guest.AutoScalingGroup.ASGMaxSize, tc.expectedASGMaxSize)
+			}
+			if a.Guest.AutoScalingGroup.ASGMinSize != tc.expectedASGMinSize {
+				t.Errorf("unexpected output, got %d, want %d", a.Guest.AutoScalingGroup.ASGMinSize, tc.expectedASGMinSize)
+			}
+		})
+	}
+}
+
+func TestAdapterAutoScalingGroupNodePools(t *testing.T) {
+	t.Parallel()
+	customObject := v1alpha1.AWSConfig{
+		Spec: v1alpha1.AWSConfigSpec{
+			Cluster: defaultCluster,
+			AWS: v1alpha1.AWSConfigSpecAWS{
+				AZ: "myaz",
+				Workers: []v1alpha1.AWSConfigSpecAWSNode{
+					{},
+				},
+			},
+		},
+		Status: v1alpha1.AWSConfigStatus{
+			AWS: v1alpha1.AWSConfigStatusAWS{
+				AvailabilityZones: []v1alpha1.AWSConfigStatusAWSAvailabilityZone{
+					{
+						Name: "myaz",
+					},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		description                   string
+		nodePools                     []StackStateNodePool
+		expectedASGTypes              []string
+		expectedMinInstancesInService []string
+	}{
+		{
+			description: "no node pools",
+			nodePools:   nil,
+		},
+		{
+			description: "two node pools",
+			nodePools: []StackStateNodePool{
+				{Name: "gpu", InstanceType: "p3.2xlarge", MaxSize: 2, MinSize: 0},
+				{Name: "memory2", InstanceType: "r5.xlarge", MaxSize: 6, MinSize: 5},
+			},
+			expectedASGTypes:              []string{"nodePoolGpu", "nodePoolMemory2"},
+			expectedMinInstancesInService: []string{"0", "4"},
+		},
+	}
+
+	for _, tc := range testCases {
+		a := Adapter{}
+		t.Run(tc.description, func(t *testing.T) {
+			cfg := Config{
+				CustomObject: customObject,
+				StackState: StackState{
+					NodePools: tc.nodePools,
+				},
+			}
+			err := a.Guest.AutoScalingGroup.Adapt(cfg)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			var asgTypes []string
+			var minInstancesInService []string
+			for _, p := range a.Guest.AutoScalingGroup.NodePools {
+				asgTypes = append(asgTypes, p.ASGType)
+				minInstancesInService = append(minInstancesInService, p.MinInstancesInService)
+			}
+
+			if !reflect.DeepEqual(asgTypes, tc.expectedASGTypes) {
+				t.Errorf("unexpected output, got %q, want %q", asgTypes, tc.expectedASGTypes)
+			}
+			if !reflect.DeepEqual(minInstancesInService, tc.expectedMinInstancesInService) {
+				t.Errorf("unexpected output, got %q, want %q", minInstancesInService, tc.expectedMinInstancesInService)
+			}
+		})
+	}
+}
+
 func TestWorkerCountRatioMaxBatchSize(t *testing.T) {
 	t.Parallel()
 	tcs := []struct {
@@ -220,3 +444,72 @@ func TestWorkerCountRatioMaxBatchSize(t *testing.T) {
 		})
 	}
 }
+
+func TestAdapterAutoScalingGroupWorkerRolloutHealthGated(t *testing.T) {
+	t.Parallel()
+	customObject := v1alpha1.AWSConfig{
+		Spec: v1alpha1.AWSConfigSpec{
+			Cluster: defaultCluster,
+			AWS: v1alpha1.AWSConfigSpecAWS{
+				AZ: "myaz",
+				Workers: []v1alpha1.AWSConfigSpecAWSNode{
+					{},
+				},
+			},
+		},
+		Status: v1alpha1.AWSConfigStatus{
+			AWS: v1alpha1.AWSConfigStatusAWS{
+				AvailabilityZones: []v1alpha1.AWSConfigStatusAWSAvailabilityZone{
+					{
+						Name: "myaz",
+					},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		description           string
+		healthGated           bool
+		expectedRollingUpdate bool
+	}{
+		{
+			description:           "rolling update policy of the ASG",
+			healthGated:           false,
+			expectedRollingUpdate: true,
+		},
+		{
+			description:           "health gated worker rollout",
+			healthGated:           true,
+			expectedRollingUpdate: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		a := Adapter{}
+		t.Run(tc.description, func(t *testing.T) {
+			cfg := Config{
+				CustomObject: customObject,
+				StackState: StackState{
+					NodePools: []StackStateNodePool{
+						{Name: "gpu", InstanceType: "p3.2xlarge", MaxSize: 2, MinSize: 0},
+					},
+				},
+				WorkerRolloutHealthGated: tc.healthGated,
+			}
+			err := a.Guest.AutoScalingGroup.Adapt(cfg)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			if a.Guest.AutoScalingGroup.RollingUpdate != tc.expectedRollingUpdate {
+				t.Errorf("expected rolling update %t got %t", tc.expectedRollingUpdate, a.Guest.AutoScalingGroup.RollingUpdate)
+			}
+			for _, p := range a.Guest.AutoScalingGroup.NodePools {
+				if p.RollingUpdate != tc.expectedRollingUpdate {
+					t.Errorf("expected rolling update %t for node pool %q got %t", tc.expectedRollingUpdate, p.Name, p.RollingUpdate)
+				}
+			}
+		})
+	}
+}
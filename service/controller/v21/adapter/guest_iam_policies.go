@@ -12,14 +12,17 @@ import (
 )
 
 type GuestIAMPoliciesAdapter struct {
+	ClusterAutoscaler bool
 	ClusterID         string
 	EC2ServiceDomain  string
+	EtcdBackupPrefix  string
 	KMSKeyARN         string
 	MasterRoleName    string
 	MasterPolicyName  string
 	MasterProfileName string
 	RegionARN         string
 	S3Bucket          string
+	SSMParameterARN   string
 	WorkerRoleName    string
 	WorkerPolicyName  string
 	WorkerProfileName string
@@ -28,8 +31,10 @@ type GuestIAMPoliciesAdapter struct {
 func (i *GuestIAMPoliciesAdapter) Adapt(cfg Config) error {
 	clusterID := key.ClusterID(cfg.CustomObject)
 
+	i.ClusterAutoscaler = key.ClusterAutoscalerEnabled(cfg.CustomObject)
 	i.ClusterID = clusterID
 	i.EC2ServiceDomain = key.EC2ServiceDomain(cfg.CustomObject)
+	i.EtcdBackupPrefix = key.EtcdBackupPrefix
 	i.MasterPolicyName = key.PolicyName(cfg.CustomObject, key.KindMaster)
 	i.MasterProfileName = key.InstanceProfileName(cfg.CustomObject, key.KindMaster)
 	i.MasterRoleName = key.RoleName(cfg.CustomObject, key.KindMaster)
@@ -39,17 +44,11 @@ func (i *GuestIAMPoliciesAdapter) Adapt(cfg Config) error {
 	i.RegionARN = key.RegionARN(cfg.CustomObject)
 
 	// KMSKeyARN
-	if cfg.EncrypterBackend == encrypter.KMSBackend {
-		keyAlias := fmt.Sprintf("alias/%s", clusterID)
-		input := &kms.DescribeKeyInput{
-			KeyId: aws.String(keyAlias),
-		}
-		output, err := cfg.Clients.KMS.DescribeKey(input)
-		if err != nil {
-			return microerror.Mask(err)
-		}
-		i.KMSKeyARN = *output.KeyMetadata.Arn
+	kmsKeyARN, err := clusterKMSKeyARN(cfg)
+	if err != nil {
+		return microerror.Mask(err)
 	}
+	i.KMSKeyARN = kmsKeyARN
 
 	// S3Bucket
 	accountID, err := AccountID(cfg.Clients)
@@ -58,5 +57,29 @@ func (i *GuestIAMPoliciesAdapter) Adapt(cfg Config) error {
 	}
 	i.S3Bucket = key.BucketName(cfg.CustomObject, accountID)
 
+	// SSMParameterARN
+	if cfg.EncrypterBackend == encrypter.SSMBackend {
+		i.SSMParameterARN = fmt.Sprintf("arn:%s:ssm:%s:%s:parameter%s/*", i.RegionARN, key.Region(cfg.CustomObject), accountID, key.SSMParameterPrefix(cfg.CustomObject))
+	}
+
 	return nil
 }
+
+// clusterKMSKeyARN returns the ARN of the KMS key the assets of the guest
+// cluster are encrypted with. It is empty in case the assets are not encrypted
+// with KMS.
+func clusterKMSKeyARN(cfg Config) (string, error) {
+	if cfg.EncrypterBackend != encrypter.KMSBackend && cfg.EncrypterBackend != encrypter.SSMBackend {
+		return "", nil
+	}
+
+	input := &kms.DescribeKeyInput{
+		KeyId: aws.String(fmt.Sprintf("alias/%s", key.ClusterID(cfg.CustomObject))),
+	}
+	output, err := cfg.Clients.KMS.DescribeKey(input)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return *output.KeyMetadata.Arn, nil
+}
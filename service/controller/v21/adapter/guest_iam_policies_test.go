@@ -117,6 +117,16 @@ func TestAdapterIamPoliciesKMSKeyARN(t *testing.T) {
 			expectedKMSKeyARN: "",
 			encrypterBackend:  "vault",
 		},
+		{
+			description: "ssm backend",
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					Cluster: defaultCluster,
+				},
+			},
+			expectedKMSKeyARN: "alias/test-cluster",
+			encrypterBackend:  "ssm",
+		},
 	}
 	for _, tc := range testCases {
 		a := Adapter{}
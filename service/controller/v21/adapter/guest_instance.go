@@ -2,7 +2,9 @@ package adapter
 
 import (
 	"encoding/base64"
+	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/giantswarm/microerror"
 
@@ -12,35 +14,40 @@ import (
 
 type GuestInstanceAdapter struct {
 	Cluster GuestInstanceAdapterCluster
-	Image   GuestInstanceAdapterImage
-	Master  GuestInstanceAdapterMaster
+	Masters []GuestInstanceAdapterMaster
 }
 
 type GuestInstanceAdapterCluster struct {
 	ID string
 }
 
-type GuestInstanceAdapterImage struct {
-	ID string
-}
-
 type GuestInstanceAdapterMaster struct {
 	AZ               string
 	CloudConfig      string
 	EncrypterBackend string
 	DockerVolume     GuestInstanceAdapterMasterDockerVolume
 	EtcdVolume       GuestInstanceAdapterMasterEtcdVolume
+	ImageID          string
 	Instance         GuestInstanceAdapterMasterInstance
 	PrivateSubnet    string
+	RootVolume       GuestInstanceAdapterMasterRootVolume
 }
 
 type GuestInstanceAdapterMasterDockerVolume struct {
-	Name         string
-	ResourceName string
+	EncryptionKeyARN string
+	Name             string
+	ResourceName     string
 }
 
 type GuestInstanceAdapterMasterEtcdVolume struct {
-	Name string
+	EncryptionKeyARN string
+	Name             string
+	ResourceName     string
+}
+
+type GuestInstanceAdapterMasterRootVolume struct {
+	DeviceName       string
+	EncryptionKeyARN string
 }
 
 type GuestInstanceAdapterMasterInstance struct {
@@ -54,10 +61,6 @@ func (i *GuestInstanceAdapter) Adapt(config Config) error {
 		i.Cluster.ID = key.ClusterID(config.CustomObject)
 	}
 
-	{
-		i.Image.ID = config.StackState.MasterImageID
-	}
-
 	{
 		zones := key.StatusAvailabilityZones(config.CustomObject)
 		sort.Slice(zones, func(i, j int) bool {
@@ -68,36 +71,179 @@ func (i *GuestInstanceAdapter) Adapt(config Config) error {
 			return microerror.Maskf(notFoundError, "CustomObject has no availability zones")
 		}
 
-		i.Master.AZ = zones[0].Name
-		i.Master.PrivateSubnet = key.PrivateSubnetName(0)
+		masterCount := masterCount(config)
+		if len(zones) < masterCount {
+			return microerror.Maskf(notFoundError, "CustomObject has %d availability zones for %d masters", len(zones), masterCount)
+		}
 
 		accountID, err := AccountID(config.Clients)
 		if err != nil {
 			return microerror.Mask(err)
 		}
-		c := SmallCloudconfigConfig{
-			S3URL: key.SmallCloudConfigS3URL(config.CustomObject, accountID, key.KindMaster),
+
+		for idx := 0; idx < masterCount; idx++ {
+			var master GuestInstanceAdapterMaster
+
+			state := masterState(config, idx)
+
+			master.AZ = zones[idx].Name
+			master.PrivateSubnet = key.PrivateSubnetName(idx)
+
+			c := SmallCloudconfigConfig{
+				S3URL: key.SmallCloudConfigS3URL(config.CustomObject, accountID, key.KindMaster),
+			}
+			// Masters of a highly available control plane not replaced yet keep
+			// the cloudconfig they were launched with. Changing it would replace
+			// them all at once.
+			if idx < len(config.StackState.Masters) {
+				c.S3URL = key.MasterSmallCloudConfigS3URL(config.CustomObject, accountID, state.VersionBundleVersion, state.CloudConfigVersion)
+			}
+			// Masters of a highly available control plane share the same
+			// cloudconfig in S3. The only thing distinguishing them is the
+			// configuration of their etcd member, which is why it is written by
+			// the small cloudconfig of each master. The same goes for the member
+			// restored from an etcd snapshot.
+			if masterCount > 1 {
+				f, err := etcdClusterMemberFile(config, idx)
+				if err != nil {
+					return microerror.Mask(err)
+				}
+				c.Files = append(c.Files, f)
+
+				f, err = etcdRestoreEnvironmentFile(config, idx)
+				if err != nil {
+					return microerror.Mask(err)
+				}
+				c.Files = append(c.Files, f)
+			}
+			rendered, err := templates.Render(key.CloudConfigSmallTemplates(), c)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+			master.CloudConfig = base64.StdEncoding.EncodeToString([]byte(rendered))
+
+			master.EncrypterBackend = config.EncrypterBackend
+
+			// All masters use the same volume names so the EBS service finds the
+			// volumes of all masters. Updates only detach the volumes attached to
+			// the master being replaced.
+			master.DockerVolume.EncryptionKeyARN = state.VolumeEncryptionKeyARN
+
+			master.DockerVolume.Name = key.DockerVolumeName(config.CustomObject)
+
+			master.DockerVolume.ResourceName = state.DockerVolumeResourceName
+
+			// The etcd volumes of guest clusters created before all EBS volumes got
+			// encrypted keep their encryption, see EtcdVolumeEncryptionKeyARN.
+			master.EtcdVolume.EncryptionKeyARN = config.StackState.EtcdVolumeEncryptionKeyARN
+
+			master.EtcdVolume.Name = key.EtcdVolumeName(config.CustomObject)
+
+			master.EtcdVolume.ResourceName = key.EtcdVolumeResourceName(idx)
+
+			master.ImageID = state.ImageID
+
+			master.Instance.ResourceName = state.InstanceResourceName
+
+			master.Instance.Type = state.InstanceType
+
+			master.Instance.Monitoring = config.StackState.MasterInstanceMonitoring
+
+			master.RootVolume.DeviceName = rootVolumeDeviceName
+
+			master.RootVolume.EncryptionKeyARN = state.VolumeEncryptionKeyARN
+
+			i.Masters = append(i.Masters, master)
 		}
-		rendered, err := templates.Render(key.CloudConfigSmallTemplates(), c)
-		if err != nil {
-			return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// etcdClusterMemberFile returns the systemd drop-in configuring the etcd member
+// of the master with the given index.
+func etcdClusterMemberFile(config Config, idx int) (SmallCloudconfigFile, error) {
+	c := EtcdClusterMemberConfig{
+		ClientDomain:   key.EtcdDomain(config.CustomObject),
+		ClientPort:     key.EtcdPort(config.CustomObject),
+		InitialCluster: key.EtcdInitialCluster(config.CustomObject, masterCount(config)),
+		Name:           key.EtcdMemberName(idx),
+		PeerDomain:     key.EtcdMemberDomain(config.CustomObject, idx),
+		PeerPort:       key.EtcdPeerPort(config.CustomObject),
+	}
+	rendered, err := templates.Render(key.CloudConfigEtcdClusterMemberTemplates(), c)
+	if err != nil {
+		return SmallCloudconfigFile{}, microerror.Mask(err)
+	}
+
+	f := SmallCloudconfigFile{
+		Path:   key.EtcdClusterMemberDropInPath,
+		Source: "data:text/plain;charset=utf-8;base64," + base64.StdEncoding.EncodeToString([]byte(rendered)),
+	}
+
+	return f, nil
+}
+
+// etcdRestoreEnvironmentFile returns the environment file telling the etcd
+// restore of the master with the given index which member it restores and which
+// peers it has to check before restoring a snapshot.
+func etcdRestoreEnvironmentFile(config Config, idx int) (SmallCloudconfigFile, error) {
+	var peerEndpoints []string
+	for i := 0; i < masterCount(config); i++ {
+		if i == idx {
+			continue
 		}
-		i.Master.CloudConfig = base64.StdEncoding.EncodeToString([]byte(rendered))
+		peerEndpoints = append(peerEndpoints, fmt.Sprintf("https://%s:%d", key.EtcdMemberDomain(config.CustomObject, i), key.EtcdPort(config.CustomObject)))
+	}
 
-		i.Master.EncrypterBackend = config.EncrypterBackend
+	c := EtcdRestoreEnvironmentConfig{
+		InitialCluster: key.EtcdInitialCluster(config.CustomObject, masterCount(config)),
+		Name:           key.EtcdMemberName(idx),
+		PeerEndpoints:  strings.Join(peerEndpoints, " "),
+		PeerURL:        fmt.Sprintf("https://%s:%d", key.EtcdMemberDomain(config.CustomObject, idx), key.EtcdPeerPort(config.CustomObject)),
+	}
+	rendered, err := templates.Render(key.CloudConfigEtcdRestoreEnvironmentTemplates(), c)
+	if err != nil {
+		return SmallCloudconfigFile{}, microerror.Mask(err)
+	}
 
-		i.Master.DockerVolume.Name = key.DockerVolumeName(config.CustomObject)
+	f := SmallCloudconfigFile{
+		Path:   key.EtcdRestoreEnvironmentPath,
+		Source: "data:text/plain;charset=utf-8;base64," + base64.StdEncoding.EncodeToString([]byte(rendered)),
+	}
 
-		i.Master.DockerVolume.ResourceName = config.StackState.DockerVolumeResourceName
+	return f, nil
+}
 
-		i.Master.EtcdVolume.Name = key.EtcdVolumeName(config.CustomObject)
+// masterCount returns the number of masters of the guest cluster. Stack states
+// without master count refer to guest clusters running a single master.
+func masterCount(config Config) int {
+	if config.StackState.MasterCount < 1 {
+		return 1
+	}
 
-		i.Master.Instance.ResourceName = config.StackState.MasterInstanceResourceName
+	return config.StackState.MasterCount
+}
 
-		i.Master.Instance.Type = config.StackState.MasterInstanceType
+// masterState returns the state of the master with the given index. Stack
+// states without state of the individual masters refer to guest clusters
+// running a single master or to guest clusters whose masters all got launched
+// with the same configuration, which is the one of the guest cluster.
+func masterState(config Config, idx int) StackStateMaster {
+	if idx < len(config.StackState.Masters) {
+		return config.StackState.Masters[idx]
+	}
 
-		i.Master.Instance.Monitoring = config.StackState.MasterInstanceMonitoring
+	m := StackStateMaster{
+		CloudConfigVersion:       config.StackState.MasterCloudConfigVersion,
+		DockerVolumeResourceName: key.MasterResourceName(config.StackState.DockerVolumeResourceName, idx),
+		EncryptionKeyID:          config.StackState.EncryptionKeyID,
+		ImageID:                  config.StackState.MasterImageID,
+		InstanceResourceName:     key.MasterResourceName(config.StackState.MasterInstanceResourceName, idx),
+		InstanceType:             config.StackState.MasterInstanceType,
+		VersionBundleVersion:     config.StackState.VersionBundleVersion,
+		VolumeEncryptionKeyARN:   config.StackState.VolumeEncryptionKeyARN,
 	}
 
-	return nil
+	return m
 }
@@ -2,6 +2,7 @@ package adapter
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"testing"
@@ -70,20 +71,24 @@ func Test_Adapter_Instance_RegularFields(t *testing.T) {
 				t.Fatal("expected", nil, "got", err)
 			}
 
-			if a.Master.AZ != tc.ExpectedAZ {
-				t.Fatalf("unexpected a.Master.AZ, got %q, want %q", a.Master.AZ, tc.ExpectedAZ)
+			if len(a.Masters) != 1 {
+				t.Fatalf("unexpected len(a.Masters), got %d, want %d", len(a.Masters), 1)
 			}
 
-			if a.Master.EtcdVolume.Name != tc.ExpectedEtcdVolumeName {
-				t.Fatalf("unexpected a.Master.EtcdVolume.Name, got %q, want %q", a.Master.EtcdVolume.Name, tc.ExpectedEtcdVolumeName)
+			if a.Masters[0].AZ != tc.ExpectedAZ {
+				t.Fatalf("unexpected a.Masters[0].AZ, got %q, want %q", a.Masters[0].AZ, tc.ExpectedAZ)
 			}
 
-			if a.Master.Instance.Type != tc.ExpectedInstanceType {
-				t.Fatalf("unexpected a.Master.Instance.Type, got %q, want %q", a.Master.Instance.Type, tc.ExpectedInstanceType)
+			if a.Masters[0].EtcdVolume.Name != tc.ExpectedEtcdVolumeName {
+				t.Fatalf("unexpected a.Masters[0].EtcdVolume.Name, got %q, want %q", a.Masters[0].EtcdVolume.Name, tc.ExpectedEtcdVolumeName)
 			}
 
-			if a.Master.EncrypterBackend != tc.ExpectedEncrypterBackend {
-				t.Fatalf("unexpected a.Master.Instance.Type, got %q, want %q", a.Master.EncrypterBackend, tc.ExpectedEncrypterBackend)
+			if a.Masters[0].Instance.Type != tc.ExpectedInstanceType {
+				t.Fatalf("unexpected a.Masters[0].Instance.Type, got %q, want %q", a.Masters[0].Instance.Type, tc.ExpectedInstanceType)
+			}
+
+			if a.Masters[0].EncrypterBackend != tc.ExpectedEncrypterBackend {
+				t.Fatalf("unexpected a.Masters[0].Instance.Type, got %q, want %q", a.Masters[0].EncrypterBackend, tc.ExpectedEncrypterBackend)
 			}
 		})
 	}
@@ -153,9 +158,9 @@ func Test_Adapter_Instance_SmallCloudConfig(t *testing.T) {
 				t.Fatalf("unexpected error %v", err)
 			}
 
-			data, err := base64.StdEncoding.DecodeString(a.Master.CloudConfig)
+			data, err := base64.StdEncoding.DecodeString(a.Masters[0].CloudConfig)
 			if err != nil {
-				t.Fatalf("unexpected error decoding a.Master.CloudConfig %v", err)
+				t.Fatalf("unexpected error decoding a.Masters[0].CloudConfig %v", err)
 			}
 
 			if !strings.Contains(string(data), tc.ExpectedLine) {
@@ -164,3 +169,177 @@ func Test_Adapter_Instance_SmallCloudConfig(t *testing.T) {
 		})
 	}
 }
+
+func Test_Adapter_Instance_HighlyAvailable(t *testing.T) {
+	t.Parallel()
+
+	clients := Clients{
+		EC2: &EC2ClientMock{},
+		IAM: &IAMClientMock{},
+		STS: &STSClientMock{accountID: "000000000000"},
+	}
+	customObject := v1alpha1.AWSConfig{
+		Spec: v1alpha1.AWSConfigSpec{
+			Cluster: v1alpha1.Cluster{
+				ID: "test-cluster",
+			},
+			AWS: v1alpha1.AWSConfigSpecAWS{
+				HostedZones: v1alpha1.AWSConfigSpecAWSHostedZones{
+					API: v1alpha1.AWSConfigSpecAWSHostedZonesZone{
+						Name: "installation.eu-central-1.aws.gigantic.io",
+					},
+				},
+				Region: "eu-central-1",
+			},
+		},
+		Status: v1alpha1.AWSConfigStatus{
+			AWS: v1alpha1.AWSConfigStatusAWS{
+				AvailabilityZones: []v1alpha1.AWSConfigStatusAWSAvailabilityZone{
+					{Name: "eu-central-1c"},
+					{Name: "eu-central-1a"},
+					{Name: "eu-central-1b"},
+				},
+			},
+		},
+	}
+	cfg := Config{
+		Clients:      clients,
+		CustomObject: customObject,
+		StackState: StackState{
+			DockerVolumeResourceName:   "DockerVolumeTESTCLUSTER12345",
+			MasterCount:                3,
+			MasterInstanceResourceName: "MasterInstanceTESTCLUSTER12345",
+		},
+	}
+
+	a := &GuestInstanceAdapter{}
+	err := a.Adapt(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	expectedMasters := []struct {
+		AZ                       string
+		DockerVolumeResourceName string
+		EtcdMemberName           string
+		EtcdVolumeResourceName   string
+		InstanceResourceName     string
+		PrivateSubnet            string
+	}{
+		{
+			AZ:                       "eu-central-1a",
+			DockerVolumeResourceName: "DockerVolumeTESTCLUSTER12345",
+			EtcdMemberName:           "etcd0",
+			EtcdVolumeResourceName:   "EtcdVolume",
+			InstanceResourceName:     "MasterInstanceTESTCLUSTER12345",
+			PrivateSubnet:            "PrivateSubnet",
+		},
+		{
+			AZ:                       "eu-central-1b",
+			DockerVolumeResourceName: "DockerVolumeTESTCLUSTER1234501",
+			EtcdMemberName:           "etcd1",
+			EtcdVolumeResourceName:   "EtcdVolume01",
+			InstanceResourceName:     "MasterInstanceTESTCLUSTER1234501",
+			PrivateSubnet:            "PrivateSubnet01",
+		},
+		{
+			AZ:                       "eu-central-1c",
+			DockerVolumeResourceName: "DockerVolumeTESTCLUSTER1234502",
+			EtcdMemberName:           "etcd2",
+			EtcdVolumeResourceName:   "EtcdVolume02",
+			InstanceResourceName:     "MasterInstanceTESTCLUSTER1234502",
+			PrivateSubnet:            "PrivateSubnet02",
+		},
+	}
+
+	if len(a.Masters) != len(expectedMasters) {
+		t.Fatalf("unexpected len(a.Masters), got %d, want %d", len(a.Masters), len(expectedMasters))
+	}
+
+	for i, e := range expectedMasters {
+		m := a.Masters[i]
+
+		if m.AZ != e.AZ {
+			t.Fatalf("unexpected a.Masters[%d].AZ, got %q, want %q", i, m.AZ, e.AZ)
+		}
+		if m.DockerVolume.ResourceName != e.DockerVolumeResourceName {
+			t.Fatalf("unexpected a.Masters[%d].DockerVolume.ResourceName, got %q, want %q", i, m.DockerVolume.ResourceName, e.DockerVolumeResourceName)
+		}
+		if m.EtcdVolume.ResourceName != e.EtcdVolumeResourceName {
+			t.Fatalf("unexpected a.Masters[%d].EtcdVolume.ResourceName, got %q, want %q", i, m.EtcdVolume.ResourceName, e.EtcdVolumeResourceName)
+		}
+		if m.Instance.ResourceName != e.InstanceResourceName {
+			t.Fatalf("unexpected a.Masters[%d].Instance.ResourceName, got %q, want %q", i, m.Instance.ResourceName, e.InstanceResourceName)
+		}
+		if m.PrivateSubnet != e.PrivateSubnet {
+			t.Fatalf("unexpected a.Masters[%d].PrivateSubnet, got %q, want %q", i, m.PrivateSubnet, e.PrivateSubnet)
+		}
+
+		data, err := base64.StdEncoding.DecodeString(m.CloudConfig)
+		if err != nil {
+			t.Fatalf("unexpected error decoding a.Masters[%d].CloudConfig %v", i, err)
+		}
+
+		var ignition struct {
+			Storage struct {
+				Files []struct {
+					Path     string `json:"path"`
+					Contents struct {
+						Source string `json:"source"`
+					} `json:"contents"`
+				} `json:"files"`
+			} `json:"storage"`
+		}
+		err = json.Unmarshal(data, &ignition)
+		if err != nil {
+			t.Fatalf("unexpected error parsing a.Masters[%d].CloudConfig %v, complete: %q", i, err, string(data))
+		}
+		if len(ignition.Storage.Files) != 2 {
+			t.Fatalf("unexpected number of files in a.Masters[%d].CloudConfig, got %d, want %d", i, len(ignition.Storage.Files), 2)
+		}
+		if ignition.Storage.Files[0].Path != key.EtcdClusterMemberDropInPath {
+			t.Fatalf("unexpected file path in a.Masters[%d].CloudConfig, got %q, want %q", i, ignition.Storage.Files[0].Path, key.EtcdClusterMemberDropInPath)
+		}
+
+		source := strings.TrimPrefix(ignition.Storage.Files[0].Contents.Source, "data:text/plain;charset=utf-8;base64,")
+		dropIn, err := base64.StdEncoding.DecodeString(source)
+		if err != nil {
+			t.Fatalf("unexpected error decoding etcd drop-in of a.Masters[%d] %v", i, err)
+		}
+
+		expectedLines := []string{
+			fmt.Sprintf("--name %s", e.EtcdMemberName),
+			fmt.Sprintf("--initial-advertise-peer-urls=https://%s.test-cluster.k8s.installation.eu-central-1.aws.gigantic.io:2380", e.EtcdMemberName),
+			fmt.Sprintf("--initial-cluster %s", key.EtcdInitialCluster(customObject, 3)),
+		}
+		for _, l := range expectedLines {
+			if !strings.Contains(string(dropIn), l) {
+				t.Fatalf("etcd drop-in of a.Masters[%d] didn't contain expected %q, complete: %q", i, l, string(dropIn))
+			}
+		}
+
+		if ignition.Storage.Files[1].Path != key.EtcdRestoreEnvironmentPath {
+			t.Fatalf("unexpected file path in a.Masters[%d].CloudConfig, got %q, want %q", i, ignition.Storage.Files[1].Path, key.EtcdRestoreEnvironmentPath)
+		}
+
+		source = strings.TrimPrefix(ignition.Storage.Files[1].Contents.Source, "data:text/plain;charset=utf-8;base64,")
+		environment, err := base64.StdEncoding.DecodeString(source)
+		if err != nil {
+			t.Fatalf("unexpected error decoding etcd restore environment of a.Masters[%d] %v", i, err)
+		}
+
+		expectedLines = []string{
+			fmt.Sprintf("ETCD_MEMBER_NAME=%s\n", e.EtcdMemberName),
+			fmt.Sprintf("ETCD_MEMBER_PEER_URL=https://%s.test-cluster.k8s.installation.eu-central-1.aws.gigantic.io:2380\n", e.EtcdMemberName),
+			fmt.Sprintf("ETCD_INITIAL_CLUSTER=%s\n", key.EtcdInitialCluster(customObject, 3)),
+		}
+		for _, l := range expectedLines {
+			if !strings.Contains(string(environment), l) {
+				t.Fatalf("etcd restore environment of a.Masters[%d] didn't contain expected %q, complete: %q", i, l, string(environment))
+			}
+		}
+		if strings.Contains(string(environment), fmt.Sprintf("https://%s.test-cluster.k8s.installation.eu-central-1.aws.gigantic.io:2379", e.EtcdMemberName)) {
+			t.Fatalf("etcd restore environment of a.Masters[%d] must not contain the member itself as peer, complete: %q", i, string(environment))
+		}
+	}
+}
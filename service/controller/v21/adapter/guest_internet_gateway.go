@@ -6,15 +6,40 @@ import (
 
 type GuestInternetGatewayAdapter struct {
 	ClusterID          string
+	EgressOnlyRoutes   []GuestInternetGatewayAdapterRoute
+	IPv6Enabled        bool
+	Managed            bool
 	PrivateRouteTables []string
 }
 
 func (a *GuestInternetGatewayAdapter) Adapt(cfg Config) error {
 	a.ClusterID = key.ClusterID(cfg.CustomObject)
+	a.IPv6Enabled = key.IPv6Enabled(cfg.CustomObject)
+	a.Managed = cfg.StackState.VPCID == ""
+
+	// Existing VPCs come with their own routing to the internet.
+	if !a.Managed {
+		return nil
+	}
 
 	for i := 0; i < len(key.StatusAvailabilityZones(cfg.CustomObject)); i++ {
 		a.PrivateRouteTables = append(a.PrivateRouteTables, key.PrivateRouteTableName(i))
+
+		// Private subnets have no NAT for IPv6. Outbound IPv6 traffic leaves the
+		// VPC through the egress-only internet gateway instead.
+		if a.IPv6Enabled {
+			r := GuestInternetGatewayAdapterRoute{
+				Name:           key.EgressOnlyRouteName(i),
+				RouteTableName: key.PrivateRouteTableName(i),
+			}
+			a.EgressOnlyRoutes = append(a.EgressOnlyRoutes, r)
+		}
 	}
 
 	return nil
 }
+
+type GuestInternetGatewayAdapterRoute struct {
+	Name           string
+	RouteTableName string
+}
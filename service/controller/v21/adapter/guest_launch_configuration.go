@@ -9,57 +9,127 @@ import (
 	"github.com/giantswarm/aws-operator/service/controller/v21/templates"
 )
 
+// GuestLaunchConfigAdapter holds the launch template of the worker ASG and the
+// launch templates of the node pools of the guest cluster.
 type GuestLaunchConfigAdapter struct {
+	GuestLaunchConfigAdapterWorker
+	NodePools []GuestLaunchConfigAdapterWorker
+}
+
+type GuestLaunchConfigAdapterWorker struct {
 	ASGType                        string
+	EncryptionKeyID                string
 	WorkerAssociatePublicIPAddress bool
 	WorkerBlockDeviceMappings      []BlockDeviceMapping
 	WorkerInstanceMonitoring       bool
 	WorkerInstanceType             string
 	WorkerImageID                  string
-	WorkerSecurityGroupID          string
-	WorkerSmallCloudConfig         string
+	// WorkerMetadataTokensRequired makes worker instances only serve the
+	// instance metadata service with session tokens, i.e. IMDSv2.
+	WorkerMetadataTokensRequired bool
+	WorkerSecurityGroupID        string
+	WorkerSmallCloudConfig       string
 }
 
 type BlockDeviceMapping struct {
 	DeleteOnTermination bool
 	DeviceName          string
-	VolumeSize          int
-	VolumeType          string
+	// EncryptionKeyARN is the ARN of the KMS key the volume is encrypted with.
+	// The volume is not encrypted in case it is empty.
+	EncryptionKeyARN string
+	// VolumeSize is expressed in GB. The size of the AMI snapshot is used in
+	// case it is zero.
+	VolumeSize int
+	VolumeType string
 }
 
 func (l *GuestLaunchConfigAdapter) Adapt(config Config) error {
-	l.ASGType = key.KindWorker
-	l.WorkerInstanceType = key.WorkerInstanceType(config.CustomObject)
-	l.WorkerImageID = config.StackState.WorkerImageID
-	l.WorkerAssociatePublicIPAddress = false
+	accountID, err := AccountID(config.Clients)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	{
+		l.ASGType = key.KindWorker
+		l.EncryptionKeyID = config.StackState.EncryptionKeyID
+		l.WorkerInstanceType = key.WorkerInstanceType(config.CustomObject)
+		l.WorkerImageID = config.StackState.WorkerImageID
+		l.WorkerAssociatePublicIPAddress = false
+		l.WorkerBlockDeviceMappings = workerBlockDeviceMappings(config.StackState.WorkerDockerVolumeSizeGB, config.StackState.VolumeEncryptionKeyARN)
+		l.WorkerInstanceMonitoring = config.StackState.WorkerInstanceMonitoring
+		l.WorkerMetadataTokensRequired = config.MetadataTokensRequired
+
+		s3URL := key.SmallCloudConfigS3URL(config.CustomObject, accountID, key.KindWorker)
+		l.WorkerSmallCloudConfig, err = workerSmallCloudConfig(s3URL)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	for _, p := range config.StackState.NodePools {
+		w := GuestLaunchConfigAdapterWorker{
+			ASGType:                        key.NodePoolASGType(p.Name),
+			EncryptionKeyID:                config.StackState.EncryptionKeyID,
+			WorkerAssociatePublicIPAddress: false,
+			WorkerBlockDeviceMappings:      workerBlockDeviceMappings(p.DockerVolumeSizeGB, config.StackState.VolumeEncryptionKeyARN),
+			WorkerInstanceMonitoring:       config.StackState.WorkerInstanceMonitoring,
+			WorkerInstanceType:             p.InstanceType,
+			WorkerImageID:                  config.StackState.WorkerImageID,
+			WorkerMetadataTokensRequired:   config.MetadataTokensRequired,
+		}
+
+		s3URL := key.SmallCloudConfigS3URL(config.CustomObject, accountID, p.CloudConfig)
+		w.WorkerSmallCloudConfig, err = workerSmallCloudConfig(s3URL)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		l.NodePools = append(l.NodePools, w)
+	}
 
-	if config.StackState.WorkerDockerVolumeSizeGB <= 0 {
-		config.StackState.WorkerDockerVolumeSizeGB = defaultEBSVolumeSize
+	return nil
+}
+
+// workerBlockDeviceMappings returns the block device mappings of worker nodes.
+// The root volume is only mapped in case the volumes are encrypted, because
+// its size and type are taken from the AMI otherwise.
+func workerBlockDeviceMappings(dockerVolumeSizeGB int, encryptionKeyARN string) []BlockDeviceMapping {
+	if dockerVolumeSizeGB <= 0 {
+		dockerVolumeSizeGB = defaultEBSVolumeSize
 	}
 
-	l.WorkerBlockDeviceMappings = []BlockDeviceMapping{
-		{
+	var mappings []BlockDeviceMapping
+
+	if encryptionKeyARN != "" {
+		mappings = append(mappings, BlockDeviceMapping{
 			DeleteOnTermination: true,
-			DeviceName:          defaultEBSVolumeMountPoint,
-			VolumeSize:          config.StackState.WorkerDockerVolumeSizeGB,
+			DeviceName:          rootVolumeDeviceName,
+			EncryptionKeyARN:    encryptionKeyARN,
 			VolumeType:          defaultEBSVolumeType,
-		},
+		})
 	}
-	l.WorkerInstanceMonitoring = config.StackState.WorkerInstanceMonitoring
 
-	// small cloud config field.
-	accountID, err := AccountID(config.Clients)
-	if err != nil {
-		return microerror.Mask(err)
-	}
+	mappings = append(mappings, BlockDeviceMapping{
+		DeleteOnTermination: true,
+		DeviceName:          defaultEBSVolumeMountPoint,
+		EncryptionKeyARN:    encryptionKeyARN,
+		VolumeSize:          dockerVolumeSizeGB,
+		VolumeType:          defaultEBSVolumeType,
+	})
+
+	return mappings
+}
+
+// workerSmallCloudConfig renders the base64 encoded small cloud config fetching
+// the actual cloud config of worker nodes from the given S3 URL.
+func workerSmallCloudConfig(s3URL string) (string, error) {
 	c := SmallCloudconfigConfig{
-		S3URL: key.SmallCloudConfigS3URL(config.CustomObject, accountID, key.KindWorker),
+		S3URL: s3URL,
 	}
 	rendered, err := templates.Render(key.CloudConfigSmallTemplates(), c)
 	if err != nil {
-		return microerror.Mask(err)
+		return "", microerror.Mask(err)
 	}
-	l.WorkerSmallCloudConfig = base64.StdEncoding.EncodeToString([]byte(rendered))
 
-	return nil
+	return base64.StdEncoding.EncodeToString([]byte(rendered)), nil
 }
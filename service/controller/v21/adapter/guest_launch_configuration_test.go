@@ -17,6 +17,7 @@ func Test_AdapterLaunchConfiguration_RegularFields(t *testing.T) {
 	testCases := []struct {
 		description                      string
 		customObject                     v1alpha1.AWSConfig
+		volumeEncryptionKeyARN           string
 		expectedError                    bool
 		expectedInstanceType             string
 		expectedAssociatePublicIPAddress bool
@@ -77,6 +78,42 @@ func Test_AdapterLaunchConfiguration_RegularFields(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "encrypted volumes, verify that the root volume is mapped as well",
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					Cluster: v1alpha1.Cluster{
+						ID: "test-cluster",
+					},
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						Workers: []v1alpha1.AWSConfigSpecAWSNode{
+							{
+								DockerVolumeSizeGB: 250,
+								InstanceType:       "myinstancetype",
+							},
+						},
+					},
+				},
+			},
+			volumeEncryptionKeyARN:           "arn:aws:kms:eu-central-1:000000000000:key/test-key",
+			expectedInstanceType:             "myinstancetype",
+			expectedAssociatePublicIPAddress: false,
+			expectedBlockDeviceMappings: []BlockDeviceMapping{
+				{
+					DeleteOnTermination: true,
+					DeviceName:          rootVolumeDeviceName,
+					EncryptionKeyARN:    "arn:aws:kms:eu-central-1:000000000000:key/test-key",
+					VolumeType:          defaultEBSVolumeType,
+				},
+				{
+					DeleteOnTermination: true,
+					DeviceName:          defaultEBSVolumeMountPoint,
+					EncryptionKeyARN:    "arn:aws:kms:eu-central-1:000000000000:key/test-key",
+					VolumeSize:          250,
+					VolumeType:          defaultEBSVolumeType,
+				},
+			},
+		},
 	}
 	for _, tc := range testCases {
 		clients := Clients{
@@ -91,6 +128,7 @@ func Test_AdapterLaunchConfiguration_RegularFields(t *testing.T) {
 				CustomObject: tc.customObject,
 				Clients:      clients,
 				StackState: StackState{
+					VolumeEncryptionKeyARN:   tc.volumeEncryptionKeyARN,
 					WorkerDockerVolumeSizeGB: key.WorkerDockerVolumeSizeGB(tc.customObject),
 				},
 			}
@@ -3,7 +3,8 @@ package adapter
 import "github.com/giantswarm/aws-operator/service/controller/v21/key"
 
 type GuestLifecycleHooksAdapter struct {
-	Worker GuestLifecycleHooksAdapterWorker
+	Worker    GuestLifecycleHooksAdapterWorker
+	NodePools []GuestLifecycleHooksAdapterWorker
 }
 
 type GuestLifecycleHooksAdapterWorker struct {
@@ -16,12 +17,23 @@ type GuestLifecycleHooksAdapterASG struct {
 }
 
 type GuestLifecycleHooksAdapterLifecycleHook struct {
-	Name string
+	Name         string
+	ResourceName string
 }
 
 func (a *GuestLifecycleHooksAdapter) Adapt(config Config) error {
 	a.Worker.ASG.Ref = key.WorkerASGRef
 	a.Worker.LifecycleHook.Name = key.NodeDrainerLifecycleHookName
+	a.Worker.LifecycleHook.ResourceName = key.NodeDrainerLifecycleHookName + "LifecycleHook"
+
+	for _, p := range config.StackState.NodePools {
+		w := GuestLifecycleHooksAdapterWorker{}
+		w.ASG.Ref = key.NodePoolASGRef(p.Name)
+		w.LifecycleHook.Name = key.NodeDrainerLifecycleHookName
+		w.LifecycleHook.ResourceName = key.NodePoolASGType(p.Name) + key.NodeDrainerLifecycleHookName + "LifecycleHook"
+
+		a.NodePools = append(a.NodePools, w)
+	}
 
 	return nil
 }
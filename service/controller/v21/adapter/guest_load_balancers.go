@@ -2,7 +2,9 @@ package adapter
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
 	"github.com/giantswarm/microerror"
 
 	"github.com/giantswarm/aws-operator/service/controller/v21/key"
@@ -12,30 +14,61 @@ const (
 	// Default values for health checks.
 	healthCheckHealthyThreshold   = 2
 	healthCheckInterval           = 5
+	healthCheckProtocol           = "TCP"
 	healthCheckTimeout            = 3
 	healthCheckUnhealthyThreshold = 2
+
+	// Network Load Balancers only accept health check intervals of 10 or 30
+	// seconds and require the healthy and unhealthy thresholds to be equal.
+	nlbHealthCheckInterval  = 10
+	nlbHealthCheckThreshold = 2
+)
+
+const (
+	apiLoadBalancerResourceName     = "ApiLoadBalancer"
+	etcdLoadBalancerResourceName    = "EtcdLoadBalancer"
+	ingressLoadBalancerResourceName = "IngressLoadBalancer"
 )
 
 type GuestLoadBalancersAdapter struct {
+	APIElbHealthCheckPort            int
 	APIElbHealthCheckTarget          string
+	APIElbHostedZoneIDAttribute      string
 	APIElbName                       string
 	APIElbPortsToOpen                []GuestLoadBalancersAdapterPortPair
+	APIElbResourceName               string
 	APIElbScheme                     string
 	APIElbSecurityGroupID            string
+	APIElbSubnets                    []string
+	APIElbSubnetMappings             []GuestLoadBalancersAdapterSubnetMapping
+	APIElbType                       string
+	EtcdElbHealthCheckPort           int
 	EtcdElbHealthCheckTarget         string
+	EtcdElbHostedZoneIDAttribute     string
 	EtcdElbName                      string
 	EtcdElbPortsToOpen               []GuestLoadBalancersAdapterPortPair
+	EtcdElbResourceName              string
 	EtcdElbScheme                    string
 	EtcdElbSecurityGroupID           string
+	EtcdElbType                      string
 	ELBHealthCheckHealthyThreshold   int
 	ELBHealthCheckInterval           int
 	ELBHealthCheckTimeout            int
 	ELBHealthCheckUnhealthyThreshold int
+	IngressElbHealthCheckPort        int
 	IngressElbHealthCheckTarget      string
+	IngressElbHostedZoneIDAttribute  string
 	IngressElbName                   string
+	IngressElbProxyProtocol          bool
 	IngressElbPortsToOpen            []GuestLoadBalancersAdapterPortPair
+	IngressElbResourceName           string
 	IngressElbScheme                 string
-	MasterInstanceResourceName       string
+	IngressElbType                   string
+	InternetGatewayManaged           bool
+	MasterInstanceResourceNames      []string
+	NLBHealthCheckInterval           int
+	NLBHealthCheckProtocol           string
+	NLBHealthCheckThreshold          int
 	PublicSubnets                    []string
 	PrivateSubnets                   []string
 }
@@ -48,81 +81,183 @@ func (a *GuestLoadBalancersAdapter) Adapt(cfg Config) error {
 		}
 	}
 
+	switch key.APIEndpointMode(cfg.CustomObject) {
+	case key.APIEndpointModePublic, key.APIEndpointModePrivate:
+	default:
+		return microerror.Maskf(invalidConfigError, "unknown API endpoint mode %#q", key.APIEndpointMode(cfg.CustomObject))
+	}
+
 	// API load balancer settings.
-	apiElbName, err := key.LoadBalancerName(cfg.CustomObject.Spec.Cluster.Kubernetes.API.Domain, cfg.CustomObject)
+	apiElbType := key.APILoadBalancerType(cfg.CustomObject)
+	apiElbName, err := key.LoadBalancerName(cfg.CustomObject.Spec.Cluster.Kubernetes.API.Domain, apiElbType, cfg.CustomObject)
 	if err != nil {
 		return microerror.Mask(err)
 	}
 
-	a.APIElbHealthCheckTarget = heathCheckTarget(cfg.CustomObject.Spec.Cluster.Kubernetes.API.SecurePort)
+	a.APIElbHealthCheckPort = cfg.CustomObject.Spec.Cluster.Kubernetes.API.SecurePort
+	a.APIElbHealthCheckTarget = heathCheckTarget(a.APIElbHealthCheckPort)
+	a.APIElbHostedZoneIDAttribute = hostedZoneIDAttribute(apiElbType)
 	a.APIElbName = apiElbName
 	a.APIElbPortsToOpen = []GuestLoadBalancersAdapterPortPair{
-		{
-			PortELB:      key.KubernetesAPISecurePort(cfg.CustomObject),
-			PortInstance: key.KubernetesAPISecurePort(cfg.CustomObject),
-		},
+		newPortPair(apiLoadBalancerResourceName, apiElbType, key.KubernetesAPISecurePort(cfg.CustomObject), key.KubernetesAPISecurePort(cfg.CustomObject)),
 	}
+	a.APIElbResourceName = loadBalancerResourceName(apiLoadBalancerResourceName, apiElbType)
 	a.APIElbScheme = externalELBScheme
+	a.APIElbType = apiElbType
 
 	// etcd load balancer settings.
-	etcdElbName, err := key.LoadBalancerName(key.EtcdDomain(cfg.CustomObject), cfg.CustomObject)
+	etcdElbType := key.EtcdLoadBalancerType(cfg.CustomObject)
+	etcdElbName, err := key.LoadBalancerName(key.EtcdDomain(cfg.CustomObject), etcdElbType, cfg.CustomObject)
 	if err != nil {
 		return microerror.Mask(err)
 	}
 
-	a.EtcdElbHealthCheckTarget = heathCheckTarget(key.EtcdPort(cfg.CustomObject))
+	a.EtcdElbHealthCheckPort = key.EtcdPort(cfg.CustomObject)
+	a.EtcdElbHealthCheckTarget = heathCheckTarget(a.EtcdElbHealthCheckPort)
+	a.EtcdElbHostedZoneIDAttribute = hostedZoneIDAttribute(etcdElbType)
 	a.EtcdElbName = etcdElbName
 	a.EtcdElbPortsToOpen = []GuestLoadBalancersAdapterPortPair{
-		{
-			PortELB:      key.EtcdPort(cfg.CustomObject),
-			PortInstance: key.EtcdPort(cfg.CustomObject),
-		},
+		newPortPair(etcdLoadBalancerResourceName, etcdElbType, key.EtcdPort(cfg.CustomObject), key.EtcdPort(cfg.CustomObject)),
 	}
+	a.EtcdElbResourceName = loadBalancerResourceName(etcdLoadBalancerResourceName, etcdElbType)
 	a.EtcdElbScheme = internalELBScheme
+	a.EtcdElbType = etcdElbType
 
 	// Ingress load balancer settings.
-	ingressElbName, err := key.LoadBalancerName(cfg.CustomObject.Spec.Cluster.Kubernetes.IngressController.Domain, cfg.CustomObject)
+	ingressElbType := key.IngressLoadBalancerType(cfg.CustomObject)
+	ingressElbName, err := key.LoadBalancerName(cfg.CustomObject.Spec.Cluster.Kubernetes.IngressController.Domain, ingressElbType, cfg.CustomObject)
 	if err != nil {
 		return microerror.Mask(err)
 	}
 
-	a.IngressElbHealthCheckTarget = heathCheckTarget(key.IngressControllerSecurePort(cfg.CustomObject))
+	a.IngressElbHealthCheckPort = key.IngressControllerSecurePort(cfg.CustomObject)
+	a.IngressElbHealthCheckTarget = heathCheckTarget(a.IngressElbHealthCheckPort)
+	a.IngressElbHostedZoneIDAttribute = hostedZoneIDAttribute(ingressElbType)
 	a.IngressElbName = ingressElbName
-	a.IngressElbPortsToOpen = []GuestLoadBalancersAdapterPortPair{
-		{
-			PortELB: httpsPort,
-
-			PortInstance: key.IngressControllerSecurePort(cfg.CustomObject),
-		},
-		{
-			PortELB:      httpPort,
-			PortInstance: key.IngressControllerInsecurePort(cfg.CustomObject),
-		},
-	}
+	a.IngressElbPortsToOpen = ingressPortPairs(cfg.CustomObject)
+	a.IngressElbResourceName = loadBalancerResourceName(ingressLoadBalancerResourceName, ingressElbType)
+	a.IngressElbProxyProtocol = cfg.IngressNLB.ProxyProtocol
 	a.IngressElbScheme = externalELBScheme
+	a.IngressElbType = ingressElbType
+
+	// The load balancers of guest clusters with their own VPC wait for the
+	// internet gateway, which is not part of the guest cluster main stack in
+	// case the guest cluster is deployed into an existing VPC.
+	a.InternetGatewayManaged = cfg.StackState.VPCID == ""
 
 	// Load balancer health check settings.
 	a.ELBHealthCheckHealthyThreshold = healthCheckHealthyThreshold
 	a.ELBHealthCheckInterval = healthCheckInterval
 	a.ELBHealthCheckTimeout = healthCheckTimeout
 	a.ELBHealthCheckUnhealthyThreshold = healthCheckUnhealthyThreshold
-	a.MasterInstanceResourceName = cfg.StackState.MasterInstanceResourceName
+	a.NLBHealthCheckInterval = nlbHealthCheckInterval
+	a.NLBHealthCheckProtocol = healthCheckProtocol
+	a.NLBHealthCheckThreshold = nlbHealthCheckThreshold
+
+	for i := 0; i < masterCount(cfg); i++ {
+		a.MasterInstanceResourceNames = append(a.MasterInstanceResourceNames, masterState(cfg, i).InstanceResourceName)
+	}
 
 	for i := 0; i < len(key.StatusAvailabilityZones(cfg.CustomObject)); i++ {
 		a.PublicSubnets = append(a.PublicSubnets, key.PublicSubnetName(i))
 		a.PrivateSubnets = append(a.PrivateSubnets, key.PrivateSubnetName(i))
 	}
 
+	// A private API is served by an internal load balancer in the private
+	// subnets of the tenant cluster.
+	a.APIElbSubnets = a.PublicSubnets
+	if key.PrivateAPIEndpoint(cfg.CustomObject) {
+		a.APIElbScheme = internalELBScheme
+		a.APIElbSubnets = a.PrivateSubnets
+	}
+
+	// The public Network Load Balancer of the API gets a static EIP in every
+	// availability zone so the API can be whitelisted by its IP addresses.
+	if a.APIElbType == key.LoadBalancerTypeNetwork && !key.PrivateAPIEndpoint(cfg.CustomObject) {
+		for i, s := range a.APIElbSubnets {
+			m := GuestLoadBalancersAdapterSubnetMapping{
+				EIPName:    fmt.Sprintf("%sEIP%02d", a.APIElbResourceName, i),
+				SubnetName: s,
+			}
+
+			a.APIElbSubnetMappings = append(a.APIElbSubnetMappings, m)
+		}
+	}
+
 	return nil
 }
 
 type GuestLoadBalancersAdapterPortPair struct {
+	// ListenerResourceName is the name of the listener resource in case the
+	// port is opened on a Network Load Balancer.
+	ListenerResourceName string
 	// PortELB is the port the ELB should listen on.
 	PortELB int
 	// PortInstance is the port on the instance the ELB forwards traffic to.
 	PortInstance int
+	// TargetGroupResourceName is the name of the target group resource in case
+	// the port is opened on a Network Load Balancer.
+	TargetGroupResourceName string
+}
+
+type GuestLoadBalancersAdapterSubnetMapping struct {
+	// EIPName is the name of the EIP resource the Network Load Balancer uses in
+	// the availability zone of the subnet.
+	EIPName string
+	// SubnetName is the name of the subnet resource.
+	SubnetName string
 }
 
 func heathCheckTarget(port int) string {
-	return fmt.Sprintf("TCP:%d", port)
+	return fmt.Sprintf("%s:%d", healthCheckProtocol, port)
+}
+
+// hostedZoneIDAttribute returns the attribute of the load balancer resource of
+// the given type holding the ID of its canonical hosted zone. The record sets
+// of the guest cluster alias the load balancers using this hosted zone.
+func hostedZoneIDAttribute(loadBalancerType string) string {
+	if loadBalancerType == key.LoadBalancerTypeNetwork {
+		return "CanonicalHostedZoneID"
+	}
+
+	return "CanonicalHostedZoneNameID"
+}
+
+// ingressPortPairs returns the ports opened on the ingress load balancer. The
+// worker ASGs register their instances with the target groups of these ports
+// in case the ingress load balancer is a Network Load Balancer.
+func ingressPortPairs(customObject v1alpha1.AWSConfig) []GuestLoadBalancersAdapterPortPair {
+	ingressElbType := key.IngressLoadBalancerType(customObject)
+
+	return []GuestLoadBalancersAdapterPortPair{
+		newPortPair(ingressLoadBalancerResourceName, ingressElbType, httpsPort, key.IngressControllerSecurePort(customObject)),
+		newPortPair(ingressLoadBalancerResourceName, ingressElbType, httpPort, key.IngressControllerInsecurePort(customObject)),
+	}
+}
+
+// loadBalancerResourceName returns the name of the load balancer resource of
+// the given type. Network Load Balancers use their own resource names because
+// CloudFormation cannot change the type of an existing resource.
+func loadBalancerResourceName(name string, loadBalancerType string) string {
+	if loadBalancerType == key.LoadBalancerTypeNetwork {
+		return strings.TrimSuffix(name, "LoadBalancer") + "NetworkLoadBalancer"
+	}
+
+	return name
+}
+
+func newPortPair(loadBalancerName string, loadBalancerType string, portELB, portInstance int) GuestLoadBalancersAdapterPortPair {
+	p := GuestLoadBalancersAdapterPortPair{
+		PortELB:      portELB,
+		PortInstance: portInstance,
+	}
+
+	if loadBalancerType == key.LoadBalancerTypeNetwork {
+		prefix := strings.TrimSuffix(loadBalancerName, "LoadBalancer")
+
+		p.ListenerResourceName = fmt.Sprintf("%sListener%d", prefix, portELB)
+		p.TargetGroupResourceName = fmt.Sprintf("%sTargetGroup%d", prefix, portELB)
+	}
+
+	return p
 }
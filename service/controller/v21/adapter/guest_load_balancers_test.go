@@ -204,3 +204,129 @@ func TestAdapterLoadBalancersRegularFields(t *testing.T) {
 		})
 	}
 }
+
+func TestAdapterLoadBalancersNetworkLoadBalancers(t *testing.T) {
+	t.Parallel()
+	customObject := v1alpha1.AWSConfig{
+		Spec: v1alpha1.AWSConfigSpec{
+			Cluster: v1alpha1.Cluster{
+				ID: "test-cluster",
+				Etcd: v1alpha1.ClusterEtcd{
+					Domain: "etcd.test-cluster.aws.giantswarm.io",
+					Port:   2379,
+				},
+				Kubernetes: v1alpha1.ClusterKubernetes{
+					API: v1alpha1.ClusterKubernetesAPI{
+						Domain:     "api.test-cluster.aws.giantswarm.io",
+						SecurePort: 443,
+					},
+					IngressController: v1alpha1.ClusterKubernetesIngressController{
+						Domain:       "ingress.test-cluster.aws.giantswarm.io",
+						InsecurePort: 30010,
+						SecurePort:   30011,
+					},
+				},
+			},
+			AWS: v1alpha1.AWSConfigSpecAWS{
+				LoadBalancers: v1alpha1.AWSConfigSpecAWSLoadBalancers{
+					API: v1alpha1.AWSConfigSpecAWSLoadBalancer{
+						Type: key.LoadBalancerTypeNetwork,
+					},
+					Ingress: v1alpha1.AWSConfigSpecAWSLoadBalancer{
+						Type: key.LoadBalancerTypeNetwork,
+					},
+				},
+			},
+		},
+		Status: v1alpha1.AWSConfigStatus{
+			AWS: v1alpha1.AWSConfigStatusAWS{
+				AvailabilityZones: []v1alpha1.AWSConfigStatusAWSAvailabilityZone{
+					{
+						Name: "eu-central-1a",
+					},
+					{
+						Name: "eu-central-1b",
+					},
+				},
+			},
+		},
+	}
+
+	a := Adapter{}
+	cfg := Config{
+		CustomObject: customObject,
+		Clients: Clients{
+			EC2: &EC2ClientMock{},
+			IAM: &IAMClientMock{},
+			STS: &STSClientMock{},
+		},
+		IngressNLB: IngressNLB{
+			ProxyProtocol: true,
+		},
+	}
+	err := a.Guest.LoadBalancers.Adapt(cfg)
+	if err != nil {
+		t.Fatalf("expected %#v got %#v", nil, err)
+	}
+
+	lb := a.Guest.LoadBalancers
+
+	if lb.APIElbName != "test-cluster-api-nlb" {
+		t.Fatalf("expected API ELB name %q got %q", "test-cluster-api-nlb", lb.APIElbName)
+	}
+	if lb.APIElbResourceName != "ApiNetworkLoadBalancer" {
+		t.Fatalf("expected API ELB resource name %q got %q", "ApiNetworkLoadBalancer", lb.APIElbResourceName)
+	}
+	if lb.APIElbHostedZoneIDAttribute != "CanonicalHostedZoneID" {
+		t.Fatalf("expected API ELB hosted zone ID attribute %q got %q", "CanonicalHostedZoneID", lb.APIElbHostedZoneIDAttribute)
+	}
+	if lb.APIElbHealthCheckPort != 443 {
+		t.Fatalf("expected API ELB health check port %d got %d", 443, lb.APIElbHealthCheckPort)
+	}
+
+	expectedSubnetMappings := []GuestLoadBalancersAdapterSubnetMapping{
+		{
+			EIPName:    "ApiNetworkLoadBalancerEIP00",
+			SubnetName: "PublicSubnet",
+		},
+		{
+			EIPName:    "ApiNetworkLoadBalancerEIP01",
+			SubnetName: "PublicSubnet01",
+		},
+	}
+	if !reflect.DeepEqual(lb.APIElbSubnetMappings, expectedSubnetMappings) {
+		t.Fatalf("expected API ELB subnet mappings %#v got %#v", expectedSubnetMappings, lb.APIElbSubnetMappings)
+	}
+
+	// The etcd load balancer is left a classic Elastic Load Balancer.
+	if lb.EtcdElbName != "test-cluster-etcd" {
+		t.Fatalf("expected etcd ELB name %q got %q", "test-cluster-etcd", lb.EtcdElbName)
+	}
+	if lb.EtcdElbResourceName != "EtcdLoadBalancer" {
+		t.Fatalf("expected etcd ELB resource name %q got %q", "EtcdLoadBalancer", lb.EtcdElbResourceName)
+	}
+	if lb.EtcdElbHostedZoneIDAttribute != "CanonicalHostedZoneNameID" {
+		t.Fatalf("expected etcd ELB hosted zone ID attribute %q got %q", "CanonicalHostedZoneNameID", lb.EtcdElbHostedZoneIDAttribute)
+	}
+
+	expectedIngressPortsToOpen := []GuestLoadBalancersAdapterPortPair{
+		{
+			ListenerResourceName:    "IngressListener443",
+			PortELB:                 443,
+			PortInstance:            30011,
+			TargetGroupResourceName: "IngressTargetGroup443",
+		},
+		{
+			ListenerResourceName:    "IngressListener80",
+			PortELB:                 80,
+			PortInstance:            30010,
+			TargetGroupResourceName: "IngressTargetGroup80",
+		},
+	}
+	if !reflect.DeepEqual(lb.IngressElbPortsToOpen, expectedIngressPortsToOpen) {
+		t.Fatalf("expected Ingress ELB ports to open %#v got %#v", expectedIngressPortsToOpen, lb.IngressElbPortsToOpen)
+	}
+	if !lb.IngressElbProxyProtocol {
+		t.Fatalf("expected Ingress ELB proxy protocol to be enabled")
+	}
+}
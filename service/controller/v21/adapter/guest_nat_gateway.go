@@ -1,34 +1,115 @@
 package adapter
 
 import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/giantswarm/microerror"
+
 	"github.com/giantswarm/aws-operator/service/controller/v21/key"
 )
 
 type Gateway struct {
-	ClusterID             string
+	// AllocationID is the allocation ID of the pre-allocated elastic IP of the
+	// NAT gateway. NATEIPName is empty in this case.
+	AllocationID     string
+	ClusterID        string
+	NATGWName        string
+	NATEIPName       string
+	PublicIP         string
+	PublicSubnetName string
+}
+
+type NATRoute struct {
 	NATGWName             string
-	NATEIPName            string
 	NATRouteName          string
 	PrivateRouteTableName string
-	PublicSubnetName      string
 }
 
 type GuestNATGatewayAdapter struct {
 	Gateways []Gateway
+	Routes   []NATRoute
 }
 
 func (a *GuestNATGatewayAdapter) Adapt(cfg Config) error {
-	for i := 0; i < len(key.StatusAvailabilityZones(cfg.CustomObject)); i++ {
+	// Existing VPCs come with their own routing to the internet.
+	if cfg.StackState.VPCID != "" {
+		return nil
+	}
+
+	// The NAT gateway configuration is taken from the stack state, because it
+	// never changes for existing guest clusters.
+	allocationIDs := cfg.StackState.NATGatewayAllocationIDs
+
+	natGatewayCount := len(key.StatusAvailabilityZones(cfg.CustomObject))
+	if cfg.StackState.NATGatewayStrategy == key.NATGatewayStrategySingle {
+		natGatewayCount = 1
+	}
+
+	var publicIPs map[string]string
+	if len(allocationIDs) != 0 {
+		var err error
+		publicIPs, err = allocationPublicIPs(cfg, allocationIDs)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	for i := 0; i < natGatewayCount; i++ {
 		gw := Gateway{
-			ClusterID:             key.ClusterID(cfg.CustomObject),
-			NATGWName:             key.NATGatewayName(i),
-			NATEIPName:            key.NATEIPName(i),
+			ClusterID:        key.ClusterID(cfg.CustomObject),
+			NATGWName:        key.NATGatewayName(i),
+			PublicSubnetName: key.PublicSubnetName(i),
+		}
+		if len(allocationIDs) != 0 {
+			gw.AllocationID = allocationIDs[i]
+			gw.PublicIP = publicIPs[allocationIDs[i]]
+		} else {
+			gw.NATEIPName = key.NATEIPName(i)
+		}
+		a.Gateways = append(a.Gateways, gw)
+	}
+
+	// The private subnets of all availability zones route through the NAT
+	// gateway of the first availability zone in case there is only a single
+	// NAT gateway.
+	for i := 0; i < len(key.StatusAvailabilityZones(cfg.CustomObject)); i++ {
+		gwName := key.NATGatewayName(0)
+		if cfg.StackState.NATGatewayStrategy != key.NATGatewayStrategySingle {
+			gwName = key.NATGatewayName(i)
+		}
+
+		r := NATRoute{
+			NATGWName:             gwName,
 			NATRouteName:          key.NATRouteName(i),
 			PrivateRouteTableName: key.PrivateRouteTableName(i),
-			PublicSubnetName:      key.PublicSubnetName(i),
 		}
-		a.Gateways = append(a.Gateways, gw)
+		a.Routes = append(a.Routes, r)
 	}
 
 	return nil
 }
+
+// allocationPublicIPs maps the allocation IDs of pre-allocated elastic IPs to
+// their public IPs.
+func allocationPublicIPs(cfg Config, allocationIDs []string) (map[string]string, error) {
+	i := &ec2.DescribeAddressesInput{
+		AllocationIds: aws.StringSlice(allocationIDs),
+	}
+	o, err := cfg.Clients.EC2.DescribeAddresses(i)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	publicIPs := map[string]string{}
+	for _, a := range o.Addresses {
+		publicIPs[aws.StringValue(a.AllocationId)] = aws.StringValue(a.PublicIp)
+	}
+
+	for _, id := range allocationIDs {
+		if publicIPs[id] == "" {
+			return nil, microerror.Maskf(notFoundError, "elastic IP with allocation ID %#q", id)
+		}
+	}
+
+	return publicIPs, nil
+}
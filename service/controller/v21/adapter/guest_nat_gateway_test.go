@@ -0,0 +1,124 @@
+package adapter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+)
+
+func TestAdapterNATGateway(t *testing.T) {
+	t.Parallel()
+	twoAZs := []v1alpha1.AWSConfigStatusAWSAvailabilityZone{
+		{Name: "eu-central-1a"},
+		{Name: "eu-central-1b"},
+	}
+
+	testCases := []struct {
+		description      string
+		natGateway       v1alpha1.AWSConfigSpecAWSNATGateway
+		vpcID            string
+		elasticIPs       []string
+		expectedGateways []Gateway
+		expectedRoutes   []NATRoute
+		expectedError    bool
+	}{
+		{
+			description: "NAT gateway in each availability zone",
+			expectedGateways: []Gateway{
+				{ClusterID: "test-cluster", NATGWName: "NATGateway", NATEIPName: "NATEIP", PublicSubnetName: "PublicSubnet"},
+				{ClusterID: "test-cluster", NATGWName: "NATGateway01", NATEIPName: "NATEIP01", PublicSubnetName: "PublicSubnet01"},
+			},
+			expectedRoutes: []NATRoute{
+				{NATGWName: "NATGateway", NATRouteName: "NATRoute", PrivateRouteTableName: "PrivateRouteTable"},
+				{NATGWName: "NATGateway01", NATRouteName: "NATRoute01", PrivateRouteTableName: "PrivateRouteTable01"},
+			},
+		},
+		{
+			description: "single NAT gateway",
+			natGateway: v1alpha1.AWSConfigSpecAWSNATGateway{
+				Strategy: "single",
+			},
+			expectedGateways: []Gateway{
+				{ClusterID: "test-cluster", NATGWName: "NATGateway", NATEIPName: "NATEIP", PublicSubnetName: "PublicSubnet"},
+			},
+			expectedRoutes: []NATRoute{
+				{NATGWName: "NATGateway", NATRouteName: "NATRoute", PrivateRouteTableName: "PrivateRouteTable"},
+				{NATGWName: "NATGateway", NATRouteName: "NATRoute01", PrivateRouteTableName: "PrivateRouteTable01"},
+			},
+		},
+		{
+			description: "NAT gateway in each availability zone with pre-allocated elastic IPs",
+			natGateway: v1alpha1.AWSConfigSpecAWSNATGateway{
+				AllocationIDs: []string{"eipalloc-a", "eipalloc-b"},
+			},
+			elasticIPs: []string{"52.0.0.1", "52.0.0.2"},
+			expectedGateways: []Gateway{
+				{AllocationID: "eipalloc-a", ClusterID: "test-cluster", NATGWName: "NATGateway", PublicIP: "52.0.0.1", PublicSubnetName: "PublicSubnet"},
+				{AllocationID: "eipalloc-b", ClusterID: "test-cluster", NATGWName: "NATGateway01", PublicIP: "52.0.0.2", PublicSubnetName: "PublicSubnet01"},
+			},
+			expectedRoutes: []NATRoute{
+				{NATGWName: "NATGateway", NATRouteName: "NATRoute", PrivateRouteTableName: "PrivateRouteTable"},
+				{NATGWName: "NATGateway01", NATRouteName: "NATRoute01", PrivateRouteTableName: "PrivateRouteTable01"},
+			},
+		},
+		{
+			description: "unknown pre-allocated elastic IP",
+			natGateway: v1alpha1.AWSConfigSpecAWSNATGateway{
+				Strategy:      "single",
+				AllocationIDs: []string{"eipalloc-a"},
+			},
+			expectedError: true,
+		},
+		{
+			description: "existing VPC",
+			vpcID:       "vpc-existing",
+		},
+	}
+
+	for _, tc := range testCases {
+		a := Adapter{}
+		t.Run(tc.description, func(t *testing.T) {
+			cfg := Config{
+				Clients: Clients{
+					EC2: &EC2ClientMock{elasticIPs: tc.elasticIPs},
+				},
+				CustomObject: v1alpha1.AWSConfig{
+					Spec: v1alpha1.AWSConfigSpec{
+						Cluster: defaultCluster,
+						AWS: v1alpha1.AWSConfigSpecAWS{
+							NATGateway: tc.natGateway,
+						},
+					},
+					Status: v1alpha1.AWSConfigStatus{
+						AWS: v1alpha1.AWSConfigStatusAWS{
+							AvailabilityZones: twoAZs,
+						},
+					},
+				},
+				StackState: StackState{
+					NATGatewayAllocationIDs: tc.natGateway.AllocationIDs,
+					NATGatewayStrategy:      tc.natGateway.Strategy,
+					VPCID:                   tc.vpcID,
+				},
+			}
+			err := a.Guest.NATGateway.Adapt(cfg)
+			if tc.expectedError && err == nil {
+				t.Fatalf("expected error didn't happen")
+			}
+			if tc.expectedError {
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			if !reflect.DeepEqual(a.Guest.NATGateway.Gateways, tc.expectedGateways) {
+				t.Errorf("unexpected Gateways, got %#v, want %#v", a.Guest.NATGateway.Gateways, tc.expectedGateways)
+			}
+			if !reflect.DeepEqual(a.Guest.NATGateway.Routes, tc.expectedRoutes) {
+				t.Errorf("unexpected Routes, got %#v, want %#v", a.Guest.NATGateway.Routes, tc.expectedRoutes)
+			}
+		})
+	}
+}
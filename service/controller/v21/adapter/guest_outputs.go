@@ -2,24 +2,58 @@ package adapter
 
 import (
 	"strconv"
+	"strings"
 
 	"github.com/giantswarm/aws-operator/service/controller/v21/key"
 )
 
 type GuestOutputsAdapter struct {
-	Master         GuestOutputsAdapterMaster
-	Worker         GuestOutputsAdapterWorker
-	Route53Enabled bool
-	VersionBundle  GuestOutputsAdapterVersionBundle
+	APIWhitelist     GuestOutputsAdapterAPIWhitelist
+	EncryptionKeyID  string
+	Master           GuestOutputsAdapterMaster
+	NATGateway       GuestOutputsAdapterNATGateway
+	Worker           GuestOutputsAdapterWorker
+	NodePools        []GuestOutputsAdapterNodePool
+	Route53Enabled   bool
+	TransitGatewayID string
+	VersionBundle    GuestOutputsAdapterVersionBundle
+	VolumeEncryption GuestOutputsAdapterVolumeEncryption
+	VPCID            string
 }
 
 func (a *GuestOutputsAdapter) Adapt(config Config) error {
+	a.APIWhitelist.APICIDRs = strings.Join(config.StackState.APIWhitelistCIDRs, ",")
+	a.APIWhitelist.IngressCIDRs = strings.Join(config.StackState.IngressWhitelistCIDRs, ",")
+	a.EncryptionKeyID = config.StackState.EncryptionKeyID
 	a.Route53Enabled = config.Route53Enabled
+	a.Master.Count = strconv.Itoa(masterCount(config))
 	a.Master.DockerVolume.ResourceName = config.StackState.DockerVolumeResourceName
 	a.Master.ImageID = config.StackState.MasterImageID
 	a.Master.Instance.ResourceName = config.StackState.MasterInstanceResourceName
 	a.Master.Instance.Type = config.StackState.MasterInstanceType
 	a.Master.CloudConfig.Version = config.StackState.MasterCloudConfigVersion
+	a.NATGateway.AllocationIDs = strings.Join(config.StackState.NATGatewayAllocationIDs, ",")
+	a.NATGateway.Strategy = config.StackState.NATGatewayStrategy
+
+	for i, m := range config.StackState.Masters {
+		values := map[string]string{
+			key.MasterCloudConfigVersionOutputKey:       m.CloudConfigVersion,
+			key.MasterDockerVolumeResourceNameOutputKey: m.DockerVolumeResourceName,
+			key.MasterEncryptionKeyIDOutputKey:          m.EncryptionKeyID,
+			key.MasterImageIDOutputKey:                  m.ImageID,
+			key.MasterInstanceResourceNameOutputKey:     m.InstanceResourceName,
+			key.MasterInstanceTypeOutputKey:             m.InstanceType,
+			key.MasterVersionBundleVersionOutputKey:     m.VersionBundleVersion,
+			key.MasterVolumeEncryptionKeyARNOutputKey:   m.VolumeEncryptionKeyARN,
+		}
+
+		v := GuestOutputsAdapterValue{
+			Key:   key.MasterOutputKey(i),
+			Value: key.MasterOutputValue(values),
+		}
+
+		a.Master.States = append(a.Master.States, v)
+	}
 
 	a.Worker.ASG.Key = key.WorkerASGKey
 	a.Worker.ASG.Ref = key.WorkerASGRef
@@ -27,18 +61,61 @@ func (a *GuestOutputsAdapter) Adapt(config Config) error {
 	a.Worker.DockerVolumeSizeGB = strconv.Itoa(config.StackState.WorkerDockerVolumeSizeGB)
 	a.Worker.ImageID = config.StackState.WorkerImageID
 	a.Worker.InstanceType = config.StackState.WorkerInstanceType
+	a.Worker.LaunchTemplate.Key = key.WorkerLaunchTemplateIDKey
+	a.Worker.LaunchTemplate.Ref = key.WorkerLaunchTemplateRef
+	a.Worker.InstanceDistribution.Enabled = len(config.StackState.WorkerInstanceTypes) > 0
+	a.Worker.InstanceDistribution.InstanceTypes = strings.Join(config.StackState.WorkerInstanceTypes, ",")
+	a.Worker.InstanceDistribution.OnDemandBaseCapacity = strconv.Itoa(config.StackState.WorkerOnDemandBaseCapacity)
+	a.Worker.InstanceDistribution.SpotAllocationStrategy = config.StackState.WorkerSpotAllocationStrategy
+	a.Worker.InstanceDistribution.SpotPercentage = strconv.Itoa(config.StackState.WorkerSpotPercentage)
 	a.Worker.CloudConfig.Version = config.StackState.WorkerCloudConfigVersion
+	a.Worker.Scaling.Enabled = config.StackState.WorkerMaxSize > 0
+	a.Worker.Scaling.MaxSize = strconv.Itoa(config.StackState.WorkerMaxSize)
+	a.Worker.Scaling.MinSize = strconv.Itoa(config.StackState.WorkerMinSize)
+
+	for _, p := range config.StackState.NodePools {
+		n := GuestOutputsAdapterNodePool{}
+		n.ASG.Key = key.NodePoolOutputKey(p.Name, key.NodePoolASGKey)
+		n.ASG.Ref = key.NodePoolASGRef(p.Name)
+		n.CloudConfig.Key = key.NodePoolOutputKey(p.Name, key.NodePoolCloudConfigKey)
+		n.CloudConfig.Value = p.CloudConfig
+		n.DockerVolumeSizeGB.Key = key.NodePoolOutputKey(p.Name, key.NodePoolDockerVolumeSizeKey)
+		n.DockerVolumeSizeGB.Value = strconv.Itoa(p.DockerVolumeSizeGB)
+		n.InstanceType.Key = key.NodePoolOutputKey(p.Name, key.NodePoolInstanceTypeKey)
+		n.InstanceType.Value = p.InstanceType
+		n.MaxSize.Key = key.NodePoolOutputKey(p.Name, key.NodePoolMaxSizeKey)
+		n.MaxSize.Value = strconv.Itoa(p.MaxSize)
+		n.MinSize.Key = key.NodePoolOutputKey(p.Name, key.NodePoolMinSizeKey)
+		n.MinSize.Value = strconv.Itoa(p.MinSize)
+
+		a.NodePools = append(a.NodePools, n)
+	}
 
 	a.VersionBundle.Version = config.StackState.VersionBundleVersion
 
+	a.VolumeEncryption.EtcdKeyARN = config.StackState.EtcdVolumeEncryptionKeyARN
+	a.VolumeEncryption.KeyARN = config.StackState.VolumeEncryptionKeyARN
+
+	a.VPCID = config.StackState.VPCID
+	a.TransitGatewayID = config.TransitGatewayID
+
 	return nil
 }
 
+type GuestOutputsAdapterAPIWhitelist struct {
+	APICIDRs     string
+	IngressCIDRs string
+}
+
 type GuestOutputsAdapterMaster struct {
+	Count        string
 	ImageID      string
 	Instance     GuestOutputsAdapterMasterInstance
 	CloudConfig  GuestOutputsAdapterMasterCloudConfig
 	DockerVolume GuestOutputsAdapterMasterDockerVolume
+	// States holds the state of the individual masters of a highly available
+	// control plane.
+	States []GuestOutputsAdapterValue
 }
 
 type GuestOutputsAdapterMasterInstance struct {
@@ -54,13 +131,21 @@ type GuestOutputsAdapterMasterDockerVolume struct {
 	ResourceName string
 }
 
+type GuestOutputsAdapterNATGateway struct {
+	AllocationIDs string
+	Strategy      string
+}
+
 type GuestOutputsAdapterWorker struct {
-	ASG                GuestOutputsAdapterWorkerASG
-	Count              string
-	DockerVolumeSizeGB string
-	ImageID            string
-	InstanceType       string
-	CloudConfig        GuestOutputsAdapterWorkerCloudConfig
+	ASG                  GuestOutputsAdapterWorkerASG
+	Count                string
+	DockerVolumeSizeGB   string
+	ImageID              string
+	InstanceType         string
+	LaunchTemplate       GuestOutputsAdapterWorkerLaunchTemplate
+	InstanceDistribution GuestOutputsAdapterWorkerInstanceDistribution
+	CloudConfig          GuestOutputsAdapterWorkerCloudConfig
+	Scaling              GuestOutputsAdapterWorkerScaling
 }
 
 type GuestOutputsAdapterWorkerASG struct {
@@ -68,10 +153,48 @@ type GuestOutputsAdapterWorkerASG struct {
 	Ref string
 }
 
+type GuestOutputsAdapterWorkerLaunchTemplate struct {
+	Key string
+	Ref string
+}
+
+type GuestOutputsAdapterWorkerInstanceDistribution struct {
+	Enabled                bool
+	InstanceTypes          string
+	OnDemandBaseCapacity   string
+	SpotAllocationStrategy string
+	SpotPercentage         string
+}
+
 type GuestOutputsAdapterWorkerCloudConfig struct {
 	Version string
 }
 
+type GuestOutputsAdapterWorkerScaling struct {
+	Enabled bool
+	MaxSize string
+	MinSize string
+}
+
+type GuestOutputsAdapterNodePool struct {
+	ASG                GuestOutputsAdapterWorkerASG
+	CloudConfig        GuestOutputsAdapterValue
+	DockerVolumeSizeGB GuestOutputsAdapterValue
+	InstanceType       GuestOutputsAdapterValue
+	MaxSize            GuestOutputsAdapterValue
+	MinSize            GuestOutputsAdapterValue
+}
+
+type GuestOutputsAdapterValue struct {
+	Key   string
+	Value string
+}
+
 type GuestOutputsAdapterVersionBundle struct {
 	Version string
 }
+
+type GuestOutputsAdapterVolumeEncryption struct {
+	EtcdKeyARN string
+	KeyARN     string
+}
@@ -7,7 +7,20 @@ type GuestRecordSetsAdapter struct {
 	EtcdDomain                 string
 	ClusterID                  string
 	MasterInstanceResourceName string
-	Route53Enabled             bool
+	Masters                    []GuestRecordSetsAdapterMaster
+	// PrivateAPI is true in case the API record set is kept in a private hosted
+	// zone associated with the tenant cluster VPC. PrivateAPIHostedZoneName is
+	// the name of this zone.
+	PrivateAPI               bool
+	PrivateAPIHostedZoneName string
+	Region                   string
+	Route53Enabled           bool
+}
+
+type GuestRecordSetsAdapterMaster struct {
+	EtcdMemberDomain     string
+	InstanceResourceName string
+	RecordSetName        string
 }
 
 func (a *GuestRecordSetsAdapter) Adapt(config Config) error {
@@ -15,7 +28,24 @@ func (a *GuestRecordSetsAdapter) Adapt(config Config) error {
 	a.EtcdDomain = key.EtcdDomain(config.CustomObject)
 	a.ClusterID = key.ClusterID(config.CustomObject)
 	a.MasterInstanceResourceName = config.StackState.MasterInstanceResourceName
+	a.PrivateAPI = key.PrivateAPIEndpoint(config.CustomObject)
+	a.PrivateAPIHostedZoneName = key.PrivateAPIHostedZoneName(config.CustomObject)
+	a.Region = key.Region(config.CustomObject)
 	a.Route53Enabled = config.Route53Enabled
 
+	// The etcd members of highly available control planes find their peers
+	// using the record sets of the individual masters.
+	if masterCount(config) > 1 {
+		for i := 0; i < masterCount(config); i++ {
+			m := GuestRecordSetsAdapterMaster{
+				EtcdMemberDomain:     key.EtcdMemberDomain(config.CustomObject, i),
+				InstanceResourceName: masterState(config, i).InstanceResourceName,
+				RecordSetName:        key.EtcdMemberRecordSetName(i),
+			}
+
+			a.Masters = append(a.Masters, m)
+		}
+	}
+
 	return nil
 }
@@ -1,21 +1,29 @@
 package adapter
 
 import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/giantswarm/microerror"
 
 	"github.com/giantswarm/aws-operator/service/controller/v21/key"
 )
 
 type RouteTableName struct {
-	ResourceName        string
-	TagName             string
-	VPCPeeringRouteName string
+	ID                      string
+	ResourceName            string
+	TagName                 string
+	TransitGatewayRouteName string
+	VPCPeeringRouteName     string
 }
 
 type GuestRouteTablesAdapter struct {
 	HostClusterCIDR        string
+	Managed                bool
 	PublicRouteTableName   RouteTableName
 	PrivateRouteTableNames []RouteTableName
+	TransitGatewayID       string
 }
 
 func (r *GuestRouteTablesAdapter) Adapt(cfg Config) error {
@@ -25,6 +33,33 @@ func (r *GuestRouteTablesAdapter) Adapt(cfg Config) error {
 	}
 
 	r.HostClusterCIDR = hostClusterCIDR
+	r.Managed = cfg.StackState.VPCID == ""
+	r.TransitGatewayID = cfg.TransitGatewayID
+
+	// The routes towards the host cluster of guest clusters deployed into an
+	// existing VPC are added to the existing route tables of the private
+	// subnets.
+	if !r.Managed {
+		ids, err := existingPrivateRouteTableIDs(cfg)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for i, id := range ids {
+			rtName := RouteTableName{
+				ID: id,
+			}
+			if cfg.TransitGatewayID != "" {
+				rtName.TransitGatewayRouteName = key.TransitGatewayRouteName(i)
+			} else {
+				rtName.VPCPeeringRouteName = key.VPCPeeringRouteName(i)
+			}
+			r.PrivateRouteTableNames = append(r.PrivateRouteTableNames, rtName)
+		}
+
+		return nil
+	}
+
 	r.PublicRouteTableName = RouteTableName{
 		ResourceName: "PublicRouteTable",
 		TagName:      key.RouteTableName(cfg.CustomObject, suffixPublic, 0),
@@ -32,12 +67,57 @@ func (r *GuestRouteTablesAdapter) Adapt(cfg Config) error {
 
 	for i := 0; i < len(key.StatusAvailabilityZones(cfg.CustomObject)); i++ {
 		rtName := RouteTableName{
-			ResourceName:        key.PrivateRouteTableName(i),
-			TagName:             key.RouteTableName(cfg.CustomObject, suffixPrivate, i),
-			VPCPeeringRouteName: key.VPCPeeringRouteName(i),
+			ResourceName: key.PrivateRouteTableName(i),
+			TagName:      key.RouteTableName(cfg.CustomObject, suffixPrivate, i),
+		}
+		if cfg.TransitGatewayID != "" {
+			rtName.TransitGatewayRouteName = key.TransitGatewayRouteName(i)
+		} else {
+			rtName.VPCPeeringRouteName = key.VPCPeeringRouteName(i)
 		}
 		r.PrivateRouteTableNames = append(r.PrivateRouteTableNames, rtName)
 	}
 
 	return nil
 }
+
+// existingPrivateRouteTableIDs returns the sorted IDs of the route tables
+// explicitly associated with the existing private subnets. Private subnets may
+// share a route table, which gets the route towards the host cluster only
+// once.
+func existingPrivateRouteTableIDs(cfg Config) ([]string, error) {
+	var subnetIDs []*string
+	for _, s := range key.VPCSubnets(cfg.CustomObject) {
+		subnetIDs = append(subnetIDs, aws.String(s.PrivateID))
+	}
+
+	input := &ec2.DescribeRouteTablesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("association.subnet-id"),
+				Values: subnetIDs,
+			},
+		},
+	}
+	output, err := cfg.Clients.EC2.DescribeRouteTables(input)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	if len(output.RouteTables) == 0 {
+		return nil, microerror.Maskf(tooFewResultsError, "route tables of private subnets")
+	}
+
+	seen := map[string]bool{}
+	var ids []string
+	for _, rt := range output.RouteTables {
+		id := aws.StringValue(rt.RouteTableId)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids, nil
+}
@@ -12,6 +12,7 @@ func TestAdapterRouteTablesRegularFields(t *testing.T) {
 	testCases := []struct {
 		description                    string
 		customObject                   v1alpha1.AWSConfig
+		transitGatewayID               string
 		expectedError                  bool
 		expectedHostClusterCIDR        string
 		expectedPublicRouteTableName   RouteTableName
@@ -60,6 +61,50 @@ func TestAdapterRouteTablesRegularFields(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "transit gateway routes instead of peering routes",
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						AvailabilityZones: 2,
+					},
+					Cluster: v1alpha1.Cluster{
+						ID: "test-cluster",
+					},
+				},
+				Status: v1alpha1.AWSConfigStatus{
+					AWS: v1alpha1.AWSConfigStatusAWS{
+						AvailabilityZones: []v1alpha1.AWSConfigStatusAWSAvailabilityZone{
+							v1alpha1.AWSConfigStatusAWSAvailabilityZone{
+								Name: "eu-central-1a",
+							},
+							v1alpha1.AWSConfigStatusAWSAvailabilityZone{
+								Name: "eu-central-1b",
+							},
+						},
+					},
+				},
+			},
+			transitGatewayID:        "tgw-0123456789abcdef0",
+			expectedError:           false,
+			expectedHostClusterCIDR: "10.0.0.0/16",
+			expectedPublicRouteTableName: RouteTableName{
+				ResourceName: "PublicRouteTable",
+				TagName:      "test-cluster-public",
+			},
+			expectedPrivateRouteTableNames: []RouteTableName{
+				{
+					ResourceName:            "PrivateRouteTable",
+					TagName:                 "test-cluster-private",
+					TransitGatewayRouteName: "TransitGatewayRoute00",
+				},
+				{
+					ResourceName:            "PrivateRouteTable01",
+					TagName:                 "test-cluster-private01",
+					TransitGatewayRouteName: "TransitGatewayRoute01",
+				},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -74,9 +119,10 @@ func TestAdapterRouteTablesRegularFields(t *testing.T) {
 
 		t.Run(tc.description, func(t *testing.T) {
 			cfg := Config{
-				CustomObject: tc.customObject,
-				Clients:      Clients{},
-				HostClients:  hostClients,
+				CustomObject:     tc.customObject,
+				Clients:          Clients{},
+				HostClients:      hostClients,
+				TransitGatewayID: tc.transitGatewayID,
 			}
 			err := a.Guest.RouteTables.Adapt(cfg)
 			if tc.expectedError && err == nil {
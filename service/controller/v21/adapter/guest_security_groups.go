@@ -24,7 +24,8 @@ const (
 	allProtocols = "-1"
 	tcpProtocol  = "tcp"
 
-	defaultCIDR = "0.0.0.0/0"
+	defaultCIDR     = "0.0.0.0/0"
+	defaultIPv6CIDR = "::/0"
 
 	ingressSecurityGroupName = "IngressSecurityGroup"
 )
@@ -52,19 +53,21 @@ func (s *GuestSecurityGroupsAdapter) Adapt(cfg Config) error {
 		return microerror.Mask(err)
 	}
 
-	s.APIWhitelistEnabled = cfg.APIWhitelist.Enabled
+	// The NAT gateways of the tenant cluster only need to be whitelisted in
+	// case the API is public. Workers reach a private API within the VPC.
+	s.APIWhitelistEnabled = apiWhitelistEnabled(cfg) && !key.PrivateAPIEndpoint(cfg.CustomObject)
 
 	s.MasterSecurityGroupName = key.SecurityGroupName(cfg.CustomObject, key.KindMaster)
-	s.MasterSecurityGroupRules = masterRules
+	s.MasterSecurityGroupRules = withIPv6Rules(cfg.CustomObject, masterRules)
 
 	s.WorkerSecurityGroupName = key.SecurityGroupName(cfg.CustomObject, key.KindWorker)
-	s.WorkerSecurityGroupRules = s.getWorkerRules(cfg.CustomObject, hostClusterCIDR)
+	s.WorkerSecurityGroupRules = withIPv6Rules(cfg.CustomObject, s.getWorkerRules(cfg, hostClusterCIDR))
 
 	s.IngressSecurityGroupName = key.SecurityGroupName(cfg.CustomObject, key.KindIngress)
-	s.IngressSecurityGroupRules = s.getIngressRules(cfg.CustomObject)
+	s.IngressSecurityGroupRules = withIPv6Rules(cfg.CustomObject, s.getIngressRules(cfg.CustomObject))
 
 	s.EtcdELBSecurityGroupName = key.SecurityGroupName(cfg.CustomObject, key.KindEtcd)
-	s.EtcdELBSecurityGroupRules = s.getEtcdRules(cfg.CustomObject, hostClusterCIDR)
+	s.EtcdELBSecurityGroupRules = withIPv6Rules(cfg.CustomObject, s.getEtcdRules(cfg.CustomObject, hostClusterCIDR))
 
 	return nil
 }
@@ -117,11 +120,27 @@ func (s *GuestSecurityGroupsAdapter) getMasterRules(cfg Config, hostClusterCIDR
 		},
 	}
 
+	// Network Load Balancers preserve the source IP of their clients and do not
+	// have security groups. The etcd clients within the VPC and the health
+	// checks of the etcd Network Load Balancer have to reach the masters
+	// directly.
+	if key.EtcdLoadBalancerType(cfg.CustomObject) == key.LoadBalancerTypeNetwork {
+		etcdRule := securityGroupRule{
+			Description: "Allow traffic from tenant cluster CIDR to 2379 for the etcd network load balancer.",
+			Port:        etcdPort,
+			Protocol:    tcpProtocol,
+			SourceCIDR:  key.ClusterNetworkCIDR(cfg.CustomObject),
+		}
+		otherRules = append(otherRules, etcdRule)
+	}
+
 	return append(apiRules, otherRules...), nil
 }
 
-func (s *GuestSecurityGroupsAdapter) getWorkerRules(customObject v1alpha1.AWSConfig, hostClusterCIDR string) []securityGroupRule {
-	return []securityGroupRule{
+func (s *GuestSecurityGroupsAdapter) getWorkerRules(cfg Config, hostClusterCIDR string) []securityGroupRule {
+	customObject := cfg.CustomObject
+
+	ingressRules := []securityGroupRule{
 		{
 			Description:         "Allow traffic from the ingress security group to the ingress controller port 443.",
 			Port:                key.IngressControllerSecurePort(customObject),
@@ -134,6 +153,40 @@ func (s *GuestSecurityGroupsAdapter) getWorkerRules(customObject v1alpha1.AWSCon
 			Protocol:            tcpProtocol,
 			SourceSecurityGroup: ingressSecurityGroupName,
 		},
+	}
+
+	// The ingress Network Load Balancer preserves the source IP of its clients
+	// and does not have a security group. The ingress controller ports are
+	// reachable from within the guest cluster VPC, which covers the health
+	// checks of the load balancer, and from the configured source CIDRs.
+	if key.IngressLoadBalancerType(customObject) == key.LoadBalancerTypeNetwork {
+		sourceCIDRs := []string{key.ClusterNetworkCIDR(customObject)}
+		if cfg.IngressNLB.SourceCIDRs != "" {
+			for _, c := range strings.Split(cfg.IngressNLB.SourceCIDRs, ",") {
+				sourceCIDRs = append(sourceCIDRs, strings.TrimSpace(c))
+			}
+		}
+
+		ingressRules = nil
+		for _, c := range sourceCIDRs {
+			ingressRules = append(ingressRules, []securityGroupRule{
+				{
+					Description: fmt.Sprintf("Allow traffic from %s to the ingress controller port 443 through the ingress network load balancer.", c),
+					Port:        key.IngressControllerSecurePort(customObject),
+					Protocol:    tcpProtocol,
+					SourceCIDR:  c,
+				},
+				{
+					Description: fmt.Sprintf("Allow traffic from %s to the ingress controller port 80 through the ingress network load balancer.", c),
+					Port:        key.IngressControllerInsecurePort(customObject),
+					Protocol:    tcpProtocol,
+					SourceCIDR:  c,
+				},
+			}...)
+		}
+	}
+
+	otherRules := []securityGroupRule{
 		{
 			Description: "Allow traffic from control plane to ingress controller secure port for tenant cluster scraping.",
 			Port:        key.IngressControllerSecurePort(customObject),
@@ -171,9 +224,34 @@ func (s *GuestSecurityGroupsAdapter) getWorkerRules(customObject v1alpha1.AWSCon
 			SourceCIDR:  hostClusterCIDR,
 		},
 	}
+
+	return append(ingressRules, otherRules...)
 }
 
 func (s *GuestSecurityGroupsAdapter) getIngressRules(customObject v1alpha1.AWSConfig) []securityGroupRule {
+	// The ingress load balancer is only reachable from the whitelisted CIDRs of
+	// the tenant cluster in case there are any.
+	if len(key.IngressWhitelistCIDRs(customObject)) > 0 {
+		var rules []securityGroupRule
+		for _, cidr := range uniqueCIDRs(key.IngressWhitelistCIDRs(customObject)) {
+			httpRule := securityGroupRule{
+				Description: "Allow http traffic from whitelisted CIDR to the ingress load balancer.",
+				Port:        httpPort,
+				Protocol:    tcpProtocol,
+				SourceCIDR:  cidr,
+			}
+			httpsRule := securityGroupRule{
+				Description: "Allow https traffic from whitelisted CIDR to the ingress load balancer.",
+				Port:        httpsPort,
+				Protocol:    tcpProtocol,
+				SourceCIDR:  cidr,
+			}
+			rules = append(rules, httpRule, httpsRule)
+		}
+
+		return rules
+	}
+
 	return []securityGroupRule{
 		{
 			Description: "Allow all http traffic to the ingress load balancer.",
@@ -212,12 +290,55 @@ type securityGroupRule struct {
 	Port                int
 	Protocol            string
 	SourceCIDR          string
+	SourceIPv6CIDR      string
 	SourceSecurityGroup string
 }
 
+// withIPv6Rules duplicates all rules allowing traffic from everywhere for IPv6
+// in case the tenant cluster VPC is dual-stack.
+func withIPv6Rules(customObject v1alpha1.AWSConfig, rules []securityGroupRule) []securityGroupRule {
+	if !key.IPv6Enabled(customObject) {
+		return rules
+	}
+
+	var ipv6Rules []securityGroupRule
+	for _, r := range rules {
+		if r.SourceCIDR != defaultCIDR {
+			continue
+		}
+
+		r.SourceCIDR = ""
+		r.SourceIPv6CIDR = defaultIPv6CIDR
+		ipv6Rules = append(ipv6Rules, r)
+	}
+
+	return append(rules, ipv6Rules...)
+}
+
 func getKubernetesAPIRules(cfg Config, hostClusterCIDR string) ([]securityGroupRule, error) {
+	// When the API is private, only allow traffic from the tenant cluster VPC
+	// and the host cluster VPC.
+	if key.PrivateAPIEndpoint(cfg.CustomObject) {
+		rules := []securityGroupRule{
+			{
+				Description: "Allow traffic from control plane CIDR.",
+				Port:        key.KubernetesAPISecurePort(cfg.CustomObject),
+				Protocol:    tcpProtocol,
+				SourceCIDR:  hostClusterCIDR,
+			},
+			{
+				Description: "Allow traffic from tenant cluster CIDR.",
+				Port:        key.KubernetesAPISecurePort(cfg.CustomObject),
+				Protocol:    tcpProtocol,
+				SourceCIDR:  key.ClusterNetworkCIDR(cfg.CustomObject),
+			},
+		}
+
+		return rules, nil
+	}
+
 	// When API whitelisting is enabled, add separate security group rule per each subnet.
-	if cfg.APIWhitelist.Enabled {
+	if apiWhitelistEnabled(cfg) {
 		rules := []securityGroupRule{
 			{
 				Description: "Allow traffic from control plane CIDR.",
@@ -233,18 +354,16 @@ func getKubernetesAPIRules(cfg Config, hostClusterCIDR string) ([]securityGroupR
 			},
 		}
 
-		// Whitelist all configured subnets.
-		whitelistSubnets := strings.Split(cfg.APIWhitelist.SubnetList, ",")
-		for _, subnet := range whitelistSubnets {
-			if subnet != "" {
-				subnetRule := securityGroupRule{
-					Description: "Custom Whitelist CIDR.",
-					Port:        key.KubernetesAPISecurePort(cfg.CustomObject),
-					Protocol:    tcpProtocol,
-					SourceCIDR:  subnet,
-				}
-				rules = append(rules, subnetRule)
+		// Whitelist all configured subnets of the installation and the tenant
+		// cluster.
+		for _, subnet := range apiWhitelistCIDRs(cfg) {
+			subnetRule := securityGroupRule{
+				Description: "Custom Whitelist CIDR.",
+				Port:        key.KubernetesAPISecurePort(cfg.CustomObject),
+				Protocol:    tcpProtocol,
+				SourceCIDR:  subnet,
 			}
+			rules = append(rules, subnetRule)
 		}
 
 		// Whitelist public EIPs of the host cluster NAT gateways.
@@ -273,6 +392,44 @@ func getKubernetesAPIRules(cfg Config, hostClusterCIDR string) ([]securityGroupR
 	}
 }
 
+// apiWhitelistEnabled returns true in case access to the Kubernetes API of the
+// tenant cluster is restricted, either by the whitelist of the installation or
+// by CIDRs whitelisted for the tenant cluster.
+func apiWhitelistEnabled(cfg Config) bool {
+	return cfg.APIWhitelist.Enabled || len(key.APIWhitelistCIDRs(cfg.CustomObject)) > 0
+}
+
+// apiWhitelistCIDRs merges the subnets whitelisted by the installation with
+// the CIDRs whitelisted for the tenant cluster. The installation subnets are
+// only used in case the installation whitelist is enabled.
+func apiWhitelistCIDRs(cfg Config) []string {
+	var cidrs []string
+	if cfg.APIWhitelist.Enabled {
+		cidrs = append(cidrs, strings.Split(cfg.APIWhitelist.SubnetList, ",")...)
+	}
+	cidrs = append(cidrs, key.APIWhitelistCIDRs(cfg.CustomObject)...)
+
+	return uniqueCIDRs(cidrs)
+}
+
+// uniqueCIDRs returns the given CIDRs in their original order without empty
+// and duplicated entries.
+func uniqueCIDRs(cidrs []string) []string {
+	var unique []string
+	seen := map[string]bool{}
+	for _, c := range cidrs {
+		c = strings.TrimSpace(c)
+		if c == "" || seen[c] {
+			continue
+		}
+
+		seen[c] = true
+		unique = append(unique, c)
+	}
+
+	return unique
+}
+
 func getHostClusterNATGatewayRules(cfg Config) ([]securityGroupRule, error) {
 	gatewayRules := []securityGroupRule{}
 
@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+
+	"github.com/giantswarm/aws-operator/service/controller/v21/key"
 )
 
 func TestAdapterSecurityGroupsRegularFields(t *testing.T) {
@@ -499,6 +501,124 @@ func TestAdapterSecurityGroupsKubernetesAPIRules(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "case 6: API whitelisting disabled with CIDRs of the tenant cluster",
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						APIWhitelist: v1alpha1.AWSConfigSpecAWSAPIWhitelist{
+							APICIDRs: []string{
+								"212.145.136.84/32",
+							},
+						},
+					},
+					Cluster: v1alpha1.Cluster{
+						ID: "test-cluster",
+						Kubernetes: v1alpha1.ClusterKubernetes{
+							API: v1alpha1.ClusterKubernetesAPI{
+								SecurePort: 443,
+							},
+						},
+					},
+				},
+				Status: v1alpha1.AWSConfigStatus{
+					Cluster: v1alpha1.StatusCluster{
+						Network: v1alpha1.StatusClusterNetwork{
+							CIDR: "10.1.1.0/24",
+						},
+					},
+				},
+			},
+			apiWhitelistingEnabled: false,
+			hostClusterCIDR:        "10.0.0.0/16",
+			expectedError:          false,
+			expectedRules: []securityGroupRule{
+				{
+					Description: "Allow traffic from control plane CIDR.",
+					Port:        443,
+					Protocol:    "tcp",
+					SourceCIDR:  "10.0.0.0/16",
+				},
+				{
+					Description: "Allow traffic from tenant cluster CIDR.",
+					Port:        443,
+					Protocol:    "tcp",
+					SourceCIDR:  "10.1.1.0/24",
+				},
+				{
+					Description: "Custom Whitelist CIDR.",
+					Port:        443,
+					Protocol:    "tcp",
+					SourceCIDR:  "212.145.136.84/32",
+				},
+			},
+		},
+		{
+			description: "case 7: API whitelisting enabled with subnets merged with CIDRs of the tenant cluster",
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						APIWhitelist: v1alpha1.AWSConfigSpecAWSAPIWhitelist{
+							APICIDRs: []string{
+								"192.168.1.1/24",
+								"172.16.0.0/16",
+							},
+						},
+					},
+					Cluster: v1alpha1.Cluster{
+						ID: "test-cluster",
+						Kubernetes: v1alpha1.ClusterKubernetes{
+							API: v1alpha1.ClusterKubernetesAPI{
+								SecurePort: 443,
+							},
+						},
+					},
+				},
+				Status: v1alpha1.AWSConfigStatus{
+					Cluster: v1alpha1.StatusCluster{
+						Network: v1alpha1.StatusClusterNetwork{
+							CIDR: "10.1.1.0/24",
+						},
+					},
+				},
+			},
+			apiWhitelistingEnabled: true,
+			apiWhitelistSubnets:    "212.145.136.84/32,192.168.1.1/24",
+			hostClusterCIDR:        "10.0.0.0/16",
+			expectedError:          false,
+			expectedRules: []securityGroupRule{
+				{
+					Description: "Allow traffic from control plane CIDR.",
+					Port:        443,
+					Protocol:    "tcp",
+					SourceCIDR:  "10.0.0.0/16",
+				},
+				{
+					Description: "Allow traffic from tenant cluster CIDR.",
+					Port:        443,
+					Protocol:    "tcp",
+					SourceCIDR:  "10.1.1.0/24",
+				},
+				{
+					Description: "Custom Whitelist CIDR.",
+					Port:        443,
+					Protocol:    "tcp",
+					SourceCIDR:  "212.145.136.84/32",
+				},
+				{
+					Description: "Custom Whitelist CIDR.",
+					Port:        443,
+					Protocol:    "tcp",
+					SourceCIDR:  "192.168.1.1/24",
+				},
+				{
+					Description: "Custom Whitelist CIDR.",
+					Port:        443,
+					Protocol:    "tcp",
+					SourceCIDR:  "172.16.0.0/16",
+				},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -539,3 +659,248 @@ func TestAdapterSecurityGroupsKubernetesAPIRules(t *testing.T) {
 		})
 	}
 }
+
+func TestAdapterSecurityGroupsIngressRules(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description   string
+		ingressCIDRs  []string
+		expectedRules []securityGroupRule
+	}{
+		{
+			description: "case 0: ingress whitelisting disabled",
+			expectedRules: []securityGroupRule{
+				{
+					Description: "Allow all http traffic to the ingress load balancer.",
+					Port:        80,
+					Protocol:    "tcp",
+					SourceCIDR:  "0.0.0.0/0",
+				},
+				{
+					Description: "Allow all https traffic to the ingress load balancer.",
+					Port:        443,
+					Protocol:    "tcp",
+					SourceCIDR:  "0.0.0.0/0",
+				},
+			},
+		},
+		{
+			description: "case 1: ingress whitelisting enabled with duplicated CIDRs",
+			ingressCIDRs: []string{
+				"212.145.136.84/32",
+				"192.168.1.0/24",
+				"212.145.136.84/32",
+			},
+			expectedRules: []securityGroupRule{
+				{
+					Description: "Allow http traffic from whitelisted CIDR to the ingress load balancer.",
+					Port:        80,
+					Protocol:    "tcp",
+					SourceCIDR:  "212.145.136.84/32",
+				},
+				{
+					Description: "Allow https traffic from whitelisted CIDR to the ingress load balancer.",
+					Port:        443,
+					Protocol:    "tcp",
+					SourceCIDR:  "212.145.136.84/32",
+				},
+				{
+					Description: "Allow http traffic from whitelisted CIDR to the ingress load balancer.",
+					Port:        80,
+					Protocol:    "tcp",
+					SourceCIDR:  "192.168.1.0/24",
+				},
+				{
+					Description: "Allow https traffic from whitelisted CIDR to the ingress load balancer.",
+					Port:        443,
+					Protocol:    "tcp",
+					SourceCIDR:  "192.168.1.0/24",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			customObject := v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						APIWhitelist: v1alpha1.AWSConfigSpecAWSAPIWhitelist{
+							IngressCIDRs: tc.ingressCIDRs,
+						},
+					},
+				},
+			}
+
+			a := &GuestSecurityGroupsAdapter{}
+			rules := a.getIngressRules(customObject)
+
+			if !reflect.DeepEqual(tc.expectedRules, rules) {
+				t.Fatalf("expected ingress rules %v got %v", tc.expectedRules, rules)
+			}
+		})
+	}
+}
+
+func TestAdapterSecurityGroupsNetworkLoadBalancerWorkerRules(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description   string
+		sourceCIDRs   string
+		expectedRules []securityGroupRule
+	}{
+		{
+			description: "case 0: no source CIDRs configured",
+			expectedRules: []securityGroupRule{
+				{
+					Description: "Allow traffic from 10.1.0.0/24 to the ingress controller port 443 through the ingress network load balancer.",
+					Port:        30011,
+					Protocol:    "tcp",
+					SourceCIDR:  "10.1.0.0/24",
+				},
+				{
+					Description: "Allow traffic from 10.1.0.0/24 to the ingress controller port 80 through the ingress network load balancer.",
+					Port:        30010,
+					Protocol:    "tcp",
+					SourceCIDR:  "10.1.0.0/24",
+				},
+			},
+		},
+		{
+			description: "case 1: source CIDRs configured",
+			sourceCIDRs: "0.0.0.0/0",
+			expectedRules: []securityGroupRule{
+				{
+					Description: "Allow traffic from 10.1.0.0/24 to the ingress controller port 443 through the ingress network load balancer.",
+					Port:        30011,
+					Protocol:    "tcp",
+					SourceCIDR:  "10.1.0.0/24",
+				},
+				{
+					Description: "Allow traffic from 10.1.0.0/24 to the ingress controller port 80 through the ingress network load balancer.",
+					Port:        30010,
+					Protocol:    "tcp",
+					SourceCIDR:  "10.1.0.0/24",
+				},
+				{
+					Description: "Allow traffic from 0.0.0.0/0 to the ingress controller port 443 through the ingress network load balancer.",
+					Port:        30011,
+					Protocol:    "tcp",
+					SourceCIDR:  "0.0.0.0/0",
+				},
+				{
+					Description: "Allow traffic from 0.0.0.0/0 to the ingress controller port 80 through the ingress network load balancer.",
+					Port:        30010,
+					Protocol:    "tcp",
+					SourceCIDR:  "0.0.0.0/0",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			cfg := Config{
+				CustomObject: v1alpha1.AWSConfig{
+					Spec: v1alpha1.AWSConfigSpec{
+						AWS: v1alpha1.AWSConfigSpecAWS{
+							LoadBalancers: v1alpha1.AWSConfigSpecAWSLoadBalancers{
+								Ingress: v1alpha1.AWSConfigSpecAWSLoadBalancer{
+									Type: key.LoadBalancerTypeNetwork,
+								},
+							},
+						},
+						Cluster: v1alpha1.Cluster{
+							Kubernetes: v1alpha1.ClusterKubernetes{
+								IngressController: v1alpha1.ClusterKubernetesIngressController{
+									InsecurePort: 30010,
+									SecurePort:   30011,
+								},
+							},
+						},
+					},
+					Status: v1alpha1.AWSConfigStatus{
+						Cluster: v1alpha1.StatusCluster{
+							Network: v1alpha1.StatusClusterNetwork{
+								CIDR: "10.1.0.0/24",
+							},
+						},
+					},
+				},
+				IngressNLB: IngressNLB{
+					SourceCIDRs: tc.sourceCIDRs,
+				},
+			}
+
+			a := &GuestSecurityGroupsAdapter{}
+			rules := a.getWorkerRules(cfg, "10.0.0.0/16")
+
+			if !reflect.DeepEqual(tc.expectedRules, rules[:len(tc.expectedRules)]) {
+				t.Fatalf("expected worker rules %v got %v", tc.expectedRules, rules[:len(tc.expectedRules)])
+			}
+		})
+	}
+}
+
+func TestAdapterSecurityGroupsIPv6Rules(t *testing.T) {
+	t.Parallel()
+	rules := []securityGroupRule{
+		{
+			Description: "Allow all http traffic to the ingress load balancer.",
+			Port:        httpPort,
+			Protocol:    tcpProtocol,
+			SourceCIDR:  defaultCIDR,
+		},
+		{
+			Description: "Only allow ssh traffic from the control plane.",
+			Port:        sshPort,
+			Protocol:    tcpProtocol,
+			SourceCIDR:  "10.0.0.0/16",
+		},
+	}
+
+	testCases := []struct {
+		description   string
+		ipv6Enabled   bool
+		expectedRules []securityGroupRule
+	}{
+		{
+			description:   "IPv6 disabled, rules unchanged",
+			ipv6Enabled:   false,
+			expectedRules: rules,
+		},
+		{
+			description: "IPv6 enabled, rules allowing everything are duplicated",
+			ipv6Enabled: true,
+			expectedRules: []securityGroupRule{
+				rules[0],
+				rules[1],
+				{
+					Description:    "Allow all http traffic to the ingress load balancer.",
+					Port:           httpPort,
+					Protocol:       tcpProtocol,
+					SourceIPv6CIDR: defaultIPv6CIDR,
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			customObject := v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						VPC: v1alpha1.AWSConfigSpecAWSVPC{
+							IPv6Enabled: tc.ipv6Enabled,
+						},
+					},
+				},
+			}
+
+			result := withIPv6Rules(customObject, append([]securityGroupRule{}, rules...))
+			if !reflect.DeepEqual(result, tc.expectedRules) {
+				t.Errorf("unexpected rules, got %v, want %v", result, tc.expectedRules)
+			}
+		})
+	}
+}
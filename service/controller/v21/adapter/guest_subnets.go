@@ -3,6 +3,7 @@ package adapter
 import (
 	"sort"
 
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
 	"github.com/giantswarm/aws-operator/service/controller/v21/key"
 	"github.com/giantswarm/microerror"
 )
@@ -10,6 +11,8 @@ import (
 type Subnet struct {
 	AvailabilityZone      string
 	CIDR                  string
+	ID                    string
+	IPv6CIDRIndex         int
 	Name                  string
 	MapPublicIPOnLaunch   bool
 	RouteTableAssociation RouteTableAssociation
@@ -22,8 +25,11 @@ type RouteTableAssociation struct {
 }
 
 type GuestSubnetsAdapter struct {
-	PublicSubnets  []Subnet
-	PrivateSubnets []Subnet
+	IPv6Enabled     bool
+	IPv6SubnetCount int
+	Managed         bool
+	PublicSubnets   []Subnet
+	PrivateSubnets  []Subnet
 }
 
 func (s *GuestSubnetsAdapter) Adapt(cfg Config) error {
@@ -39,11 +45,37 @@ func (s *GuestSubnetsAdapter) Adapt(cfg Config) error {
 		}
 	}
 
+	// The /64 IPv6 blocks are carved from the Amazon provided block of the VPC
+	// in the same order the IPv4 blocks are split up by the IPAM resource. The
+	// private subnet of an availability zone comes first, its public subnet
+	// second.
+	s.IPv6Enabled = key.IPv6Enabled(cfg.CustomObject)
+	s.IPv6SubnetCount = len(zones) * 2
+	s.Managed = cfg.StackState.VPCID == ""
+
+	// Guest clusters deployed into an existing VPC reference the existing
+	// subnets of their availability zones instead of creating their own.
+	existingSubnets := map[string]v1alpha1.AWSConfigSpecAWSVPCSubnet{}
+	for _, e := range key.VPCSubnets(cfg.CustomObject) {
+		existingSubnets[e.AvailabilityZone] = e
+	}
+
 	for i, az := range zones {
+		var existing v1alpha1.AWSConfigSpecAWSVPCSubnet
+		if !s.Managed {
+			var ok bool
+			existing, ok = existingSubnets[az.Name]
+			if !ok {
+				return microerror.Maskf(invalidConfigError, "no existing subnets configured for availability zone %#q", az.Name)
+			}
+		}
+
 		snetName := key.PublicSubnetName(i)
 		snet := Subnet{
 			AvailabilityZone:    az.Name,
 			CIDR:                az.Subnet.Public.CIDR,
+			ID:                  existing.PublicID,
+			IPv6CIDRIndex:       i*2 + 1,
 			Name:                snetName,
 			MapPublicIPOnLaunch: false,
 			RouteTableAssociation: RouteTableAssociation{
@@ -58,6 +90,8 @@ func (s *GuestSubnetsAdapter) Adapt(cfg Config) error {
 		snet = Subnet{
 			AvailabilityZone:    az.Name,
 			CIDR:                az.Subnet.Private.CIDR,
+			ID:                  existing.PrivateID,
+			IPv6CIDRIndex:       i * 2,
 			Name:                snetName,
 			MapPublicIPOnLaunch: false,
 			RouteTableAssociation: RouteTableAssociation{
@@ -63,6 +63,7 @@ func TestAdapterSubnetsRegularFields(t *testing.T) {
 				{
 					AvailabilityZone: "eu-west-1a",
 					CIDR:             "10.100.2.0/25",
+					IPv6CIDRIndex:    1,
 					Name:             "PublicSubnet",
 					RouteTableAssociation: RouteTableAssociation{
 						Name:           "PublicSubnetRouteTableAssociation",
@@ -73,6 +74,7 @@ func TestAdapterSubnetsRegularFields(t *testing.T) {
 				{
 					AvailabilityZone: "eu-west-1b",
 					CIDR:             "10.100.1.0/25",
+					IPv6CIDRIndex:    3,
 					Name:             "PublicSubnet01",
 					RouteTableAssociation: RouteTableAssociation{
 						Name:           "PublicSubnetRouteTableAssociation01",
@@ -83,6 +85,7 @@ func TestAdapterSubnetsRegularFields(t *testing.T) {
 				{
 					AvailabilityZone: "eu-west-1c",
 					CIDR:             "10.100.3.0/25",
+					IPv6CIDRIndex:    5,
 					Name:             "PublicSubnet02",
 					RouteTableAssociation: RouteTableAssociation{
 						Name:           "PublicSubnetRouteTableAssociation02",
@@ -95,6 +98,7 @@ func TestAdapterSubnetsRegularFields(t *testing.T) {
 				{
 					AvailabilityZone: "eu-west-1a",
 					CIDR:             "10.100.2.128/25",
+					IPv6CIDRIndex:    0,
 					Name:             "PrivateSubnet",
 					RouteTableAssociation: RouteTableAssociation{
 						Name:           "PrivateSubnetRouteTableAssociation",
@@ -105,6 +109,7 @@ func TestAdapterSubnetsRegularFields(t *testing.T) {
 				{
 					AvailabilityZone: "eu-west-1b",
 					CIDR:             "10.100.1.128/25",
+					IPv6CIDRIndex:    2,
 					Name:             "PrivateSubnet01",
 					RouteTableAssociation: RouteTableAssociation{
 						Name:           "PrivateSubnetRouteTableAssociation01",
@@ -115,6 +120,7 @@ func TestAdapterSubnetsRegularFields(t *testing.T) {
 				{
 					AvailabilityZone: "eu-west-1c",
 					CIDR:             "10.100.3.128/25",
+					IPv6CIDRIndex:    4,
 					Name:             "PrivateSubnet02",
 					RouteTableAssociation: RouteTableAssociation{
 						Name:           "PrivateSubnetRouteTableAssociation02",
@@ -14,8 +14,11 @@ type GuestVPCAdapter struct {
 	ClusterID        string
 	InstallationName string
 	HostAccountID    string
+	ID               string
+	IPv6Enabled      bool
 	PeerVPCID        string
 	PeerRoleArn      string
+	TransitGatewayID string
 }
 
 func (v *GuestVPCAdapter) Adapt(cfg Config) error {
@@ -23,7 +26,16 @@ func (v *GuestVPCAdapter) Adapt(cfg Config) error {
 	v.ClusterID = key.ClusterID(cfg.CustomObject)
 	v.InstallationName = cfg.InstallationName
 	v.HostAccountID = cfg.HostAccountID
+	v.ID = cfg.StackState.VPCID
+	v.IPv6Enabled = key.IPv6Enabled(cfg.CustomObject)
 	v.PeerVPCID = key.PeerID(cfg.CustomObject)
+	v.TransitGatewayID = cfg.TransitGatewayID
+
+	// The peer role is only created in the host cluster account when the tenant
+	// cluster VPC is peered with the host cluster VPC.
+	if v.TransitGatewayID != "" {
+		return nil
+	}
 
 	// PeerRoleArn.
 	roleName := key.PeerAccessRoleName(cfg.CustomObject)
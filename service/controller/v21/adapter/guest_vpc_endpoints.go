@@ -0,0 +1,97 @@
+package adapter
+
+import (
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/aws-operator/service/controller/v21/key"
+)
+
+const (
+	vpcEndpointSecurityGroupName = "vpc-endpoint"
+)
+
+type GuestVPCEndpointsAdapter struct {
+	CIDRBlock          string
+	ECRLayerBucket     string
+	Enabled            bool
+	InterfaceEndpoints []GuestVPCEndpointsAdapterInterfaceEndpoint
+	PrivateSubnets     []string
+	RegionARN          string
+	S3Bucket           string
+	S3ServiceName      string
+	SecurityGroupName  string
+}
+
+func (a *GuestVPCEndpointsAdapter) Adapt(cfg Config) error {
+	a.Enabled = cfg.VPCEndpointsEnabled
+	if !a.Enabled {
+		return nil
+	}
+
+	accountID, err := AccountID(cfg.Clients)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	a.CIDRBlock = key.ClusterNetworkCIDR(cfg.CustomObject)
+	a.ECRLayerBucket = key.ECRLayerBucketName(cfg.CustomObject)
+	a.RegionARN = key.RegionARN(cfg.CustomObject)
+	a.S3Bucket = key.BucketName(cfg.CustomObject, accountID)
+	a.S3ServiceName = key.VPCEndpointServiceName(cfg.CustomObject, key.VPCEndpointServiceS3)
+	a.SecurityGroupName = key.SecurityGroupName(cfg.CustomObject, vpcEndpointSecurityGroupName)
+
+	for i := 0; i < len(key.StatusAvailabilityZones(cfg.CustomObject)); i++ {
+		a.PrivateSubnets = append(a.PrivateSubnets, key.PrivateSubnetName(i))
+	}
+
+	endpoints := []GuestVPCEndpointsAdapterInterfaceEndpoint{
+		{
+			ResourceName: "EC2VPCEndpoint",
+			ServiceName:  key.VPCEndpointServiceName(cfg.CustomObject, key.VPCEndpointServiceEC2),
+		},
+		{
+			ResourceName: "ECRAPIVPCEndpoint",
+			ServiceName:  key.VPCEndpointServiceName(cfg.CustomObject, key.VPCEndpointServiceECRAPI),
+		},
+		{
+			ResourceName: "ECRDockerVPCEndpoint",
+			ServiceName:  key.VPCEndpointServiceName(cfg.CustomObject, key.VPCEndpointServiceECRDocker),
+		},
+		{
+			ResourceName: "ELBVPCEndpoint",
+			ServiceName:  key.VPCEndpointServiceName(cfg.CustomObject, key.VPCEndpointServiceELB),
+		},
+		{
+			ResourceName: "STSVPCEndpoint",
+			ServiceName:  key.VPCEndpointServiceName(cfg.CustomObject, key.VPCEndpointServiceSTS),
+		},
+	}
+
+	// Nodes only talk to KMS for decrypting the assets of the guest cluster,
+	// which is why the KMS endpoint only exists in case they are encrypted with
+	// KMS and is scoped to the KMS key of the guest cluster.
+	kmsKeyARN, err := clusterKMSKeyARN(cfg)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if kmsKeyARN != "" {
+		e := GuestVPCEndpointsAdapterInterfaceEndpoint{
+			KMSKeyARN:    kmsKeyARN,
+			ResourceName: "KMSVPCEndpoint",
+			ServiceName:  key.VPCEndpointServiceName(cfg.CustomObject, key.VPCEndpointServiceKMS),
+		}
+		endpoints = append(endpoints, e)
+	}
+
+	a.InterfaceEndpoints = endpoints
+
+	return nil
+}
+
+type GuestVPCEndpointsAdapterInterfaceEndpoint struct {
+	// KMSKeyARN is the ARN of the only KMS key the endpoint policy allows to
+	// use. It is only set for the KMS endpoint.
+	KMSKeyARN    string
+	ResourceName string
+	ServiceName  string
+}
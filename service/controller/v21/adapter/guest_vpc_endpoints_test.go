@@ -0,0 +1,100 @@
+package adapter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+)
+
+func TestAdapterVPCEndpoints(t *testing.T) {
+	t.Parallel()
+	customObject := v1alpha1.AWSConfig{
+		Spec: v1alpha1.AWSConfigSpec{
+			Cluster: defaultCluster,
+			AWS: v1alpha1.AWSConfigSpecAWS{
+				Region: "eu-central-1",
+			},
+		},
+		Status: v1alpha1.AWSConfigStatus{
+			AWS: v1alpha1.AWSConfigStatusAWS{
+				AvailabilityZones: []v1alpha1.AWSConfigStatusAWSAvailabilityZone{
+					{Name: "eu-central-1a"},
+					{Name: "eu-central-1b"},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		description                string
+		enabled                    bool
+		encrypterBackend           string
+		expectedInterfaceEndpoints []GuestVPCEndpointsAdapterInterfaceEndpoint
+		expectedPrivateSubnets     []string
+	}{
+		{
+			description:      "disabled",
+			enabled:          false,
+			encrypterBackend: "kms",
+		},
+		{
+			description:      "enabled with vault backend",
+			enabled:          true,
+			encrypterBackend: "vault",
+			expectedInterfaceEndpoints: []GuestVPCEndpointsAdapterInterfaceEndpoint{
+				{ResourceName: "EC2VPCEndpoint", ServiceName: "com.amazonaws.eu-central-1.ec2"},
+				{ResourceName: "ECRAPIVPCEndpoint", ServiceName: "com.amazonaws.eu-central-1.ecr.api"},
+				{ResourceName: "ECRDockerVPCEndpoint", ServiceName: "com.amazonaws.eu-central-1.ecr.dkr"},
+				{ResourceName: "ELBVPCEndpoint", ServiceName: "com.amazonaws.eu-central-1.elasticloadbalancing"},
+				{ResourceName: "STSVPCEndpoint", ServiceName: "com.amazonaws.eu-central-1.sts"},
+			},
+			expectedPrivateSubnets: []string{"PrivateSubnet", "PrivateSubnet01"},
+		},
+		{
+			description:      "enabled with kms backend",
+			enabled:          true,
+			encrypterBackend: "kms",
+			expectedInterfaceEndpoints: []GuestVPCEndpointsAdapterInterfaceEndpoint{
+				{ResourceName: "EC2VPCEndpoint", ServiceName: "com.amazonaws.eu-central-1.ec2"},
+				{ResourceName: "ECRAPIVPCEndpoint", ServiceName: "com.amazonaws.eu-central-1.ecr.api"},
+				{ResourceName: "ECRDockerVPCEndpoint", ServiceName: "com.amazonaws.eu-central-1.ecr.dkr"},
+				{ResourceName: "ELBVPCEndpoint", ServiceName: "com.amazonaws.eu-central-1.elasticloadbalancing"},
+				{ResourceName: "STSVPCEndpoint", ServiceName: "com.amazonaws.eu-central-1.sts"},
+				{KMSKeyARN: "arn:aws:kms:key", ResourceName: "KMSVPCEndpoint", ServiceName: "com.amazonaws.eu-central-1.kms"},
+			},
+			expectedPrivateSubnets: []string{"PrivateSubnet", "PrivateSubnet01"},
+		},
+	}
+
+	for _, tc := range testCases {
+		a := Adapter{}
+		t.Run(tc.description, func(t *testing.T) {
+			cfg := Config{
+				Clients: Clients{
+					KMS: &KMSClientMock{keyARN: "arn:aws:kms:key"},
+					STS: &STSClientMock{},
+				},
+				CustomObject:        customObject,
+				EncrypterBackend:    tc.encrypterBackend,
+				VPCEndpointsEnabled: tc.enabled,
+			}
+			err := a.Guest.VPCEndpoints.Adapt(cfg)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			if a.Guest.VPCEndpoints.Enabled != tc.enabled {
+				t.Errorf("unexpected Enabled, got %t, want %t", a.Guest.VPCEndpoints.Enabled, tc.enabled)
+			}
+
+			if !reflect.DeepEqual(a.Guest.VPCEndpoints.InterfaceEndpoints, tc.expectedInterfaceEndpoints) {
+				t.Errorf("unexpected InterfaceEndpoints, got %#v, want %#v", a.Guest.VPCEndpoints.InterfaceEndpoints, tc.expectedInterfaceEndpoints)
+			}
+
+			if !reflect.DeepEqual(a.Guest.VPCEndpoints.PrivateSubnets, tc.expectedPrivateSubnets) {
+				t.Errorf("unexpected PrivateSubnets, got %#v, want %#v", a.Guest.VPCEndpoints.PrivateSubnets, tc.expectedPrivateSubnets)
+			}
+		})
+	}
+}
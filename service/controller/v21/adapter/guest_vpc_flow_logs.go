@@ -0,0 +1,53 @@
+package adapter
+
+import (
+	"fmt"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/aws-operator/service/controller/v21/key"
+)
+
+type GuestVPCFlowLogsAdapter struct {
+	BucketARN              string
+	BucketName             string
+	Destination            string
+	LogDestinationARN      string
+	LogGroupName           string
+	LogsARN                string
+	MaxAggregationInterval int
+	RoleName               string
+	TrafficType            string
+}
+
+func (a *GuestVPCFlowLogsAdapter) Adapt(cfg Config) error {
+	a.Destination = cfg.VPCFlowLogs.Destination
+	if a.Destination == "" {
+		return nil
+	}
+
+	a.MaxAggregationInterval = cfg.VPCFlowLogs.MaxAggregationInterval
+	a.TrafficType = cfg.VPCFlowLogs.TrafficType
+
+	switch a.Destination {
+	case key.VPCFlowLogsDestinationCloudWatch:
+		a.LogGroupName = key.VPCFlowLogsLogGroupName(cfg.CustomObject)
+		a.RoleName = key.VPCFlowLogsRoleName(cfg.CustomObject)
+	case key.VPCFlowLogsDestinationS3:
+		accountID, err := AccountID(cfg.Clients)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		a.BucketName = key.TargetLogBucketName(cfg.CustomObject)
+		a.BucketARN = fmt.Sprintf("arn:%s:s3:::%s", key.RegionARN(cfg.CustomObject), a.BucketName)
+		a.LogDestinationARN = fmt.Sprintf("%s/%s/", a.BucketARN, key.VPCFlowLogsPrefix(cfg.CustomObject))
+		// The log delivery service writes the flow logs below the AWSLogs folder
+		// of the account within the configured prefix.
+		a.LogsARN = fmt.Sprintf("%s/%s/AWSLogs/%s/*", a.BucketARN, key.VPCFlowLogsPrefix(cfg.CustomObject), accountID)
+	default:
+		return microerror.Maskf(invalidConfigError, "unknown VPC flow logs destination %#q", a.Destination)
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package adapter
+
+import (
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+)
+
+func TestAdapterVPCFlowLogs(t *testing.T) {
+	t.Parallel()
+	customObject := v1alpha1.AWSConfig{
+		Spec: v1alpha1.AWSConfigSpec{
+			Cluster: defaultCluster,
+			AWS: v1alpha1.AWSConfigSpecAWS{
+				Region: "eu-central-1",
+			},
+		},
+	}
+
+	testCases := []struct {
+		description               string
+		vpcFlowLogs               VPCFlowLogs
+		expectedBucketName        string
+		expectedLogDestinationARN string
+		expectedLogGroupName      string
+		expectedLogsARN           string
+		expectedRoleName          string
+		expectedError             bool
+	}{
+		{
+			description: "disabled",
+		},
+		{
+			description: "cloudwatch destination",
+			vpcFlowLogs: VPCFlowLogs{
+				Destination:            "cloudwatch",
+				MaxAggregationInterval: 600,
+				TrafficType:            "ALL",
+			},
+			expectedLogGroupName: "test-cluster-vpc-flow-logs",
+			expectedRoleName:     "test-cluster-vpc-flow-logs",
+		},
+		{
+			description: "s3 destination",
+			vpcFlowLogs: VPCFlowLogs{
+				Destination:            "s3",
+				MaxAggregationInterval: 60,
+				TrafficType:            "REJECT",
+			},
+			expectedBucketName:        "test-cluster-g8s-access-logs",
+			expectedLogDestinationARN: "arn:aws:s3:::test-cluster-g8s-access-logs/vpc-flow-logs/test-cluster/",
+			expectedLogsARN:           "arn:aws:s3:::test-cluster-g8s-access-logs/vpc-flow-logs/test-cluster/AWSLogs/000000000000/*",
+		},
+		{
+			description: "unknown destination",
+			vpcFlowLogs: VPCFlowLogs{
+				Destination: "kinesis",
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		a := Adapter{}
+		t.Run(tc.description, func(t *testing.T) {
+			cfg := Config{
+				Clients: Clients{
+					STS: &STSClientMock{},
+				},
+				CustomObject: customObject,
+				VPCFlowLogs:  tc.vpcFlowLogs,
+			}
+			err := a.Guest.VPCFlowLogs.Adapt(cfg)
+			if tc.expectedError && err == nil {
+				t.Fatalf("expected error didn't happen")
+			}
+			if tc.expectedError {
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			v := a.Guest.VPCFlowLogs
+			if v.Destination != tc.vpcFlowLogs.Destination {
+				t.Errorf("unexpected Destination, got %q, want %q", v.Destination, tc.vpcFlowLogs.Destination)
+			}
+			if v.MaxAggregationInterval != tc.vpcFlowLogs.MaxAggregationInterval {
+				t.Errorf("unexpected MaxAggregationInterval, got %d, want %d", v.MaxAggregationInterval, tc.vpcFlowLogs.MaxAggregationInterval)
+			}
+			if v.TrafficType != tc.vpcFlowLogs.TrafficType {
+				t.Errorf("unexpected TrafficType, got %q, want %q", v.TrafficType, tc.vpcFlowLogs.TrafficType)
+			}
+			if v.BucketName != tc.expectedBucketName {
+				t.Errorf("unexpected BucketName, got %q, want %q", v.BucketName, tc.expectedBucketName)
+			}
+			if v.LogDestinationARN != tc.expectedLogDestinationARN {
+				t.Errorf("unexpected LogDestinationARN, got %q, want %q", v.LogDestinationARN, tc.expectedLogDestinationARN)
+			}
+			if v.LogGroupName != tc.expectedLogGroupName {
+				t.Errorf("unexpected LogGroupName, got %q, want %q", v.LogGroupName, tc.expectedLogGroupName)
+			}
+			if v.LogsARN != tc.expectedLogsARN {
+				t.Errorf("unexpected LogsARN, got %q, want %q", v.LogsARN, tc.expectedLogsARN)
+			}
+			if v.RoleName != tc.expectedRoleName {
+				t.Errorf("unexpected RoleName, got %q, want %q", v.RoleName, tc.expectedRoleName)
+			}
+		})
+	}
+}
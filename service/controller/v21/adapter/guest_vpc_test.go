@@ -126,3 +126,34 @@ func TestAdapterVPCPeerRoleField(t *testing.T) {
 		})
 	}
 }
+
+func TestAdapterVPCTransitGateway(t *testing.T) {
+	t.Parallel()
+	customObject := v1alpha1.AWSConfig{
+		Spec: v1alpha1.AWSConfigSpec{
+			Cluster: v1alpha1.Cluster{
+				ID: "test-cluster",
+			},
+		},
+	}
+
+	a := Adapter{}
+	cfg := Config{
+		CustomObject: customObject,
+		// The host cluster IAM client is not set because the peer role must not
+		// be looked up when attaching the VPC to a Transit Gateway.
+		HostClients:      Clients{},
+		TransitGatewayID: "tgw-0123456789abcdef0",
+	}
+	err := a.Guest.VPC.Adapt(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if a.Guest.VPC.TransitGatewayID != "tgw-0123456789abcdef0" {
+		t.Errorf("unexpected TransitGatewayID, got %q, want %q", a.Guest.VPC.TransitGatewayID, "tgw-0123456789abcdef0")
+	}
+	if a.Guest.VPC.PeerRoleArn != "" {
+		t.Errorf("unexpected PeerRoleArn, got %q, want %q", a.Guest.VPC.PeerRoleArn, "")
+	}
+}
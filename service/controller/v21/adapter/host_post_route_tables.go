@@ -22,9 +22,16 @@ type HostPostRouteTablesAdapter struct {
 }
 
 func (i *HostPostRouteTablesAdapter) Adapt(cfg Config) error {
-	peerConnectionID, err := waitForPeeringConnectionID(cfg)
-	if err != nil {
-		return microerror.Mask(err)
+	// Routes towards tenant clusters attached to a Transit Gateway target the
+	// Transit Gateway the host cluster VPC is attached to already. There is no
+	// peering connection to wait for in that case.
+	var peerConnectionID string
+	if cfg.TransitGatewayID == "" {
+		var err error
+		peerConnectionID, err = waitForPeeringConnectionID(cfg)
+		if err != nil {
+			return microerror.Mask(err)
+		}
 	}
 
 	var tenantPrivateSubnetCidrs []string
@@ -47,6 +54,7 @@ func (i *HostPostRouteTablesAdapter) Adapt(cfg Config) error {
 				// Requester CIDR block, we create the peering connection from the guest's private subnets.
 				CidrBlock:        cidrBlock,
 				PeerConnectionID: peerConnectionID,
+				TransitGatewayID: cfg.TransitGatewayID,
 			}
 			i.PrivateRoutes = append(i.PrivateRoutes, rt)
 		}
@@ -67,6 +75,7 @@ func (i *HostPostRouteTablesAdapter) Adapt(cfg Config) error {
 				// guest's CIDR for being able to access Vault's ELB.
 				CidrBlock:        key.ClusterNetworkCIDR(cfg.CustomObject),
 				PeerConnectionID: peerConnectionID,
+				TransitGatewayID: cfg.TransitGatewayID,
 			}
 			i.PublicRoutes = append(i.PublicRoutes, rt)
 		}
@@ -79,6 +88,7 @@ type HostPostRouteTablesAdapterRoute struct {
 	RouteTableID     string
 	CidrBlock        string
 	PeerConnectionID string
+	TransitGatewayID string
 }
 
 // waitForPeeringConnectionID keeps asking for the peering connection ID until it is obtained or
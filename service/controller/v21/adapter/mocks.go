@@ -37,10 +37,13 @@ type EC2ClientMock struct {
 func (e *EC2ClientMock) DescribeAddresses(input *ec2.DescribeAddressesInput) (*ec2.DescribeAddressesOutput, error) {
 	addresses := make([]*ec2.Address, 0)
 
-	for _, eip := range e.elasticIPs {
+	for i, eip := range e.elasticIPs {
 		address := &ec2.Address{
 			PublicIp: aws.String(eip),
 		}
+		if i < len(input.AllocationIds) {
+			address.AllocationId = input.AllocationIds[i]
+		}
 
 		addresses = append(addresses, address)
 	}
@@ -52,6 +55,10 @@ func (e *EC2ClientMock) DescribeAddresses(input *ec2.DescribeAddressesInput) (*e
 	return output, nil
 }
 
+func (e *EC2ClientMock) DescribeNatGateways(input *ec2.DescribeNatGatewaysInput) (*ec2.DescribeNatGatewaysOutput, error) {
+	return &ec2.DescribeNatGatewaysOutput{}, nil
+}
+
 func (e *EC2ClientMock) DescribeSecurityGroups(input *ec2.DescribeSecurityGroupsInput) (*ec2.DescribeSecurityGroupsOutput, error) {
 	if !e.unexistingSg {
 		output := &ec2.DescribeSecurityGroupsOutput{
@@ -24,6 +24,9 @@ const (
 	defaultEBSVolumeSize = 100
 	// defaultEBSVolumeType is the EBS volume type.
 	defaultEBSVolumeType = "gp2"
+	// rootVolumeDeviceName is the device name of the root volume of Container
+	// Linux instances.
+	rootVolumeDeviceName = "/dev/xvda"
 	// rollingUpdatePauseTime is how long to pause ASG operations after creating
 	// new instances. This allows time for new nodes to join the cluster.
 	rollingUpdatePauseTime = "PT15M"
@@ -58,6 +61,23 @@ type APIWhitelist struct {
 	SubnetList string
 }
 
+// IngressNLB defines how the ingress Network Load Balancers of guest clusters
+// forward traffic to the ingress controller running on the workers.
+// SourceCIDRs is a comma separated list of CIDRs allowed to reach the ingress
+// controller ports of the workers in addition to the guest cluster VPC.
+type IngressNLB struct {
+	ProxyProtocol bool
+	SourceCIDRs   string
+}
+
+// VPCFlowLogs defines the delivery of the flow logs of guest cluster VPCs.
+// They are disabled in case Destination is empty.
+type VPCFlowLogs struct {
+	Destination            string
+	MaxAggregationInterval int
+	TrafficType            string
+}
+
 type Clients struct {
 	CloudFormation CFClient
 	EC2            EC2Client
@@ -76,7 +96,21 @@ type StackState struct {
 
 	HostedZoneNameServers string
 
+	EncryptionKeyID string
+
+	EtcdVolumeEncryptionKeyARN string
+	VolumeEncryptionKeyARN     string
+
+	VPCID string
+
+	NATGatewayAllocationIDs []string
+	NATGatewayStrategy      string
+
+	APIWhitelistCIDRs     []string
+	IngressWhitelistCIDRs []string
+
 	DockerVolumeResourceName   string
+	MasterCount                int
 	MasterImageID              string
 	MasterInstanceType         string
 	MasterInstanceResourceName string
@@ -85,6 +119,7 @@ type StackState struct {
 	// version should be used here ever.
 	MasterCloudConfigVersion string
 	MasterInstanceMonitoring bool
+	Masters                  []StackStateMaster
 
 	WorkerCount              string
 	WorkerDockerVolumeSizeGB int
@@ -96,9 +131,40 @@ type StackState struct {
 	// version should be used here ever.
 	WorkerCloudConfigVersion string
 
+	WorkerInstanceTypes          []string
+	WorkerOnDemandBaseCapacity   int
+	WorkerSpotAllocationStrategy string
+	WorkerSpotPercentage         int
+
+	WorkerMaxSize int
+	WorkerMinSize int
+
+	NodePools []StackStateNodePool
+
 	VersionBundleVersion string
 }
 
+type StackStateMaster struct {
+	CloudConfigVersion       string
+	DockerVolumeResourceName string
+	EncryptionKeyID          string
+	ImageID                  string
+	InstanceResourceName     string
+	InstanceType             string
+	VersionBundleVersion     string
+	VolumeEncryptionKeyARN   string
+}
+
+type StackStateNodePool struct {
+	Name string
+
+	CloudConfig        string
+	DockerVolumeSizeGB int
+	InstanceType       string
+	MaxSize            int
+	MinSize            int
+}
+
 // CFClient describes the methods required to be implemented by a CloudFormation
 // AWS client.
 type CFClient interface {
@@ -135,12 +201,41 @@ type KMSClient interface {
 	DescribeKey(*kms.DescribeKeyInput) (*kms.DescribeKeyOutput, error)
 }
 
+// EtcdClusterMemberConfig represents the data structure required for executing
+// the etcd cluster member drop-in template.
+type EtcdClusterMemberConfig struct {
+	ClientDomain   string
+	ClientPort     int
+	InitialCluster string
+	Name           string
+	PeerDomain     string
+	PeerPort       int
+}
+
+// EtcdRestoreEnvironmentConfig represents the data structure required for
+// executing the etcd restore environment template.
+type EtcdRestoreEnvironmentConfig struct {
+	InitialCluster string
+	Name           string
+	PeerEndpoints  string
+	PeerURL        string
+}
+
 // SmallCloudconfigConfig represents the data structure required for executing
 // the small cloudconfig template.
 type SmallCloudconfigConfig struct {
+	Files []SmallCloudconfigFile
 	S3URL string
 }
 
+// SmallCloudconfigFile represents a file written by the small cloudconfig
+// before the actual cloudconfig fetched from S3 is applied. Source is a data URL
+// holding the file content.
+type SmallCloudconfigFile struct {
+	Path   string
+	Source string
+}
+
 // ELBClient describes the methods required to be implemented by a ELB AWS
 // client.
 type ELBClient interface {
@@ -0,0 +1,292 @@
+package ami
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/aws-operator/service/controller/v21/controllercontext"
+	"github.com/giantswarm/aws-operator/service/controller/v21/key"
+)
+
+const (
+	// DefaultOwner is the ID of the AWS account publishing the Container Linux
+	// AMIs in the regions of the aws partition.
+	DefaultOwner = "595879546273"
+
+	// cacheTTL is the duration resolved AMIs are cached for. Published AMIs
+	// never change, the TTL only exists to pick up AMIs shared or copied to
+	// the tenant cluster accounts later on.
+	cacheTTL = 1 * time.Hour
+)
+
+const (
+	partitionAWS         = "aws"
+	partitionAWSChina    = "aws-cn"
+	partitionAWSGovCloud = "aws-us-gov"
+)
+
+// defaultImageIDs are the Container Linux AMIs of the regions in the aws-cn
+// and aws-us-gov partitions, keyed by image name and region. The AMIs there
+// are not published by DefaultOwner, so they are used unless an owner is
+// configured for the partition of the region. Add the AMIs of a new release
+// when bumping key.ContainerLinuxVersion.
+var defaultImageIDs = map[string]map[string]string{
+	"CoreOS-stable-1855.5.0-hvm": {
+		"cn-north-1":     "ami-0211d60ca1aaa3c7d",
+		"cn-northwest-1": "ami-0deaa8ada18aec612",
+		"us-gov-west-1":  "ami-9d58c2fc",
+	},
+}
+
+type Config struct {
+	Logger micrologger.Logger
+
+	// Channel is the Container Linux release channel, e.g. stable.
+	Channel string
+	// Overrides is a comma separated list of region=ami-id pairs pinning the
+	// AMI used in a region. Private, copied or encrypted AMIs are configured
+	// this way.
+	Overrides string
+	// Owner is a comma separated list of partition=account-id pairs defining
+	// the AWS accounts owning the AMIs in the regions of each partition, e.g.
+	// aws=595879546273,aws-cn=123456789012. A plain account ID defines the
+	// owner of the aws partition.
+	Owner string
+	// Version is the Container Linux release version, e.g. 1855.5.0.
+	Version string
+}
+
+type Catalog struct {
+	logger micrologger.Logger
+
+	channel   string
+	overrides map[string]string
+	owners    map[string]string
+	version   string
+
+	cache map[string]cacheEntry
+	mutex sync.Mutex
+}
+
+type cacheEntry struct {
+	expiry  time.Time
+	imageID string
+}
+
+func New(config Config) (*Catalog, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.Channel == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Channel must not be empty", config)
+	}
+	if config.Version == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Version must not be empty", config)
+	}
+
+	overrides, err := parseOverrides(config.Overrides)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	owners, err := parseOwners(config.Owner)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	if _, ok := owners[partitionAWS]; !ok {
+		owners[partitionAWS] = DefaultOwner
+	}
+
+	c := &Catalog{
+		logger: config.Logger,
+
+		channel:   config.Channel,
+		overrides: overrides,
+		owners:    owners,
+		version:   config.Version,
+
+		cache: map[string]cacheEntry{},
+	}
+
+	return c, nil
+}
+
+func (c *Catalog) ImageID(ctx context.Context, customObject v1alpha1.AWSConfig) (string, error) {
+	region := key.Region(customObject)
+
+	if imageID, ok := c.overrides[region]; ok {
+		return imageID, nil
+	}
+
+	owner, ok := c.owners[partition(region)]
+	if !ok {
+		imageID, ok := defaultImageIDs[c.imageName()][region]
+		if !ok {
+			return "", microerror.Maskf(imageNotFoundError, "no AMI owner configured for partition %#q of region %#q and no default AMI named %#q", partition(region), region, c.imageName())
+		}
+
+		return imageID, nil
+	}
+
+	controllerCtx, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	// AMIs can be shared with or copied to single accounts, so the resolved
+	// AMIs are cached per account and region.
+	var cacheKey string
+	{
+		o, err := controllerCtx.AWSClient.STS.GetCallerIdentity(&sts.GetCallerIdentityInput{})
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+
+		cacheKey = fmt.Sprintf("%s/%s", aws.StringValue(o.Account), region)
+	}
+
+	if imageID, ok := c.cached(cacheKey); ok {
+		return imageID, nil
+	}
+
+	c.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("finding Container Linux %s %s AMI in region %#q", c.channel, c.version, region))
+
+	i := &ec2.DescribeImagesInput{
+		Owners: []*string{
+			aws.String(owner),
+		},
+		Filters: []*ec2.Filter{
+			{
+				Name: aws.String("name"),
+				Values: []*string{
+					aws.String(c.imageName()),
+				},
+			},
+			{
+				Name: aws.String("state"),
+				Values: []*string{
+					aws.String(ec2.ImageStateAvailable),
+				},
+			},
+			{
+				Name: aws.String("virtualization-type"),
+				Values: []*string{
+					aws.String(ec2.VirtualizationTypeHvm),
+				},
+			},
+		},
+	}
+	o, err := controllerCtx.AWSClient.EC2.DescribeImages(i)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+	if len(o.Images) == 0 {
+		return "", microerror.Maskf(imageNotFoundError, "no AMI named %#q owned by %#q in region %#q", c.imageName(), owner, region)
+	}
+	// Picking one of multiple AMIs of the same release would make tenant
+	// cluster nodes roll whenever another one shows up, so ambiguous releases
+	// have to be pinned using an override.
+	if len(o.Images) > 1 {
+		return "", microerror.Maskf(tooManyImagesError, "%d AMIs named %#q owned by %#q in region %#q", len(o.Images), c.imageName(), owner, region)
+	}
+	imageID := aws.StringValue(o.Images[0].ImageId)
+
+	c.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found Container Linux %s %s AMI %#q in region %#q", c.channel, c.version, imageID, region))
+
+	c.mutex.Lock()
+	c.cache[cacheKey] = cacheEntry{
+		expiry:  time.Now().Add(cacheTTL),
+		imageID: imageID,
+	}
+	c.mutex.Unlock()
+
+	return imageID, nil
+}
+
+func (c *Catalog) cached(cacheKey string) (string, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	e, ok := c.cache[cacheKey]
+	if !ok || time.Now().After(e.expiry) {
+		return "", false
+	}
+
+	return e.imageID, true
+}
+
+// imageName returns the name of the Container Linux AMI for the configured
+// channel and version, e.g. CoreOS-stable-1855.5.0-hvm.
+func (c *Catalog) imageName() string {
+	return fmt.Sprintf("CoreOS-%s-%s-hvm", c.channel, c.version)
+}
+
+func parseOverrides(s string) (map[string]string, error) {
+	overrides := map[string]string{}
+
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		split := strings.Split(pair, "=")
+		if len(split) != 2 || split[0] == "" || !strings.HasPrefix(split[1], "ami-") {
+			return nil, microerror.Maskf(invalidConfigError, "AMI override %#q must have the format region=ami-id", pair)
+		}
+
+		overrides[split[0]] = split[1]
+	}
+
+	return overrides, nil
+}
+
+func parseOwners(s string) (map[string]string, error) {
+	owners := map[string]string{}
+
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		split := strings.Split(pair, "=")
+		if len(split) == 1 {
+			split = []string{partitionAWS, split[0]}
+		}
+		if len(split) != 2 || split[1] == "" {
+			return nil, microerror.Maskf(invalidConfigError, "AMI owner %#q must have the format partition=account-id", pair)
+		}
+		switch split[0] {
+		case partitionAWS, partitionAWSChina, partitionAWSGovCloud:
+		default:
+			return nil, microerror.Maskf(invalidConfigError, "AMI owner partition must be one of %#q, %#q or %#q, got %#q", partitionAWS, partitionAWSChina, partitionAWSGovCloud, split[0])
+		}
+
+		owners[split[0]] = split[1]
+	}
+
+	return owners, nil
+}
+
+// partition returns the AWS partition of the given region.
+func partition(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return partitionAWSChina
+	case strings.HasPrefix(region, "us-gov-"):
+		return partitionAWSGovCloud
+	default:
+		return partitionAWS
+	}
+}
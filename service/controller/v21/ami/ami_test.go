@@ -0,0 +1,358 @@
+package ami
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+
+	awsclient "github.com/giantswarm/aws-operator/client/aws"
+	"github.com/giantswarm/aws-operator/service/controller/v21/controllercontext"
+)
+
+type ec2ClientMock struct {
+	ec2iface.EC2API
+
+	calls  int
+	images []*ec2.Image
+	input  *ec2.DescribeImagesInput
+}
+
+func (e *ec2ClientMock) DescribeImages(input *ec2.DescribeImagesInput) (*ec2.DescribeImagesOutput, error) {
+	e.calls++
+	e.input = input
+
+	return &ec2.DescribeImagesOutput{Images: e.images}, nil
+}
+
+type stsClientMock struct {
+	stsiface.STSAPI
+
+	account string
+}
+
+func (s *stsClientMock) GetCallerIdentity(*sts.GetCallerIdentityInput) (*sts.GetCallerIdentityOutput, error) {
+	return &sts.GetCallerIdentityOutput{Account: aws.String(s.account)}, nil
+}
+
+func Test_Catalog_ImageID(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description     string
+		region          string
+		overrides       string
+		owner           string
+		images          []*ec2.Image
+		errorMatcher    func(error) bool
+		expectedImageID string
+		expectedCalls   int
+	}{
+		{
+			description: "case 0: resolve published AMI",
+			region:      "eu-central-1",
+			images: []*ec2.Image{
+				{ImageId: aws.String("ami-0e6601a88a9753474"), CreationDate: aws.String("2018-11-08T18:09:16.000Z")},
+			},
+			expectedImageID: "ami-0e6601a88a9753474",
+			expectedCalls:   1,
+		},
+		{
+			description: "case 1: multiple published AMIs of the same release",
+			region:      "eu-central-1",
+			images: []*ec2.Image{
+				{ImageId: aws.String("ami-0000000000000000a"), CreationDate: aws.String("2018-11-08T18:09:16.000Z")},
+				{ImageId: aws.String("ami-0000000000000000b"), CreationDate: aws.String("2018-11-09T10:00:00.000Z")},
+			},
+			errorMatcher:  IsTooManyImages,
+			expectedCalls: 1,
+		},
+		{
+			description: "case 2: override takes precedence",
+			region:      "eu-west-1",
+			overrides:   "eu-central-1=ami-0000000000000000c, eu-west-1=ami-0000000000000000d",
+			images: []*ec2.Image{
+				{ImageId: aws.String("ami-06c40d1010f762df9"), CreationDate: aws.String("2018-11-08T18:09:16.000Z")},
+			},
+			expectedImageID: "ami-0000000000000000d",
+			expectedCalls:   0,
+		},
+		{
+			description:   "case 3: no published AMI",
+			region:        "eu-central-1",
+			errorMatcher:  IsImageNotFound,
+			expectedCalls: 1,
+		},
+		{
+			description:     "case 4: default AMI of China region without owner",
+			region:          "cn-north-1",
+			expectedImageID: "ami-0211d60ca1aaa3c7d",
+			expectedCalls:   0,
+		},
+		{
+			description:     "case 5: default AMI of GovCloud region without owner",
+			region:          "us-gov-west-1",
+			expectedImageID: "ami-9d58c2fc",
+			expectedCalls:   0,
+		},
+		{
+			description: "case 6: resolve published AMI of China region with owner",
+			region:      "cn-northwest-1",
+			owner:       "aws-cn=123456789012",
+			images: []*ec2.Image{
+				{ImageId: aws.String("ami-0000000000000000f"), CreationDate: aws.String("2018-11-08T18:09:16.000Z")},
+			},
+			expectedImageID: "ami-0000000000000000f",
+			expectedCalls:   1,
+		},
+		{
+			description:   "case 7: China region without owner or default AMI",
+			region:        "cn-east-1",
+			errorMatcher:  IsImageNotFound,
+			expectedCalls: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			c := Config{
+				Logger: microloggertest.New(),
+
+				Channel:   "stable",
+				Overrides: tc.overrides,
+				Owner:     tc.owner,
+				Version:   "1855.5.0",
+			}
+			catalog, err := New(c)
+			if err != nil {
+				t.Fatalf("expected %#v got %#v", nil, err)
+			}
+
+			client := &ec2ClientMock{images: tc.images}
+			ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{
+				AWSClient: awsclient.Clients{EC2: client, STS: &stsClientMock{account: "000000000000"}},
+			})
+
+			customObject := v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						Region: tc.region,
+					},
+				},
+			}
+
+			// Resolve twice to verify the second lookup is served from the cache.
+			for i := 0; i < 2; i++ {
+				imageID, err := catalog.ImageID(ctx, customObject)
+
+				switch {
+				case err == nil && tc.errorMatcher == nil:
+					// correct; carry on
+				case err != nil && tc.errorMatcher == nil:
+					t.Fatalf("error == %#v, want nil", err)
+				case err == nil && tc.errorMatcher != nil:
+					t.Fatalf("error == nil, want non-nil")
+				case !tc.errorMatcher(err):
+					t.Fatalf("error == %#v, want matching", err)
+				}
+
+				if tc.errorMatcher != nil {
+					return
+				}
+
+				if imageID != tc.expectedImageID {
+					t.Fatalf("imageID == %q, want %q", imageID, tc.expectedImageID)
+				}
+			}
+
+			if client.calls != tc.expectedCalls {
+				t.Fatalf("DescribeImages calls == %d, want %d", client.calls, tc.expectedCalls)
+			}
+			if client.input != nil && aws.StringValue(client.input.Filters[0].Values[0]) != "CoreOS-stable-1855.5.0-hvm" {
+				t.Fatalf("name filter == %q, want %q", aws.StringValue(client.input.Filters[0].Values[0]), "CoreOS-stable-1855.5.0-hvm")
+			}
+		})
+	}
+}
+
+func Test_Catalog_ImageID_cachesPerAccount(t *testing.T) {
+	t.Parallel()
+	c := Config{
+		Logger: microloggertest.New(),
+
+		Channel: "stable",
+		Version: "1855.5.0",
+	}
+	catalog, err := New(c)
+	if err != nil {
+		t.Fatalf("expected %#v got %#v", nil, err)
+	}
+
+	client := &ec2ClientMock{
+		images: []*ec2.Image{
+			{ImageId: aws.String("ami-0e6601a88a9753474")},
+		},
+	}
+	customObject := v1alpha1.AWSConfig{
+		Spec: v1alpha1.AWSConfigSpec{
+			AWS: v1alpha1.AWSConfigSpecAWS{
+				Region: "eu-central-1",
+			},
+		},
+	}
+
+	for _, account := range []string{"000000000000", "111111111111", "000000000000"} {
+		ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{
+			AWSClient: awsclient.Clients{EC2: client, STS: &stsClientMock{account: account}},
+		})
+
+		_, err := catalog.ImageID(ctx, customObject)
+		if err != nil {
+			t.Fatalf("expected %#v got %#v", nil, err)
+		}
+	}
+
+	if client.calls != 2 {
+		t.Fatalf("DescribeImages calls == %d, want %d", client.calls, 2)
+	}
+	if aws.StringValue(client.input.Owners[0]) != DefaultOwner {
+		t.Fatalf("owner == %q, want %q", aws.StringValue(client.input.Owners[0]), DefaultOwner)
+	}
+}
+
+func Test_parseOwners(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description    string
+		owner          string
+		errorMatcher   func(error) bool
+		expectedOwners map[string]string
+	}{
+		{
+			description:    "case 0: no owner",
+			owner:          "",
+			expectedOwners: map[string]string{},
+		},
+		{
+			description: "case 1: plain account ID",
+			owner:       "595879546273",
+			expectedOwners: map[string]string{
+				"aws": "595879546273",
+			},
+		},
+		{
+			description: "case 2: owners per partition",
+			owner:       "aws=595879546273, aws-cn=123456789012,aws-us-gov=210987654321",
+			expectedOwners: map[string]string{
+				"aws":        "595879546273",
+				"aws-cn":     "123456789012",
+				"aws-us-gov": "210987654321",
+			},
+		},
+		{
+			description:  "case 3: unknown partition",
+			owner:        "aws-iso=123456789012",
+			errorMatcher: IsInvalidConfig,
+		},
+		{
+			description:  "case 4: owner without account ID",
+			owner:        "aws-cn=",
+			errorMatcher: IsInvalidConfig,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			owners, err := parseOwners(tc.owner)
+
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if tc.errorMatcher != nil {
+				return
+			}
+
+			if !reflect.DeepEqual(owners, tc.expectedOwners) {
+				t.Fatalf("owners == %#v, want %#v", owners, tc.expectedOwners)
+			}
+		})
+	}
+}
+
+func Test_parseOverrides(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description       string
+		overrides         string
+		errorMatcher      func(error) bool
+		expectedOverrides map[string]string
+	}{
+		{
+			description:       "case 0: no overrides",
+			overrides:         "",
+			expectedOverrides: map[string]string{},
+		},
+		{
+			description: "case 1: multiple overrides",
+			overrides:   "eu-central-1=ami-0000000000000000c,cn-north-1=ami-0000000000000000e",
+			expectedOverrides: map[string]string{
+				"eu-central-1": "ami-0000000000000000c",
+				"cn-north-1":   "ami-0000000000000000e",
+			},
+		},
+		{
+			description:  "case 2: malformed override",
+			overrides:    "eu-central-1:ami-0000000000000000c",
+			errorMatcher: IsInvalidConfig,
+		},
+		{
+			description:  "case 3: override without AMI ID",
+			overrides:    "eu-central-1=",
+			errorMatcher: IsInvalidConfig,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			overrides, err := parseOverrides(tc.overrides)
+
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if tc.errorMatcher != nil {
+				return
+			}
+
+			if len(overrides) != len(tc.expectedOverrides) {
+				t.Fatalf("overrides == %#v, want %#v", overrides, tc.expectedOverrides)
+			}
+			for k, v := range tc.expectedOverrides {
+				if overrides[k] != v {
+					t.Fatalf("overrides == %#v, want %#v", overrides, tc.expectedOverrides)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package ami
+
+import (
+	"context"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+)
+
+// Interface describes the methods provided by the AMI catalog.
+type Interface interface {
+	// ImageID returns the ID of the Container Linux AMI for the region of the
+	// given tenant cluster. AMIs configured as override for the region take
+	// precedence over the ones published for the configured channel and
+	// version.
+	ImageID(ctx context.Context, customObject v1alpha1.AWSConfig) (string, error)
+}
@@ -2,11 +2,13 @@ package cloudconfig
 
 import (
 	"context"
+	"strings"
 
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
 	"github.com/giantswarm/microerror"
 
 	"github.com/giantswarm/aws-operator/service/controller/v21/encrypter"
+	"github.com/giantswarm/aws-operator/service/controller/v21/encrypter/ssm"
 	"github.com/giantswarm/aws-operator/service/controller/v21/encrypter/vault"
 )
 
@@ -14,16 +16,19 @@ type baseExtension struct {
 	customObject  v1alpha1.AWSConfig
 	encrypter     encrypter.Interface
 	encryptionKey string
+	etcdBackup    etcdBackupTemplateData
 }
 
 func (e *baseExtension) templateData() templateData {
 	var encrypterType string
 	var vaultAddress string
-	v, ok := e.encrypter.(*vault.Encrypter)
-	if ok {
+	switch v := e.encrypter.(type) {
+	case *vault.Encrypter:
 		encrypterType = encrypter.VaultBackend
 		vaultAddress = v.Address()
-	} else {
+	case *ssm.Encrypter:
+		encrypterType = encrypter.SSMBackend
+	default:
 		encrypterType = encrypter.KMSBackend
 	}
 	data := templateData{
@@ -31,15 +36,16 @@ func (e *baseExtension) templateData() templateData {
 		EncrypterType: encrypterType,
 		VaultAddress:  vaultAddress,
 		EncryptionKey: e.encryptionKey,
+		EtcdBackup:    e.etcdBackup,
 	}
 
 	return data
 }
 
-func (e *baseExtension) encrypt(ctx context.Context, data []byte) ([]byte, error) {
+func (e *baseExtension) encrypt(ctx context.Context, asset string, data []byte) ([]byte, error) {
 	var encrypted []byte
 	{
-		e, err := e.encrypter.Encrypt(ctx, e.encryptionKey, string(data))
+		e, err := encryptAsset(ctx, e.encrypter, e.encryptionKey, asset, string(data))
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
@@ -48,3 +54,30 @@ func (e *baseExtension) encrypt(ctx context.Context, data []byte) ([]byte, error
 
 	return encrypted, nil
 }
+
+// assetEncrypter is implemented by encrypters storing every asset under a
+// stable name, like the SSM encrypter.
+type assetEncrypter interface {
+	EncryptAsset(ctx context.Context, key, asset, plaintext string) (string, error)
+}
+
+// encryptAsset encrypts the plaintext of the given asset. The asset is the
+// path of the file the plaintext is written to on the nodes or another name
+// identifying it within the cluster.
+func encryptAsset(ctx context.Context, e encrypter.Interface, key, asset, plaintext string) (string, error) {
+	if a, ok := e.(assetEncrypter); ok {
+		encrypted, err := a.EncryptAsset(ctx, key, strings.TrimPrefix(asset, "/"), plaintext)
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+
+		return encrypted, nil
+	}
+
+	encrypted, err := e.Encrypt(ctx, key, plaintext)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return encrypted, nil
+}
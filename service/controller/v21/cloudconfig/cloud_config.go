@@ -2,6 +2,7 @@ package cloudconfig
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
@@ -20,6 +21,9 @@ type Config struct {
 	Encrypter encrypter.Interface
 	Logger    micrologger.Logger
 
+	// EtcdBackupInterval is the interval in which masters upload encrypted etcd
+	// snapshots to the S3 bucket of their cluster.
+	EtcdBackupInterval     time.Duration
 	IgnitionPath           string
 	OIDC                   OIDCConfig
 	PodInfraContainerImage string
@@ -32,6 +36,7 @@ type CloudConfig struct {
 	encrypter encrypter.Interface
 	logger    micrologger.Logger
 
+	etcdBackupInterval  time.Duration
 	ignitionPath        string
 	k8sAPIExtraArgs     []string
 	k8sKubeletExtraArgs []string
@@ -55,6 +60,9 @@ func New(config Config) (*CloudConfig, error) {
 	if config.Logger == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
 	}
+	if config.EtcdBackupInterval <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.EtcdBackupInterval must be greater than 0", config)
+	}
 	if config.IgnitionPath == "" {
 		return nil, microerror.Maskf(invalidConfigError, "%T.IgnitionPath must not be empty", config)
 	}
@@ -89,6 +97,7 @@ func New(config Config) (*CloudConfig, error) {
 		encrypter: config.Encrypter,
 		logger:    config.Logger,
 
+		etcdBackupInterval:  config.EtcdBackupInterval,
 		ignitionPath:        config.IgnitionPath,
 		k8sAPIExtraArgs:     k8sAPIExtraArgs,
 		k8sKubeletExtraArgs: k8sKubeletExtraArgs,
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
 	"github.com/giantswarm/certs"
@@ -14,6 +15,7 @@ import (
 	"github.com/giantswarm/micrologger/microloggertest"
 	"github.com/giantswarm/randomkeys"
 
+	awsservice "github.com/giantswarm/aws-operator/service/aws"
 	"github.com/giantswarm/aws-operator/service/controller/v21/controllercontext"
 	"github.com/giantswarm/aws-operator/service/controller/v21/encrypter"
 )
@@ -42,7 +44,11 @@ func Test_Service_CloudConfig_NewMasterTemplate(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		ctlCtx := controllercontext.Context{}
+		ctlCtx := controllercontext.Context{
+			AWSService: &awsservice.AwsServiceMock{
+				AccountID: "myaccountid",
+			},
+		}
 		ctx := controllercontext.NewContext(context.Background(), ctlCtx)
 
 		ccService, err := testNewCloudConfigService()
@@ -65,6 +71,10 @@ func Test_Service_CloudConfig_NewMasterTemplate(t *testing.T) {
 			"/etc/kubernetes/ssl/etcd/client-crt.pem.enc",
 			"/etc/kubernetes/ssl/etcd/client-key.pem.enc",
 			"decrypt-tls-assets.service",
+			"/etc/kubernetes/encryption/etcd-backup-passphrase.enc",
+			"etcd-backup.timer",
+			"etcd-restore.service",
+			"OnUnitActiveSec=3600s",
 			"a2luZDogRW5jcnlwdGlvbkNvbmZpZwphcGlWZXJzaW9uOiB2MQpyZXNvdXJjZXM6CiAgLSByZXNvdXJjZXM6CiAgICAtIHNlY3JldHMKICAgIHByb3ZpZGVyczoKICAgIC0gYWVzY2JjOgogICAgICAgIGtleXM6CiAgICAgICAgLSBuYW1lOiBrZXkxCiAgICAgICAgICBzZWNyZXQ6IGZla2hmaXdvaXFob2lmaHdxZWZvaXF3ZWZvaWtxaHdlZgogICAgLSBpZGVudGl0eToge30=",
 		}
 		for _, expectedString := range expectedStrings {
@@ -131,10 +141,11 @@ func testNewCloudConfigService() (*CloudConfig, error) {
 		}
 
 		c := Config{
-			Encrypter:      &encrypter.EncrypterMock{},
-			Logger:         microloggertest.New(),
-			IgnitionPath:   packagePath,
-			RegistryDomain: "quay.io",
+			Encrypter:          &encrypter.EncrypterMock{},
+			Logger:             microloggertest.New(),
+			EtcdBackupInterval: time.Hour,
+			IgnitionPath:       packagePath,
+			RegistryDomain:     "quay.io",
 		}
 
 		ccService, err = New(c)
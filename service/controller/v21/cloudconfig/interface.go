@@ -10,5 +10,6 @@ import (
 
 type Interface interface {
 	NewMasterTemplate(ctx context.Context, customObject v1alpha1.AWSConfig, clusterCerts certs.Cluster, clusterKeys randomkeys.Cluster) (string, error)
+	NewNodePoolTemplate(ctx context.Context, customObject v1alpha1.AWSConfig, clusterCerts certs.Cluster, nodePool v1alpha1.AWSConfigSpecAWSNodePool) (string, error)
 	NewWorkerTemplate(ctx context.Context, customObject v1alpha1.AWSConfig, clusterCerts certs.Cluster) (string, error)
 }
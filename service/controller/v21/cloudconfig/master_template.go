@@ -2,6 +2,7 @@ package cloudconfig
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
 	"github.com/giantswarm/certs"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/giantswarm/aws-operator/service/controller/v21/controllercontext"
 	"github.com/giantswarm/aws-operator/service/controller/v21/encrypter/vault"
+	"github.com/giantswarm/aws-operator/service/controller/v21/key"
 	"github.com/giantswarm/aws-operator/service/controller/v21/templates/cloudconfig"
 )
 
@@ -33,16 +35,28 @@ func (c *CloudConfig) NewMasterTemplate(ctx context.Context, customObject v1alph
 		return "", microerror.Mask(err)
 	}
 
+	accountID, err := ctlCtx.AWSService.GetAccountID()
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
 	var params k8scloudconfig.Params
 	{
+		params = k8scloudconfig.DefaultParams()
+
 		be := baseExtension{
 			customObject:  customObject,
 			encrypter:     c.encrypter,
 			encryptionKey: encryptionKey,
+			etcdBackup: etcdBackupTemplateData{
+				Bucket:                 key.BucketName(customObject, accountID),
+				EtcdImage:              c.registryDomain + "/" + params.Images.Etcd,
+				Interval:               fmt.Sprintf("%ds", int(c.etcdBackupInterval.Seconds())),
+				Prefix:                 key.EtcdBackupPrefix,
+				RestoreEnvironmentPath: key.EtcdRestoreEnvironmentPath,
+			},
 		}
 
-		params = k8scloudconfig.DefaultParams()
-
 		params.Cluster = customObject.Spec.Cluster
 		params.DisableEncryptionAtREST = true
 		// Ingress controller service remains in k8scloudconfig and will be
@@ -92,6 +106,7 @@ func (c *CloudConfig) NewMasterTemplate(ctx context.Context, customObject v1alph
 // encryption via KMS.
 type RandomKeyTmplSet struct {
 	APIServerEncryptionKey string
+	EtcdBackupPassphrase   string
 }
 
 type MasterExtension struct {
@@ -140,6 +155,42 @@ func (e *MasterExtension) Files() ([]k8scloudconfig.FileAsset, error) {
 			},
 			Permissions: 0644,
 		},
+		{
+			AssetContent: e.RandomKeyTmplSet.EtcdBackupPassphrase,
+			Path:         "/etc/kubernetes/encryption/etcd-backup-passphrase.enc",
+			Owner: k8scloudconfig.Owner{
+				User:  FileOwnerUser,
+				Group: FileOwnerGroup,
+			},
+			Permissions: 0600,
+		},
+		{
+			AssetContent: cloudconfig.EtcdBackupScript,
+			Path:         "/opt/bin/etcd-backup",
+			Owner: k8scloudconfig.Owner{
+				User:  FileOwnerUser,
+				Group: FileOwnerGroup,
+			},
+			Permissions: FilePermission,
+		},
+		{
+			AssetContent: cloudconfig.EtcdRestoreScript,
+			Path:         "/opt/bin/etcd-restore",
+			Owner: k8scloudconfig.Owner{
+				User:  FileOwnerUser,
+				Group: FileOwnerGroup,
+			},
+			Permissions: FilePermission,
+		},
+		{
+			AssetContent: cloudconfig.EtcdRestoreDropIn,
+			Path:         "/etc/systemd/system/etcd3.service.d/20-restore.conf",
+			Owner: k8scloudconfig.Owner{
+				User:  FileOwnerUser,
+				Group: FileOwnerGroup,
+			},
+			Permissions: 0644,
+		},
 		{
 			AssetContent: cloudconfig.WaitDockerConf,
 			Path:         "/etc/systemd/system/docker.service.d/01-wait-docker.conf",
@@ -210,7 +261,7 @@ func (e *MasterExtension) Files() ([]k8scloudconfig.FileAsset, error) {
 			//
 			ctx = controllercontext.NewContext(ctx, *e.ctlCtx)
 
-			data, err := e.encrypt(ctx, f.Data)
+			data, err := e.encrypt(ctx, f.AbsolutePath, f.Data)
 			if err != nil {
 				return nil, microerror.Mask(err)
 			}
@@ -298,12 +349,29 @@ func (e *MasterExtension) Units() ([]k8scloudconfig.UnitAsset, error) {
 			Name:         "var-lib-etcd.mount",
 			Enabled:      true,
 		},
+		// Restore etcd from the latest snapshot when the etcd EBS volume is empty.
+		{
+			AssetContent: cloudconfig.EtcdRestoreService,
+			Name:         "etcd-restore.service",
+			Enabled:      true,
+		},
+		// Periodically upload encrypted etcd snapshots to S3.
+		{
+			AssetContent: cloudconfig.EtcdBackupService,
+			Name:         "etcd-backup.service",
+			Enabled:      false,
+		},
+		{
+			AssetContent: cloudconfig.EtcdBackupTimer,
+			Name:         "etcd-backup.timer",
+			Enabled:      true,
+		},
 	}
 
 	var newUnits []k8scloudconfig.UnitAsset
 
 	for _, fm := range unitsMeta {
-		c, err := k8scloudconfig.RenderAssetContent(fm.AssetContent, e.customObject.Spec)
+		c, err := k8scloudconfig.RenderAssetContent(fm.AssetContent, e.templateData())
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
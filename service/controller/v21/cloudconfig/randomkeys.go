@@ -3,6 +3,9 @@ package cloudconfig
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"text/template"
 
 	"github.com/giantswarm/microerror"
@@ -29,7 +32,7 @@ func renderRandomKeyTmplSet(ctx context.Context, encrypter encrypter.Interface,
 			return RandomKeyTmplSet{}, microerror.Mask(err)
 		}
 
-		enc, err := encrypter.Encrypt(ctx, key, buf.String())
+		enc, err := encryptAsset(ctx, encrypter, key, "encryption-config", buf.String())
 		if err != nil {
 			return RandomKeyTmplSet{}, microerror.Mask(err)
 		}
@@ -37,5 +40,27 @@ func renderRandomKeyTmplSet(ctx context.Context, encrypter encrypter.Interface,
 		randomKeyTmplSet.APIServerEncryptionKey = enc
 	}
 
+	{
+		enc, err := encryptAsset(ctx, encrypter, key, "etcd-backup-passphrase", etcdBackupPassphrase(clusterKeys))
+		if err != nil {
+			return RandomKeyTmplSet{}, microerror.Mask(err)
+		}
+
+		randomKeyTmplSet.EtcdBackupPassphrase = enc
+	}
+
 	return randomKeyTmplSet, nil
 }
+
+// etcdBackupPassphrase derives the passphrase etcd snapshots are encrypted with
+// from the API server encryption key of the cluster. The passphrase has to be
+// stable across master replacements, otherwise snapshots taken before could
+// not be restored anymore. The passphrase itself is encrypted with the
+// encrypter backend of the cluster, see EtcdBackupScript for why snapshots are
+// not encrypted with the backend directly.
+func etcdBackupPassphrase(clusterKeys randomkeys.Cluster) string {
+	mac := hmac.New(sha256.New, []byte(clusterKeys.APIServerEncryptionKey))
+	mac.Write([]byte("etcd-backup"))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -9,4 +9,15 @@ type templateData struct {
 	EncrypterType string
 	VaultAddress  string
 	EncryptionKey string
+	EtcdBackup    etcdBackupTemplateData
+}
+
+// etcdBackupTemplateData holds the information master templates need to
+// upload etcd snapshots and restore them.
+type etcdBackupTemplateData struct {
+	Bucket                 string
+	EtcdImage              string
+	Interval               string
+	Prefix                 string
+	RestoreEnvironmentPath string
 }
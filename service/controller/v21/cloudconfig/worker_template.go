@@ -2,6 +2,8 @@ package cloudconfig
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
 	"github.com/giantswarm/certs"
@@ -9,12 +11,32 @@ import (
 	"github.com/giantswarm/microerror"
 
 	"github.com/giantswarm/aws-operator/service/controller/v21/controllercontext"
+	"github.com/giantswarm/aws-operator/service/controller/v21/key"
 	"github.com/giantswarm/aws-operator/service/controller/v21/templates/cloudconfig"
 )
 
 // NewWorkerTemplate generates a new worker cloud config template and returns it
 // as a string.
 func (c *CloudConfig) NewWorkerTemplate(ctx context.Context, customObject v1alpha1.AWSConfig, clusterCerts certs.Cluster) (string, error) {
+	return c.newWorkerTemplate(ctx, customObject, clusterCerts, "", c.k8sKubeletExtraArgs)
+}
+
+// NewNodePoolTemplate generates a new worker cloud config template for the
+// worker nodes of the given node pool and returns it as a string. The labels
+// and taints of the node pool are registered by the kubelet.
+func (c *CloudConfig) NewNodePoolTemplate(ctx context.Context, customObject v1alpha1.AWSConfig, clusterCerts certs.Cluster, nodePool v1alpha1.AWSConfigSpecAWSNodePool) (string, error) {
+	var kubeletExtraArgs []string
+	kubeletExtraArgs = append(kubeletExtraArgs, c.k8sKubeletExtraArgs...)
+
+	taints := key.NodePoolTaints(nodePool)
+	if taints != "" {
+		kubeletExtraArgs = append(kubeletExtraArgs, fmt.Sprintf("--register-with-taints=%s", taints))
+	}
+
+	return c.newWorkerTemplate(ctx, customObject, clusterCerts, key.NodePoolLabels(nodePool), kubeletExtraArgs)
+}
+
+func (c *CloudConfig) newWorkerTemplate(ctx context.Context, customObject v1alpha1.AWSConfig, clusterCerts certs.Cluster, labels string, kubeletExtraArgs []string) (string, error) {
 	var err error
 
 	ctlCtx, err := controllercontext.FromContext(ctx)
@@ -40,13 +62,16 @@ func (c *CloudConfig) NewWorkerTemplate(ctx context.Context, customObject v1alph
 		params = k8scloudconfig.DefaultParams()
 
 		params.Cluster = customObject.Spec.Cluster
+		if labels != "" {
+			params.Cluster.Kubernetes.Kubelet.Labels = joinLabels(params.Cluster.Kubernetes.Kubelet.Labels, labels)
+		}
 		params.Extension = &WorkerExtension{
 			baseExtension: be,
 			ctlCtx:        ctlCtx,
 
 			ClusterCerts: clusterCerts,
 		}
-		params.Hyperkube.Kubelet.Docker.CommandExtraArgs = c.k8sKubeletExtraArgs
+		params.Hyperkube.Kubelet.Docker.CommandExtraArgs = kubeletExtraArgs
 		params.RegistryDomain = c.registryDomain
 		params.SSOPublicKey = c.SSOPublicKey
 
@@ -77,6 +102,19 @@ func (c *CloudConfig) NewWorkerTemplate(ctx context.Context, customObject v1alph
 	return newCloudConfig.String(), nil
 }
 
+// joinLabels joins the given comma separated lists of node labels, ignoring
+// empty lists.
+func joinLabels(labels ...string) string {
+	var nonEmpty []string
+	for _, l := range labels {
+		if l != "" {
+			nonEmpty = append(nonEmpty, l)
+		}
+	}
+
+	return strings.Join(nonEmpty, ",")
+}
+
 type WorkerExtension struct {
 	baseExtension
 
@@ -127,7 +165,7 @@ func (e *WorkerExtension) Files() ([]k8scloudconfig.FileAsset, error) {
 			//
 			ctx = controllercontext.NewContext(ctx, *e.ctlCtx)
 
-			data, err := e.encrypt(ctx, f.Data)
+			data, err := e.encrypt(ctx, f.AbsolutePath, f.Data)
 			if err != nil {
 				return nil, microerror.Mask(err)
 			}
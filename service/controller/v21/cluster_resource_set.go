@@ -2,6 +2,7 @@ package v21
 
 import (
 	"context"
+	"fmt"
 	"net"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/giantswarm/aws-operator/client/aws"
 	awsservice "github.com/giantswarm/aws-operator/service/aws"
 	"github.com/giantswarm/aws-operator/service/controller/v21/adapter"
+	"github.com/giantswarm/aws-operator/service/controller/v21/ami"
 	"github.com/giantswarm/aws-operator/service/controller/v21/cloudconfig"
 	cloudformationservice "github.com/giantswarm/aws-operator/service/controller/v21/cloudformation"
 	"github.com/giantswarm/aws-operator/service/controller/v21/controllercontext"
@@ -16,13 +18,19 @@ import (
 	"github.com/giantswarm/aws-operator/service/controller/v21/ebs"
 	"github.com/giantswarm/aws-operator/service/controller/v21/encrypter"
 	"github.com/giantswarm/aws-operator/service/controller/v21/encrypter/kms"
+	"github.com/giantswarm/aws-operator/service/controller/v21/encrypter/ssm"
 	"github.com/giantswarm/aws-operator/service/controller/v21/encrypter/vault"
+	"github.com/giantswarm/aws-operator/service/controller/v21/etcdmember"
 	"github.com/giantswarm/aws-operator/service/controller/v21/key"
+	"github.com/giantswarm/aws-operator/service/controller/v21/maintenance"
 	"github.com/giantswarm/aws-operator/service/controller/v21/resource/bridgezone"
 	cloudformationresource "github.com/giantswarm/aws-operator/service/controller/v21/resource/cloudformation"
+	"github.com/giantswarm/aws-operator/service/controller/v21/resource/ebsencryption"
 	"github.com/giantswarm/aws-operator/service/controller/v21/resource/ebsvolume"
 	"github.com/giantswarm/aws-operator/service/controller/v21/resource/encryptionkey"
+	"github.com/giantswarm/aws-operator/service/controller/v21/resource/encryptionrekey"
 	"github.com/giantswarm/aws-operator/service/controller/v21/resource/endpoints"
+	"github.com/giantswarm/aws-operator/service/controller/v21/resource/etcdbackup"
 	"github.com/giantswarm/aws-operator/service/controller/v21/resource/hostedzone"
 	"github.com/giantswarm/aws-operator/service/controller/v21/resource/ipam"
 	"github.com/giantswarm/aws-operator/service/controller/v21/resource/loadbalancer"
@@ -31,6 +39,8 @@ import (
 	"github.com/giantswarm/aws-operator/service/controller/v21/resource/s3bucket"
 	"github.com/giantswarm/aws-operator/service/controller/v21/resource/s3object"
 	"github.com/giantswarm/aws-operator/service/controller/v21/resource/service"
+	"github.com/giantswarm/aws-operator/service/controller/v21/resource/stackdrift"
+	"github.com/giantswarm/aws-operator/service/controller/v21/resource/workerrollout"
 	"github.com/giantswarm/certs"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
@@ -60,28 +70,49 @@ type ClusterResourceSetConfig struct {
 	Logger             micrologger.Logger
 	RandomKeysSearcher randomkeys.Interface
 
-	AccessLogsExpiration       int
-	AdvancedMonitoringEC2      bool
-	APIWhitelist               adapter.APIWhitelist
-	EncrypterBackend           string
-	GuestAvailabilityZones     []string
-	GuestPrivateSubnetMaskBits int
-	GuestPublicSubnetMaskBits  int
-	GuestSubnetMaskBits        int
-	GuestUpdateEnabled         bool
-	IncludeTags                bool
-	IgnitionPath               string
-	InstallationName           string
-	IPAMNetworkRange           net.IPNet
-	DeleteLoggingBucket        bool
-	OIDC                       cloudconfig.OIDCConfig
-	ProjectName                string
-	PublicRouteTables          string
-	Route53Enabled             bool
-	PodInfraContainerImage     string
-	RegistryDomain             string
-	SSOPublicKey               string
-	VaultAddress               string
+	AccessLogsExpiration        int
+	AdvancedMonitoringEC2       bool
+	AMIOverrides                string
+	AMIOwner                    string
+	APIWhitelist                adapter.APIWhitelist
+	ChangeSetApprovalRequired   bool
+	EBSEncryptionKeyARN         string
+	EncrypterBackend            string
+	EncryptionRekeyGracePeriod  time.Duration
+	EncryptionRekeyInterval     time.Duration
+	EtcdBackupInterval          time.Duration
+	EtcdBackupRetention         int
+	GuestAvailabilityZones      []string
+	GuestPrivateSubnetMaskBits  int
+	GuestPublicSubnetMaskBits   int
+	GuestSubnetMaskBits         int
+	GuestUpdateEnabled          bool
+	IncludeTags                 bool
+	IngressNLB                  adapter.IngressNLB
+	IgnitionPath                string
+	InstallationName            string
+	IPAMNetworkRange            net.IPNet
+	MetadataTokensRequired      bool
+	DeleteLoggingBucket         bool
+	OIDC                        cloudconfig.OIDCConfig
+	ProjectName                 string
+	PublicRouteTables           string
+	RecoverDeleteFailed         bool
+	RecoverRollbackComplete     bool
+	RecoverUpdateRollbackFailed bool
+	Route53Enabled              bool
+	PodInfraContainerImage      string
+	RegistryDomain              string
+	SSOPublicKey                string
+	StackDriftInterval          time.Duration
+	StackDriftRemediation       bool
+	TransitGatewayID            string
+	VaultAddress                string
+	VaultAuth                   vault.AuthConfig
+	VPCEndpointsEnabled         bool
+	VPCFlowLogs                 adapter.VPCFlowLogs
+	WorkerRolloutBatchTimeout   time.Duration
+	WorkerRolloutHealthGated    bool
 }
 
 func NewClusterResourceSet(config ClusterResourceSetConfig) (*controller.ResourceSet, error) {
@@ -158,6 +189,7 @@ func NewClusterResourceSet(config ClusterResourceSetConfig) (*controller.Resourc
 			Logger: config.Logger,
 
 			Address: config.VaultAddress,
+			Auth:    config.VaultAuth,
 		}
 
 		encrypterObject, err = vault.NewEncrypter(c)
@@ -176,16 +208,62 @@ func NewClusterResourceSet(config ClusterResourceSetConfig) (*controller.Resourc
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
+	case encrypter.SSMBackend:
+		c := &ssm.EncrypterConfig{
+			Logger: config.Logger,
+
+			InstallationName: config.InstallationName,
+		}
+
+		encrypterObject, err = ssm.NewEncrypter(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
 	default:
 		return nil, microerror.Maskf(invalidConfigError, "unknown encrypter backend %q", config.EncrypterBackend)
 	}
 
+	var amiCatalog *ami.Catalog
+	{
+		// The Container Linux release is defined by the version bundle, so
+		// tenant cluster nodes only roll when the version bundle changes.
+		c := ami.Config{
+			Logger: config.Logger,
+
+			Channel:   key.ContainerLinuxChannel,
+			Overrides: config.AMIOverrides,
+			Owner:     config.AMIOwner,
+			Version:   key.ContainerLinuxVersion,
+		}
+
+		amiCatalog, err = ami.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var etcdMember *etcdmember.Checker
+	{
+		c := etcdmember.Config{
+			CertsSearcher: config.CertsSearcher,
+			Logger:        config.Logger,
+
+			Timeout: etcdmember.DefaultTimeout,
+		}
+
+		etcdMember, err = etcdmember.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var cloudConfig *cloudconfig.CloudConfig
 	{
 		c := cloudconfig.Config{
 			Encrypter: encrypterObject,
 			Logger:    config.Logger,
 
+			EtcdBackupInterval:     config.EtcdBackupInterval,
 			IgnitionPath:           config.IgnitionPath,
 			OIDC:                   config.OIDC,
 			PodInfraContainerImage: config.PodInfraContainerImage,
@@ -212,6 +290,21 @@ func NewClusterResourceSet(config ClusterResourceSetConfig) (*controller.Resourc
 		}
 	}
 
+	var ebsEncryptionResource controller.Resource
+	{
+		c := ebsencryption.Config{
+			Logger: config.Logger,
+
+			InstallationName: config.InstallationName,
+			KeyARN:           config.EBSEncryptionKeyARN,
+		}
+
+		ebsEncryptionResource, err = ebsencryption.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var migrationResource controller.Resource
 	{
 		c := migration.Config{
@@ -245,6 +338,7 @@ func NewClusterResourceSet(config ClusterResourceSetConfig) (*controller.Resourc
 	var hostedZoneResource controller.Resource
 	{
 		c := hostedzone.Config{
+			G8sClient:   config.G8sClient,
 			HostRoute53: config.HostAWSClients.Route53,
 			Logger:      config.Logger,
 
@@ -317,6 +411,42 @@ func NewClusterResourceSet(config ClusterResourceSetConfig) (*controller.Resourc
 		}
 	}
 
+	var etcdBackupResource controller.Resource
+	{
+		c := etcdbackup.Config{
+			G8sClient: config.G8sClient,
+			Logger:    config.Logger,
+
+			Retention: config.EtcdBackupRetention,
+		}
+
+		etcdBackupResource, err = etcdbackup.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var stackDriftResource controller.Resource
+	{
+		c := stackdrift.Config{
+			G8sClient:          config.G8sClient,
+			HostCloudFormation: config.HostAWSClients.CloudFormation,
+			Logger:             config.Logger,
+
+			Interval:    config.StackDriftInterval,
+			Remediation: config.StackDriftRemediation,
+		}
+
+		if c.Interval == 0 {
+			c.Interval = stackdrift.DefaultInterval
+		}
+
+		stackDriftResource, err = stackdrift.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var loadBalancerResource controller.Resource
 	{
 		c := loadbalancer.Config{
@@ -350,20 +480,34 @@ func NewClusterResourceSet(config ClusterResourceSetConfig) (*controller.Resourc
 				STS:            config.HostAWSClients.STS,
 				CloudFormation: config.HostAWSClients.CloudFormation,
 			},
-			Logger: config.Logger,
+			G8sClient:  config.G8sClient,
+			K8sClient:  config.K8sClient,
+			Logger:     config.Logger,
+			AMICatalog: amiCatalog,
+			EtcdMember: etcdMember,
 			APIWhitelist: adapter.APIWhitelist{
 				Enabled:    config.APIWhitelist.Enabled,
 				SubnetList: config.APIWhitelist.SubnetList,
 			},
 
-			AdvancedMonitoringEC2:      config.AdvancedMonitoringEC2,
-			EncrypterBackend:           config.EncrypterBackend,
-			EncrypterRoleManager:       encrypterRoleManager,
-			GuestPrivateSubnetMaskBits: config.GuestPrivateSubnetMaskBits,
-			GuestPublicSubnetMaskBits:  config.GuestPublicSubnetMaskBits,
-			InstallationName:           config.InstallationName,
-			PublicRouteTables:          config.PublicRouteTables,
-			Route53Enabled:             config.Route53Enabled,
+			AdvancedMonitoringEC2:       config.AdvancedMonitoringEC2,
+			ChangeSetApprovalRequired:   config.ChangeSetApprovalRequired,
+			EncrypterBackend:            config.EncrypterBackend,
+			EncrypterRoleManager:        encrypterRoleManager,
+			GuestPrivateSubnetMaskBits:  config.GuestPrivateSubnetMaskBits,
+			GuestPublicSubnetMaskBits:   config.GuestPublicSubnetMaskBits,
+			IngressNLB:                  config.IngressNLB,
+			InstallationName:            config.InstallationName,
+			MetadataTokensRequired:      config.MetadataTokensRequired,
+			PublicRouteTables:           config.PublicRouteTables,
+			RecoverDeleteFailed:         config.RecoverDeleteFailed,
+			RecoverRollbackComplete:     config.RecoverRollbackComplete,
+			RecoverUpdateRollbackFailed: config.RecoverUpdateRollbackFailed,
+			Route53Enabled:              config.Route53Enabled,
+			TransitGatewayID:            config.TransitGatewayID,
+			VPCEndpointsEnabled:         config.VPCEndpointsEnabled,
+			VPCFlowLogs:                 config.VPCFlowLogs,
+			WorkerRolloutHealthGated:    config.WorkerRolloutHealthGated,
 		}
 
 		ops, err := cloudformationresource.New(c)
@@ -480,6 +624,49 @@ func NewClusterResourceSet(config ClusterResourceSetConfig) (*controller.Resourc
 		}
 	}
 
+	var workerRolloutResource controller.Resource
+	if config.WorkerRolloutHealthGated {
+		c := workerrollout.Config{
+			G8sClient:     config.G8sClient,
+			Logger:        config.Logger,
+			TenantCluster: tenantCluster,
+
+			BatchTimeout: config.WorkerRolloutBatchTimeout,
+		}
+
+		if c.BatchTimeout == 0 {
+			c.BatchTimeout = workerrollout.DefaultBatchTimeout
+		}
+
+		workerRolloutResource, err = workerrollout.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	// Re-keying is only supported by encrypters managing the encryption key of
+	// the guest cluster.
+	var encryptionRekeyResource controller.Resource
+	if keyRotator, ok := encrypterObject.(encrypter.KeyRotator); ok && config.EncryptionRekeyInterval > 0 {
+		c := encryptionrekey.Config{
+			Encrypter: keyRotator,
+			G8sClient: config.G8sClient,
+			Logger:    config.Logger,
+
+			GracePeriod: config.EncryptionRekeyGracePeriod,
+			Interval:    config.EncryptionRekeyInterval,
+		}
+
+		if c.GracePeriod == 0 {
+			c.GracePeriod = encryptionrekey.DefaultGracePeriod
+		}
+
+		encryptionRekeyResource, err = encryptionrekey.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	resources := []controller.Resource{
 		statusResource,
 		migrationResource,
@@ -487,14 +674,32 @@ func NewClusterResourceSet(config ClusterResourceSetConfig) (*controller.Resourc
 		hostedZoneResource,
 		bridgeZoneResource,
 		encryptionKeyResource,
+		ebsEncryptionResource,
+	}
+
+	// The re-key has to happen before the s3object resource renders the
+	// assets, so that they get encrypted with the new key right away.
+	if encryptionRekeyResource != nil {
+		resources = append(resources, encryptionRekeyResource)
+	}
+
+	resources = append(resources,
 		s3BucketResource,
 		s3BucketObjectResource,
+		etcdBackupResource,
 		loadBalancerResource,
 		ebsVolumeResource,
 		cloudformationResource,
+		stackDriftResource,
 		namespaceResource,
 		serviceResource,
 		endpointsResource,
+	)
+
+	// Worker instances are replaced by the rolling update policy of the ASGs
+	// unless worker rollouts are health gated.
+	if workerRolloutResource != nil {
+		resources = append(resources, workerRolloutResource)
 	}
 
 	{
@@ -534,7 +739,24 @@ func NewClusterResourceSet(config ClusterResourceSetConfig) (*controller.Resourc
 
 	initCtxFunc := func(ctx context.Context, obj interface{}) (context.Context, error) {
 		if config.GuestUpdateEnabled {
-			updateallowedcontext.SetUpdateAllowed(ctx)
+			customObject, err := key.ToCustomObject(obj)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+
+			// Tenant clusters having a maintenance window are only updated within
+			// it. Scaling is not affected by the maintenance window. An invalid
+			// maintenance window must not block the reconciliation, e.g. the
+			// deletion of the tenant cluster, which is why it only prevents
+			// updates.
+			w, err := maintenance.FromCustomObject(customObject)
+			if maintenance.IsInvalidConfig(err) {
+				config.Logger.LogCtx(ctx, "level", "warning", "message", "not allowing updates due to invalid maintenance window", "stack", fmt.Sprintf("%#v", err))
+			} else if err != nil {
+				return nil, microerror.Mask(err)
+			} else if w == nil || w.Contains(time.Now()) {
+				updateallowedcontext.SetUpdateAllowed(ctx)
+			}
 		}
 
 		var awsClient aws.Clients
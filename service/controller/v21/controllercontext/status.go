@@ -11,6 +11,9 @@ type Cluster struct {
 	// HostedZones is filled by the hostedzone resource. This information
 	// is used when creating CloudFormation templates.
 	HostedZones ClusterHostedZones
+	// VolumeEncryption is filled by the ebsencryption resource. This
+	// information is used when creating CloudFormation templates.
+	VolumeEncryption ClusterVolumeEncryption
 }
 
 type ClusterHostedZones struct {
@@ -23,7 +26,15 @@ type ClusterHostedZonesZone struct {
 	ID string
 }
 
+type ClusterVolumeEncryption struct {
+	// KeyARN is the ARN of the KMS key the EBS volumes of the guest cluster are
+	// encrypted with.
+	KeyARN string
+}
+
 type Drainer struct {
-	// WorkerASGName is filled by the workerasgname resource.
-	WorkerASGName string
+	// WorkerASGNames is filled by the workerasgname resource. It contains the
+	// name of the worker ASG followed by the names of the ASGs of all node
+	// pools.
+	WorkerASGNames []string
 }
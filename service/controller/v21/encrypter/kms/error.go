@@ -47,3 +47,10 @@ var keyScheduledForDeletionError = &microerror.Error{
 func IsKeyScheduledForDeletion(err error) bool {
 	return microerror.Cause(err) == keyScheduledForDeletionError
 }
+
+// isInvalidState asserts the KMS invalid state error, e.g. returned when
+// scheduling the deletion of a key which is already pending deletion.
+func isInvalidState(err error) bool {
+	aerr, ok := microerror.Cause(err).(awserr.Error)
+	return ok && aerr.Code() == kms.ErrCodeInvalidStateException
+}
@@ -2,6 +2,7 @@ package kms
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/kms"
@@ -55,7 +56,7 @@ func (e *Encrypter) EnsureCreatedEncryptionKey(ctx context.Context, customObject
 	{
 		e.logger.LogCtx(ctx, "level", "debug", "message", "finding out encryption key")
 
-		_, err := e.describeKey(ctx, customObject)
+		out, err := e.describeKey(ctx, customObject)
 		if IsKeyNotFound(err) {
 			e.logger.LogCtx(ctx, "level", "debug", "message", "did not find encryption key")
 
@@ -69,6 +70,14 @@ func (e *Encrypter) EnsureCreatedEncryptionKey(ctx context.Context, customObject
 
 		} else {
 			e.logger.LogCtx(ctx, "level", "debug", "message", "found encryption key")
+
+			// Keys of older guest clusters may have been created without
+			// rotation, so we make sure it is enabled for existing keys as well.
+			err := e.ensureKeyRotation(ctx, out.KeyMetadata.KeyId)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+
 			e.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
 			return nil
 		}
@@ -92,44 +101,9 @@ func (e *Encrypter) EnsureCreatedEncryptionKey(ctx context.Context, customObject
 		e.logger.LogCtx(ctx, "level", "debug", "message", "deleted old encryption key alias")
 	}
 
-	var keyID *string
-	{
-		e.logger.LogCtx(ctx, "level", "debug", "message", "creating encryption key")
-
-		tags := key.ClusterTags(customObject, e.installationName)
-
-		// TODO already created case should be handled here. Otherwise there is a chance alias wasn't created yet and EncryptionKey will tell there is no key. We can check tags to see if the key was created. Issue: https://github.com/giantswarm/giantswarm/issues/4262.
-		in := &kms.CreateKeyInput{
-			Tags: awstags.NewKMS(tags),
-		}
-
-		out, err := ctlCtx.AWSClient.KMS.CreateKey(in)
-		if err != nil {
-			return microerror.Mask(err)
-		}
-
-		keyID = out.KeyMetadata.KeyId
-
-		e.logger.LogCtx(ctx, "level", "debug", "message", "created encryption key")
-	}
-
-	// NOTE: Key roation must be enabled before creation alias. Otherwise
-	// it is not guaranteed it will be reconciled. Right now we *always*
-	// enable rotation before aliasing the key. So it is enough reconcile
-	// aliasing properly to make sure rotation is enabled.
-	{
-		e.logger.LogCtx(ctx, "level", "debug", "message", "enabling encryption key rotation")
-
-		in := &kms.EnableKeyRotationInput{
-			KeyId: keyID,
-		}
-
-		_, err = ctlCtx.AWSClient.KMS.EnableKeyRotation(in)
-		if err != nil {
-			return microerror.Mask(err)
-		}
-
-		e.logger.LogCtx(ctx, "level", "debug", "message", "enabled encryption key rotation")
+	keyID, err := e.createKey(ctx, customObject)
+	if err != nil {
+		return microerror.Mask(err)
 	}
 
 	{
@@ -152,11 +126,6 @@ func (e *Encrypter) EnsureCreatedEncryptionKey(ctx context.Context, customObject
 }
 
 func (e *Encrypter) EnsureDeletedEncryptionKey(ctx context.Context, customObject v1alpha1.AWSConfig) error {
-	ctlCtx, err := controllercontext.FromContext(ctx)
-	if err != nil {
-		return microerror.Mask(err)
-	}
-
 	var keyID *string
 	{
 		e.logger.LogCtx(ctx, "level", "debug", "message", "finding out encryption key")
@@ -183,28 +152,9 @@ func (e *Encrypter) EnsureDeletedEncryptionKey(ctx context.Context, customObject
 		}
 	}
 
-	{
-		e.logger.LogCtx(ctx, "level", "info", "message", "scheduling deletion of encryption key")
-
-		// AWS API doesn't allow to delete the KMS key immediately, but
-		// we can schedule its deletion. This also removes associated
-		// aliases. 7 days is the smallest possible pending window.
-		//
-		// https://docs.aws.amazon.com/kms/latest/developerguide/deleting-keys.html
-
-		pendingWindowInDays := aws.Int64(7)
-
-		in := &kms.ScheduleKeyDeletionInput{
-			KeyId:               keyID,
-			PendingWindowInDays: pendingWindowInDays,
-		}
-
-		_, err = ctlCtx.AWSClient.KMS.ScheduleKeyDeletion(in)
-		if err != nil {
-			return microerror.Mask(err)
-		}
-
-		e.logger.LogCtx(ctx, "level", "info", "message", "scheduled deletion of encryption key")
+	err := e.ScheduleEncryptionKeyDeletion(ctx, *keyID)
+	if err != nil {
+		return microerror.Mask(err)
 	}
 
 	return nil
@@ -222,6 +172,9 @@ func (k *Encrypter) CurrentState(ctx context.Context, customObject v1alpha1.AWSC
 		return currentState, microerror.Mask(err)
 	}
 
+	if output.KeyMetadata.CreationDate != nil {
+		currentState.CreationTime = *output.KeyMetadata.CreationDate
+	}
 	currentState.KeyID = *output.KeyMetadata.KeyId
 	currentState.KeyName = keyAlias(customObject)
 
@@ -269,6 +222,79 @@ func (k *Encrypter) Encrypt(ctx context.Context, key, plaintext string) (string,
 	return string(encryptOutput.CiphertextBlob), nil
 }
 
+// RotateEncryptionKey creates a new encryption key and points the alias of the
+// guest cluster to it. Assets encrypted from now on use the new key. The
+// previous key is kept so that assets encrypted with it can still be
+// decrypted until it is deleted using ScheduleEncryptionKeyDeletion.
+func (e *Encrypter) RotateEncryptionKey(ctx context.Context, customObject v1alpha1.AWSConfig) (encrypter.EncryptionKeyState, error) {
+	ctlCtx, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return encrypter.EncryptionKeyState{}, microerror.Mask(err)
+	}
+
+	keyID, err := e.createKey(ctx, customObject)
+	if err != nil {
+		return encrypter.EncryptionKeyState{}, microerror.Mask(err)
+	}
+
+	{
+		e.logger.LogCtx(ctx, "level", "debug", "message", "updating encryption key alias")
+
+		in := &kms.UpdateAliasInput{
+			AliasName:   aws.String(keyAlias(customObject)),
+			TargetKeyId: keyID,
+		}
+
+		_, err = ctlCtx.AWSClient.KMS.UpdateAlias(in)
+		if err != nil {
+			return encrypter.EncryptionKeyState{}, microerror.Mask(err)
+		}
+
+		e.logger.LogCtx(ctx, "level", "debug", "message", "updated encryption key alias")
+	}
+
+	state, err := e.CurrentState(ctx, customObject)
+	if err != nil {
+		return encrypter.EncryptionKeyState{}, microerror.Mask(err)
+	}
+
+	return state, nil
+}
+
+func (e *Encrypter) ScheduleEncryptionKeyDeletion(ctx context.Context, keyID string) error {
+	ctlCtx, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	e.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("scheduling deletion of encryption key %#q", keyID))
+
+	// AWS API doesn't allow to delete the KMS key immediately, but
+	// we can schedule its deletion. This also removes associated
+	// aliases. 7 days is the smallest possible pending window.
+	//
+	// https://docs.aws.amazon.com/kms/latest/developerguide/deleting-keys.html
+
+	pendingWindowInDays := aws.Int64(7)
+
+	in := &kms.ScheduleKeyDeletionInput{
+		KeyId:               aws.String(keyID),
+		PendingWindowInDays: pendingWindowInDays,
+	}
+
+	_, err = ctlCtx.AWSClient.KMS.ScheduleKeyDeletion(in)
+	if IsKeyNotFound(err) || isInvalidState(err) {
+		e.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("encryption key %#q is already deleted or scheduled for deletion", keyID))
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	e.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("scheduled deletion of encryption key %#q", keyID))
+
+	return nil
+}
+
 func (e *Encrypter) IsKeyNotFound(err error) bool {
 	return IsKeyNotFound(err) || IsKeyScheduledForDeletion(err)
 }
@@ -296,3 +322,84 @@ func (k *Encrypter) describeKey(ctx context.Context, customObject v1alpha1.AWSCo
 
 	return out, nil
 }
+
+// createKey creates a new encryption key for the guest cluster with rotation
+// enabled and returns its ID.
+func (e *Encrypter) createKey(ctx context.Context, customObject v1alpha1.AWSConfig) (*string, error) {
+	ctlCtx, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var keyID *string
+	{
+		e.logger.LogCtx(ctx, "level", "debug", "message", "creating encryption key")
+
+		tags := key.ClusterTags(customObject, e.installationName)
+
+		// TODO already created case should be handled here. Otherwise there is a chance alias wasn't created yet and EncryptionKey will tell there is no key. We can check tags to see if the key was created. Issue: https://github.com/giantswarm/giantswarm/issues/4262.
+		in := &kms.CreateKeyInput{
+			Tags: awstags.NewKMS(tags),
+		}
+
+		out, err := ctlCtx.AWSClient.KMS.CreateKey(in)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		keyID = out.KeyMetadata.KeyId
+
+		e.logger.LogCtx(ctx, "level", "debug", "message", "created encryption key")
+	}
+
+	// NOTE: Key roation must be enabled before creation alias. Otherwise
+	// it is not guaranteed it will be reconciled. Right now we *always*
+	// enable rotation before aliasing the key. So it is enough reconcile
+	// aliasing properly to make sure rotation is enabled.
+	{
+		e.logger.LogCtx(ctx, "level", "debug", "message", "enabling encryption key rotation")
+
+		in := &kms.EnableKeyRotationInput{
+			KeyId: keyID,
+		}
+
+		_, err = ctlCtx.AWSClient.KMS.EnableKeyRotation(in)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		e.logger.LogCtx(ctx, "level", "debug", "message", "enabled encryption key rotation")
+	}
+
+	return keyID, nil
+}
+
+func (e *Encrypter) ensureKeyRotation(ctx context.Context, keyID *string) error {
+	ctlCtx, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	e.logger.LogCtx(ctx, "level", "debug", "message", "finding out if encryption key rotation is enabled")
+
+	out, err := ctlCtx.AWSClient.KMS.GetKeyRotationStatus(&kms.GetKeyRotationStatusInput{KeyId: keyID})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if aws.BoolValue(out.KeyRotationEnabled) {
+		e.logger.LogCtx(ctx, "level", "debug", "message", "encryption key rotation is enabled")
+		return nil
+	}
+
+	e.logger.LogCtx(ctx, "level", "debug", "message", "enabling encryption key rotation")
+
+	_, err = ctlCtx.AWSClient.KMS.EnableKeyRotation(&kms.EnableKeyRotationInput{KeyId: keyID})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	e.logger.LogCtx(ctx, "level", "debug", "message", "enabled encryption key rotation")
+
+	return nil
+}
@@ -2,18 +2,21 @@ package encrypter
 
 import (
 	"context"
+	"time"
 
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
 )
 
 const (
 	KMSBackend   = "kms"
+	SSMBackend   = "ssm"
 	VaultBackend = "vault"
 )
 
 type EncryptionKeyState struct {
-	KeyID   string
-	KeyName string
+	CreationTime time.Time
+	KeyID        string
+	KeyName      string
 }
 
 type Interface interface {
@@ -36,3 +39,18 @@ type RoleManager interface {
 	EnsureCreatedAuthorizedIAMRoles(context.Context, v1alpha1.AWSConfig) error
 	EnsureDeletedAuthorizedIAMRoles(context.Context, v1alpha1.AWSConfig) error
 }
+
+// KeyRotator is implemented by encrypters supporting the replacement of the
+// encryption key of a guest cluster.
+type KeyRotator interface {
+	// CurrentState returns the state of the encryption key currently used to
+	// encrypt the assets of the guest cluster.
+	CurrentState(context.Context, v1alpha1.AWSConfig) (EncryptionKeyState, error)
+	// RotateEncryptionKey creates a new encryption key and uses it to encrypt
+	// the assets of the guest cluster from now on. It returns the state of the
+	// new encryption key.
+	RotateEncryptionKey(context.Context, v1alpha1.AWSConfig) (EncryptionKeyState, error)
+	// ScheduleEncryptionKeyDeletion schedules the deletion of the encryption
+	// key with the given ID.
+	ScheduleEncryptionKeyDeletion(ctx context.Context, keyID string) error
+}
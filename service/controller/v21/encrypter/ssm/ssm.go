@@ -0,0 +1,225 @@
+package ssm
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"path"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/aws-operator/service/controller/v21/controllercontext"
+	"github.com/giantswarm/aws-operator/service/controller/v21/encrypter"
+	"github.com/giantswarm/aws-operator/service/controller/v21/encrypter/kms"
+	"github.com/giantswarm/aws-operator/service/controller/v21/key"
+)
+
+const (
+	// deleteParametersBatchSize is the maximum number of parameters the SSM API
+	// deletes with a single request.
+	deleteParametersBatchSize = 10
+)
+
+// Encrypter stores the assets of the guest cluster as SecureString parameters
+// in the SSM Parameter Store. The parameters are encrypted with the KMS key of
+// the guest cluster, which is managed the same way the KMS encrypter does it.
+// The encrypted assets written into the cloud config only reference the
+// parameters, which are fetched by the nodes on boot.
+type Encrypter struct {
+	kms    *kms.Encrypter
+	logger micrologger.Logger
+}
+
+type EncrypterConfig struct {
+	Logger micrologger.Logger
+
+	InstallationName string
+}
+
+func NewEncrypter(c *EncrypterConfig) (*Encrypter, error) {
+	if c.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", c)
+	}
+
+	var err error
+
+	var kmsEncrypter *kms.Encrypter
+	{
+		c := &kms.EncrypterConfig{
+			Logger: c.Logger,
+
+			InstallationName: c.InstallationName,
+		}
+
+		kmsEncrypter, err = kms.NewEncrypter(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	e := &Encrypter{
+		kms:    kmsEncrypter,
+		logger: c.Logger,
+	}
+
+	return e, nil
+}
+
+func (e *Encrypter) EnsureCreatedEncryptionKey(ctx context.Context, customObject v1alpha1.AWSConfig) error {
+	err := e.kms.EnsureCreatedEncryptionKey(ctx, customObject)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (e *Encrypter) EnsureDeletedEncryptionKey(ctx context.Context, customObject v1alpha1.AWSConfig) error {
+	ctlCtx, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	var names []*string
+	{
+		e.logger.LogCtx(ctx, "level", "debug", "message", "finding SSM parameters")
+
+		i := &ssm.GetParametersByPathInput{
+			Path:      aws.String(key.SSMParameterPrefix(customObject)),
+			Recursive: aws.Bool(true),
+		}
+
+		err := ctlCtx.AWSClient.SSM.GetParametersByPathPages(i, func(o *ssm.GetParametersByPathOutput, lastPage bool) bool {
+			for _, p := range o.Parameters {
+				names = append(names, p.Name)
+			}
+			return true
+		})
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		e.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found %d SSM parameters", len(names)))
+	}
+
+	if len(names) > 0 {
+		e.logger.LogCtx(ctx, "level", "debug", "message", "deleting SSM parameters")
+
+		for len(names) > 0 {
+			n := deleteParametersBatchSize
+			if len(names) < n {
+				n = len(names)
+			}
+
+			i := &ssm.DeleteParametersInput{
+				Names: names[:n],
+			}
+
+			_, err := ctlCtx.AWSClient.SSM.DeleteParameters(i)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+
+			names = names[n:]
+		}
+
+		e.logger.LogCtx(ctx, "level", "debug", "message", "deleted SSM parameters")
+	}
+
+	err = e.kms.EnsureDeletedEncryptionKey(ctx, customObject)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// EncryptionKey returns the path the SSM parameters of the guest cluster are
+// stored under, once the KMS key encrypting them exists.
+func (e *Encrypter) EncryptionKey(ctx context.Context, customObject v1alpha1.AWSConfig) (string, error) {
+	_, err := e.kms.EncryptionKey(ctx, customObject)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return key.SSMParameterPrefix(customObject), nil
+}
+
+// Encrypt is not supported since every parameter needs a stable name. Assets
+// have to be encrypted using EncryptAsset.
+func (e *Encrypter) Encrypt(ctx context.Context, key, plaintext string) (string, error) {
+	return "", microerror.Maskf(executionFailedError, "SSM parameters must be named, use EncryptAsset")
+}
+
+// EncryptAsset stores the given plaintext of the named asset as SecureString
+// parameter below the path returned by EncryptionKey and returns the name of
+// the parameter. Each asset is always stored in the same parameter, which is
+// overwritten when the asset changes. This way no parameters are left behind
+// once assets like certificates are renewed. The value is base64 encoded so
+// that nodes can restore the plaintext byte by byte.
+func (e *Encrypter) EncryptAsset(ctx context.Context, key, asset, plaintext string) (string, error) {
+	ctlCtx, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	name := path.Join(key, asset)
+
+	i := &ssm.PutParameterInput{
+		KeyId:     aws.String(keyAlias(key)),
+		Name:      aws.String(name),
+		Overwrite: aws.Bool(true),
+		Type:      aws.String(ssm.ParameterTypeSecureString),
+		Value:     aws.String(base64.StdEncoding.EncodeToString([]byte(plaintext))),
+	}
+
+	_, err = ctlCtx.AWSClient.SSM.PutParameter(i)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return name, nil
+}
+
+func (e *Encrypter) CurrentState(ctx context.Context, customObject v1alpha1.AWSConfig) (encrypter.EncryptionKeyState, error) {
+	state, err := e.kms.CurrentState(ctx, customObject)
+	if err != nil {
+		return encrypter.EncryptionKeyState{}, microerror.Mask(err)
+	}
+
+	return state, nil
+}
+
+// RotateEncryptionKey replaces the KMS key of the guest cluster. The
+// parameters are encrypted with the new key once they are written again.
+func (e *Encrypter) RotateEncryptionKey(ctx context.Context, customObject v1alpha1.AWSConfig) (encrypter.EncryptionKeyState, error) {
+	state, err := e.kms.RotateEncryptionKey(ctx, customObject)
+	if err != nil {
+		return encrypter.EncryptionKeyState{}, microerror.Mask(err)
+	}
+
+	return state, nil
+}
+
+func (e *Encrypter) ScheduleEncryptionKeyDeletion(ctx context.Context, keyID string) error {
+	err := e.kms.ScheduleEncryptionKeyDeletion(ctx, keyID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (e *Encrypter) IsKeyNotFound(err error) bool {
+	return e.kms.IsKeyNotFound(err)
+}
+
+// keyAlias returns the alias of the KMS key of the guest cluster the
+// parameters below the given path belong to.
+func keyAlias(prefix string) string {
+	return fmt.Sprintf("alias/%s", path.Base(prefix))
+}
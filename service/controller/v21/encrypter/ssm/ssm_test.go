@@ -0,0 +1,193 @@
+package ssm
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+
+	awsclient "github.com/giantswarm/aws-operator/client/aws"
+	"github.com/giantswarm/aws-operator/service/controller/v21/controllercontext"
+)
+
+type kmsMock struct {
+	kmsiface.KMSAPI
+}
+
+func (k *kmsMock) DescribeKey(*kms.DescribeKeyInput) (*kms.DescribeKeyOutput, error) {
+	return nil, awserr.New(kms.ErrCodeNotFoundException, "key not found", nil)
+}
+
+// ssmMock stores parameters in memory and returns them in pages of two.
+type ssmMock struct {
+	ssmiface.SSMAPI
+
+	parameters map[string]*ssm.PutParameterInput
+	deletes    int
+}
+
+func (s *ssmMock) DeleteParameters(input *ssm.DeleteParametersInput) (*ssm.DeleteParametersOutput, error) {
+	if len(input.Names) > deleteParametersBatchSize {
+		return nil, fmt.Errorf("too many parameters %d", len(input.Names))
+	}
+
+	s.deletes++
+	for _, n := range input.Names {
+		delete(s.parameters, *n)
+	}
+
+	return &ssm.DeleteParametersOutput{DeletedParameters: input.Names}, nil
+}
+
+func (s *ssmMock) GetParametersByPathPages(input *ssm.GetParametersByPathInput, fn func(*ssm.GetParametersByPathOutput, bool) bool) error {
+	var names []string
+	for n := range s.parameters {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	for start := 0; start < len(names); start += 2 {
+		end := start + 2
+		if end > len(names) {
+			end = len(names)
+		}
+
+		o := &ssm.GetParametersByPathOutput{}
+		for _, n := range names[start:end] {
+			o.Parameters = append(o.Parameters, &ssm.Parameter{Name: aws.String(n)})
+		}
+
+		if !fn(o, end == len(names)) {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (s *ssmMock) PutParameter(input *ssm.PutParameterInput) (*ssm.PutParameterOutput, error) {
+	s.parameters[*input.Name] = input
+
+	return &ssm.PutParameterOutput{Version: aws.Int64(1)}, nil
+}
+
+func newTestContext(s *ssmMock) context.Context {
+	c := controllercontext.Context{
+		AWSClient: awsclient.Clients{
+			KMS: &kmsMock{},
+			SSM: s,
+		},
+	}
+
+	return controllercontext.NewContext(context.Background(), c)
+}
+
+func newTestEncrypter(t *testing.T) *Encrypter {
+	c := &EncrypterConfig{
+		Logger: microloggertest.New(),
+
+		InstallationName: "test-installation",
+	}
+
+	e, err := NewEncrypter(c)
+	if err != nil {
+		t.Fatalf("expected nil got %#v", err)
+	}
+
+	return e
+}
+
+func Test_Encrypter_EncryptAsset(t *testing.T) {
+	s := &ssmMock{parameters: map[string]*ssm.PutParameterInput{}}
+	ctx := newTestContext(s)
+	e := newTestEncrypter(t)
+
+	name, err := e.EncryptAsset(ctx, "/giantswarm/al9qy", "etc/kubernetes/ssl/apiserver-key.pem", "secret")
+	if err != nil {
+		t.Fatalf("expected nil got %#v", err)
+	}
+
+	expectedName := "/giantswarm/al9qy/etc/kubernetes/ssl/apiserver-key.pem"
+	if name != expectedName {
+		t.Fatalf("expected %#q got %#q", expectedName, name)
+	}
+
+	p, ok := s.parameters[name]
+	if !ok {
+		t.Fatalf("expected parameter %#q to be stored", name)
+	}
+	if *p.KeyId != "alias/al9qy" {
+		t.Fatalf("expected key %#q got %#q", "alias/al9qy", *p.KeyId)
+	}
+	if *p.Type != ssm.ParameterTypeSecureString {
+		t.Fatalf("expected type %#q got %#q", ssm.ParameterTypeSecureString, *p.Type)
+	}
+	if *p.Value != base64.StdEncoding.EncodeToString([]byte("secret")) {
+		t.Fatalf("expected base64 encoded plaintext got %#q", *p.Value)
+	}
+
+	// Encrypting a changed asset overwrites its parameter.
+	again, err := e.EncryptAsset(ctx, "/giantswarm/al9qy", "etc/kubernetes/ssl/apiserver-key.pem", "renewed")
+	if err != nil {
+		t.Fatalf("expected nil got %#v", err)
+	}
+	if again != name || len(s.parameters) != 1 {
+		t.Fatalf("expected 1 parameter %#q got %d parameters and %#q", name, len(s.parameters), again)
+	}
+	if *s.parameters[name].Value != base64.StdEncoding.EncodeToString([]byte("renewed")) {
+		t.Fatalf("expected base64 encoded renewed plaintext got %#q", *s.parameters[name].Value)
+	}
+	if !*s.parameters[name].Overwrite {
+		t.Fatalf("expected parameter to be overwritten")
+	}
+
+	// Unnamed assets are not supported.
+	_, err = e.Encrypt(ctx, "/giantswarm/al9qy", "secret")
+	if !IsExecutionFailed(err) {
+		t.Fatalf("expected execution failed error got %#v", err)
+	}
+}
+
+func Test_Encrypter_EnsureDeletedEncryptionKey(t *testing.T) {
+	s := &ssmMock{parameters: map[string]*ssm.PutParameterInput{}}
+	ctx := newTestContext(s)
+	e := newTestEncrypter(t)
+
+	for i := 0; i < 25; i++ {
+		_, err := e.EncryptAsset(ctx, "/giantswarm/al9qy", strconv.Itoa(i), "secret")
+		if err != nil {
+			t.Fatalf("expected nil got %#v", err)
+		}
+	}
+
+	customObject := v1alpha1.AWSConfig{
+		Spec: v1alpha1.AWSConfigSpec{
+			Cluster: v1alpha1.Cluster{
+				ID: "al9qy",
+			},
+		},
+	}
+
+	err := e.EnsureDeletedEncryptionKey(ctx, customObject)
+	if err != nil {
+		t.Fatalf("expected nil got %#v", err)
+	}
+
+	if len(s.parameters) != 0 {
+		t.Fatalf("expected 0 parameters got %d", len(s.parameters))
+	}
+	if s.deletes != 3 {
+		t.Fatalf("expected 3 delete requests got %d", s.deletes)
+	}
+}
@@ -0,0 +1,203 @@
+package vault
+
+import (
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+)
+
+const (
+	AuthMethodAppRole    = "approle"
+	AuthMethodAWS        = "aws"
+	AuthMethodKubernetes = "kubernetes"
+)
+
+const (
+	// defaultKubernetesTokenPath is the path the service account token of the
+	// operator pod is mounted at.
+	defaultKubernetesTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+// AuthConfig configures the Vault auth method the encrypter logs in with.
+type AuthConfig struct {
+	// Method is the auth method used to log in to Vault. It is one of
+	// AuthMethodAWS, AuthMethodKubernetes and AuthMethodAppRole. The AWS auth
+	// method is used in case it is empty.
+	Method string
+	// Mount is the path the auth method is enabled at in Vault. The name of the
+	// auth method is used in case it is empty.
+	Mount string
+	// Role is the Vault role used by the AWS and the Kubernetes auth method.
+	Role string
+
+	// AppRoleRoleID is the role ID used by the AppRole auth method.
+	AppRoleRoleID string
+	// AppRoleSecretIDPath is the path of the file containing the secret ID used
+	// by the AppRole auth method, e.g. a key of a mounted Secret. No secret ID
+	// is used in case it is empty.
+	AppRoleSecretIDPath string
+
+	// KubernetesTokenPath is the path of the service account token used by the
+	// Kubernetes auth method.
+	KubernetesTokenPath string
+}
+
+// authMethod provides the login requests of a Vault auth method.
+type authMethod interface {
+	// loginPath returns the path of the login endpoint relative to the Vault
+	// API.
+	loginPath() string
+	// loginPayload returns the payload of the next login request.
+	loginPayload() (interface{}, error)
+	// loggedIn is called with the auth data of each successful login.
+	loggedIn(auth LoginAuthResponse)
+}
+
+func newAuthMethod(config AuthConfig) (authMethod, error) {
+	role := config.Role
+	if role == "" {
+		role = defaultRole
+	}
+
+	switch config.Method {
+	case "", AuthMethodAWS:
+		a := &awsAuth{
+			mount: mountOrDefault(config.Mount, AuthMethodAWS),
+			role:  role,
+			// fixed nonce, so that we can reauthenticate from the same host.
+			nonce: defaultNonce,
+		}
+
+		return a, nil
+	case AuthMethodAppRole:
+		if config.AppRoleRoleID == "" {
+			return nil, microerror.Maskf(invalidConfigError, "%T.AppRoleRoleID must not be empty", config)
+		}
+
+		a := &appRoleAuth{
+			mount:        mountOrDefault(config.Mount, AuthMethodAppRole),
+			roleID:       config.AppRoleRoleID,
+			secretIDPath: config.AppRoleSecretIDPath,
+		}
+
+		return a, nil
+	case AuthMethodKubernetes:
+		tokenPath := config.KubernetesTokenPath
+		if tokenPath == "" {
+			tokenPath = defaultKubernetesTokenPath
+		}
+
+		a := &kubernetesAuth{
+			mount:     mountOrDefault(config.Mount, AuthMethodKubernetes),
+			role:      role,
+			tokenPath: tokenPath,
+		}
+
+		return a, nil
+	}
+
+	return nil, microerror.Maskf(invalidConfigError, "%T.Method must be one of %#q, %#q and %#q, got %#q", config, AuthMethodAWS, AuthMethodKubernetes, AuthMethodAppRole, config.Method)
+}
+
+// awsAuth logs in using the AWS auth method with the PKCS7 signature of the
+// instance identity document of the EC2 instance the operator runs on.
+type awsAuth struct {
+	mount string
+	nonce string
+	role  string
+}
+
+func (a *awsAuth) loginPath() string {
+	return path.Join("auth", a.mount, "login")
+}
+
+func (a *awsAuth) loginPayload() (interface{}, error) {
+	pkcs7, err := getPKCS7()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	payload := &LoginPayload{
+		Role:  a.role,
+		PKCS7: pkcs7,
+		Nonce: a.nonce,
+	}
+
+	return payload, nil
+}
+
+func (a *awsAuth) loggedIn(auth LoginAuthResponse) {
+	a.nonce = auth.Metadata.Nonce
+}
+
+// appRoleAuth logs in using the AppRole auth method. The secret ID is read
+// for every login because it may be rotated.
+type appRoleAuth struct {
+	mount        string
+	roleID       string
+	secretIDPath string
+}
+
+func (a *appRoleAuth) loginPath() string {
+	return path.Join("auth", a.mount, "login")
+}
+
+func (a *appRoleAuth) loginPayload() (interface{}, error) {
+	var secretID string
+	if a.secretIDPath != "" {
+		b, err := ioutil.ReadFile(a.secretIDPath)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		secretID = strings.TrimSpace(string(b))
+	}
+
+	payload := &AppRoleLoginPayload{
+		RoleID:   a.roleID,
+		SecretID: secretID,
+	}
+
+	return payload, nil
+}
+
+func (a *appRoleAuth) loggedIn(auth LoginAuthResponse) {}
+
+// kubernetesAuth logs in using the Kubernetes auth method with the service
+// account token of the operator pod. The token is read for every login
+// because it may be rotated.
+type kubernetesAuth struct {
+	mount     string
+	role      string
+	tokenPath string
+}
+
+func (a *kubernetesAuth) loginPath() string {
+	return path.Join("auth", a.mount, "login")
+}
+
+func (a *kubernetesAuth) loginPayload() (interface{}, error) {
+	b, err := ioutil.ReadFile(a.tokenPath)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	payload := &KubernetesLoginPayload{
+		Role: a.role,
+		JWT:  strings.TrimSpace(string(b)),
+	}
+
+	return payload, nil
+}
+
+func (a *kubernetesAuth) loggedIn(auth LoginAuthResponse) {}
+
+func mountOrDefault(mount, method string) string {
+	if mount == "" {
+		return method
+	}
+
+	return mount
+}
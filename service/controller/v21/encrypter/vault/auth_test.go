@@ -0,0 +1,203 @@
+package vault
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+)
+
+// fakeVault is a minimal Vault server implementing the login, token lookup and
+// token renew endpoints.
+type fakeVault struct {
+	// validToken is the token accepted by the lookup and renew endpoints.
+	validToken string
+
+	logins   []map[string]string
+	renewals int
+}
+
+func (v *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/v1/auth/token/lookup-self":
+		if r.Header.Get("X-Vault-Token") != v.validToken {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	case "/v1/auth/token/renew-self":
+		if r.Header.Get("X-Vault-Token") != v.validToken {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		v.renewals++
+		json.NewEncoder(w).Encode(&LoginResponse{Auth: LoginAuthResponse{ClientToken: v.validToken, LeaseDuration: 3600, Renewable: true}})
+	case "/v1/auth/approle/login", "/v1/auth/k8s/login":
+		payload := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		v.logins = append(v.logins, payload)
+		v.validToken = "token"
+		json.NewEncoder(w).Encode(&LoginResponse{Auth: LoginAuthResponse{ClientToken: v.validToken, LeaseDuration: 3600, Renewable: true}})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func Test_Encrypter_ensureToken_AppRole(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vault")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	secretIDPath := filepath.Join(dir, "secret-id")
+	err = ioutil.WriteFile(secretIDPath, []byte("secret-id\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := &fakeVault{}
+	s := httptest.NewServer(v)
+	defer s.Close()
+
+	c := &EncrypterConfig{
+		Logger: microloggertest.New(),
+
+		Address: s.URL,
+		Auth: AuthConfig{
+			Method:              AuthMethodAppRole,
+			AppRoleRoleID:       "role-id",
+			AppRoleSecretIDPath: secretIDPath,
+		},
+	}
+
+	e, err := NewEncrypter(c)
+	if err != nil {
+		t.Fatalf("expected nil got %#v", err)
+	}
+
+	// The first call logs in.
+	err = e.ensureToken()
+	if err != nil {
+		t.Fatalf("expected nil got %#v", err)
+	}
+
+	expectedLogins := []map[string]string{
+		{"role_id": "role-id", "secret_id": "secret-id"},
+	}
+	if !reflect.DeepEqual(v.logins, expectedLogins) {
+		t.Fatalf("expected logins %#v got %#v", expectedLogins, v.logins)
+	}
+
+	// The second call finds the token to be valid and not due for renewal.
+	err = e.ensureToken()
+	if err != nil {
+		t.Fatalf("expected nil got %#v", err)
+	}
+	if len(v.logins) != 1 || v.renewals != 0 {
+		t.Fatalf("expected 1 login and 0 renewals got %d and %d", len(v.logins), v.renewals)
+	}
+
+	// Once half of the lease duration passed, the token is renewed.
+	e.tokenRenewTime = time.Now().Add(-time.Second)
+
+	err = e.ensureToken()
+	if err != nil {
+		t.Fatalf("expected nil got %#v", err)
+	}
+	if len(v.logins) != 1 || v.renewals != 1 {
+		t.Fatalf("expected 1 login and 1 renewal got %d and %d", len(v.logins), v.renewals)
+	}
+
+	// Invalid tokens cause a new login.
+	v.validToken = "revoked"
+
+	err = e.ensureToken()
+	if err != nil {
+		t.Fatalf("expected nil got %#v", err)
+	}
+	if len(v.logins) != 2 {
+		t.Fatalf("expected 2 logins got %d", len(v.logins))
+	}
+}
+
+func Test_Encrypter_ensureToken_Kubernetes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vault")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tokenPath := filepath.Join(dir, "token")
+	err = ioutil.WriteFile(tokenPath, []byte("service-account-token\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := &fakeVault{}
+	s := httptest.NewServer(v)
+	defer s.Close()
+
+	c := &EncrypterConfig{
+		Logger: microloggertest.New(),
+
+		Address: s.URL,
+		Auth: AuthConfig{
+			Method:              AuthMethodKubernetes,
+			Mount:               "k8s",
+			KubernetesTokenPath: tokenPath,
+		},
+	}
+
+	e, err := NewEncrypter(c)
+	if err != nil {
+		t.Fatalf("expected nil got %#v", err)
+	}
+
+	err = e.ensureToken()
+	if err != nil {
+		t.Fatalf("expected nil got %#v", err)
+	}
+
+	expectedLogins := []map[string]string{
+		{"role": defaultRole, "jwt": "service-account-token"},
+	}
+	if !reflect.DeepEqual(v.logins, expectedLogins) {
+		t.Fatalf("expected logins %#v got %#v", expectedLogins, v.logins)
+	}
+	if e.token != "token" {
+		t.Fatalf("expected token %#q got %#q", "token", e.token)
+	}
+}
+
+func Test_newAuthMethod_Invalid(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config AuthConfig
+	}{
+		{
+			name:   "case 0: unknown method",
+			config: AuthConfig{Method: "ldap"},
+		},
+		{
+			name:   "case 1: AppRole without role ID",
+			config: AuthConfig{Method: AuthMethodAppRole, AppRoleSecretIDPath: "/var/run/secrets/vault/secret-id"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newAuthMethod(tc.config)
+			if !IsInvalidConfig(err) {
+				t.Fatalf("expected invalid config error got %#v", err)
+			}
+		})
+	}
+}
@@ -18,13 +18,25 @@ type LoginPayload struct {
 	Nonce string `json:"nonce,omitempty"`
 }
 
+type AppRoleLoginPayload struct {
+	RoleID   string `json:"role_id"`
+	SecretID string `json:"secret_id,omitempty"`
+}
+
+type KubernetesLoginPayload struct {
+	Role string `json:"role"`
+	JWT  string `json:"jwt"`
+}
+
 type LoginResponse struct {
 	Auth LoginAuthResponse `json:"auth"`
 }
 
 type LoginAuthResponse struct {
-	Metadata    LoginAuthMetadataResponse `json:"metadata"`
-	ClientToken string                    `json:"client_token"`
+	Metadata      LoginAuthMetadataResponse `json:"metadata"`
+	ClientToken   string                    `json:"client_token"`
+	LeaseDuration int                       `json:"lease_duration"`
+	Renewable     bool                      `json:"renewable"`
 }
 
 type LoginAuthMetadataResponse struct {
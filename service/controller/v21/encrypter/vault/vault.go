@@ -27,19 +27,25 @@ const (
 )
 
 type Encrypter struct {
+	auth       authMethod
 	httpClient *http.Client
 	logger     micrologger.Logger
 
 	address string
 	base    *url.URL
-	nonce   string
 	token   string
+	// tokenRenewable is true in case the token can be renewed. tokenRenewTime
+	// is the time after which the token is renewed, which is half of its lease
+	// duration after it was issued or renewed.
+	tokenRenewable bool
+	tokenRenewTime time.Time
 }
 
 type EncrypterConfig struct {
 	Logger micrologger.Logger
 
 	Address string
+	Auth    AuthConfig
 }
 
 func NewEncrypter(c *EncrypterConfig) (*Encrypter, error) {
@@ -50,6 +56,11 @@ func NewEncrypter(c *EncrypterConfig) (*Encrypter, error) {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Address must not be empty", c)
 	}
 
+	auth, err := newAuthMethod(c.Auth)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
 	base, err := url.Parse(c.Address + "/v1/")
 	if err != nil {
 		return nil, microerror.Mask(err)
@@ -61,13 +72,12 @@ func NewEncrypter(c *EncrypterConfig) (*Encrypter, error) {
 	}
 
 	e := &Encrypter{
+		auth:       auth,
 		httpClient: httpClient,
 		logger:     c.Logger,
 
 		address: c.Address,
 		base:    base,
-		// fixed nonce, so that we can reauthenticate from the same host.
-		nonce: defaultNonce,
 	}
 
 	return e, nil
@@ -445,9 +455,21 @@ func (e *Encrypter) Address() string {
 	return e.address
 }
 
+// ensureToken makes sure the encrypter has a valid token. Tokens are renewed
+// once half of their lease duration passed. In case the token is invalid or
+// cannot be renewed, the encrypter logs in again using its auth method.
 func (e *Encrypter) ensureToken() error {
 	if e.isTokenValid() {
-		return nil
+		if !e.tokenRenewable || time.Now().Before(e.tokenRenewTime) {
+			return nil
+		}
+
+		err := e.renewToken()
+		if err == nil {
+			return nil
+		}
+
+		e.logger.Log("level", "warning", "message", fmt.Sprintf("could not renew token: %#v", err))
 	}
 
 	err := e.login()
@@ -459,6 +481,10 @@ func (e *Encrypter) ensureToken() error {
 }
 
 func (e *Encrypter) isTokenValid() bool {
+	if e.token == "" {
+		return false
+	}
+
 	p := path.Join("auth", "token", "lookup-self")
 
 	req, err := e.newRequest("GET", p)
@@ -484,45 +510,77 @@ func (e *Encrypter) isTokenValid() bool {
 }
 
 func (e *Encrypter) login() error {
-	pkcs7, err := e.getPKCS7()
+	payload, err := e.auth.loginPayload()
 	if err != nil {
 		return microerror.Mask(err)
 	}
 
-	payload := &LoginPayload{
-		Role:  defaultRole,
-		PKCS7: pkcs7,
-		Nonce: e.nonce,
+	// Login requests must not carry the token of a previous login.
+	e.token = ""
+
+	loginResp, err := e.postAuth(e.auth.loginPath(), payload)
+	if err != nil {
+		return microerror.Mask(err)
 	}
-	p := path.Join("auth", "aws", "login")
 
-	req, err := e.newPayloadRequest(p, payload)
+	e.auth.loggedIn(loginResp.Auth)
+	e.setToken(loginResp.Auth)
+
+	return nil
+}
+
+func (e *Encrypter) renewToken() error {
+	p := path.Join("auth", "token", "renew-self")
+
+	renewResp, err := e.postAuth(p, &struct{}{})
 	if err != nil {
 		return microerror.Mask(err)
 	}
 
+	e.setToken(renewResp.Auth)
+
+	return nil
+}
+
+func (e *Encrypter) setToken(auth LoginAuthResponse) {
+	leaseDuration := time.Duration(auth.LeaseDuration) * time.Second
+
+	// The renew endpoint may omit the token, which does not change when being
+	// renewed.
+	if auth.ClientToken != "" {
+		e.token = auth.ClientToken
+	}
+	e.tokenRenewable = auth.Renewable
+	e.tokenRenewTime = time.Now().Add(leaseDuration / 2)
+}
+
+// postAuth sends the given payload to the given path and returns the auth
+// data of the response, as returned by the login and renew endpoints.
+func (e *Encrypter) postAuth(p string, payload interface{}) (*LoginResponse, error) {
+	req, err := e.newPayloadRequest(p, payload)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
 	resp, err := e.httpClient.Do(req)
 	if err != nil {
-		return microerror.Mask(err)
+		return nil, microerror.Mask(err)
 	}
 
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := ioutil.ReadAll(resp.Body)
-		return microerror.Maskf(invalidHTTPStatusCodeError, "want 200, got %d, response body: %q", resp.StatusCode, body)
+		return nil, microerror.Maskf(invalidHTTPStatusCodeError, "want 200, got %d, response body: %q", resp.StatusCode, body)
 	}
 
 	loginResp := &LoginResponse{}
 	err = json.NewDecoder(resp.Body).Decode(loginResp)
 	if err != nil {
-		return microerror.Mask(err)
+		return nil, microerror.Mask(err)
 	}
 
-	e.token = loginResp.Auth.ClientToken
-	e.nonce = loginResp.Auth.Metadata.Nonce
-
-	return nil
+	return loginResp, nil
 }
 
 func (e *Encrypter) newRequest(method, path string) (*http.Request, error) {
@@ -565,7 +623,7 @@ func (e *Encrypter) newPayloadRequest(path string, payload interface{}) (*http.R
 	return req, nil
 }
 
-func (e *Encrypter) getPKCS7() (string, error) {
+func getPKCS7() (string, error) {
 	response, err := http.Get(instanceIdentityPKCS7Endpoint)
 	if err != nil {
 		return "", microerror.Mask(err)
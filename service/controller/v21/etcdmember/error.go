@@ -0,0 +1,21 @@
+package etcdmember
+
+import "github.com/giantswarm/microerror"
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var peerDomainNotCoveredError = &microerror.Error{
+	Kind: "peerDomainNotCoveredError",
+}
+
+// IsPeerDomainNotCovered asserts peerDomainNotCoveredError.
+func IsPeerDomainNotCovered(err error) bool {
+	return microerror.Cause(err) == peerDomainNotCoveredError
+}
@@ -0,0 +1,177 @@
+package etcdmember
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/certs"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/aws-operator/service/controller/v21/key"
+)
+
+const (
+	// DefaultTimeout is the time the health endpoint of an etcd member has to
+	// respond in before the member is considered unhealthy.
+	DefaultTimeout = 5 * time.Second
+)
+
+type Config struct {
+	CertsSearcher certs.Interface
+	Logger        micrologger.Logger
+
+	Timeout time.Duration
+}
+
+type Checker struct {
+	certsSearcher certs.Interface
+	logger        micrologger.Logger
+
+	timeout time.Duration
+}
+
+func New(config Config) (*Checker, error) {
+	if config.CertsSearcher == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CertsSearcher must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.Timeout == 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Timeout must not be empty", config)
+	}
+
+	c := &Checker{
+		certsSearcher: config.CertsSearcher,
+		logger:        config.Logger,
+
+		timeout: config.Timeout,
+	}
+
+	return c, nil
+}
+
+func (c *Checker) Healthy(ctx context.Context, customObject v1alpha1.AWSConfig, idx int) (bool, error) {
+	tlsAssets, err := c.certsSearcher.SearchTLS(key.ClusterID(customObject), certs.EtcdCert)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	tlsConfig, err := newTLSConfig(tlsAssets)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	client := &http.Client{
+		Timeout: c.timeout,
+		Transport: &http.Transport{
+			TLSClientConfig: tlsConfig,
+		},
+	}
+
+	url := fmt.Sprintf("https://%s:%d/health", key.EtcdMemberDomain(customObject, idx), key.EtcdPort(customObject))
+
+	res, err := client.Get(url)
+	if err != nil {
+		// Members of masters being replaced cannot be reached until the new
+		// master booted. This is expected, which is why we do not return an
+		// error here.
+		c.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("did not reach etcd member %#q", key.EtcdMemberName(idx)), "stack", fmt.Sprintf("%#v", err))
+		return false, nil
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		c.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("etcd member %#q responded with status code %d", key.EtcdMemberName(idx), res.StatusCode))
+		return false, nil
+	}
+
+	return isHealthy(res)
+}
+
+func (c *Checker) VerifyPeerDomains(ctx context.Context, customObject v1alpha1.AWSConfig, masterCount int) error {
+	tlsAssets, err := c.certsSearcher.SearchTLS(key.ClusterID(customObject), certs.EtcdCert)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	var domains []string
+	for i := 0; i < masterCount; i++ {
+		domains = append(domains, key.EtcdMemberDomain(customObject, i))
+	}
+
+	err = verifyDomains(tlsAssets.Crt, domains)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// isHealthy parses the response of the health endpoint of an etcd member. The
+// member reports itself healthy once it is part of an etcd cluster having a
+// leader, which is the case as soon as a replaced member rejoined the cluster.
+func isHealthy(res *http.Response) (bool, error) {
+	var health struct {
+		Health string `json:"health"`
+	}
+
+	err := json.NewDecoder(res.Body).Decode(&health)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	return health.Health == "true", nil
+}
+
+func newTLSConfig(tlsAssets certs.TLS) (*tls.Config, error) {
+	crt, err := tls.X509KeyPair(tlsAssets.Crt, tlsAssets.Key)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	rootCAs := x509.NewCertPool()
+	if !rootCAs.AppendCertsFromPEM(tlsAssets.CA) {
+		return nil, microerror.Maskf(invalidConfigError, "CA of etcd certificate must be PEM encoded")
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{crt},
+		RootCAs:      rootCAs,
+	}
+
+	return tlsConfig, nil
+}
+
+// verifyDomains ensures the given PEM encoded certificate is valid for all of
+// the given domains. This covers the alternative names of the certificate as
+// well as wildcards.
+func verifyDomains(crtPEM []byte, domains []string) error {
+	block, _ := pem.Decode(crtPEM)
+	if block == nil {
+		return microerror.Maskf(invalidConfigError, "etcd certificate must be PEM encoded")
+	}
+
+	crt, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, d := range domains {
+		err := crt.VerifyHostname(d)
+		if err != nil {
+			return microerror.Maskf(peerDomainNotCoveredError, "etcd certificate does not cover peer domain %#q", d)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package etcdmember
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_verifyDomains(t *testing.T) {
+	domains := []string{
+		"etcd0.al9qy.k8s.gauss.eu-central-1.aws.gigantic.io",
+		"etcd1.al9qy.k8s.gauss.eu-central-1.aws.gigantic.io",
+		"etcd2.al9qy.k8s.gauss.eu-central-1.aws.gigantic.io",
+	}
+
+	testCases := []struct {
+		name         string
+		dnsNames     []string
+		errorMatcher func(error) bool
+	}{
+		{
+			name:         "case 0: certificate covers all peer domains",
+			dnsNames:     append([]string{"etcd.al9qy.k8s.gauss.eu-central-1.aws.gigantic.io"}, domains...),
+			errorMatcher: nil,
+		},
+		{
+			name:         "case 1: wildcard covers all peer domains",
+			dnsNames:     []string{"*.al9qy.k8s.gauss.eu-central-1.aws.gigantic.io"},
+			errorMatcher: nil,
+		},
+		{
+			name:         "case 2: certificate only covers the etcd domain",
+			dnsNames:     []string{"etcd.al9qy.k8s.gauss.eu-central-1.aws.gigantic.io"},
+			errorMatcher: IsPeerDomainNotCovered,
+		},
+		{
+			name:         "case 3: certificate misses a single peer domain",
+			dnsNames:     domains[:2],
+			errorMatcher: IsPeerDomainNotCovered,
+		},
+		{
+			name:         "case 4: wildcard of another cluster",
+			dnsNames:     []string{"*.xyz12.k8s.gauss.eu-central-1.aws.gigantic.io"},
+			errorMatcher: IsPeerDomainNotCovered,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			err := verifyDomains(newCertificate(t, tc.dnsNames), domains)
+
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("%s: error == %#v, want nil", tc.name, err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("%s: error == nil, want non-nil", tc.name)
+			case !tc.errorMatcher(err):
+				t.Fatalf("%s: error == %#v, want matching", tc.name, err)
+			}
+		})
+	}
+}
+
+func newCertificate(t *testing.T, dnsNames []string) []byte {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject: pkix.Name{
+			CommonName: "etcd",
+		},
+		DNSNames:  dnsNames,
+		NotBefore: time.Now(),
+		NotAfter:  time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
@@ -0,0 +1,19 @@
+package etcdmember
+
+import (
+	"context"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+)
+
+// Interface describes the methods provided by the etcd member checker.
+type Interface interface {
+	// Healthy returns whether the etcd member running on the master with the
+	// given index is part of the etcd cluster of the given tenant cluster and
+	// reports itself healthy. Members which cannot be reached are not healthy.
+	Healthy(ctx context.Context, customObject v1alpha1.AWSConfig, idx int) (bool, error)
+	// VerifyPeerDomains ensures the etcd server certificate of the given tenant
+	// cluster covers the domains the etcd members of the given number of masters
+	// use to talk to their peers.
+	VerifyPeerDomains(ctx context.Context, customObject v1alpha1.AWSConfig, masterCount int) error
+}
@@ -3,6 +3,7 @@ package key
 import (
 	"crypto/sha1"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -24,6 +25,12 @@ const (
 	// CloudProviderTagName is used to add Cloud Provider tags to AWS resources.
 	CloudProviderTagName = "kubernetes.io/cluster/%s"
 
+	// ContainerLinuxChannel and ContainerLinuxVersion define the Container
+	// Linux release the AMI catalog resolves AMIs for. Keep them in sync with
+	// the containerlinux component of the version bundle.
+	ContainerLinuxChannel = "stable"
+	ContainerLinuxVersion = "1855.5.0"
+
 	// Cluster tag name for tagging all resources helping cost analysis in AWS.
 	ClusterTagName = "giantswarm.io/cluster"
 
@@ -50,28 +57,111 @@ const (
 	LogDeliveryURI = "uri=http://acs.amazonaws.com/groups/s3/LogDelivery"
 
 	InstanceIDAnnotation = "aws-operator.giantswarm.io/instance"
+	// AutoScalingGroupAnnotation is used to transport the name of the ASG an
+	// EC2 instance belongs to from the drainer to the drainfinisher resource.
+	AutoScalingGroupAnnotation = "aws-operator.giantswarm.io/auto-scaling-group"
+	// ChangeSetApprovalAnnotation is set on the AWSConfig CR with the name of a
+	// blocked change set as value to approve the execution of the change set.
+	ChangeSetApprovalAnnotation = "aws-operator.giantswarm.io/approved-change-set"
 
 	chinaAWSCliContainerRegistry   = "docker://registry-intl.cn-shanghai.aliyuncs.com/giantswarm/awscli:latest"
 	defaultAWSCliContainerRegistry = "quay.io/coreos/awscli:025a357f05242fdad6a81e8a6b520098aa65a600"
 	defaultDockerVolumeSizeGB      = 100
+
+	// MasterCountHA is the number of master nodes a tenant cluster runs with in
+	// its highly available control plane mode. Each master is put into its own
+	// availability zone and runs a member of the etcd cluster.
+	MasterCountHA = 3
+
+	// EtcdClusterMemberDropInPath is the path of the systemd drop-in configuring
+	// the etcd member of a master in a highly available control plane.
+	EtcdClusterMemberDropInPath = "/etc/systemd/system/etcd3.service.d/10-cluster-member.conf"
+
+	// EtcdRestoreEnvironmentPath is the path of the environment file telling the
+	// etcd restore of a master in a highly available control plane which member
+	// it restores.
+	EtcdRestoreEnvironmentPath = "/etc/etcd-restore.env"
+
+	// EncryptionKeyTagName is used to tag worker instances with the ID of the
+	// encryption key their assets are encrypted with after a re-key.
+	EncryptionKeyTagName = "giantswarm.io/encryption-key"
+
+	// AutoScalingServiceLinkedRoleName is the name of the service-linked role
+	// the EC2 Auto Scaling service launches instances with. It needs a grant on
+	// the KMS key the EBS volumes of the worker nodes are encrypted with.
+	AutoScalingServiceLinkedRoleName = "AWSServiceRoleForAutoScaling"
+
+	// EtcdBackupPrefix is the prefix of the S3 objects masters upload their
+	// encrypted etcd snapshots to within the S3 bucket of the cluster.
+	EtcdBackupPrefix = "etcd-backup/"
+)
+
+const (
+	APIWhitelistCIDRsKey            = "APIWhitelistCIDRs"
+	DockerVolumeResourceNameKey     = "DockerVolumeResourceName"
+	EncryptionKeyIDKey              = "EncryptionKeyID"
+	EtcdVolumeEncryptionKeyARNKey   = "EtcdVolumeEncryptionKeyARN"
+	HostedZoneNameServers           = "HostedZoneNameServers"
+	IngressWhitelistCIDRsKey        = "IngressWhitelistCIDRs"
+	MasterCountKey                  = "MasterCount"
+	MasterImageIDKey                = "MasterImageID"
+	MasterInstanceResourceNameKey   = "MasterInstanceResourceName"
+	MasterInstanceTypeKey           = "MasterInstanceType"
+	MasterInstanceMonitoring        = "Monitoring"
+	MasterCloudConfigVersionKey     = "MasterCloudConfigVersion"
+	NATGatewayAllocationIDsKey      = "NATGatewayAllocationIDs"
+	NATGatewayStrategyKey           = "NATGatewayStrategy"
+	WorkerASGKey                    = "WorkerASGName"
+	WorkerCountKey                  = "WorkerCount"
+	WorkerDockerVolumeSizeKey       = "WorkerDockerVolumeSizeGB"
+	WorkerImageIDKey                = "WorkerImageID"
+	WorkerInstanceMonitoring        = "Monitoring"
+	WorkerInstanceTypeKey           = "WorkerInstanceType"
+	WorkerInstanceTypesKey          = "WorkerInstanceTypes"
+	WorkerLaunchTemplateIDKey       = "WorkerLaunchTemplateID"
+	WorkerMaxSizeKey                = "WorkerMaxSize"
+	WorkerMinSizeKey                = "WorkerMinSize"
+	WorkerOnDemandBaseCapacityKey   = "WorkerOnDemandBaseCapacity"
+	WorkerSpotAllocationStrategyKey = "WorkerSpotAllocationStrategy"
+	WorkerSpotPercentageKey         = "WorkerSpotPercentage"
+	WorkerCloudConfigVersionKey     = "WorkerCloudConfigVersion"
+	TransitGatewayIDKey             = "TransitGatewayID"
+	VersionBundleVersionKey         = "VersionBundleVersion"
+	VolumeEncryptionKeyARNKey       = "VolumeEncryptionKeyARN"
+	VPCIDKey                        = "VPCID"
+)
+
+const (
+	// NodePoolOutputPrefix prefixes the stack output keys of node pools. An
+	// output key consists of the prefix, the capitalized node pool name and one
+	// of the node pool keys below, e.g. NodePoolGpuASGName.
+	NodePoolOutputPrefix = "NodePool"
+
+	NodePoolASGKey              = "ASGName"
+	NodePoolCloudConfigKey      = "CloudConfig"
+	NodePoolDockerVolumeSizeKey = "DockerVolumeSizeGB"
+	NodePoolInstanceTypeKey     = "InstanceType"
+	NodePoolMaxSizeKey          = "MaxSize"
+	NodePoolMinSizeKey          = "MinSize"
 )
 
 const (
-	DockerVolumeResourceNameKey   = "DockerVolumeResourceName"
-	HostedZoneNameServers         = "HostedZoneNameServers"
-	MasterImageIDKey              = "MasterImageID"
-	MasterInstanceResourceNameKey = "MasterInstanceResourceName"
-	MasterInstanceTypeKey         = "MasterInstanceType"
-	MasterInstanceMonitoring      = "Monitoring"
-	MasterCloudConfigVersionKey   = "MasterCloudConfigVersion"
-	WorkerASGKey                  = "WorkerASGName"
-	WorkerCountKey                = "WorkerCount"
-	WorkerDockerVolumeSizeKey     = "WorkerDockerVolumeSizeGB"
-	WorkerImageIDKey              = "WorkerImageID"
-	WorkerInstanceMonitoring      = "Monitoring"
-	WorkerInstanceTypeKey         = "WorkerInstanceType"
-	WorkerCloudConfigVersionKey   = "WorkerCloudConfigVersion"
-	VersionBundleVersionKey       = "VersionBundleVersion"
+	// MasterOutputPrefix prefixes the stack output keys of the masters of a
+	// highly available control plane. An output key consists of the prefix and
+	// the two digit index of the master, e.g. Master01. The output value holds
+	// the master keys below as comma separated key=value pairs. Using a single
+	// output per master keeps the main stack within the output limit of
+	// CloudFormation.
+	MasterOutputPrefix = "Master"
+
+	MasterCloudConfigVersionOutputKey       = "CloudConfigVersion"
+	MasterDockerVolumeResourceNameOutputKey = "DockerVolumeResourceName"
+	MasterEncryptionKeyIDOutputKey          = "EncryptionKeyID"
+	MasterImageIDOutputKey                  = "ImageID"
+	MasterInstanceResourceNameOutputKey     = "InstanceResourceName"
+	MasterInstanceTypeOutputKey             = "InstanceType"
+	MasterVersionBundleVersionOutputKey     = "VersionBundleVersion"
+	MasterVolumeEncryptionKeyARNOutputKey   = "VolumeEncryptionKeyARN"
 )
 
 const (
@@ -89,9 +179,75 @@ const (
 	LegacyLabelCluster = "cluster"
 )
 
+const (
+	// VPCEndpointService* are the services the VPC endpoints of guest cluster
+	// VPCs connect to. S3 is reached through a gateway endpoint, all other
+	// services through interface endpoints.
+	VPCEndpointServiceEC2       = "ec2"
+	VPCEndpointServiceECRAPI    = "ecr.api"
+	VPCEndpointServiceECRDocker = "ecr.dkr"
+	VPCEndpointServiceELB       = "elasticloadbalancing"
+	VPCEndpointServiceKMS       = "kms"
+	VPCEndpointServiceS3        = "s3"
+	VPCEndpointServiceSTS       = "sts"
+)
+
+const (
+	// VPCFlowLogsDestination* are the destinations the flow logs of guest
+	// cluster VPCs are delivered to. S3 delivers them to the logging bucket of
+	// the guest cluster, CloudWatch to a log group of the guest cluster.
+	VPCFlowLogsDestinationCloudWatch = "cloudwatch"
+	VPCFlowLogsDestinationS3         = "s3"
+)
+
 const (
 	NodeDrainerLifecycleHookName = "NodeDrainer"
 	WorkerASGRef                 = "workerAutoScalingGroup"
+	WorkerLaunchTemplateRef      = "workerLaunchTemplate"
+)
+
+const (
+	// SpotAllocationStrategyLowestPrice launches spot instances from the
+	// acceptable instance types with the lowest price.
+	SpotAllocationStrategyLowestPrice = "lowest-price"
+	// SpotAllocationStrategyCapacityOptimized launches spot instances from the
+	// acceptable instance types with the most spare capacity.
+	SpotAllocationStrategyCapacityOptimized = "capacity-optimized"
+
+	// MaxWorkerInstanceTypes is the maximum number of instance types AWS accepts
+	// in the mixed instances policy of an ASG.
+	MaxWorkerInstanceTypes = 20
+
+	// MaxSecurityGroupRules is the default maximum number of inbound rules AWS
+	// accepts per security group.
+	MaxSecurityGroupRules = 60
+)
+
+const (
+	// APIEndpointModePublic makes the Kubernetes API reachable from the
+	// internet.
+	APIEndpointModePublic = "public"
+	// APIEndpointModePrivate makes the Kubernetes API only reachable from the
+	// tenant cluster VPC and the host cluster VPC.
+	APIEndpointModePrivate = "private"
+)
+
+const (
+	// NATGatewayStrategyPerAZ puts a NAT gateway into each availability zone
+	// of the tenant cluster.
+	NATGatewayStrategyPerAZ = "perAZ"
+	// NATGatewayStrategySingle puts a single NAT gateway into the first
+	// availability zone of the tenant cluster, which is shared by all
+	// availability zones.
+	NATGatewayStrategySingle = "single"
+)
+
+const (
+	// LoadBalancerTypeClassic is the type of a classic Elastic Load Balancer.
+	LoadBalancerTypeClassic = "elb"
+	// LoadBalancerTypeNetwork is the type of a Network Load Balancer managed by
+	// the elbv2 API.
+	LoadBalancerTypeNetwork = "nlb"
 )
 
 const (
@@ -101,6 +257,42 @@ const (
 	KindEtcd    = "etcd-elb"
 )
 
+// ChangeSetApproved returns true in case the change set with the given name
+// is approved by the approval annotation of the AWSConfig CR.
+func ChangeSetApproved(customObject v1alpha1.AWSConfig, name string) bool {
+	return name != "" && customObject.GetAnnotations()[ChangeSetApprovalAnnotation] == name
+}
+
+// ClusterAutoscalerEnabled returns true when the worker ASGs are scaled by the
+// cluster autoscaler within the scaling bounds of the cluster. This is the
+// case as soon as a maximum number of worker nodes is configured.
+func ClusterAutoscalerEnabled(customObject v1alpha1.AWSConfig) bool {
+	return customObject.Spec.Cluster.Scaling.Max > 0
+}
+
+// APIEndpointMode returns the mode of the Kubernetes API endpoint. It defaults
+// to a public Kubernetes API.
+func APIEndpointMode(customObject v1alpha1.AWSConfig) string {
+	mode := customObject.Spec.AWS.APIEndpoint.Mode
+	if mode == "" {
+		return APIEndpointModePublic
+	}
+
+	return mode
+}
+
+// APIWhitelistCIDRs returns the CIDRs allowed to access the Kubernetes API of
+// the tenant cluster in addition to the whitelist of the installation.
+func APIWhitelistCIDRs(customObject v1alpha1.AWSConfig) []string {
+	return customObject.Spec.AWS.APIWhitelist.APICIDRs
+}
+
+// APILoadBalancerType returns the type of the load balancer in front of the
+// Kubernetes API. It defaults to a classic Elastic Load Balancer.
+func APILoadBalancerType(customObject v1alpha1.AWSConfig) string {
+	return loadBalancerType(customObject.Spec.AWS.LoadBalancers.API.Type)
+}
+
 func ClusterAPIEndpoint(customObject v1alpha1.AWSConfig) string {
 	return customObject.Spec.Cluster.Kubernetes.API.Domain
 }
@@ -109,6 +301,10 @@ func AutoScalingGroupName(customObject v1alpha1.AWSConfig, groupName string) str
 	return fmt.Sprintf("%s-%s", ClusterID(customObject), groupName)
 }
 
+func AutoScalingServiceLinkedRoleARN(customObject v1alpha1.AWSConfig, accountID string) string {
+	return fmt.Sprintf("arn:%s:iam::%s:role/aws-service-role/autoscaling.amazonaws.com/%s", RegionARN(customObject), accountID, AutoScalingServiceLinkedRoleName)
+}
+
 func AvailabilityZone(customObject v1alpha1.AWSConfig) string {
 	return customObject.Spec.AWS.AZ
 }
@@ -141,6 +337,18 @@ func CredentialNamespace(customObject v1alpha1.AWSConfig) string {
 	return customObject.Spec.AWS.CredentialSecret.Namespace
 }
 
+func CloudConfigEtcdClusterMemberTemplates() []string {
+	return []string{
+		cloudconfig.EtcdClusterMemberDropIn,
+	}
+}
+
+func CloudConfigEtcdRestoreEnvironmentTemplates() []string {
+	return []string{
+		cloudconfig.EtcdRestoreEnvironment,
+	}
+}
+
 func CloudConfigSmallTemplates() []string {
 	return []string{
 		cloudconfig.Small,
@@ -153,7 +361,7 @@ func CloudFormationGuestTemplates() []string {
 		guest.IAMPolicies,
 		guest.Instance,
 		guest.InternetGateway,
-		guest.LaunchConfiguration,
+		guest.LaunchTemplate,
 		guest.LoadBalancers,
 		guest.Main,
 		guest.NatGateway,
@@ -164,6 +372,8 @@ func CloudFormationGuestTemplates() []string {
 		guest.SecurityGroups,
 		guest.Subnets,
 		guest.VPC,
+		guest.VPCEndpoints,
+		guest.VPCFlowLogs,
 	}
 }
 
@@ -237,10 +447,23 @@ func DockerVolumeName(customObject v1alpha1.AWSConfig) string {
 	return fmt.Sprintf("%s-docker", ClusterID(customObject))
 }
 
+// EncryptionKeyID returns the ID of the encryption key created by the latest
+// re-key of the guest cluster. It is empty in case the guest cluster was never
+// re-keyed.
+func EncryptionKeyID(customObject v1alpha1.AWSConfig) string {
+	return customObject.Status.AWS.EncryptionKey.ID
+}
+
 func EtcdVolumeName(customObject v1alpha1.AWSConfig) string {
 	return fmt.Sprintf("%s-etcd", ClusterID(customObject))
 }
 
+// ECRLayerBucketName returns the name of the S3 bucket Amazon ECR serves the
+// image layers of the region from.
+func ECRLayerBucketName(customObject v1alpha1.AWSConfig) string {
+	return fmt.Sprintf("prod-%s-starport-layer-bucket", Region(customObject))
+}
+
 func EC2ServiceDomain(customObject v1alpha1.AWSConfig) string {
 	domain := "ec2.amazonaws.com"
 
@@ -279,6 +502,23 @@ func IngressControllerSecurePort(customObject v1alpha1.AWSConfig) int {
 	return customObject.Spec.Cluster.Kubernetes.IngressController.SecurePort
 }
 
+// IngressWhitelistCIDRs returns the CIDRs allowed to access the ingress load
+// balancer of the tenant cluster. It is empty in case the ingress load balancer
+// is reachable from anywhere.
+func IngressWhitelistCIDRs(customObject v1alpha1.AWSConfig) []string {
+	return customObject.Spec.AWS.APIWhitelist.IngressCIDRs
+}
+
+// IngressLoadBalancerType returns the type of the load balancer in front of
+// the ingress controller. It defaults to a classic Elastic Load Balancer.
+func IngressLoadBalancerType(customObject v1alpha1.AWSConfig) string {
+	return loadBalancerType(customObject.Spec.AWS.LoadBalancers.Ingress.Type)
+}
+
+func IPv6Enabled(customObject v1alpha1.AWSConfig) bool {
+	return customObject.Spec.AWS.VPC.IPv6Enabled
+}
+
 func InstanceProfileName(customObject v1alpha1.AWSConfig, profileType string) string {
 	return fmt.Sprintf("%s-%s-%s", ClusterID(customObject), profileType, ProfileNameTemplate)
 }
@@ -303,9 +543,63 @@ func EtcdPort(customObject v1alpha1.AWSConfig) int {
 	return 2379
 }
 
-// LoadBalancerName produces a unique name for the load balancer.
-// It takes the domain name, extracts the first subdomain, and combines it with the cluster name.
-func LoadBalancerName(domainName string, cluster v1alpha1.AWSConfig) (string, error) {
+func EtcdPeerPort(customObject v1alpha1.AWSConfig) int {
+	return 2380
+}
+
+// EtcdInitialCluster returns the value of etcd's --initial-cluster flag for an
+// etcd cluster formed by the given number of masters.
+func EtcdInitialCluster(customObject v1alpha1.AWSConfig, masterCount int) string {
+	var members []string
+	for i := 0; i < masterCount; i++ {
+		members = append(members, fmt.Sprintf("%s=https://%s:%d", EtcdMemberName(i), EtcdMemberDomain(customObject, i), EtcdPeerPort(customObject)))
+	}
+
+	return strings.Join(members, ",")
+}
+
+// EtcdMemberDomain returns the domain the etcd member of the master with the
+// given index uses to talk to its peers. Note that the domain has to be covered
+// by the alternative names of the etcd server certificate.
+func EtcdMemberDomain(customObject v1alpha1.AWSConfig, idx int) string {
+	return strings.Join([]string{EtcdMemberName(idx), ClusterID(customObject), "k8s", BaseDomain(customObject)}, ".")
+}
+
+// EtcdMemberName returns the name of the etcd member running on the master with
+// the given index. The first member is named etcd0, which is the name single
+// master clusters always used.
+func EtcdMemberName(idx int) string {
+	return fmt.Sprintf("etcd%d", idx)
+}
+
+func EtcdMemberRecordSetName(idx int) string {
+	return fmt.Sprintf("Etcd%dRecordSet", idx)
+}
+
+// EtcdLoadBalancerType returns the type of the load balancer in front of etcd.
+// It defaults to a classic Elastic Load Balancer.
+func EtcdLoadBalancerType(customObject v1alpha1.AWSConfig) string {
+	return loadBalancerType(customObject.Spec.AWS.LoadBalancers.Etcd.Type)
+}
+
+func EgressOnlyRouteName(idx int) string {
+	return fmt.Sprintf("EgressOnlyRoute%02d", idx)
+}
+
+func EtcdVolumeResourceName(idx int) string {
+	// Since CloudFormation cannot recognize resource renaming, use non-indexed
+	// resource name for first master.
+	if idx < 1 {
+		return "EtcdVolume"
+	}
+	return fmt.Sprintf("EtcdVolume%02d", idx)
+}
+
+// LoadBalancerName returns the name of the load balancer of the given type in
+// front of the component of the given domain name. Network Load Balancers get
+// their own name so they can be created next to the classic Elastic Load
+// Balancers they replace.
+func LoadBalancerName(domainName string, loadBalancerType string, cluster v1alpha1.AWSConfig) (string, error) {
 	if ClusterID(cluster) == "" {
 		return "", microerror.Maskf(missingCloudConfigKeyError, "spec.cluster.id")
 	}
@@ -315,7 +609,15 @@ func LoadBalancerName(domainName string, cluster v1alpha1.AWSConfig) (string, er
 		return "", microerror.Maskf(malformedCloudConfigKeyError, "spec.cluster.id")
 	}
 
-	lbName := fmt.Sprintf("%s-%s", ClusterID(cluster), componentName)
+	var lbName string
+	switch loadBalancerType {
+	case LoadBalancerTypeClassic:
+		lbName = fmt.Sprintf("%s-%s", ClusterID(cluster), componentName)
+	case LoadBalancerTypeNetwork:
+		lbName = fmt.Sprintf("%s-%s-%s", ClusterID(cluster), componentName, LoadBalancerTypeNetwork)
+	default:
+		return "", microerror.Maskf(malformedCloudConfigKeyError, "unknown load balancer type %#q", loadBalancerType)
+	}
 
 	return lbName, nil
 }
@@ -338,6 +640,18 @@ func MainHostPostStackName(customObject v1alpha1.AWSConfig) string {
 	return fmt.Sprintf("cluster-%s-host-main", clusterID)
 }
 
+func MaintenanceWindowDuration(customObject v1alpha1.AWSConfig) string {
+	return customObject.Spec.AWS.MaintenanceWindow.Duration
+}
+
+func MaintenanceWindowSchedule(customObject v1alpha1.AWSConfig) string {
+	return customObject.Spec.AWS.MaintenanceWindow.Schedule
+}
+
+func MaintenanceWindowTimeZone(customObject v1alpha1.AWSConfig) string {
+	return customObject.Spec.AWS.MaintenanceWindow.TimeZone
+}
+
 func MasterCount(customObject v1alpha1.AWSConfig) int {
 	return len(customObject.Spec.AWS.Masters)
 }
@@ -356,6 +670,64 @@ func MasterInstanceResourceName(customObject v1alpha1.AWSConfig) string {
 	return getResourcenameWithTimeHash("MasterInstance", customObject)
 }
 
+// MasterResourceName returns the CloudFormation resource name of the master
+// with the given index, derived from the resource name of the first master.
+// This is used for the master instances and their docker volumes.
+func MasterResourceName(name string, idx int) string {
+	// Since CloudFormation cannot recognize resource renaming, use non-indexed
+	// resource name for first master.
+	if idx < 1 {
+		return name
+	}
+	return fmt.Sprintf("%s%02d", name, idx)
+}
+
+// MasterOutputKey returns the stack output key of the master with the given
+// index, e.g. Master01.
+func MasterOutputKey(idx int) string {
+	return fmt.Sprintf("%s%02d", MasterOutputPrefix, idx)
+}
+
+// MasterOutputValue joins the given values of a master into the value of its
+// stack output, e.g. ImageID=ami-123,InstanceType=m4.xlarge. Empty values are
+// omitted.
+func MasterOutputValue(values map[string]string) string {
+	var pairs []string
+	for k, v := range values {
+		if v == "" {
+			continue
+		}
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+// MasterOutputValues splits the given stack output value of a master into its
+// values. It is the inverse of MasterOutputValue.
+func MasterOutputValues(outputValue string) map[string]string {
+	values := map[string]string{}
+	for _, p := range strings.Split(outputValue, ",") {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		values[kv[0]] = kv[1]
+	}
+
+	return values
+}
+
+// MasterSmallCloudConfigS3URL returns the S3 URL of the cloud config of a
+// master launched with the given version bundle version and cloud config
+// version. Masters of a highly available control plane are replaced one after
+// another, which is why masters not replaced yet keep the cloud config they
+// were launched with.
+func MasterSmallCloudConfigS3URL(customObject v1alpha1.AWSConfig, accountID, versionBundleVersion, cloudConfigVersion string) string {
+	return fmt.Sprintf("s3://%s/version/%s/cloudconfig/%s/%s", BucketName(customObject, accountID), versionBundleVersion, cloudConfigVersion, KindMaster)
+}
+
 func MasterInstanceName(customObject v1alpha1.AWSConfig) string {
 	clusterID := ClusterID(customObject)
 
@@ -376,6 +748,34 @@ func MasterRoleARN(customObject v1alpha1.AWSConfig, accountID string) string {
 	return baseRoleARN(customObject, accountID, "master")
 }
 
+// NATGatewayAllocationIDs returns the allocation IDs of the pre-allocated
+// elastic IPs of the NAT gateways of the tenant cluster. It is empty in case
+// the NAT gateways get new elastic IPs.
+func NATGatewayAllocationIDs(customObject v1alpha1.AWSConfig) []string {
+	return customObject.Spec.AWS.NATGateway.AllocationIDs
+}
+
+// NATGatewayCount returns the number of NAT gateways of the tenant cluster
+// according to its NAT gateway strategy.
+func NATGatewayCount(customObject v1alpha1.AWSConfig) int {
+	if NATGatewayStrategy(customObject) == NATGatewayStrategySingle {
+		return 1
+	}
+
+	return len(StatusAvailabilityZones(customObject))
+}
+
+// NATGatewayStrategy returns the NAT gateway strategy of the tenant cluster.
+// It defaults to a NAT gateway in each availability zone.
+func NATGatewayStrategy(customObject v1alpha1.AWSConfig) string {
+	strategy := customObject.Spec.AWS.NATGateway.Strategy
+	if strategy == "" {
+		return NATGatewayStrategyPerAZ
+	}
+
+	return strategy
+}
+
 func NATEIPName(idx int) string {
 	// Since CloudFormation cannot recognize resource renaming, use non-indexed
 	// resource name for first AZ.
@@ -403,6 +803,98 @@ func NATRouteName(idx int) string {
 	return fmt.Sprintf("NATRoute%02d", idx)
 }
 
+// NodePoolASGRef returns the CloudFormation resource name of the ASG of the
+// node pool with the given name.
+func NodePoolASGRef(name string) string {
+	return NodePoolASGType(name) + "AutoScalingGroup"
+}
+
+// NodePoolASGType returns the prefix of the CloudFormation resource names of
+// the node pool with the given name, e.g. nodePoolGpu for the node pool gpu.
+func NodePoolASGType(name string) string {
+	return "nodePool" + capitalize(name)
+}
+
+// NodePoolCloudConfigRole returns the role the cloud config of the given node
+// pool is stored under in the S3 bucket. It contains a hash of the labels and
+// taints of the node pool, so changing them results in a new cloud config and
+// a rolling update of the worker nodes of the node pool.
+func NodePoolCloudConfigRole(nodePool v1alpha1.AWSConfigSpecAWSNodePool) string {
+	h := sha1.New()
+	h.Write([]byte(NodePoolLabels(nodePool)))
+	h.Write([]byte(NodePoolTaints(nodePool)))
+
+	return fmt.Sprintf("%s-%s-%x", KindWorker, nodePool.Name, h.Sum(nil)[0:4])
+}
+
+func NodePoolDockerVolumeSizeGB(nodePool v1alpha1.AWSConfigSpecAWSNodePool) int {
+	if nodePool.DockerVolumeSizeGB <= 0 {
+		return defaultDockerVolumeSizeGB
+	}
+
+	return nodePool.DockerVolumeSizeGB
+}
+
+// NodePoolLabels returns the labels of the given node pool sorted by key in the
+// format of the kubelet --node-labels flag, e.g. gpu=true,team=ml.
+func NodePoolLabels(nodePool v1alpha1.AWSConfigSpecAWSNodePool) string {
+	var labels []string
+	for k, v := range nodePool.Labels {
+		labels = append(labels, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(labels)
+
+	return strings.Join(labels, ",")
+}
+
+// NodePoolNameFromASGKey returns the name of the node pool the given stack
+// output key refers to in case it is the ASG name output of a node pool. An
+// empty string is returned for any other output key.
+func NodePoolNameFromASGKey(outputKey string) string {
+	if !strings.HasPrefix(outputKey, NodePoolOutputPrefix) || !strings.HasSuffix(outputKey, NodePoolASGKey) {
+		return ""
+	}
+
+	name := strings.TrimSuffix(strings.TrimPrefix(outputKey, NodePoolOutputPrefix), NodePoolASGKey)
+
+	return strings.ToLower(name)
+}
+
+// NodePoolOutputKey returns the stack output key of the given key of the node
+// pool with the given name, e.g. NodePoolGpuASGName.
+func NodePoolOutputKey(name, outputKey string) string {
+	return NodePoolOutputPrefix + capitalize(name) + outputKey
+}
+
+func NodePools(customObject v1alpha1.AWSConfig) []v1alpha1.AWSConfigSpecAWSNodePool {
+	return customObject.Spec.AWS.NodePools
+}
+
+// NodePoolTaints returns the taints of the given node pool in the format of
+// the kubelet --register-with-taints flag, e.g. gpu=true:NoSchedule.
+func NodePoolTaints(nodePool v1alpha1.AWSConfigSpecAWSNodePool) string {
+	var taints []string
+	for _, t := range nodePool.Taints {
+		taints = append(taints, fmt.Sprintf("%s=%s:%s", t.Key, t.Value, t.Effect))
+	}
+
+	return strings.Join(taints, ",")
+}
+
+// PrivateAPIEndpoint returns true in case the Kubernetes API is only
+// reachable from the tenant cluster VPC and the host cluster VPC.
+func PrivateAPIEndpoint(customObject v1alpha1.AWSConfig) bool {
+	return APIEndpointMode(customObject) == APIEndpointModePrivate
+}
+
+// PrivateAPIHostedZoneName returns the name of the private hosted zone holding
+// the record set of a private Kubernetes API. The zone only covers the API
+// domain so all other record sets of the tenant cluster are still resolved
+// using its public hosted zone.
+func PrivateAPIHostedZoneName(customObject v1alpha1.AWSConfig) string {
+	return fmt.Sprintf("api.%s.k8s.%s", ClusterID(customObject), BaseDomain(customObject))
+}
+
 func PeerAccessRoleName(customObject v1alpha1.AWSConfig) string {
 	return fmt.Sprintf("%s-vpc-peer-access", ClusterID(customObject))
 }
@@ -518,6 +1010,14 @@ func S3ServiceDomain(customObject v1alpha1.AWSConfig) string {
 	return s3Domain
 }
 
+func ScalingMax(customObject v1alpha1.AWSConfig) int {
+	return customObject.Spec.Cluster.Scaling.Max
+}
+
+func ScalingMin(customObject v1alpha1.AWSConfig) int {
+	return customObject.Spec.Cluster.Scaling.Min
+}
+
 func SecurityGroupName(customObject v1alpha1.AWSConfig, groupName string) string {
 	return fmt.Sprintf("%s-%s", ClusterID(customObject), groupName)
 }
@@ -538,6 +1038,15 @@ func SpecAvailabilityZones(customObject v1alpha1.AWSConfig) int {
 	return customObject.Spec.AWS.AvailabilityZones
 }
 
+// SSMParameterPrefix returns the path of the SSM parameters storing the
+// encrypted assets of the guest cluster, e.g.
+//
+//     /giantswarm/al9qy
+//
+func SSMParameterPrefix(customObject v1alpha1.AWSConfig) string {
+	return fmt.Sprintf("/giantswarm/%s", ClusterID(customObject))
+}
+
 func StatusAvailabilityZones(customObject v1alpha1.AWSConfig) []v1alpha1.AWSConfigStatusAWSAvailabilityZone {
 	return customObject.Status.AWS.AvailabilityZones
 }
@@ -613,11 +1122,84 @@ func ToVersionBundleVersion(v interface{}) (string, error) {
 	return VersionBundleVersion(customObject), nil
 }
 
+// TransitGatewayRouteName returns the resource name of the route sending the
+// host cluster traffic of the route table with the given index through the
+// Transit Gateway.
+func TransitGatewayRouteName(idx int) string {
+	return fmt.Sprintf("TransitGatewayRoute%02d", idx)
+}
+
 // VersionBundleVersion returns the version contained in the Version Bundle.
 func VersionBundleVersion(customObject v1alpha1.AWSConfig) string {
 	return customObject.Spec.VersionBundle.Version
 }
 
+// VolumeEncryptionKeyAlias returns the alias of the KMS key the EBS volumes of
+// the guest cluster are encrypted with. The key is separate from the key
+// encrypting the guest cluster assets, because the latter is replaced by a
+// re-key while volumes cannot change their key.
+func VolumeEncryptionKeyAlias(customObject v1alpha1.AWSConfig) string {
+	return fmt.Sprintf("alias/%s-ebs", ClusterID(customObject))
+}
+
+// VPCID returns the ID of the existing VPC the guest cluster is deployed into.
+// It is empty in case the VPC of the guest cluster is created by the operator.
+func VPCID(customObject v1alpha1.AWSConfig) string {
+	return customObject.Spec.AWS.VPC.ID
+}
+
+// VPCSubnets returns the existing subnets of the VPC the guest cluster is
+// deployed into, ordered by their availability zone like the availability
+// zones in the CR status.
+func VPCSubnets(customObject v1alpha1.AWSConfig) []v1alpha1.AWSConfigSpecAWSVPCSubnet {
+	subnets := make([]v1alpha1.AWSConfigSpecAWSVPCSubnet, len(customObject.Spec.AWS.VPC.Subnets))
+	copy(subnets, customObject.Spec.AWS.VPC.Subnets)
+	sort.Slice(subnets, func(i, j int) bool {
+		return subnets[i].AvailabilityZone < subnets[j].AvailabilityZone
+	})
+
+	return subnets
+}
+
+// VPCEndpointServiceName returns the name of the service a VPC endpoint of the
+// guest cluster VPC connects to, e.g.
+//
+//     com.amazonaws.eu-central-1.ec2
+//
+func VPCEndpointServiceName(customObject v1alpha1.AWSConfig, service string) string {
+	name := fmt.Sprintf("com.amazonaws.%s.%s", Region(customObject), service)
+
+	// Interface endpoints in China regions connect to services prefixed with
+	// cn. The S3 gateway endpoint is the only exception.
+	if IsChinaRegion(customObject) && service != VPCEndpointServiceS3 {
+		name = "cn." + name
+	}
+
+	return name
+}
+
+// VPCFlowLogsLogGroupName returns the name of the CloudWatch log group the
+// flow logs of the guest cluster VPC are delivered to, e.g.
+//
+//     al9qy-vpc-flow-logs
+//
+func VPCFlowLogsLogGroupName(customObject v1alpha1.AWSConfig) string {
+	return fmt.Sprintf("%s-vpc-flow-logs", ClusterID(customObject))
+}
+
+// VPCFlowLogsPrefix returns the prefix of the flow logs of the guest cluster
+// VPC within the logging bucket, e.g.
+//
+//     vpc-flow-logs/al9qy
+//
+func VPCFlowLogsPrefix(customObject v1alpha1.AWSConfig) string {
+	return fmt.Sprintf("vpc-flow-logs/%s", ClusterID(customObject))
+}
+
+func VPCFlowLogsRoleName(customObject v1alpha1.AWSConfig) string {
+	return fmt.Sprintf("%s-vpc-flow-logs", ClusterID(customObject))
+}
+
 func VPCPeeringRouteName(idx int) string {
 	// Since CloudFormation cannot recognize resource renaming, use non-indexed
 	// resource name for first AZ.
@@ -667,6 +1249,47 @@ func WorkerInstanceType(customObject v1alpha1.AWSConfig) string {
 	return instanceType
 }
 
+// WorkerInstanceTypes returns the instance types acceptable for worker nodes
+// launched using a mixed instances policy. The worker instance type is used in
+// case no instance types are configured.
+func WorkerInstanceTypes(customObject v1alpha1.AWSConfig) []string {
+	instanceTypes := customObject.Spec.AWS.WorkerInstanceDistribution.InstanceTypes
+	if len(instanceTypes) > 0 {
+		return instanceTypes
+	}
+
+	return []string{WorkerInstanceType(customObject)}
+}
+
+// WorkerMixedInstances returns true when the worker nodes have to be launched
+// using a mixed instances policy. This is the case as soon as acceptable
+// instance types, an on-demand base capacity or a spot percentage are
+// configured.
+func WorkerMixedInstances(customObject v1alpha1.AWSConfig) bool {
+	d := customObject.Spec.AWS.WorkerInstanceDistribution
+
+	return len(d.InstanceTypes) > 0 || d.OnDemandBaseCapacity > 0 || d.SpotPercentage > 0
+}
+
+func WorkerOnDemandBaseCapacity(customObject v1alpha1.AWSConfig) int {
+	return customObject.Spec.AWS.WorkerInstanceDistribution.OnDemandBaseCapacity
+}
+
+// WorkerSpotAllocationStrategy returns the strategy used to allocate spot
+// instances for worker nodes. It defaults to the lowest price strategy.
+func WorkerSpotAllocationStrategy(customObject v1alpha1.AWSConfig) string {
+	strategy := customObject.Spec.AWS.WorkerInstanceDistribution.SpotAllocationStrategy
+	if strategy == "" {
+		return SpotAllocationStrategyLowestPrice
+	}
+
+	return strategy
+}
+
+func WorkerSpotPercentage(customObject v1alpha1.AWSConfig) int {
+	return customObject.Spec.AWS.WorkerInstanceDistribution.SpotPercentage
+}
+
 func WorkerRoleARN(customObject v1alpha1.AWSConfig, accountID string) string {
 	return baseRoleARN(customObject, accountID, "worker")
 }
@@ -690,50 +1313,21 @@ func componentName(domainName string) (string, error) {
 	return splits[0], nil
 }
 
-// ImageID returns the EC2 AMI for the configured region.
-func ImageID(customObject v1alpha1.AWSConfig) (string, error) {
-	region := Region(customObject)
-
-	/*
-		Container Linux AMIs for each active AWS region.
-
-		NOTE 1: AMIs should always be for HVM virtualisation and not PV.
-		NOTE 2: You also need to update the tests.
-
-		service/controller/v21/key/key_test.go
-		service/controller/v21/adapter/adapter_test.go
-		service/controller/v21/resource/cloudformation/main_stack_test.go
-
-		Current Release: CoreOS Container Linux stable 1855.5.0 (HVM)
-		AMI IDs copied from https://stable.release.core-os.net/amd64-usr/1855.5.0/coreos_production_ami_hvm.txt.
-	*/
-	imageIDs := map[string]string{
-		"ap-northeast-1": "ami-0b0fc6983c9be8f9e",
-		"ap-northeast-2": "ami-09143ca0b3755b428",
-		"ap-south-1":     "ami-03eba32062e159d3c",
-		"ap-southeast-1": "ami-0d0079786d2ee66ae",
-		"ap-southeast-2": "ami-029d8ef1a02553d2d",
-		"ca-central-1":   "ami-09985fec721ff6f89",
-		"cn-north-1":     "ami-0211d60ca1aaa3c7d",
-		"cn-northwest-1": "ami-0deaa8ada18aec612",
-		"eu-central-1":   "ami-0e6601a88a9753474",
-		"eu-west-1":      "ami-06c40d1010f762df9",
-		"eu-west-2":      "ami-0f55bf46b69b768bd",
-		"eu-west-3":      "ami-0a2c50627b8b434df",
-		"sa-east-1":      "ami-0c188431919e8b2f1",
-		"us-east-1":      "ami-0f74e41ea6c13f74b",
-		"us-east-2":      "ami-0ba531e8a11f8965d",
-		"us-gov-west-1":  "ami-9d58c2fc",
-		"us-west-1":      "ami-08b3af8ec59b84ef9",
-		"us-west-2":      "ami-05507591f0fcb2b75",
-	}
-
-	imageID, ok := imageIDs[region]
-	if !ok {
-		return "", microerror.Maskf(invalidConfigError, "no image id for region '%s'", region)
+func loadBalancerType(t string) string {
+	if t == "" {
+		return LoadBalancerTypeClassic
+	}
+
+	return t
+}
+
+// capitalize returns the given string with its first character in upper case.
+func capitalize(s string) string {
+	if s == "" {
+		return s
 	}
 
-	return imageID, nil
+	return strings.ToUpper(s[:1]) + s[1:]
 }
 
 // getResourcenameWithTimeHash returns the string compared from specific prefix,
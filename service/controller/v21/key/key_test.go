@@ -10,6 +10,7 @@ import (
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
 	"github.com/giantswarm/microerror"
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func Test_AutoScalingGroupName(t *testing.T) {
@@ -90,6 +91,61 @@ func Test_BucketName(t *testing.T) {
 	}
 }
 
+func Test_ChangeSetApproved(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description      string
+		annotations      map[string]string
+		name             string
+		expectedApproved bool
+	}{
+		{
+			description:      "case 0: no annotation",
+			name:             "cluster-al9qy-guest-main-0123456789ab",
+			expectedApproved: false,
+		},
+		{
+			description: "case 1: annotation approves change set",
+			annotations: map[string]string{
+				ChangeSetApprovalAnnotation: "cluster-al9qy-guest-main-0123456789ab",
+			},
+			name:             "cluster-al9qy-guest-main-0123456789ab",
+			expectedApproved: true,
+		},
+		{
+			description: "case 2: annotation approves different change set",
+			annotations: map[string]string{
+				ChangeSetApprovalAnnotation: "cluster-al9qy-guest-main-ba9876543210",
+			},
+			name:             "cluster-al9qy-guest-main-0123456789ab",
+			expectedApproved: false,
+		},
+		{
+			description: "case 3: empty change set name",
+			annotations: map[string]string{
+				ChangeSetApprovalAnnotation: "",
+			},
+			name:             "",
+			expectedApproved: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			customObject := v1alpha1.AWSConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: tc.annotations,
+				},
+			}
+
+			approved := ChangeSetApproved(customObject, tc.name)
+			if approved != tc.expectedApproved {
+				t.Fatalf("expected %t got %t", tc.expectedApproved, approved)
+			}
+		})
+	}
+}
+
 func Test_ClusterID(t *testing.T) {
 	t.Parallel()
 	expectedID := "test-cluster"
@@ -331,6 +387,55 @@ func Test_EtcdVolumeName(t *testing.T) {
 	}
 }
 
+func Test_EtcdInitialCluster(t *testing.T) {
+	t.Parallel()
+
+	customObject := v1alpha1.AWSConfig{
+		Spec: v1alpha1.AWSConfigSpec{
+			AWS: v1alpha1.AWSConfigSpecAWS{
+				HostedZones: v1alpha1.AWSConfigSpecAWSHostedZones{
+					API: v1alpha1.AWSConfigSpecAWSHostedZonesZone{
+						Name: "installation.eu-central-1.aws.gigantic.io",
+					},
+				},
+			},
+			Cluster: v1alpha1.Cluster{
+				ID: "al9qy",
+			},
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		masterCount    int
+		expectedResult string
+	}{
+		{
+			name:           "case 0: single master",
+			masterCount:    1,
+			expectedResult: "etcd0=https://etcd0.al9qy.k8s.installation.eu-central-1.aws.gigantic.io:2380",
+		},
+		{
+			name:        "case 1: three masters",
+			masterCount: 3,
+			expectedResult: strings.Join([]string{
+				"etcd0=https://etcd0.al9qy.k8s.installation.eu-central-1.aws.gigantic.io:2380",
+				"etcd1=https://etcd1.al9qy.k8s.installation.eu-central-1.aws.gigantic.io:2380",
+				"etcd2=https://etcd2.al9qy.k8s.installation.eu-central-1.aws.gigantic.io:2380",
+			}, ","),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := EtcdInitialCluster(customObject, tc.masterCount)
+			if result != tc.expectedResult {
+				t.Fatalf("expected %#q got %#q", tc.expectedResult, result)
+			}
+		})
+	}
+}
+
 func Test_IngressControllerInsecurePort(t *testing.T) {
 	t.Parallel()
 	expectedPort := 30010
@@ -554,6 +659,91 @@ func Test_MasterInstanceResourceName_Inequivalence(t *testing.T) {
 	}
 }
 
+func Test_MasterResourceName(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		idx            int
+		expectedResult string
+	}{
+		{
+			name:           "case 0: first master keeps the unindexed name",
+			idx:            0,
+			expectedResult: "MasterInstanceAL9QY4DD3C",
+		},
+		{
+			name:           "case 1: second master",
+			idx:            1,
+			expectedResult: "MasterInstanceAL9QY4DD3C01",
+		},
+		{
+			name:           "case 2: third master",
+			idx:            2,
+			expectedResult: "MasterInstanceAL9QY4DD3C02",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := MasterResourceName("MasterInstanceAL9QY4DD3C", tc.idx)
+			if result != tc.expectedResult {
+				t.Fatalf("expected %#q got %#q", tc.expectedResult, result)
+			}
+		})
+	}
+}
+
+func Test_MasterOutputValue(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		values         map[string]string
+		expectedResult string
+	}{
+		{
+			name: "case 0: values are sorted by key",
+			values: map[string]string{
+				MasterInstanceTypeOutputKey: "m4.xlarge",
+				MasterImageIDOutputKey:      "ami-123",
+			},
+			expectedResult: "ImageID=ami-123,InstanceType=m4.xlarge",
+		},
+		{
+			name: "case 1: empty values are omitted",
+			values: map[string]string{
+				MasterEncryptionKeyIDOutputKey: "",
+				MasterImageIDOutputKey:         "ami-123",
+			},
+			expectedResult: "ImageID=ami-123",
+		},
+		{
+			name: "case 2: ARNs are kept intact",
+			values: map[string]string{
+				MasterVolumeEncryptionKeyARNOutputKey: "arn:aws:kms:eu-central-1:000000000000:key/test-key",
+			},
+			expectedResult: "VolumeEncryptionKeyARN=arn:aws:kms:eu-central-1:000000000000:key/test-key",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := MasterOutputValue(tc.values)
+			if result != tc.expectedResult {
+				t.Fatalf("expected %#q got %#q", tc.expectedResult, result)
+			}
+
+			values := MasterOutputValues(result)
+			for k, v := range tc.values {
+				if values[k] != v {
+					t.Fatalf("expected %#q for %#q got %#q", v, k, values[k])
+				}
+			}
+		})
+	}
+}
+
 func Test_MasterInstanceType(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -946,6 +1136,186 @@ func Test_WorkerInstanceType(t *testing.T) {
 	}
 }
 
+func Test_WorkerInstanceTypes(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		customObject          v1alpha1.AWSConfig
+		expectedInstanceTypes []string
+	}{
+		{
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						Workers: []v1alpha1.AWSConfigSpecAWSNode{
+							{
+								InstanceType: "m3.medium",
+							},
+						},
+					},
+				},
+			},
+			expectedInstanceTypes: []string{"m3.medium"},
+		},
+		{
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						Workers: []v1alpha1.AWSConfigSpecAWSNode{
+							{
+								InstanceType: "m3.medium",
+							},
+						},
+						WorkerInstanceDistribution: v1alpha1.AWSConfigSpecAWSWorkerInstanceDistribution{
+							InstanceTypes: []string{"m5.large", "m4.large"},
+						},
+					},
+				},
+			},
+			expectedInstanceTypes: []string{"m5.large", "m4.large"},
+		},
+	}
+
+	for _, tc := range tests {
+		if !reflect.DeepEqual(WorkerInstanceTypes(tc.customObject), tc.expectedInstanceTypes) {
+			t.Fatalf("Expected worker instance types %v but was %v", tc.expectedInstanceTypes, WorkerInstanceTypes(tc.customObject))
+		}
+	}
+}
+
+func Test_WorkerMixedInstances(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		distribution           v1alpha1.AWSConfigSpecAWSWorkerInstanceDistribution
+		expectedMixedInstances bool
+	}{
+		{
+			distribution:           v1alpha1.AWSConfigSpecAWSWorkerInstanceDistribution{},
+			expectedMixedInstances: false,
+		},
+		{
+			distribution: v1alpha1.AWSConfigSpecAWSWorkerInstanceDistribution{
+				SpotAllocationStrategy: SpotAllocationStrategyCapacityOptimized,
+			},
+			expectedMixedInstances: false,
+		},
+		{
+			distribution: v1alpha1.AWSConfigSpecAWSWorkerInstanceDistribution{
+				SpotPercentage: 50,
+			},
+			expectedMixedInstances: true,
+		},
+		{
+			distribution: v1alpha1.AWSConfigSpecAWSWorkerInstanceDistribution{
+				InstanceTypes: []string{"m5.large"},
+			},
+			expectedMixedInstances: true,
+		},
+	}
+
+	for _, tc := range tests {
+		customObject := v1alpha1.AWSConfig{
+			Spec: v1alpha1.AWSConfigSpec{
+				AWS: v1alpha1.AWSConfigSpecAWS{
+					WorkerInstanceDistribution: tc.distribution,
+				},
+			},
+		}
+
+		if WorkerMixedInstances(customObject) != tc.expectedMixedInstances {
+			t.Fatalf("Expected worker mixed instances %t but was %t", tc.expectedMixedInstances, WorkerMixedInstances(customObject))
+		}
+	}
+}
+
+func Test_WorkerSpotAllocationStrategy(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		strategy         string
+		expectedStrategy string
+	}{
+		{
+			strategy:         "",
+			expectedStrategy: SpotAllocationStrategyLowestPrice,
+		},
+		{
+			strategy:         SpotAllocationStrategyCapacityOptimized,
+			expectedStrategy: SpotAllocationStrategyCapacityOptimized,
+		},
+	}
+
+	for _, tc := range tests {
+		customObject := v1alpha1.AWSConfig{
+			Spec: v1alpha1.AWSConfigSpec{
+				AWS: v1alpha1.AWSConfigSpecAWS{
+					WorkerInstanceDistribution: v1alpha1.AWSConfigSpecAWSWorkerInstanceDistribution{
+						SpotAllocationStrategy: tc.strategy,
+					},
+				},
+			},
+		}
+
+		if WorkerSpotAllocationStrategy(customObject) != tc.expectedStrategy {
+			t.Fatalf("Expected worker spot allocation strategy %s but was %s", tc.expectedStrategy, WorkerSpotAllocationStrategy(customObject))
+		}
+	}
+}
+
+func Test_NodePoolNameFromASGKey(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		outputKey    string
+		expectedName string
+	}{
+		{
+			outputKey:    NodePoolOutputKey("gpu", NodePoolASGKey),
+			expectedName: "gpu",
+		},
+		{
+			outputKey:    NodePoolOutputKey("gpu", NodePoolInstanceTypeKey),
+			expectedName: "",
+		},
+		{
+			outputKey:    WorkerASGKey,
+			expectedName: "",
+		},
+	}
+
+	for _, tc := range tests {
+		if NodePoolNameFromASGKey(tc.outputKey) != tc.expectedName {
+			t.Fatalf("Expected node pool name %q for output key %s but was %q", tc.expectedName, tc.outputKey, NodePoolNameFromASGKey(tc.outputKey))
+		}
+	}
+}
+
+func Test_NodePoolCloudConfigRole(t *testing.T) {
+	t.Parallel()
+	nodePool := v1alpha1.AWSConfigSpecAWSNodePool{
+		Name: "gpu",
+		Labels: map[string]string{
+			"team": "ml",
+			"gpu":  "true",
+		},
+	}
+
+	role := NodePoolCloudConfigRole(nodePool)
+	if !strings.HasPrefix(role, "worker-gpu-") {
+		t.Fatalf("Expected node pool cloud config role to start with %s but was %s", "worker-gpu-", role)
+	}
+	if NodePoolLabels(nodePool) != "gpu=true,team=ml" {
+		t.Fatalf("Expected node pool labels %s but was %s", "gpu=true,team=ml", NodePoolLabels(nodePool))
+	}
+
+	nodePool.Taints = []v1alpha1.AWSConfigSpecAWSNodePoolTaint{
+		{Effect: "NoSchedule", Key: "gpu", Value: "true"},
+	}
+	if NodePoolCloudConfigRole(nodePool) == role {
+		t.Fatalf("Expected node pool cloud config role to change with the taints of the node pool")
+	}
+	if NodePoolTaints(nodePool) != "gpu=true:NoSchedule" {
+		t.Fatalf("Expected node pool taints %s but was %s", "gpu=true:NoSchedule", NodePoolTaints(nodePool))
+	}
+}
+
 func Test_MainGuestStackName(t *testing.T) {
 	t.Parallel()
 	expected := "cluster-xyz-guest-main"
@@ -1025,6 +1395,7 @@ func TestLoadBalancerName(t *testing.T) {
 	tests := []struct {
 		desc       string
 		domainName string
+		lbType     string
 		tpo        v1alpha1.AWSConfig
 		res        string
 		err        error
@@ -1032,6 +1403,7 @@ func TestLoadBalancerName(t *testing.T) {
 		{
 			desc:       "works",
 			domainName: "component.foo.bar.example.com",
+			lbType:     LoadBalancerTypeClassic,
 			tpo: v1alpha1.AWSConfig{
 				Spec: v1alpha1.AWSConfigSpec{
 					Cluster: v1alpha1.Cluster{
@@ -1044,6 +1416,7 @@ func TestLoadBalancerName(t *testing.T) {
 		{
 			desc:       "also works",
 			domainName: "component.of.a.well.formed.domain",
+			lbType:     LoadBalancerTypeClassic,
 			tpo: v1alpha1.AWSConfig{
 				Spec: v1alpha1.AWSConfigSpec{
 					Cluster: v1alpha1.Cluster{
@@ -1056,6 +1429,7 @@ func TestLoadBalancerName(t *testing.T) {
 		{
 			desc:       "missing ID key in cloudconfig",
 			domainName: "component.foo.bar.example.com",
+			lbType:     LoadBalancerTypeClassic,
 			tpo: v1alpha1.AWSConfig{
 				Spec: v1alpha1.AWSConfigSpec{
 					Cluster: v1alpha1.Cluster{
@@ -1069,6 +1443,7 @@ func TestLoadBalancerName(t *testing.T) {
 		{
 			desc:       "malformed domain name",
 			domainName: "not a domain name",
+			lbType:     LoadBalancerTypeClassic,
 			tpo: v1alpha1.AWSConfig{
 				Spec: v1alpha1.AWSConfigSpec{
 					Cluster: v1alpha1.Cluster{
@@ -1082,6 +1457,34 @@ func TestLoadBalancerName(t *testing.T) {
 		{
 			desc:       "missing domain name",
 			domainName: "",
+			lbType:     LoadBalancerTypeClassic,
+			tpo: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					Cluster: v1alpha1.Cluster{
+						ID: "foo-customer",
+					},
+				},
+			},
+			res: "",
+			err: malformedCloudConfigKeyError,
+		},
+		{
+			desc:       "network load balancer",
+			domainName: "component.foo.bar.example.com",
+			lbType:     LoadBalancerTypeNetwork,
+			tpo: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					Cluster: v1alpha1.Cluster{
+						ID: "foo-customer",
+					},
+				},
+			},
+			res: "foo-customer-component-nlb",
+		},
+		{
+			desc:       "unknown load balancer type",
+			domainName: "component.foo.bar.example.com",
+			lbType:     "alb",
 			tpo: v1alpha1.AWSConfig{
 				Spec: v1alpha1.AWSConfigSpec{
 					Cluster: v1alpha1.Cluster{
@@ -1095,7 +1498,7 @@ func TestLoadBalancerName(t *testing.T) {
 	}
 
 	for _, tc := range tests {
-		res, err := LoadBalancerName(tc.domainName, tc.tpo)
+		res, err := LoadBalancerName(tc.domainName, tc.lbType, tc.tpo)
 
 		if err != nil {
 			underlying := microerror.Cause(err)
@@ -1327,69 +1730,6 @@ func Test_PeerID(t *testing.T) {
 	}
 }
 
-func Test_ImageID(t *testing.T) {
-	t.Parallel()
-	testCases := []struct {
-		description     string
-		customObject    v1alpha1.AWSConfig
-		errorMatcher    func(error) bool
-		expectedImageID string
-	}{
-		{
-			description: "basic match",
-			customObject: v1alpha1.AWSConfig{
-				Spec: v1alpha1.AWSConfigSpec{
-					AWS: v1alpha1.AWSConfigSpecAWS{
-						Region: "eu-central-1",
-					},
-				},
-			},
-			errorMatcher:    nil,
-			expectedImageID: "ami-0e6601a88a9753474",
-		},
-		{
-			description: "different region",
-			customObject: v1alpha1.AWSConfig{
-				Spec: v1alpha1.AWSConfigSpec{
-					AWS: v1alpha1.AWSConfigSpecAWS{
-						Region: "eu-west-1",
-					},
-				},
-			},
-			errorMatcher:    nil,
-			expectedImageID: "ami-06c40d1010f762df9",
-		},
-		{
-			description: "invalid region",
-			customObject: v1alpha1.AWSConfig{
-				Spec: v1alpha1.AWSConfigSpec{
-					AWS: v1alpha1.AWSConfigSpecAWS{
-						Region: "invalid-1",
-					},
-				},
-			},
-			errorMatcher: IsInvalidConfig,
-		},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.description, func(t *testing.T) {
-			imageID, err := ImageID(tc.customObject)
-			if tc.errorMatcher != nil && err == nil {
-				t.Error("expected error didn't happen")
-			}
-
-			if tc.errorMatcher != nil && !tc.errorMatcher(err) {
-				t.Error("expected", true, "got", false)
-			}
-
-			if tc.expectedImageID != imageID {
-				t.Errorf("unexpected imageID, expecting %q, want %q", tc.expectedImageID, imageID)
-			}
-		})
-	}
-}
-
 func Test_TargetLogBucketName(t *testing.T) {
 	t.Parallel()
 	expectedName := "test-cluster-g8s-access-logs"
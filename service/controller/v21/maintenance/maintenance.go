@@ -0,0 +1,119 @@
+package maintenance
+
+import (
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/robfig/cron"
+
+	"github.com/giantswarm/aws-operator/service/controller/v21/key"
+)
+
+type Config struct {
+	// Duration is the length of each maintenance window.
+	Duration time.Duration
+	// Schedule is the cron expression defining when maintenance windows start.
+	// It has the five standard fields minute, hour, day of month, month and day
+	// of week.
+	Schedule string
+	// TimeZone is the IANA time zone the schedule is evaluated in. UTC is used
+	// in case it is empty.
+	TimeZone string
+}
+
+// Window is a recurring maintenance window of a tenant cluster.
+type Window struct {
+	duration time.Duration
+	location *time.Location
+	schedule cron.Schedule
+}
+
+func New(config Config) (*Window, error) {
+	if config.Duration <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Duration must be greater than 0", config)
+	}
+	if config.Schedule == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Schedule must not be empty", config)
+	}
+
+	location := time.UTC
+	if config.TimeZone != "" {
+		var err error
+		location, err = time.LoadLocation(config.TimeZone)
+		if err != nil {
+			return nil, microerror.Maskf(invalidConfigError, "%T.TimeZone %#q is unknown: %s", config, config.TimeZone, err)
+		}
+	}
+
+	s, err := cron.ParseStandard(config.Schedule)
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Schedule %#q is invalid: %s", config, config.Schedule, err)
+	}
+
+	w := &Window{
+		duration: config.Duration,
+		location: location,
+		schedule: s,
+	}
+
+	if _, _, ok := w.Next(time.Now()); !ok {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Schedule %#q never matches", config, config.Schedule)
+	}
+
+	return w, nil
+}
+
+// FromCustomObject returns the maintenance window configured for the given
+// tenant cluster. It returns nil in case the tenant cluster does not have a
+// maintenance window.
+func FromCustomObject(customObject v1alpha1.AWSConfig) (*Window, error) {
+	if key.MaintenanceWindowSchedule(customObject) == "" {
+		return nil, nil
+	}
+
+	d, err := time.ParseDuration(key.MaintenanceWindowDuration(customObject))
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "maintenance window duration: %s", err)
+	}
+
+	c := Config{
+		Duration: d,
+		Schedule: key.MaintenanceWindowSchedule(customObject),
+		TimeZone: key.MaintenanceWindowTimeZone(customObject),
+	}
+
+	w, err := New(c)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return w, nil
+}
+
+// Contains returns true in case t is within a maintenance window.
+func (w *Window) Contains(t time.Time) bool {
+	start, _, ok := w.Next(t)
+	if !ok {
+		return false
+	}
+
+	return !start.After(t)
+}
+
+// Next returns the start and end of the maintenance window containing t. In
+// case t is not within a maintenance window, the start and end of the next
+// maintenance window are returned. It returns false in case the schedule does
+// not match any time.
+func (w *Window) Next(t time.Time) (time.Time, time.Time, bool) {
+	// The window containing t is the first window starting after t minus the
+	// window duration. In case this window starts after t, t is not within any
+	// window and the window is the next one. The schedule returns the zero time
+	// in case it does not match any time within the next five years.
+	start := w.schedule.Next(t.In(w.location).Add(-w.duration))
+	if start.IsZero() {
+		return time.Time{}, time.Time{}, false
+	}
+
+	return start, start.Add(w.duration), true
+}
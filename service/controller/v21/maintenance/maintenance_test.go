@@ -0,0 +1,191 @@
+package maintenance
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+)
+
+func mustParseTime(t *testing.T, s string) time.Time {
+	v, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return v
+}
+
+func Test_Window_Next(t *testing.T) {
+	testCases := []struct {
+		name             string
+		config           Config
+		now              string
+		expectedStart    string
+		expectedEnd      string
+		expectedContains bool
+	}{
+		{
+			name: "case 0: before the window",
+			config: Config{
+				Duration: 4 * time.Hour,
+				Schedule: "0 2 * * 6",
+			},
+			// Thursday.
+			now:              "2019-01-03T12:00:00Z",
+			expectedStart:    "2019-01-05T02:00:00Z",
+			expectedEnd:      "2019-01-05T06:00:00Z",
+			expectedContains: false,
+		},
+		{
+			name: "case 1: within the window",
+			config: Config{
+				Duration: 4 * time.Hour,
+				Schedule: "0 2 * * 6",
+			},
+			now:              "2019-01-05T05:59:59Z",
+			expectedStart:    "2019-01-05T02:00:00Z",
+			expectedEnd:      "2019-01-05T06:00:00Z",
+			expectedContains: true,
+		},
+		{
+			name: "case 2: at the end of the window",
+			config: Config{
+				Duration: 4 * time.Hour,
+				Schedule: "0 2 * * 6",
+			},
+			now:              "2019-01-05T06:00:00Z",
+			expectedStart:    "2019-01-12T02:00:00Z",
+			expectedEnd:      "2019-01-12T06:00:00Z",
+			expectedContains: false,
+		},
+		{
+			name: "case 3: time zone",
+			config: Config{
+				Duration: time.Hour,
+				Schedule: "30 3 * * *",
+				TimeZone: "Europe/Berlin",
+			},
+			now:              "2019-07-01T01:45:00Z",
+			expectedStart:    "2019-07-01T01:30:00Z",
+			expectedEnd:      "2019-07-01T02:30:00Z",
+			expectedContains: true,
+		},
+		{
+			name: "case 4: day of month and day of week",
+			config: Config{
+				Duration: time.Hour,
+				Schedule: "0 0 15 * 1",
+			},
+			// Wednesday the 2nd. The next Monday is the 7th.
+			now:              "2019-01-02T00:00:00Z",
+			expectedStart:    "2019-01-07T00:00:00Z",
+			expectedEnd:      "2019-01-07T01:00:00Z",
+			expectedContains: false,
+		},
+		{
+			name: "case 5: steps and lists",
+			config: Config{
+				Duration: 10 * time.Minute,
+				Schedule: "*/20 1,13 1-7 2 *",
+			},
+			now:              "2019-01-31T23:59:00Z",
+			expectedStart:    "2019-02-01T01:00:00Z",
+			expectedEnd:      "2019-02-01T01:10:00Z",
+			expectedContains: false,
+		},
+		{
+			name: "case 6: window spanning midnight",
+			config: Config{
+				Duration: 3 * time.Hour,
+				Schedule: "0 23 * * 0",
+			},
+			// Monday.
+			now:              "2019-01-07T01:00:00Z",
+			expectedStart:    "2019-01-06T23:00:00Z",
+			expectedEnd:      "2019-01-07T02:00:00Z",
+			expectedContains: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, err := New(tc.config)
+			if err != nil {
+				t.Fatalf("expected nil got %#v", err)
+			}
+
+			now := mustParseTime(t, tc.now)
+
+			start, end, ok := w.Next(now)
+			if !ok {
+				t.Fatalf("expected next window")
+			}
+			if !start.Equal(mustParseTime(t, tc.expectedStart)) {
+				t.Fatalf("expected start %s got %s", tc.expectedStart, start.UTC().Format(time.RFC3339))
+			}
+			if !end.Equal(mustParseTime(t, tc.expectedEnd)) {
+				t.Fatalf("expected end %s got %s", tc.expectedEnd, end.UTC().Format(time.RFC3339))
+			}
+			if w.Contains(now) != tc.expectedContains {
+				t.Fatalf("expected contains %t got %t", tc.expectedContains, !tc.expectedContains)
+			}
+		})
+	}
+}
+
+func Test_New_Invalid(t *testing.T) {
+	testCases := []Config{
+		{Duration: time.Hour},
+		{Duration: 0, Schedule: "0 2 * * *"},
+		{Duration: time.Hour, Schedule: "0 2 * *"},
+		{Duration: time.Hour, Schedule: "60 2 * * *"},
+		{Duration: time.Hour, Schedule: "0 2-1 * * *"},
+		{Duration: time.Hour, Schedule: "*/0 2 * * *"},
+		{Duration: time.Hour, Schedule: "0 2 * * 8"},
+		{Duration: time.Hour, Schedule: "0 2 30 2 *"},
+		{Duration: time.Hour, Schedule: "0 2 * * *", TimeZone: "Mars/Olympus_Mons"},
+	}
+
+	for i, c := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			_, err := New(c)
+			if !IsInvalidConfig(err) {
+				t.Fatalf("expected invalid config error got %#v", err)
+			}
+		})
+	}
+}
+
+func Test_FromCustomObject(t *testing.T) {
+	customObject := v1alpha1.AWSConfig{}
+
+	w, err := FromCustomObject(customObject)
+	if err != nil {
+		t.Fatalf("expected nil got %#v", err)
+	}
+	if w != nil {
+		t.Fatalf("expected no maintenance window got %#v", w)
+	}
+
+	customObject.Spec.AWS.MaintenanceWindow = v1alpha1.AWSConfigSpecAWSMaintenanceWindow{
+		Schedule: "0 2 * * 6",
+		Duration: "four hours",
+	}
+
+	_, err = FromCustomObject(customObject)
+	if !IsInvalidConfig(err) {
+		t.Fatalf("expected invalid config error got %#v", err)
+	}
+
+	customObject.Spec.AWS.MaintenanceWindow.Duration = "4h"
+
+	w, err = FromCustomObject(customObject)
+	if err != nil {
+		t.Fatalf("expected nil got %#v", err)
+	}
+	if w == nil {
+		t.Fatalf("expected maintenance window got nil")
+	}
+}
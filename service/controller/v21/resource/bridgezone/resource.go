@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/route53"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
@@ -351,6 +352,11 @@ func (r *Resource) findHostedZoneID(ctx context.Context, client *route53.Route53
 			if hz.Name == nil || hz.Id == nil {
 				continue
 			}
+			// The private API hosted zone of the tenant cluster must never be
+			// delegated to.
+			if hz.Config != nil && aws.BoolValue(hz.Config.PrivateZone) {
+				continue
+			}
 
 			hzName := *hz.Name
 			hzName = strings.TrimSuffix(hzName, ".")
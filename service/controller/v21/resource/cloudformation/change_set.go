@@ -0,0 +1,337 @@
+package cloudformation
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/aws-operator/service/controller/v21/controllercontext"
+	"github.com/giantswarm/aws-operator/service/controller/v21/key"
+)
+
+const (
+	changeSetEventReasonBlocked = "ChangeSetBlocked"
+	changeSetEventReasonCreated = "ChangeSetCreated"
+)
+
+// protectedResourcePrefixes are the prefixes of the logical IDs of resources
+// which must not be replaced without approval. Replacing the etcd volume loses
+// the etcd data of the tenant cluster.
+var protectedResourcePrefixes = []string{
+	"EtcdVolume",
+}
+
+// changeSet is the classified preview of an update of the tenant cluster main
+// stack.
+type changeSet struct {
+	Name string
+	// Modifications is the number of resources the change set adds, modifies
+	// or removes.
+	Modifications int
+	// Protected are the logical IDs of the replaced protected resources.
+	Protected []string
+	// Replacements are the logical IDs of the replaced resources. Conditional
+	// replacements are considered replacements since CloudFormation only knows
+	// at execution time whether the resource is replaced.
+	Replacements []string
+}
+
+func newChangeSet(name string, changes []*cloudformation.Change) changeSet {
+	cs := changeSet{
+		Name: name,
+	}
+
+	for _, c := range changes {
+		rc := c.ResourceChange
+		if rc == nil {
+			continue
+		}
+
+		cs.Modifications++
+
+		replacement := aws.StringValue(rc.Replacement)
+		if replacement != cloudformation.ReplacementTrue && replacement != cloudformation.ReplacementConditional {
+			continue
+		}
+
+		id := aws.StringValue(rc.LogicalResourceId)
+		cs.Replacements = append(cs.Replacements, id)
+		if isProtectedResource(id) {
+			cs.Protected = append(cs.Protected, id)
+		}
+	}
+
+	sort.Strings(cs.Protected)
+	sort.Strings(cs.Replacements)
+
+	return cs
+}
+
+// Summary returns a human readable summary of the change set, e.g.
+//
+//	change set cluster-al9qy-guest-main-0123456789ab changes 3 resources and replaces 2 resources: EtcdVolume (protected), MasterInstance
+func (c changeSet) Summary() string {
+	s := fmt.Sprintf("change set %s changes %d resources", c.Name, c.Modifications)
+	if len(c.Replacements) == 0 {
+		return s + " and replaces no resources"
+	}
+
+	var replacements []string
+	for _, r := range c.Replacements {
+		if isProtectedResource(r) {
+			r += " (protected)"
+		}
+		replacements = append(replacements, r)
+	}
+
+	return fmt.Sprintf("%s and replaces %d resources: %s", s, len(c.Replacements), strings.Join(replacements, ", "))
+}
+
+// changeSetName returns a name for the change set of the given update which is
+// stable as long as the update does not change. This way the change set can
+// be approved using its name before it is executed.
+func changeSetName(input cloudformation.UpdateStackInput) string {
+	h := sha1.New()
+	h.Write([]byte(aws.StringValue(input.TemplateBody)))
+	for _, p := range input.Parameters {
+		h.Write([]byte(aws.StringValue(p.ParameterKey) + "=" + aws.StringValue(p.ParameterValue)))
+	}
+
+	return fmt.Sprintf("%s-%x", aws.StringValue(input.StackName), h.Sum(nil)[:6])
+}
+
+func isProtectedResource(logicalID string) bool {
+	for _, p := range protectedResourcePrefixes {
+		if strings.HasPrefix(logicalID, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ensureChangeSet makes sure the change set previewing the given update of the
+// tenant cluster main stack exists and returns its classified changes. The
+// returned boolean is false in case the update does not contain any changes.
+func (r *Resource) ensureChangeSet(ctx context.Context, customObject v1alpha1.AWSConfig, input cloudformation.UpdateStackInput) (changeSet, bool, error) {
+	sc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return changeSet{}, false, microerror.Mask(err)
+	}
+
+	name := changeSetName(input)
+
+	describeInput := &cloudformation.DescribeChangeSetInput{
+		ChangeSetName: aws.String(name),
+		StackName:     input.StackName,
+	}
+
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("finding change set %#q", name))
+
+		_, err := sc.AWSClient.CloudFormation.DescribeChangeSet(describeInput)
+		if IsChangeSetNotFound(err) {
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("did not find change set %#q", name))
+
+			err = r.deleteObsoleteChangeSets(ctx, input.StackName)
+			if err != nil {
+				return changeSet{}, false, microerror.Mask(err)
+			}
+
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("creating change set %#q", name))
+
+			i := &cloudformation.CreateChangeSetInput{
+				Capabilities:  input.Capabilities,
+				ChangeSetName: aws.String(name),
+				ChangeSetType: aws.String(cloudformation.ChangeSetTypeUpdate),
+				Parameters:    input.Parameters,
+				StackName:     input.StackName,
+				TemplateBody:  input.TemplateBody,
+			}
+			_, err = sc.AWSClient.CloudFormation.CreateChangeSet(i)
+			if err != nil {
+				return changeSet{}, false, microerror.Mask(err)
+			}
+
+			// The waiter fails in case the change set fails to be created, which
+			// is also the case when the update does not contain any changes. The
+			// status of the change set is checked below.
+			err = sc.AWSClient.CloudFormation.WaitUntilChangeSetCreateComplete(describeInput)
+			if err != nil && !IsResourceNotReady(err) {
+				return changeSet{}, false, microerror.Mask(err)
+			}
+
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("created change set %#q", name))
+		} else if err != nil {
+			return changeSet{}, false, microerror.Mask(err)
+		} else {
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found change set %#q", name))
+		}
+	}
+
+	var changes []*cloudformation.Change
+	{
+		i := *describeInput
+		for {
+			o, err := sc.AWSClient.CloudFormation.DescribeChangeSet(&i)
+			if err != nil {
+				return changeSet{}, false, microerror.Mask(err)
+			}
+
+			if aws.StringValue(o.Status) == cloudformation.ChangeSetStatusFailed {
+				r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("change set %#q failed: %s", name, aws.StringValue(o.StatusReason)))
+
+				_, err := sc.AWSClient.CloudFormation.DeleteChangeSet(&cloudformation.DeleteChangeSetInput{
+					ChangeSetName: aws.String(name),
+					StackName:     input.StackName,
+				})
+				if err != nil {
+					return changeSet{}, false, microerror.Mask(err)
+				}
+
+				// CloudFormation fails change sets of updates which do not change
+				// anything. Any other failure must not be mistaken for a no-op.
+				if isNoChangesReason(aws.StringValue(o.StatusReason)) {
+					return changeSet{}, false, nil
+				}
+
+				return changeSet{}, false, microerror.Maskf(executionFailedError, "change set %#q failed: %s", name, aws.StringValue(o.StatusReason))
+			}
+			if aws.StringValue(o.Status) != cloudformation.ChangeSetStatusCreateComplete {
+				return changeSet{}, false, microerror.Maskf(resourceNotReadyError, "change set %#q is in status %#q", name, aws.StringValue(o.Status))
+			}
+
+			changes = append(changes, o.Changes...)
+
+			if o.NextToken == nil {
+				break
+			}
+			i.NextToken = o.NextToken
+		}
+	}
+
+	cs := newChangeSet(name, changes)
+
+	err = r.publishChangeSet(ctx, customObject, cs)
+	if err != nil {
+		return changeSet{}, false, microerror.Mask(err)
+	}
+
+	return cs, true, nil
+}
+
+// deleteObsoleteChangeSets deletes the change sets of previous updates which
+// were never executed, e.g. because they were blocked and the desired state
+// of the tenant cluster changed in the meantime.
+func (r *Resource) deleteObsoleteChangeSets(ctx context.Context, stackName *string) error {
+	sc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	o, err := sc.AWSClient.CloudFormation.ListChangeSets(&cloudformation.ListChangeSetsInput{StackName: stackName})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, s := range o.Summaries {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("deleting obsolete change set %#q", aws.StringValue(s.ChangeSetName)))
+
+		i := &cloudformation.DeleteChangeSetInput{
+			ChangeSetName: s.ChangeSetName,
+			StackName:     stackName,
+		}
+		_, err := sc.AWSClient.CloudFormation.DeleteChangeSet(i)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("deleted obsolete change set %#q", aws.StringValue(s.ChangeSetName)))
+	}
+
+	return nil
+}
+
+// isChangeSetBlocked returns true in case the execution of the given change
+// set requires an approval which is not yet given.
+func (r *Resource) isChangeSetBlocked(customObject v1alpha1.AWSConfig, cs changeSet) bool {
+	if !r.changeSetApprovalRequired || len(cs.Protected) == 0 {
+		return false
+	}
+
+	return !key.ChangeSetApproved(customObject, cs.Name)
+}
+
+// publishChangeSet makes the classified change set visible to users by
+// emitting a Kubernetes event for the AWSConfig CR and writing its summary to
+// the CR status. The event is only emitted once per change set. The status is
+// also updated when a blocked change set gets approved.
+func (r *Resource) publishChangeSet(ctx context.Context, customObject v1alpha1.AWSConfig, cs changeSet) error {
+	blocked := r.isChangeSetBlocked(customObject, cs)
+	status := customObject.Status.AWS.ChangeSet
+
+	if status.Name == cs.Name && status.Blocked == blocked {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "CR status already contains change set")
+		return nil
+	}
+
+	if status.Name != cs.Name {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("publishing %s", cs.Summary()))
+
+		eventType := corev1.EventTypeNormal
+		message := cs.Summary()
+		reason := changeSetEventReasonCreated
+		if blocked {
+			eventType = corev1.EventTypeWarning
+			message = fmt.Sprintf("%s; approve it by setting the annotation %s=%s", message, key.ChangeSetApprovalAnnotation, cs.Name)
+			reason = changeSetEventReasonBlocked
+		}
+
+		err := r.emitEvent(customObject, eventType, reason, message)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "updating CR status with change set")
+
+		newObj, err := r.g8sClient.ProviderV1alpha1().AWSConfigs(customObject.GetNamespace()).Get(customObject.GetName(), metav1.GetOptions{})
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		newObj.Status.AWS.ChangeSet = v1alpha1.AWSConfigStatusAWSChangeSet{
+			Name:         cs.Name,
+			Blocked:      blocked,
+			Replacements: cs.Replacements,
+			Summary:      cs.Summary(),
+		}
+
+		_, err = r.g8sClient.ProviderV1alpha1().AWSConfigs(newObj.GetNamespace()).UpdateStatus(newObj)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "updated CR status with change set")
+	}
+
+	return nil
+}
+
+// isNoChangesReason returns true in case the given status reason of a failed
+// change set states that the update does not contain any changes, e.g. "The
+// submitted information didn't contain changes. Submit different information
+// to create a change set.".
+func isNoChangesReason(reason string) bool {
+	return strings.Contains(reason, "didn't contain changes")
+}
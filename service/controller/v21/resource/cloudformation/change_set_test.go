@@ -0,0 +1,231 @@
+package cloudformation
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awscloudformation "github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/aws-operator/service/controller/v21/key"
+)
+
+func newResourceChange(logicalID, replacement string) *awscloudformation.Change {
+	return &awscloudformation.Change{
+		ResourceChange: &awscloudformation.ResourceChange{
+			LogicalResourceId: aws.String(logicalID),
+			Replacement:       aws.String(replacement),
+		},
+	}
+}
+
+func Test_Resource_Cloudformation_newChangeSet(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description       string
+		changes           []*awscloudformation.Change
+		expectedChangeSet changeSet
+		expectedSummary   string
+	}{
+		{
+			description: "case 0: no replacements",
+			changes: []*awscloudformation.Change{
+				newResourceChange("workerAutoScalingGroup", awscloudformation.ReplacementFalse),
+			},
+			expectedChangeSet: changeSet{
+				Name:          "test",
+				Modifications: 1,
+			},
+			expectedSummary: "change set test changes 1 resources and replaces no resources",
+		},
+		{
+			description: "case 1: replacements of unprotected resources",
+			changes: []*awscloudformation.Change{
+				newResourceChange("workerLaunchTemplate", awscloudformation.ReplacementConditional),
+				newResourceChange("MasterInstance", awscloudformation.ReplacementTrue),
+				newResourceChange("workerAutoScalingGroup", awscloudformation.ReplacementFalse),
+			},
+			expectedChangeSet: changeSet{
+				Name:          "test",
+				Modifications: 3,
+				Replacements: []string{
+					"MasterInstance",
+					"workerLaunchTemplate",
+				},
+			},
+			expectedSummary: "change set test changes 3 resources and replaces 2 resources: MasterInstance, workerLaunchTemplate",
+		},
+		{
+			description: "case 2: replacements of protected resources",
+			changes: []*awscloudformation.Change{
+				newResourceChange("MasterInstance", awscloudformation.ReplacementTrue),
+				newResourceChange("EtcdVolume", awscloudformation.ReplacementTrue),
+				newResourceChange("EtcdVolume01", awscloudformation.ReplacementConditional),
+			},
+			expectedChangeSet: changeSet{
+				Name:          "test",
+				Modifications: 3,
+				Protected: []string{
+					"EtcdVolume",
+					"EtcdVolume01",
+				},
+				Replacements: []string{
+					"EtcdVolume",
+					"EtcdVolume01",
+					"MasterInstance",
+				},
+			},
+			expectedSummary: "change set test changes 3 resources and replaces 3 resources: EtcdVolume (protected), EtcdVolume01 (protected), MasterInstance",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			cs := newChangeSet("test", tc.changes)
+
+			if !reflect.DeepEqual(cs, tc.expectedChangeSet) {
+				t.Fatalf("expected %#v got %#v", tc.expectedChangeSet, cs)
+			}
+			if cs.Summary() != tc.expectedSummary {
+				t.Fatalf("expected %q got %q", tc.expectedSummary, cs.Summary())
+			}
+		})
+	}
+}
+
+func Test_Resource_Cloudformation_changeSetName(t *testing.T) {
+	t.Parallel()
+	input := awscloudformation.UpdateStackInput{
+		StackName:    aws.String("cluster-al9qy-guest-main"),
+		TemplateBody: aws.String("template"),
+		Parameters: []*awscloudformation.Parameter{
+			{
+				ParameterKey:   aws.String(versionBundleVersionParameterKey),
+				ParameterValue: aws.String("21.0.0"),
+			},
+		},
+	}
+
+	name := changeSetName(input)
+	if name != changeSetName(input) {
+		t.Fatalf("expected change set name to be stable")
+	}
+	if len(name) != len("cluster-al9qy-guest-main-")+12 {
+		t.Fatalf("expected change set name with 12 character hash suffix got %q", name)
+	}
+
+	input.Parameters[0].ParameterValue = aws.String("21.0.1")
+	if name == changeSetName(input) {
+		t.Fatalf("expected change set name to change with the parameters")
+	}
+}
+
+func Test_Resource_Cloudformation_isChangeSetBlocked(t *testing.T) {
+	t.Parallel()
+	protected := changeSet{
+		Name:         "cluster-al9qy-guest-main-0123456789ab",
+		Protected:    []string{"EtcdVolume"},
+		Replacements: []string{"EtcdVolume"},
+	}
+	unprotected := changeSet{
+		Name:         "cluster-al9qy-guest-main-0123456789ab",
+		Replacements: []string{"MasterInstance"},
+	}
+
+	testCases := []struct {
+		description      string
+		approvalRequired bool
+		annotation       string
+		changeSet        changeSet
+		expectedBlocked  bool
+	}{
+		{
+			description:      "case 0: approval not required",
+			approvalRequired: false,
+			changeSet:        protected,
+			expectedBlocked:  false,
+		},
+		{
+			description:      "case 1: no protected replacements",
+			approvalRequired: true,
+			changeSet:        unprotected,
+			expectedBlocked:  false,
+		},
+		{
+			description:      "case 2: protected replacements without approval",
+			approvalRequired: true,
+			changeSet:        protected,
+			expectedBlocked:  true,
+		},
+		{
+			description:      "case 3: protected replacements approved",
+			approvalRequired: true,
+			annotation:       "cluster-al9qy-guest-main-0123456789ab",
+			changeSet:        protected,
+			expectedBlocked:  false,
+		},
+		{
+			description:      "case 4: protected replacements with outdated approval",
+			approvalRequired: true,
+			annotation:       "cluster-al9qy-guest-main-ba9876543210",
+			changeSet:        protected,
+			expectedBlocked:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			r := &Resource{
+				changeSetApprovalRequired: tc.approvalRequired,
+			}
+			customObject := v1alpha1.AWSConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						key.ChangeSetApprovalAnnotation: tc.annotation,
+					},
+				},
+			}
+
+			blocked := r.isChangeSetBlocked(customObject, tc.changeSet)
+			if blocked != tc.expectedBlocked {
+				t.Fatalf("expected %t got %t", tc.expectedBlocked, blocked)
+			}
+		})
+	}
+}
+
+func Test_Resource_Cloudformation_isNoChangesReason(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description    string
+		reason         string
+		expectedResult bool
+	}{
+		{
+			description:    "case 0: change set without changes",
+			reason:         "The submitted information didn't contain changes. Submit different information to create a change set.",
+			expectedResult: true,
+		},
+		{
+			description:    "case 1: change set failed for another reason",
+			reason:         "Template format error: Unresolved resource dependencies [MasterInstance] in the Resources block of the template",
+			expectedResult: false,
+		},
+		{
+			description:    "case 2: empty reason",
+			reason:         "",
+			expectedResult: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			result := isNoChangesReason(tc.reason)
+			if result != tc.expectedResult {
+				t.Fatalf("expected %t got %t", tc.expectedResult, result)
+			}
+		})
+	}
+}
@@ -118,16 +118,19 @@ func (r *Resource) newCreateChange(ctx context.Context, obj, currentState, desir
 	if currentStackState.Name == "" || desiredStackState.Name != currentStackState.Name {
 		r.logger.LogCtx(ctx, "level", "debug", "message", "the guest cluster main stack has to be created")
 
-		if err := r.validateCluster(customObject); err != nil {
+		if err := r.validateCluster(ctx, customObject); err != nil {
 			return cloudformation.CreateStackInput{}, microerror.Mask(err)
 		}
 
 		// We need to create the required peering resources in the host account before
 		// getting the guest main stack template body, it requires id values from host
-		// resources.
-		err = r.createHostPreStack(ctx, customObject)
-		if err != nil {
-			return cloudformation.CreateStackInput{}, microerror.Mask(err)
+		// resources. Tenant cluster VPCs attached to a Transit Gateway are not
+		// peered and do not need them.
+		if desiredStackState.TransitGatewayID == "" {
+			err = r.createHostPreStack(ctx, customObject)
+			if err != nil {
+				return cloudformation.CreateStackInput{}, microerror.Mask(err)
+			}
 		}
 
 		var mainTemplate string
@@ -151,8 +154,9 @@ func (r *Resource) newCreateChange(ctx context.Context, obj, currentState, desir
 
 		// Create host post-main stack once the guest main stack got created. This
 		// here usually happens on the second or third attempt dependening on the
-		// resnyc period. It includes the peering routes, which need resources from
-		// the guest stack to be in place before it can be created.
+		// resnyc period. It includes the peering or Transit Gateway routes, which
+		// need resources from the guest stack to be in place before it can be
+		// created.
 		err = r.createHostPostStack(ctx, customObject, currentStackState)
 		if err != nil {
 			return nil, microerror.Mask(err)
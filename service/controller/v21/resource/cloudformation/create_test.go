@@ -6,7 +6,9 @@ import (
 
 	awscloudformation "github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	versionedfake "github.com/giantswarm/apiextensions/pkg/clientset/versioned/fake"
 	"github.com/giantswarm/micrologger/microloggertest"
+	"k8s.io/client-go/kubernetes/fake"
 
 	"github.com/giantswarm/aws-operator/client/aws"
 	"github.com/giantswarm/aws-operator/service/controller/v21/adapter"
@@ -91,6 +93,10 @@ func Test_Resource_Cloudformation_newCreate(t *testing.T) {
 	{
 		c := Config{}
 
+		c.AMICatalog = &AMICatalogMock{}
+		c.EtcdMember = &EtcdMemberMock{}
+		c.G8sClient = versionedfake.NewSimpleClientset()
+		c.K8sClient = fake.NewSimpleClientset()
 		c.HostClients = &adapter.Clients{
 			EC2:            &adapter.EC2ClientMock{},
 			CloudFormation: &adapter.CloudFormationMock{},
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/cloudformation"
@@ -97,10 +98,10 @@ func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) (interf
 	// stack. We dispatch our custom StackState structure and enrich it with all
 	// information necessary to reconcile the cloudformation resource.
 	var stackOutputs []*cloudformation.Output
+	var stackStatus string
 	{
 		r.logger.LogCtx(ctx, "level", "debug", "message", "finding the guest cluster main stack outputs in the AWS API")
 
-		var stackStatus string
 		stackOutputs, stackStatus, err = ctlCtx.CloudFormation.DescribeOutputsAndStatus(stackName)
 		if cloudformationservice.IsStackNotFound(err) {
 			r.logger.LogCtx(ctx, "level", "debug", "message", "did not find the guest cluster main stack outputs in the AWS API")
@@ -116,6 +117,12 @@ func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) (interf
 				r.logger.LogCtx(ctx, "level", "debug", "message", "keeping finalizers")
 				finalizerskeptcontext.SetKept(ctx)
 			}
+			if isFailedStackStatus(stackStatus) {
+				err := r.recoverStack(ctx, customObject, stackStatus)
+				if err != nil {
+					return StackState{}, microerror.Mask(err)
+				}
+			}
 			r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
 			resourcecanceledcontext.SetCanceled(ctx)
 
@@ -128,6 +135,22 @@ func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) (interf
 		r.logger.LogCtx(ctx, "level", "debug", "message", "found the guest cluster main stack outputs in the AWS API")
 	}
 
+	// Stacks which failed to be created end up in ROLLBACK_COMPLETE. Their
+	// outputs are accessible but do not contain any values we could work with.
+	if isFailedStackStatus(stackStatus) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("the guest cluster main stack has status '%s'", stackStatus))
+
+		err := r.recoverStack(ctx, customObject, stackStatus)
+		if err != nil {
+			return StackState{}, microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+		resourcecanceledcontext.SetCanceled(ctx)
+
+		return StackState{}, nil
+	}
+
 	var currentState StackState
 	{
 		var hostedZoneNameServers string
@@ -190,6 +213,24 @@ func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) (interf
 			workerDockerVolumeSizeGB = int(sz)
 		}
 
+		var masterCount int
+		{
+			v, err := ctlCtx.CloudFormation.GetOutputValue(stackOutputs, key.MasterCountKey)
+			if cloudformationservice.IsOutputNotFound(err) {
+				// Clusters created before the introduction of the highly available
+				// control plane do not have the master count in their CF stack
+				// outputs. All of them run exactly one master.
+				v = "1"
+			} else if err != nil {
+				return StackState{}, microerror.Mask(err)
+			}
+
+			masterCount, err = strconv.Atoi(v)
+			if err != nil {
+				return StackState{}, microerror.Mask(err)
+			}
+		}
+
 		masterImageID, err := ctlCtx.CloudFormation.GetOutputValue(stackOutputs, key.MasterImageIDKey)
 		if err != nil {
 			return StackState{}, microerror.Mask(err)
@@ -234,6 +275,200 @@ func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) (interf
 			return StackState{}, microerror.Mask(err)
 		}
 
+		var workerLaunchTemplate bool
+		{
+			_, err := ctlCtx.CloudFormation.GetOutputValue(stackOutputs, key.WorkerLaunchTemplateIDKey)
+			if cloudformationservice.IsOutputNotFound(err) {
+				// Guest clusters created before the introduction of launch templates
+				// still launch their workers using a launch configuration. The
+				// reconciliation detects this and migrates the worker ASG in place.
+				workerLaunchTemplate = false
+			} else if err != nil {
+				return StackState{}, microerror.Mask(err)
+			} else {
+				workerLaunchTemplate = true
+			}
+		}
+
+		var workerInstanceTypes []string
+		var workerOnDemandBaseCapacity int
+		var workerSpotAllocationStrategy string
+		var workerSpotPercentage int
+		{
+			v, err := ctlCtx.CloudFormation.GetOutputValue(stackOutputs, key.WorkerInstanceTypesKey)
+			if cloudformationservice.IsOutputNotFound(err) {
+				// The mixed instances policy outputs are only present in case the
+				// workers are launched using a mixed instances policy. Otherwise the
+				// workers are launched as on-demand instances of the worker instance
+				// type.
+			} else if err != nil {
+				return StackState{}, microerror.Mask(err)
+			} else {
+				workerInstanceTypes = strings.Split(v, ",")
+
+				v, err := ctlCtx.CloudFormation.GetOutputValue(stackOutputs, key.WorkerOnDemandBaseCapacityKey)
+				if err != nil {
+					return StackState{}, microerror.Mask(err)
+				}
+				workerOnDemandBaseCapacity, err = strconv.Atoi(v)
+				if err != nil {
+					return StackState{}, microerror.Mask(err)
+				}
+
+				workerSpotAllocationStrategy, err = ctlCtx.CloudFormation.GetOutputValue(stackOutputs, key.WorkerSpotAllocationStrategyKey)
+				if err != nil {
+					return StackState{}, microerror.Mask(err)
+				}
+
+				v, err = ctlCtx.CloudFormation.GetOutputValue(stackOutputs, key.WorkerSpotPercentageKey)
+				if err != nil {
+					return StackState{}, microerror.Mask(err)
+				}
+				workerSpotPercentage, err = strconv.Atoi(v)
+				if err != nil {
+					return StackState{}, microerror.Mask(err)
+				}
+			}
+		}
+
+		var workerMaxSize int
+		var workerMinSize int
+		{
+			v, err := ctlCtx.CloudFormation.GetOutputValue(stackOutputs, key.WorkerMaxSizeKey)
+			if cloudformationservice.IsOutputNotFound(err) {
+				// The scaling bounds of the worker ASG are only present in case the
+				// worker nodes are scaled by the cluster autoscaler.
+			} else if err != nil {
+				return StackState{}, microerror.Mask(err)
+			} else {
+				workerMaxSize, err = strconv.Atoi(v)
+				if err != nil {
+					return StackState{}, microerror.Mask(err)
+				}
+
+				v, err := ctlCtx.CloudFormation.GetOutputValue(stackOutputs, key.WorkerMinSizeKey)
+				if err != nil {
+					return StackState{}, microerror.Mask(err)
+				}
+				workerMinSize, err = strconv.Atoi(v)
+				if err != nil {
+					return StackState{}, microerror.Mask(err)
+				}
+			}
+		}
+
+		var nodePools []StackStateNodePool
+		{
+			// Node pools are not known upfront. They are discovered using the ASG
+			// name outputs each node pool adds to the stack outputs.
+			for _, o := range stackOutputs {
+				name := key.NodePoolNameFromASGKey(*o.OutputKey)
+				if name == "" {
+					continue
+				}
+
+				nodePool, err := getNodePoolState(ctlCtx.CloudFormation, stackOutputs, name)
+				if err != nil {
+					return StackState{}, microerror.Mask(err)
+				}
+
+				nodePools = append(nodePools, nodePool)
+			}
+		}
+
+		encryptionKeyID, err := ctlCtx.CloudFormation.GetOutputValue(stackOutputs, key.EncryptionKeyIDKey)
+		if cloudformationservice.IsOutputNotFound(err) {
+			// The encryption key ID is only present in case the guest cluster was
+			// re-keyed.
+			encryptionKeyID = ""
+		} else if err != nil {
+			return StackState{}, microerror.Mask(err)
+		}
+
+		// Guest clusters created before all EBS volumes got encrypted do not have
+		// the KMS key ARNs of their volumes in their CF stack outputs. Their
+		// volumes get encrypted with the next update, except for the etcd
+		// volumes, which keep their encryption.
+		etcdVolumeEncryptionKeyARN, err := ctlCtx.CloudFormation.GetOutputValue(stackOutputs, key.EtcdVolumeEncryptionKeyARNKey)
+		if cloudformationservice.IsOutputNotFound(err) {
+			etcdVolumeEncryptionKeyARN = ""
+		} else if err != nil {
+			return StackState{}, microerror.Mask(err)
+		}
+		volumeEncryptionKeyARN, err := ctlCtx.CloudFormation.GetOutputValue(stackOutputs, key.VolumeEncryptionKeyARNKey)
+		if cloudformationservice.IsOutputNotFound(err) {
+			volumeEncryptionKeyARN = ""
+		} else if err != nil {
+			return StackState{}, microerror.Mask(err)
+		}
+
+		// The ID of the VPC is only present in case the guest cluster is deployed
+		// into an existing VPC.
+		vpcID, err := ctlCtx.CloudFormation.GetOutputValue(stackOutputs, key.VPCIDKey)
+		if cloudformationservice.IsOutputNotFound(err) {
+			vpcID = ""
+		} else if err != nil {
+			return StackState{}, microerror.Mask(err)
+		}
+
+		// The ID of the Transit Gateway is only present in case the guest
+		// cluster VPC is attached to a Transit Gateway. Guest clusters created
+		// before that are peered with the host cluster VPC.
+		transitGatewayID, err := ctlCtx.CloudFormation.GetOutputValue(stackOutputs, key.TransitGatewayIDKey)
+		if cloudformationservice.IsOutputNotFound(err) {
+			transitGatewayID = ""
+		} else if err != nil {
+			return StackState{}, microerror.Mask(err)
+		}
+
+		// The NAT gateway strategy is only present for guest clusters created
+		// after the strategy became configurable. All guest clusters created
+		// before got a NAT gateway with a new elastic IP in each availability
+		// zone.
+		natGatewayStrategy, err := ctlCtx.CloudFormation.GetOutputValue(stackOutputs, key.NATGatewayStrategyKey)
+		if cloudformationservice.IsOutputNotFound(err) {
+			natGatewayStrategy = key.NATGatewayStrategyPerAZ
+		} else if err != nil {
+			return StackState{}, microerror.Mask(err)
+		}
+		var natGatewayAllocationIDs []string
+		{
+			v, err := ctlCtx.CloudFormation.GetOutputValue(stackOutputs, key.NATGatewayAllocationIDsKey)
+			if cloudformationservice.IsOutputNotFound(err) {
+				// The allocation IDs are only present in case the NAT gateways use
+				// pre-allocated elastic IPs.
+			} else if err != nil {
+				return StackState{}, microerror.Mask(err)
+			} else {
+				natGatewayAllocationIDs = strings.Split(v, ",")
+			}
+		}
+
+		var apiWhitelistCIDRs []string
+		{
+			v, err := ctlCtx.CloudFormation.GetOutputValue(stackOutputs, key.APIWhitelistCIDRsKey)
+			if cloudformationservice.IsOutputNotFound(err) {
+				// The whitelisted CIDRs are only present in case the guest cluster
+				// whitelists CIDRs of its own.
+			} else if err != nil {
+				return StackState{}, microerror.Mask(err)
+			} else {
+				apiWhitelistCIDRs = strings.Split(v, ",")
+			}
+		}
+		var ingressWhitelistCIDRs []string
+		{
+			v, err := ctlCtx.CloudFormation.GetOutputValue(stackOutputs, key.IngressWhitelistCIDRsKey)
+			if cloudformationservice.IsOutputNotFound(err) {
+				// The ingress load balancer is reachable from anywhere in case
+				// there are no whitelisted CIDRs.
+			} else if err != nil {
+				return StackState{}, microerror.Mask(err)
+			} else {
+				ingressWhitelistCIDRs = strings.Split(v, ",")
+			}
+		}
+
 		versionBundleVersion, err := ctlCtx.CloudFormation.GetOutputValue(stackOutputs, key.VersionBundleVersionKey)
 		if cloudformationservice.IsOutputNotFound(err) {
 			// Since we are transitioning between versions we will have situations in
@@ -249,22 +484,78 @@ func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) (interf
 			return StackState{}, microerror.Mask(err)
 		}
 
+		var masters []StackStateMaster
+		if masterCount > 1 {
+			for i := 0; i < masterCount; i++ {
+				master, err := getMasterState(ctlCtx.CloudFormation, stackOutputs, i)
+				if cloudformationservice.IsOutputNotFound(err) {
+					// Guest clusters created before the masters of highly available
+					// control planes got replaced one after another do not have the
+					// state of their masters in their CF stack outputs. All their
+					// masters got launched with the configuration of the guest
+					// cluster.
+					master = StackStateMaster{
+						CloudConfigVersion:       masterCloudConfigVersion,
+						DockerVolumeResourceName: key.MasterResourceName(dockerVolumeResourceName, i),
+						EncryptionKeyID:          encryptionKeyID,
+						ImageID:                  masterImageID,
+						InstanceResourceName:     key.MasterResourceName(masterInstanceResourceName, i),
+						InstanceType:             masterInstanceType,
+						VersionBundleVersion:     versionBundleVersion,
+						VolumeEncryptionKeyARN:   volumeEncryptionKeyARN,
+					}
+				} else if err != nil {
+					return StackState{}, microerror.Mask(err)
+				}
+
+				masters = append(masters, master)
+			}
+		}
+
 		currentState = StackState{
 			Name: stackName,
 
 			HostedZoneNameServers: hostedZoneNameServers,
 
+			EncryptionKeyID: encryptionKeyID,
+
+			EtcdVolumeEncryptionKeyARN: etcdVolumeEncryptionKeyARN,
+			VolumeEncryptionKeyARN:     volumeEncryptionKeyARN,
+
+			VPCID: vpcID,
+
+			TransitGatewayID: transitGatewayID,
+
+			NATGatewayAllocationIDs: natGatewayAllocationIDs,
+			NATGatewayStrategy:      natGatewayStrategy,
+
+			APIWhitelistCIDRs:     apiWhitelistCIDRs,
+			IngressWhitelistCIDRs: ingressWhitelistCIDRs,
+
 			DockerVolumeResourceName:   dockerVolumeResourceName,
+			MasterCount:                masterCount,
 			MasterImageID:              masterImageID,
 			MasterInstanceResourceName: masterInstanceResourceName,
 			MasterInstanceType:         masterInstanceType,
 			MasterCloudConfigVersion:   masterCloudConfigVersion,
+			Masters:                    masters,
 
 			WorkerCount:              workerCount,
 			WorkerDockerVolumeSizeGB: workerDockerVolumeSizeGB,
 			WorkerImageID:            workerImageID,
 			WorkerInstanceType:       workerInstanceType,
 			WorkerCloudConfigVersion: workerCloudConfigVersion,
+			WorkerLaunchTemplate:     workerLaunchTemplate,
+
+			WorkerInstanceTypes:          workerInstanceTypes,
+			WorkerOnDemandBaseCapacity:   workerOnDemandBaseCapacity,
+			WorkerSpotAllocationStrategy: workerSpotAllocationStrategy,
+			WorkerSpotPercentage:         workerSpotPercentage,
+
+			WorkerMaxSize: workerMaxSize,
+			WorkerMinSize: workerMinSize,
+
+			NodePools: nodePools,
 
 			VersionBundleVersion: versionBundleVersion,
 		}
@@ -272,3 +563,72 @@ func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) (interf
 
 	return currentState, nil
 }
+
+func getMasterState(cf cloudformationservice.CloudFormation, stackOutputs []*cloudformation.Output, idx int) (StackStateMaster, error) {
+	v, err := cf.GetOutputValue(stackOutputs, key.MasterOutputKey(idx))
+	if err != nil {
+		return StackStateMaster{}, microerror.Mask(err)
+	}
+
+	values := key.MasterOutputValues(v)
+
+	master := StackStateMaster{
+		CloudConfigVersion:       values[key.MasterCloudConfigVersionOutputKey],
+		DockerVolumeResourceName: values[key.MasterDockerVolumeResourceNameOutputKey],
+		EncryptionKeyID:          values[key.MasterEncryptionKeyIDOutputKey],
+		ImageID:                  values[key.MasterImageIDOutputKey],
+		InstanceResourceName:     values[key.MasterInstanceResourceNameOutputKey],
+		InstanceType:             values[key.MasterInstanceTypeOutputKey],
+		VersionBundleVersion:     values[key.MasterVersionBundleVersionOutputKey],
+		VolumeEncryptionKeyARN:   values[key.MasterVolumeEncryptionKeyARNOutputKey],
+	}
+
+	return master, nil
+}
+
+func getNodePoolState(cf cloudformationservice.CloudFormation, stackOutputs []*cloudformation.Output, name string) (StackStateNodePool, error) {
+	get := func(k string) (string, error) {
+		return cf.GetOutputValue(stackOutputs, key.NodePoolOutputKey(name, k))
+	}
+	getInt := func(k string) (int, error) {
+		v, err := get(k)
+		if err != nil {
+			return 0, microerror.Mask(err)
+		}
+
+		return strconv.Atoi(v)
+	}
+
+	cloudConfig, err := get(key.NodePoolCloudConfigKey)
+	if err != nil {
+		return StackStateNodePool{}, microerror.Mask(err)
+	}
+	dockerVolumeSizeGB, err := getInt(key.NodePoolDockerVolumeSizeKey)
+	if err != nil {
+		return StackStateNodePool{}, microerror.Mask(err)
+	}
+	instanceType, err := get(key.NodePoolInstanceTypeKey)
+	if err != nil {
+		return StackStateNodePool{}, microerror.Mask(err)
+	}
+	maxSize, err := getInt(key.NodePoolMaxSizeKey)
+	if err != nil {
+		return StackStateNodePool{}, microerror.Mask(err)
+	}
+	minSize, err := getInt(key.NodePoolMinSizeKey)
+	if err != nil {
+		return StackStateNodePool{}, microerror.Mask(err)
+	}
+
+	nodePool := StackStateNodePool{
+		Name: name,
+
+		CloudConfig:        cloudConfig,
+		DockerVolumeSizeGB: dockerVolumeSizeGB,
+		InstanceType:       instanceType,
+		MaxSize:            maxSize,
+		MinSize:            minSize,
+	}
+
+	return nodePool, nil
+}
@@ -45,9 +45,19 @@ func (r *Resource) ApplyDeleteChange(ctx context.Context, obj, deleteChange inte
 		} else if err != nil {
 			return microerror.Mask(err)
 		} else {
+			resourcesToRetain, err := r.resourcesToRetain(ctx, customObject)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+
 			i := &cloudformation.DeleteStackInput{
 				StackName: stackName,
 			}
+			// Resources can only be retained when deleting stacks in
+			// DELETE_FAILED state.
+			if len(resourcesToRetain) > 0 {
+				i.RetainResources = aws.StringSlice(resourcesToRetain)
+			}
 			_, err = sc.AWSClient.CloudFormation.DeleteStack(i)
 			if err != nil {
 				return microerror.Mask(err)
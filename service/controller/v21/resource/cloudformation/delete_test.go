@@ -5,7 +5,9 @@ import (
 	"testing"
 
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	versionedfake "github.com/giantswarm/apiextensions/pkg/clientset/versioned/fake"
 	"github.com/giantswarm/micrologger/microloggertest"
+	"k8s.io/client-go/kubernetes/fake"
 
 	"github.com/giantswarm/aws-operator/service/controller/v21/adapter"
 )
@@ -88,6 +90,10 @@ func Test_Resource_Cloudformation_newDelete(t *testing.T) {
 	{
 		c := Config{}
 
+		c.AMICatalog = &AMICatalogMock{}
+		c.EtcdMember = &EtcdMemberMock{}
+		c.G8sClient = versionedfake.NewSimpleClientset()
+		c.K8sClient = fake.NewSimpleClientset()
 		c.HostClients = &adapter.Clients{}
 		c.Logger = microloggertest.New()
 		c.EncrypterBackend = "kms"
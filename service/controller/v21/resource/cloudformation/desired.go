@@ -6,6 +6,7 @@ import (
 
 	"github.com/giantswarm/microerror"
 
+	"github.com/giantswarm/aws-operator/service/controller/v21/controllercontext"
 	"github.com/giantswarm/aws-operator/service/controller/v21/key"
 )
 
@@ -15,11 +16,16 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 		return nil, microerror.Mask(err)
 	}
 
+	controllerCtx, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
 	var mainStack StackState
 	{
 		r.logger.LogCtx(ctx, "level", "debug", "message", "computing desired state for the guest cluster main stack")
 
-		imageID, err := key.ImageID(customObject)
+		imageID, err := r.amiCatalog.ImageID(ctx, customObject)
 		if err != nil {
 			return StackState{}, microerror.Mask(err)
 		}
@@ -39,7 +45,23 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 		mainStack = StackState{
 			Name: key.MainGuestStackName(customObject),
 
+			EncryptionKeyID: key.EncryptionKeyID(customObject),
+
+			EtcdVolumeEncryptionKeyARN: controllerCtx.Status.Cluster.VolumeEncryption.KeyARN,
+			VolumeEncryptionKeyARN:     controllerCtx.Status.Cluster.VolumeEncryption.KeyARN,
+
+			VPCID: key.VPCID(customObject),
+
+			TransitGatewayID: r.transitGatewayID,
+
+			NATGatewayAllocationIDs: key.NATGatewayAllocationIDs(customObject),
+			NATGatewayStrategy:      key.NATGatewayStrategy(customObject),
+
+			APIWhitelistCIDRs:     key.APIWhitelistCIDRs(customObject),
+			IngressWhitelistCIDRs: key.IngressWhitelistCIDRs(customObject),
+
 			DockerVolumeResourceName:   key.DockerVolumeResourceName(customObject),
+			MasterCount:                key.MasterCount(customObject),
 			MasterImageID:              imageID,
 			MasterInstanceResourceName: key.MasterInstanceResourceName(customObject),
 			MasterInstanceType:         masterInstanceType,
@@ -52,12 +74,65 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 			WorkerInstanceMonitoring: r.monitoring,
 			WorkerInstanceType:       workerInstanceType,
 			WorkerCloudConfigVersion: key.CloudConfigVersion,
+			WorkerLaunchTemplate:     true,
 
 			VersionBundleVersion: key.VersionBundleVersion(customObject),
 		}
 
+		if key.WorkerCount(customObject) > 0 && key.WorkerMixedInstances(customObject) {
+			mainStack.WorkerInstanceTypes = key.WorkerInstanceTypes(customObject)
+			mainStack.WorkerOnDemandBaseCapacity = key.WorkerOnDemandBaseCapacity(customObject)
+			mainStack.WorkerSpotAllocationStrategy = key.WorkerSpotAllocationStrategy(customObject)
+			mainStack.WorkerSpotPercentage = key.WorkerSpotPercentage(customObject)
+		}
+
+		if key.ClusterAutoscalerEnabled(customObject) {
+			mainStack.WorkerMaxSize = key.ScalingMax(customObject)
+			mainStack.WorkerMinSize = key.ScalingMin(customObject)
+		}
+
+		mainStack.Masters = desiredMasters(mainStack)
+
+		for _, p := range key.NodePools(customObject) {
+			mainStack.NodePools = append(mainStack.NodePools, StackStateNodePool{
+				Name: p.Name,
+
+				CloudConfig:        key.NodePoolCloudConfigRole(p),
+				DockerVolumeSizeGB: key.NodePoolDockerVolumeSizeGB(p),
+				InstanceType:       p.InstanceType,
+				MaxSize:            p.MaxSize,
+				MinSize:            p.MinSize,
+			})
+		}
+
 		r.logger.LogCtx(ctx, "level", "debug", "message", "computed desired state for the guest cluster main stack")
 	}
 
 	return mainStack, nil
 }
+
+// desiredMasters returns the desired state of the individual masters of a
+// highly available control plane. These masters are replaced one after another
+// and get their own resources for that reason. Guest clusters running a single
+// master do not track the state of their master separately.
+func desiredMasters(stackState StackState) []StackStateMaster {
+	if stackState.MasterCount < 2 {
+		return nil
+	}
+
+	var masters []StackStateMaster
+	for i := 0; i < stackState.MasterCount; i++ {
+		masters = append(masters, StackStateMaster{
+			CloudConfigVersion:       stackState.MasterCloudConfigVersion,
+			DockerVolumeResourceName: key.MasterResourceName(stackState.DockerVolumeResourceName, i),
+			EncryptionKeyID:          stackState.EncryptionKeyID,
+			ImageID:                  stackState.MasterImageID,
+			InstanceResourceName:     key.MasterResourceName(stackState.MasterInstanceResourceName, i),
+			InstanceType:             stackState.MasterInstanceType,
+			VersionBundleVersion:     stackState.VersionBundleVersion,
+			VolumeEncryptionKeyARN:   stackState.VolumeEncryptionKeyARN,
+		})
+	}
+
+	return masters
+}
@@ -5,17 +5,21 @@ import (
 	"testing"
 
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	versionedfake "github.com/giantswarm/apiextensions/pkg/clientset/versioned/fake"
 	"github.com/giantswarm/micrologger/microloggertest"
+	"k8s.io/client-go/kubernetes/fake"
 
 	"github.com/giantswarm/aws-operator/service/controller/v21/adapter"
+	"github.com/giantswarm/aws-operator/service/controller/v21/controllercontext"
 )
 
 func Test_Resource_Cloudformation_GetDesiredState(t *testing.T) {
 	t.Parallel()
 	testCases := []struct {
-		obj          interface{}
-		expectedName string
-		description  string
+		obj                            interface{}
+		expectedName                   string
+		expectedVolumeEncryptionKeyARN string
+		description                    string
 	}{
 		{
 			description: "CloudFormation gets name from custom object",
@@ -30,7 +34,8 @@ func Test_Resource_Cloudformation_GetDesiredState(t *testing.T) {
 					},
 				},
 			},
-			expectedName: "cluster-5xchu-guest-main",
+			expectedName:                   "cluster-5xchu-guest-main",
+			expectedVolumeEncryptionKeyARN: "arn:aws:kms:eu-central-1:000000000000:key/test-key",
 		},
 	}
 
@@ -39,6 +44,10 @@ func Test_Resource_Cloudformation_GetDesiredState(t *testing.T) {
 	{
 		c := Config{}
 
+		c.AMICatalog = &AMICatalogMock{}
+		c.EtcdMember = &EtcdMemberMock{}
+		c.G8sClient = versionedfake.NewSimpleClientset()
+		c.K8sClient = fake.NewSimpleClientset()
 		c.HostClients = &adapter.Clients{}
 		c.Logger = microloggertest.New()
 		c.EncrypterBackend = "kms"
@@ -51,7 +60,9 @@ func Test_Resource_Cloudformation_GetDesiredState(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.description, func(t *testing.T) {
-			ctx := context.TODO()
+			c := controllercontext.Context{}
+			c.Status.Cluster.VolumeEncryption.KeyARN = "arn:aws:kms:eu-central-1:000000000000:key/test-key"
+			ctx := controllercontext.NewContext(context.TODO(), c)
 
 			result, err := newResource.GetDesiredState(ctx, tc.obj)
 			if err != nil {
@@ -66,6 +77,9 @@ func Test_Resource_Cloudformation_GetDesiredState(t *testing.T) {
 			if tc.expectedName != desiredStack.Name {
 				t.Fatalf("expected cloudformation name '%s' got '%s'", tc.expectedName, desiredStack.Name)
 			}
+			if tc.expectedVolumeEncryptionKeyARN != desiredStack.VolumeEncryptionKeyARN {
+				t.Fatalf("expected volume encryption key ARN '%s' got '%s'", tc.expectedVolumeEncryptionKeyARN, desiredStack.VolumeEncryptionKeyARN)
+			}
 		})
 	}
 }
@@ -0,0 +1,87 @@
+package cloudformation
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/aws-operator/service/controller/v21/controllercontext"
+	"github.com/giantswarm/aws-operator/service/controller/v21/key"
+)
+
+// publishEgressIPs writes the public IPs of the available NAT gateways of the
+// tenant cluster to the CR status, so that customers are able to whitelist the
+// egress of the tenant cluster. The CR status is only updated in case the
+// egress IPs changed.
+func (r *Resource) publishEgressIPs(ctx context.Context, customObject v1alpha1.AWSConfig) error {
+	ctlCtx, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	var egressIPs []string
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "finding egress IPs of the NAT gateways")
+
+		i := &ec2.DescribeNatGatewaysInput{
+			Filter: []*ec2.Filter{
+				{
+					Name:   aws.String(fmt.Sprintf("tag:%s", key.ClusterTagName)),
+					Values: aws.StringSlice([]string{key.ClusterID(customObject)}),
+				},
+				{
+					Name:   aws.String("state"),
+					Values: aws.StringSlice([]string{ec2.NatGatewayStateAvailable}),
+				},
+			},
+		}
+		o, err := ctlCtx.AWSClient.EC2.DescribeNatGateways(i)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, gw := range o.NatGateways {
+			for _, a := range gw.NatGatewayAddresses {
+				if a.PublicIp != nil {
+					egressIPs = append(egressIPs, *a.PublicIp)
+				}
+			}
+		}
+		sort.Strings(egressIPs)
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found %d egress IPs of the NAT gateways", len(egressIPs)))
+	}
+
+	current := customObject.Status.AWS.NATGateway.EgressIPs
+	if len(current) == 0 && len(egressIPs) == 0 || reflect.DeepEqual(current, egressIPs) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "CR status already contains egress IPs")
+		return nil
+	}
+
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "updating CR status with egress IPs")
+
+		newObj, err := r.g8sClient.ProviderV1alpha1().AWSConfigs(customObject.GetNamespace()).Get(customObject.GetName(), metav1.GetOptions{})
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		newObj.Status.AWS.NATGateway.EgressIPs = egressIPs
+
+		_, err = r.g8sClient.ProviderV1alpha1().AWSConfigs(newObj.GetNamespace()).UpdateStatus(newObj)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "updated CR status with egress IPs")
+	}
+
+	return nil
+}
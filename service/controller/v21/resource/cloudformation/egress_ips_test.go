@@ -0,0 +1,85 @@
+package cloudformation
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	versionedfake "github.com/giantswarm/apiextensions/pkg/clientset/versioned/fake"
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	awsclient "github.com/giantswarm/aws-operator/client/aws"
+	"github.com/giantswarm/aws-operator/service/controller/v21/controllercontext"
+)
+
+func Test_Resource_Cloudformation_publishEgressIPs(t *testing.T) {
+	t.Parallel()
+	customObject := &v1alpha1.AWSConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.AWSConfigSpec{
+			Cluster: v1alpha1.Cluster{
+				ID: "test-cluster",
+			},
+		},
+	}
+
+	g8sClient := versionedfake.NewSimpleClientset(customObject)
+
+	r := &Resource{
+		g8sClient: g8sClient,
+		logger:    microloggertest.New(),
+	}
+
+	awsClients := awsclient.Clients{
+		EC2: &EC2NATGatewaysMock{
+			natGateways: []*ec2.NatGateway{
+				{
+					NatGatewayAddresses: []*ec2.NatGatewayAddress{
+						{PublicIp: aws.String("52.0.0.2")},
+					},
+				},
+				{
+					NatGatewayAddresses: []*ec2.NatGatewayAddress{
+						{PublicIp: aws.String("52.0.0.1")},
+					},
+				},
+			},
+		},
+	}
+
+	ctx := controllercontext.NewContext(context.TODO(), controllercontext.Context{AWSClient: awsClients})
+
+	err := r.publishEgressIPs(ctx, *customObject)
+	if err != nil {
+		t.Fatalf("expected nil got %#v", err)
+	}
+
+	updated, err := g8sClient.ProviderV1alpha1().AWSConfigs("default").Get("test-cluster", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected nil got %#v", err)
+	}
+
+	expected := []string{"52.0.0.1", "52.0.0.2"}
+	if !reflect.DeepEqual(updated.Status.AWS.NATGateway.EgressIPs, expected) {
+		t.Fatalf("expected %v got %v", expected, updated.Status.AWS.NATGateway.EgressIPs)
+	}
+
+	// Publishing the same egress IPs again must not update the CR status.
+	actions := len(g8sClient.Actions())
+
+	err = r.publishEgressIPs(ctx, *updated)
+	if err != nil {
+		t.Fatalf("expected nil got %#v", err)
+	}
+
+	if len(g8sClient.Actions()) != actions {
+		t.Fatalf("expected %d actions got %d", actions, len(g8sClient.Actions()))
+	}
+}
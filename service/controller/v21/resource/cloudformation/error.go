@@ -30,6 +30,29 @@ func IsAlreadyExists(err error) bool {
 	return false
 }
 
+var changeSetNotFoundError = &microerror.Error{
+	Kind: "changeSetNotFoundError",
+}
+
+// IsChangeSetNotFound asserts changeSetNotFoundError.
+func IsChangeSetNotFound(err error) bool {
+	c := microerror.Cause(err)
+
+	if c == nil {
+		return false
+	}
+
+	if strings.Contains(c.Error(), cloudformation.ErrCodeChangeSetNotFoundException) {
+		return true
+	}
+
+	if c == changeSetNotFoundError {
+		return true
+	}
+
+	return false
+}
+
 var deleteInProgressError = &microerror.Error{
 	Kind: "deleteInProgressError",
 }
@@ -0,0 +1,47 @@
+package cloudformation
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// emitEvent creates a Kubernetes event for the given AWSConfig CR so that
+// users see what the resource does with the tenant cluster main stack.
+func (r *Resource) emitEvent(customObject v1alpha1.AWSConfig, eventType, reason, message string) error {
+	now := metav1.NewTime(time.Now())
+	e := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", customObject.GetName(), now.UnixNano()),
+			Namespace: customObject.GetNamespace(),
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion:      "provider.giantswarm.io/v1alpha1",
+			Kind:            "AWSConfig",
+			Name:            customObject.GetName(),
+			Namespace:       customObject.GetNamespace(),
+			ResourceVersion: customObject.GetResourceVersion(),
+			UID:             customObject.GetUID(),
+		},
+		Count:          1,
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Message:        message,
+		Reason:         reason,
+		Source: corev1.EventSource{
+			Component: "aws-operator",
+		},
+		Type: eventType,
+	}
+
+	_, err := r.k8sClient.CoreV1().Events(customObject.GetNamespace()).Create(e)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
@@ -39,19 +39,36 @@ func (r *Resource) getMainGuestTemplateBody(ctx context.Context, customObject v1
 		Clients:           adapterClients,
 		EncrypterBackend:  r.encrypterBackend,
 		HostClients:       *r.hostClients,
+		IngressNLB:        r.ingressNLB,
 		InstallationName:  r.installationName,
 		HostAccountID:     hostAccountID,
 		PublicRouteTables: r.publicRouteTables,
 		Route53Enabled:    r.route53Enabled,
+		TransitGatewayID:  stackState.TransitGatewayID,
 		StackState: adapter.StackState{
 			Name: stackState.Name,
 
+			EncryptionKeyID: stackState.EncryptionKeyID,
+
+			EtcdVolumeEncryptionKeyARN: stackState.EtcdVolumeEncryptionKeyARN,
+			VolumeEncryptionKeyARN:     stackState.VolumeEncryptionKeyARN,
+
+			VPCID: stackState.VPCID,
+
+			NATGatewayAllocationIDs: stackState.NATGatewayAllocationIDs,
+			NATGatewayStrategy:      stackState.NATGatewayStrategy,
+
+			APIWhitelistCIDRs:     stackState.APIWhitelistCIDRs,
+			IngressWhitelistCIDRs: stackState.IngressWhitelistCIDRs,
+
 			DockerVolumeResourceName:   stackState.DockerVolumeResourceName,
+			MasterCount:                stackState.MasterCount,
 			MasterImageID:              stackState.MasterImageID,
 			MasterInstanceResourceName: stackState.MasterInstanceResourceName,
 			MasterInstanceType:         stackState.MasterInstanceType,
 			MasterCloudConfigVersion:   stackState.MasterCloudConfigVersion,
 			MasterInstanceMonitoring:   stackState.MasterInstanceMonitoring,
+			Masters:                    adapterMasters(stackState.Masters),
 
 			WorkerCount:              stackState.WorkerCount,
 			WorkerDockerVolumeSizeGB: stackState.WorkerDockerVolumeSizeGB,
@@ -60,8 +77,22 @@ func (r *Resource) getMainGuestTemplateBody(ctx context.Context, customObject v1
 			WorkerInstanceType:       stackState.WorkerInstanceType,
 			WorkerCloudConfigVersion: stackState.WorkerCloudConfigVersion,
 
+			WorkerInstanceTypes:          stackState.WorkerInstanceTypes,
+			WorkerOnDemandBaseCapacity:   stackState.WorkerOnDemandBaseCapacity,
+			WorkerSpotAllocationStrategy: stackState.WorkerSpotAllocationStrategy,
+			WorkerSpotPercentage:         stackState.WorkerSpotPercentage,
+
+			WorkerMaxSize: stackState.WorkerMaxSize,
+			WorkerMinSize: stackState.WorkerMinSize,
+
+			NodePools: adapterNodePools(stackState.NodePools),
+
 			VersionBundleVersion: stackState.VersionBundleVersion,
 		},
+		MetadataTokensRequired:   r.metadataTokensRequired,
+		VPCEndpointsEnabled:      r.vpcEndpointsEnabled,
+		VPCFlowLogs:              r.vpcFlowLogs,
+		WorkerRolloutHealthGated: r.workerRolloutHealthGated,
 	}
 
 	adp, err := adapter.NewGuest(cfg)
@@ -77,6 +108,43 @@ func (r *Resource) getMainGuestTemplateBody(ctx context.Context, customObject v1
 	return rendered, nil
 }
 
+func adapterMasters(masters []StackStateMaster) []adapter.StackStateMaster {
+	var adapterMasters []adapter.StackStateMaster
+
+	for _, m := range masters {
+		adapterMasters = append(adapterMasters, adapter.StackStateMaster{
+			CloudConfigVersion:       m.CloudConfigVersion,
+			DockerVolumeResourceName: m.DockerVolumeResourceName,
+			EncryptionKeyID:          m.EncryptionKeyID,
+			ImageID:                  m.ImageID,
+			InstanceResourceName:     m.InstanceResourceName,
+			InstanceType:             m.InstanceType,
+			VersionBundleVersion:     m.VersionBundleVersion,
+			VolumeEncryptionKeyARN:   m.VolumeEncryptionKeyARN,
+		})
+	}
+
+	return adapterMasters
+}
+
+func adapterNodePools(nodePools []StackStateNodePool) []adapter.StackStateNodePool {
+	var adapterNodePools []adapter.StackStateNodePool
+
+	for _, p := range nodePools {
+		adapterNodePools = append(adapterNodePools, adapter.StackStateNodePool{
+			Name: p.Name,
+
+			CloudConfig:        p.CloudConfig,
+			DockerVolumeSizeGB: p.DockerVolumeSizeGB,
+			InstanceType:       p.InstanceType,
+			MaxSize:            p.MaxSize,
+			MinSize:            p.MinSize,
+		})
+	}
+
+	return adapterNodePools
+}
+
 func (r *Resource) getMainHostPreTemplateBody(ctx context.Context, customObject v1alpha1.AWSConfig) (string, error) {
 	sc, err := controllercontext.FromContext(ctx)
 	if err != nil {
@@ -136,6 +204,7 @@ func (r *Resource) getMainHostPostTemplateBody(ctx context.Context, customObject
 		EncrypterBackend:  r.encrypterBackend,
 		PublicRouteTables: r.publicRouteTables,
 		Route53Enabled:    r.route53Enabled,
+		TransitGatewayID:  guestMainStackState.TransitGatewayID,
 		StackState: adapter.StackState{
 			HostedZoneNameServers: guestMainStackState.HostedZoneNameServers,
 		},
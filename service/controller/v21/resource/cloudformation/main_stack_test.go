@@ -7,8 +7,13 @@ import (
 	"strings"
 	"testing"
 
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	versionedfake "github.com/giantswarm/apiextensions/pkg/clientset/versioned/fake"
 	"github.com/giantswarm/micrologger/microloggertest"
+	"k8s.io/client-go/kubernetes/fake"
 
 	"github.com/giantswarm/aws-operator/client/aws"
 	"github.com/giantswarm/aws-operator/service/controller/v21/adapter"
@@ -19,6 +24,10 @@ import (
 func testConfig() Config {
 	c := Config{}
 
+	c.AMICatalog = &AMICatalogMock{}
+	c.EtcdMember = &EtcdMemberMock{}
+	c.G8sClient = versionedfake.NewSimpleClientset()
+	c.K8sClient = fake.NewSimpleClientset()
 	c.GuestPrivateSubnetMaskBits = 25
 	c.GuestPublicSubnetMaskBits = 25
 	c.HostClients = &adapter.Clients{}
@@ -169,10 +178,7 @@ func TestMainGuestTemplateExistingFields(t *testing.T) {
 		},
 	}
 
-	imageID, err := key.ImageID(customObject)
-	if err != nil {
-		t.Fatalf("expected %#v got %#v", nil, err)
-	}
+	imageID := "ami-0e6601a88a9753474"
 
 	stackState := StackState{
 		Name: key.MainGuestStackName(customObject),
@@ -227,8 +233,12 @@ func TestMainGuestTemplateExistingFields(t *testing.T) {
 		t.Fatal("stack header not found")
 	}
 
-	if !strings.Contains(body, "  workerLaunchConfiguration:") {
-		t.Fatal("launch configuration header not found")
+	if !strings.Contains(body, "  workerLaunchTemplate:") {
+		t.Fatal("launch template header not found")
+	}
+
+	if !strings.Contains(body, "LaunchTemplateId: !Ref workerLaunchTemplate") {
+		t.Fatal("asg launch template reference not found")
 	}
 
 	if !strings.Contains(body, "  workerAutoScalingGroup:") {
@@ -236,7 +246,7 @@ func TestMainGuestTemplateExistingFields(t *testing.T) {
 	}
 
 	if !strings.Contains(body, "InstanceType: m3.large") {
-		t.Fatal("launch configuration element not found")
+		t.Fatal("launch template element not found")
 	}
 
 	if !strings.Contains(body, "Outputs:") {
@@ -248,7 +258,7 @@ func TestMainGuestTemplateExistingFields(t *testing.T) {
 		fmt.Println(body)
 		t.Fatal("MasterImageID output element not found")
 	}
-	if !strings.Contains(body, key.MasterInstanceMonitoring+": true") {
+	if !strings.Contains(body, key.MasterInstanceMonitoring+": false") {
 		fmt.Println(body)
 		t.Fatal("MasterInstanceMonitoring output element not found")
 	}
@@ -276,7 +286,19 @@ func TestMainGuestTemplateExistingFields(t *testing.T) {
 		fmt.Println(body)
 		t.Fatal("worker CloudConfig version output element not found")
 	}
-	if !strings.Contains(body, key.WorkerInstanceMonitoring+": true") {
+	if !strings.Contains(body, key.WorkerLaunchTemplateIDKey+":") {
+		fmt.Println(body)
+		t.Fatal("WorkerLaunchTemplateID output element not found")
+	}
+	if strings.Contains(body, "MixedInstancesPolicy:") || strings.Contains(body, key.WorkerInstanceTypesKey+":") {
+		fmt.Println(body)
+		t.Fatal("mixed instances policy found for on-demand workers")
+	}
+	if !strings.Contains(body, "DesiredCapacity:") || strings.Contains(body, "k8s.io/cluster-autoscaler/enabled") {
+		fmt.Println(body)
+		t.Fatal("cluster autoscaler configuration found for workers without scaling bounds")
+	}
+	if !strings.Contains(body, key.WorkerInstanceMonitoring+":\n          Enabled: true") {
 		fmt.Println(body)
 		t.Fatal("WorkerInstanceMonitoring output element not found")
 	}
@@ -298,6 +320,10 @@ func TestMainGuestTemplateExistingFields(t *testing.T) {
 		fmt.Println(body)
 		t.Fatal("PolicyName output element not found")
 	}
+	if !strings.Contains(body, "Action: \"s3:PutObject\"") || !strings.Contains(body, "/"+key.EtcdBackupPrefix+"*") {
+		fmt.Println(body)
+		t.Fatal("etcd backup policy element not found")
+	}
 	if !strings.Contains(body, "ApiRecordSet:") {
 		fmt.Println(body)
 		t.Fatal("ApiRecordSet element not found")
@@ -557,6 +583,65 @@ func TestMainHostPostTemplateExistingFields(t *testing.T) {
 	}
 }
 
+func TestMainHostPostTemplateTransitGateway(t *testing.T) {
+	t.Parallel()
+	customObject := v1alpha1.AWSConfig{
+		Spec: v1alpha1.AWSConfigSpec{
+			Cluster: v1alpha1.Cluster{
+				ID: "test-cluster",
+			},
+			AWS: v1alpha1.AWSConfigSpecAWS{
+				VPC: v1alpha1.AWSConfigSpecAWSVPC{
+					RouteTableNames: []string{
+						"route_table_1",
+					},
+					PeerID: "mypeerid",
+				},
+			},
+		},
+		Status: statusWithAllocatedSubnet("10.1.1.0/24", []string{"eu-central-1a"}),
+	}
+
+	cfg := testConfig()
+	ec2Mock := &adapter.EC2ClientMock{}
+	ec2Mock.SetMatchingRouteTables(1)
+	cfg.HostClients = &adapter.Clients{
+		EC2: ec2Mock,
+		IAM: &adapter.IAMClientMock{},
+		STS: &adapter.STSClientMock{},
+	}
+	newResource, err := New(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	awsClients := aws.Clients{
+		EC2: &adapter.EC2ClientMock{},
+		STS: &adapter.STSClientMock{},
+	}
+
+	ctx := context.TODO()
+	ctx = controllercontext.NewContext(ctx, controllercontext.Context{AWSClient: awsClients})
+
+	body, err := newResource.getMainHostPostTemplateBody(ctx, customObject, StackState{TransitGatewayID: "tgw-0123456789abcdef0"})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if !strings.Contains(body, "  PrivateRoute0:") {
+		fmt.Println(body)
+		t.Fatal("route header not found")
+	}
+	if !strings.Contains(body, "      TransitGatewayId: tgw-0123456789abcdef0") {
+		fmt.Println(body)
+		t.Fatal("Transit Gateway route target not found")
+	}
+	if strings.Contains(body, "VpcPeeringConnectionId") {
+		fmt.Println(body)
+		t.Fatal("expected no peering route target")
+	}
+}
+
 func TestMainGuestTemplateRoute53Disabled(t *testing.T) {
 	t.Parallel()
 	// customObject with example fields for both asg and launch config
@@ -611,10 +696,7 @@ func TestMainGuestTemplateRoute53Disabled(t *testing.T) {
 		Status: statusWithAllocatedSubnet("10.1.1.0/24", []string{"eu-central-1a"}),
 	}
 
-	imageID, err := key.ImageID(customObject)
-	if err != nil {
-		t.Fatalf("expected %#v got %#v", nil, err)
-	}
+	imageID := "ami-0e6601a88a9753474"
 
 	stackState := StackState{
 		Name: key.MainGuestStackName(customObject),
@@ -735,10 +817,7 @@ func TestMainGuestTemplateChinaRegion(t *testing.T) {
 		Status: statusWithAllocatedSubnet("10.1.1.0/24", []string{"cn-north-1a"}),
 	}
 
-	imageID, err := key.ImageID(customObject)
-	if err != nil {
-		t.Fatalf("expected %#v got %#v", nil, err)
-	}
+	imageID := "ami-0e6601a88a9753474"
 
 	stackState := StackState{
 		Name: key.MainGuestStackName(customObject),
@@ -793,3 +872,681 @@ func TestMainGuestTemplateChinaRegion(t *testing.T) {
 		t.Fatal("ARN region dependent element not found")
 	}
 }
+
+// testGuestCustomObject returns the custom object of a guest cluster with a
+// single master and a single worker spread over the given availability
+// zones. The guest template feature tests adapt it to the feature under test.
+func testGuestCustomObject(azs ...string) v1alpha1.AWSConfig {
+	customObject := v1alpha1.AWSConfig{
+		Spec: v1alpha1.AWSConfigSpec{
+			Cluster: v1alpha1.Cluster{
+				ID:      "test-cluster",
+				Version: "myversion",
+				Kubernetes: v1alpha1.ClusterKubernetes{
+					API: v1alpha1.ClusterKubernetesAPI{
+						Domain:     "api.domain",
+						SecurePort: 443,
+					},
+					IngressController: v1alpha1.ClusterKubernetesIngressController{
+						Domain:       "ingress.domain",
+						InsecurePort: 30010,
+						SecurePort:   30011,
+					},
+				},
+				Etcd: v1alpha1.ClusterEtcd{
+					Domain: "etcd.domain",
+				},
+			},
+			AWS: v1alpha1.AWSConfigSpecAWS{
+				Region: "eu-central-1",
+				AZ:     "eu-central-1a",
+				Masters: []v1alpha1.AWSConfigSpecAWSNode{
+					{
+						ImageID:      "ami-1234-master",
+						InstanceType: "m3.large",
+					},
+				},
+				Workers: []v1alpha1.AWSConfigSpecAWSNode{
+					{
+						DockerVolumeSizeGB: 150,
+						ImageID:            "ami-1234-worker",
+						InstanceType:       "m5.large",
+					},
+				},
+			},
+		},
+		Status: statusWithAllocatedSubnet("10.1.1.0/24", azs),
+	}
+
+	return customObject
+}
+
+// testGuestStackState returns the stack state of a guest cluster using worker
+// launch templates, the way the desired state computes it for the given
+// custom object.
+func testGuestStackState(customObject v1alpha1.AWSConfig) StackState {
+	imageID := "ami-0e6601a88a9753474"
+
+	stackState := StackState{
+		Name: key.MainGuestStackName(customObject),
+
+		NATGatewayAllocationIDs: key.NATGatewayAllocationIDs(customObject),
+		NATGatewayStrategy:      key.NATGatewayStrategy(customObject),
+
+		DockerVolumeResourceName:   key.DockerVolumeResourceName(customObject),
+		MasterCount:                key.MasterCount(customObject),
+		MasterImageID:              imageID,
+		MasterInstanceResourceName: key.MasterInstanceResourceName(customObject),
+		MasterInstanceType:         key.MasterInstanceType(customObject),
+		MasterCloudConfigVersion:   key.CloudConfigVersion,
+
+		WorkerCount:              strconv.Itoa(key.WorkerCount(customObject)),
+		WorkerDockerVolumeSizeGB: key.WorkerDockerVolumeSizeGB(customObject),
+		WorkerImageID:            imageID,
+		WorkerInstanceType:       key.WorkerInstanceType(customObject),
+		WorkerCloudConfigVersion: key.CloudConfigVersion,
+		WorkerLaunchTemplate:     true,
+
+		VersionBundleVersion: key.VersionBundleVersion(customObject),
+	}
+	for _, p := range key.NodePools(customObject) {
+		stackState.NodePools = append(stackState.NodePools, StackStateNodePool{
+			Name: p.Name,
+
+			CloudConfig:        key.NodePoolCloudConfigRole(p),
+			DockerVolumeSizeGB: key.NodePoolDockerVolumeSizeGB(p),
+			InstanceType:       p.InstanceType,
+			MaxSize:            p.MaxSize,
+			MinSize:            p.MinSize,
+		})
+	}
+
+	return stackState
+}
+
+func TestMainGuestTemplateFeatures(t *testing.T) {
+	t.Parallel()
+
+	masterInstanceResourceName := key.MasterInstanceResourceName(testGuestCustomObject())
+
+	var haElements []string
+	haCounts := map[string]int{}
+	for i := 0; i < key.MasterCountHA; i++ {
+		instance := key.MasterResourceName(masterInstanceResourceName, i)
+
+		haElements = append(haElements,
+			"  "+instance+":",
+			"  "+key.EtcdVolumeResourceName(i)+":",
+			"  "+key.EtcdMemberRecordSetName(i)+":",
+		)
+		// The instance is registered with the API and etcd load balancers.
+		haCounts["- !Ref "+instance+"\n"] = 2
+	}
+	haElements = append(haElements, key.MasterCountKey+":\n    Value: 3")
+
+	gpuNodePool := v1alpha1.AWSConfigSpecAWSNodePool{
+		Name:               "gpu",
+		DockerVolumeSizeGB: 200,
+		InstanceType:       "p3.2xlarge",
+		Labels: map[string]string{
+			"gpu": "true",
+		},
+		MaxSize: 4,
+		MinSize: 0,
+		Taints: []v1alpha1.AWSConfigSpecAWSNodePoolTaint{
+			{Effect: "NoSchedule", Key: "gpu", Value: "true"},
+		},
+	}
+
+	keyARN := "arn:aws:kms:eu-central-1:000000000000:key/test-key"
+
+	testCases := []struct {
+		name               string
+		azs                []string
+		customObject       func(customObject *v1alpha1.AWSConfig)
+		stackState         func(customObject v1alpha1.AWSConfig, stackState *StackState)
+		config             func(config *Config)
+		ec2                ec2iface.EC2API
+		expectedElements   []string
+		expectedCounts     map[string]int
+		unexpectedElements []string
+	}{
+		{
+			name: "case 0: highly available masters",
+			azs:  []string{"eu-central-1a", "eu-central-1b", "eu-central-1c"},
+			customObject: func(customObject *v1alpha1.AWSConfig) {
+				master := customObject.Spec.AWS.Masters[0]
+				customObject.Spec.AWS.AvailabilityZones = 3
+				customObject.Spec.AWS.Masters = []v1alpha1.AWSConfigSpecAWSNode{master, master, master}
+			},
+			stackState: func(customObject v1alpha1.AWSConfig, stackState *StackState) {
+				stackState.MasterInstanceResourceName = masterInstanceResourceName
+				stackState.WorkerLaunchTemplate = false
+			},
+			expectedElements: haElements,
+			expectedCounts:   haCounts,
+		},
+		{
+			name: "case 1: worker mixed instances",
+			customObject: func(customObject *v1alpha1.AWSConfig) {
+				customObject.Spec.AWS.WorkerInstanceDistribution = v1alpha1.AWSConfigSpecAWSWorkerInstanceDistribution{
+					InstanceTypes:        []string{"m5.large", "m4.large"},
+					OnDemandBaseCapacity: 1,
+					SpotPercentage:       75,
+				}
+			},
+			stackState: func(customObject v1alpha1.AWSConfig, stackState *StackState) {
+				stackState.WorkerInstanceTypes = key.WorkerInstanceTypes(customObject)
+				stackState.WorkerOnDemandBaseCapacity = key.WorkerOnDemandBaseCapacity(customObject)
+				stackState.WorkerSpotAllocationStrategy = key.WorkerSpotAllocationStrategy(customObject)
+				stackState.WorkerSpotPercentage = key.WorkerSpotPercentage(customObject)
+			},
+			expectedElements: []string{
+				"      MixedInstancesPolicy:",
+				"          OnDemandBaseCapacity: 1",
+				"          OnDemandPercentageAboveBaseCapacity: 25",
+				"          SpotAllocationStrategy: lowest-price",
+				"            LaunchTemplateId: !Ref workerLaunchTemplate",
+				"          - InstanceType: m5.large\n          - InstanceType: m4.large\n",
+				key.WorkerInstanceTypesKey + ":\n    Value: m5.large,m4.large",
+				key.WorkerOnDemandBaseCapacityKey + ":\n    Value: 1",
+				key.WorkerSpotAllocationStrategyKey + ":\n    Value: lowest-price",
+				key.WorkerSpotPercentageKey + ":\n    Value: 75",
+			},
+			unexpectedElements: []string{
+				// The worker ASG must not reference the launch template next to
+				// the mixed instances policy.
+				"      LaunchTemplate:\n        LaunchTemplateId:",
+			},
+		},
+		{
+			name: "case 2: node pools",
+			customObject: func(customObject *v1alpha1.AWSConfig) {
+				customObject.Spec.AWS.NodePools = []v1alpha1.AWSConfigSpecAWSNodePool{
+					gpuNodePool,
+					{
+						Name:         "memory",
+						InstanceType: "r5.xlarge",
+						MaxSize:      4,
+						MinSize:      3,
+					},
+				}
+			},
+			expectedElements: []string{
+				"  workerLaunchTemplate:",
+				"  workerAutoScalingGroup:",
+				"  NodeDrainerLifecycleHook:",
+				"Value: test-cluster-worker\n",
+				"  nodePoolGpuLaunchTemplate:",
+				"        InstanceType: p3.2xlarge",
+				"            VolumeSize: 200",
+				"  nodePoolGpuAutoScalingGroup:",
+				"        LaunchTemplateId: !Ref nodePoolGpuLaunchTemplate",
+				"  nodePoolGpuNodeDrainerLifecycleHook:",
+				"        Ref: nodePoolGpuAutoScalingGroup",
+				"Value: test-cluster-worker-gpu\n",
+				"        MinInstancesInService: 0\n",
+				"  nodePoolMemoryLaunchTemplate:",
+				"        InstanceType: r5.xlarge",
+				"  nodePoolMemoryAutoScalingGroup:",
+				"      MinSize: 3\n      MaxSize: 4\n",
+				"  nodePoolMemoryNodeDrainerLifecycleHook:",
+				"NodePoolGpuASGName:\n    Value: !Ref nodePoolGpuAutoScalingGroup",
+				"NodePoolGpuCloudConfig:\n    Value: " + key.NodePoolCloudConfigRole(gpuNodePool),
+				"NodePoolGpuDockerVolumeSizeGB:\n    Value: 200",
+				"NodePoolGpuInstanceType:\n    Value: p3.2xlarge",
+				"NodePoolGpuMaxSize:\n    Value: 4",
+				"NodePoolGpuMinSize:\n    Value: 0",
+				"NodePoolMemoryASGName:\n    Value: !Ref nodePoolMemoryAutoScalingGroup",
+				"NodePoolMemoryDockerVolumeSizeGB:\n    Value: 100",
+			},
+			expectedCounts: map[string]int{
+				// Only the masters may scale the worker ASGs without the cluster
+				// autoscaler.
+				"\"autoscaling:SetDesiredCapacity\"": 1,
+			},
+		},
+		{
+			name: "case 3: cluster autoscaler",
+			customObject: func(customObject *v1alpha1.AWSConfig) {
+				customObject.Spec.Cluster.Scaling = v1alpha1.ClusterScaling{
+					Max: 10,
+					Min: 3,
+				}
+				customObject.Spec.AWS.NodePools = []v1alpha1.AWSConfigSpecAWSNodePool{
+					{
+						Name:         "memory",
+						InstanceType: "r5.xlarge",
+						MaxSize:      4,
+						MinSize:      1,
+					},
+				}
+			},
+			stackState: func(customObject v1alpha1.AWSConfig, stackState *StackState) {
+				stackState.WorkerMaxSize = key.ScalingMax(customObject)
+				stackState.WorkerMinSize = key.ScalingMin(customObject)
+			},
+			expectedElements: []string{
+				"  workerAutoScalingGroup:\n    Type: \"AWS::AutoScaling::AutoScalingGroup\"",
+				"      MinSize: 3\n      MaxSize: 10\n",
+				"      MinSize: 1\n      MaxSize: 4\n",
+				"        - Key: k8s.io/cluster-autoscaler/enabled\n          Value: \"true\"",
+				"        - Key: k8s.io/cluster-autoscaler/test-cluster\n          Value: owned",
+				key.WorkerMaxSizeKey + ":\n    Value: 10",
+				key.WorkerMinSizeKey + ":\n    Value: 3",
+			},
+			expectedCounts: map[string]int{
+				// The worker ASG and the node pool ASG are discoverable by the
+				// cluster autoscaler.
+				"k8s.io/cluster-autoscaler/enabled": 2,
+				// The cluster autoscaler running on the workers may scale the
+				// worker ASGs next to the masters.
+				"\"autoscaling:SetDesiredCapacity\"": 2,
+			},
+			unexpectedElements: []string{
+				// Worker ASGs scaled by the cluster autoscaler must not set the
+				// desired capacity.
+				"DesiredCapacity:",
+			},
+		},
+		{
+			name: "case 4: network load balancers",
+			azs:  []string{"eu-central-1a", "eu-central-1b"},
+			config: func(config *Config) {
+				config.IngressNLB = adapter.IngressNLB{
+					ProxyProtocol: true,
+				}
+			},
+			customObject: func(customObject *v1alpha1.AWSConfig) {
+				customObject.Spec.Cluster.Etcd.Port = 2379
+				customObject.Spec.AWS.LoadBalancers = v1alpha1.AWSConfigSpecAWSLoadBalancers{
+					API: v1alpha1.AWSConfigSpecAWSLoadBalancer{
+						Type: key.LoadBalancerTypeNetwork,
+					},
+					Etcd: v1alpha1.AWSConfigSpecAWSLoadBalancer{
+						Type: key.LoadBalancerTypeNetwork,
+					},
+					Ingress: v1alpha1.AWSConfigSpecAWSLoadBalancer{
+						Type: key.LoadBalancerTypeNetwork,
+					},
+				}
+				customObject.Spec.AWS.NodePools = []v1alpha1.AWSConfigSpecAWSNodePool{
+					{
+						Name:         "memory",
+						InstanceType: "r5.xlarge",
+						MaxSize:      4,
+						MinSize:      1,
+					},
+				}
+			},
+			expectedElements: []string{
+				"  ApiNetworkLoadBalancer:\n    Type: AWS::ElasticLoadBalancingV2::LoadBalancer",
+				"      Name: test-cluster-api-nlb",
+				"  ApiNetworkLoadBalancerEIP00:\n    Type: AWS::EC2::EIP",
+				"      - AllocationId: !GetAtt ApiNetworkLoadBalancerEIP01.AllocationId\n        SubnetId: !Ref PublicSubnet01",
+				"  ApiListener443:\n    Type: AWS::ElasticLoadBalancingV2::Listener",
+				"      Targets:\n      - Id: !Ref MasterInstance",
+				"      HealthCheckPort: 2379",
+				"  EtcdNetworkLoadBalancer:\n    Type: AWS::ElasticLoadBalancingV2::LoadBalancer",
+				"      Scheme: internal",
+				"  IngressTargetGroup443:\n    Type: AWS::ElasticLoadBalancingV2::TargetGroup",
+				"  IngressTargetGroup80:\n    Type: AWS::ElasticLoadBalancingV2::TargetGroup",
+				"      - Key: proxy_protocol_v2.enabled",
+				"      TargetGroupARNs:\n        - !Ref IngressTargetGroup443\n        - !Ref IngressTargetGroup80",
+				"        HostedZoneId: !GetAtt ApiNetworkLoadBalancer.CanonicalHostedZoneID",
+				"        DNSName: !GetAtt IngressNetworkLoadBalancer.DNSName",
+			},
+			expectedCounts: map[string]int{
+				// The worker ASG and the node pool ASG register with the ingress
+				// target groups.
+				"TargetGroupARNs:": 2,
+			},
+			unexpectedElements: []string{
+				"AWS::ElasticLoadBalancing::LoadBalancer",
+				"LoadBalancerNames:",
+			},
+		},
+		{
+			name: "case 5: private API",
+			customObject: func(customObject *v1alpha1.AWSConfig) {
+				customObject.Spec.AWS.APIEndpoint = v1alpha1.AWSConfigSpecAWSAPIEndpoint{
+					Mode: key.APIEndpointModePrivate,
+				}
+				customObject.Spec.AWS.HostedZones = v1alpha1.AWSConfigSpecAWSHostedZones{
+					API: v1alpha1.AWSConfigSpecAWSHostedZonesZone{
+						Name: "installation.eu-central-1.aws.gigantic.io",
+					},
+				}
+			},
+			expectedElements: []string{
+				"      LoadBalancerName: test-cluster-api\n      Scheme: internal\n      SecurityGroups:\n        - !Ref MasterSecurityGroup\n      Subnets:\n        - !Ref PrivateSubnet\n",
+				"  PrivateAPIHostedZone:\n    Type: 'AWS::Route53::HostedZone'\n    Properties:\n      Name: 'api.test-cluster.k8s.installation.eu-central-1.aws.gigantic.io.'",
+				"        - VPCId: !Ref VPC\n          VPCRegion: 'eu-central-1'",
+				"      Name: 'api.test-cluster.k8s.installation.eu-central-1.aws.gigantic.io.'\n      HostedZoneId: !Ref 'PrivateAPIHostedZone'",
+				"      Name: 'ingress.test-cluster.k8s.installation.eu-central-1.aws.gigantic.io.'\n      HostedZoneId: !Ref 'HostedZone'",
+			},
+			unexpectedElements: []string{
+				"Allow all traffic to the master instance.",
+			},
+		},
+		{
+			name: "case 6: transit gateway",
+			azs:  []string{"eu-central-1a", "eu-central-1b"},
+			stackState: func(customObject v1alpha1.AWSConfig, stackState *StackState) {
+				stackState.TransitGatewayID = "tgw-0123456789abcdef0"
+			},
+			expectedElements: []string{
+				"  TransitGatewayID:\n    Value: tgw-0123456789abcdef0\n",
+				"  TransitGatewayAttachment:\n    Type: AWS::EC2::TransitGatewayAttachment\n    Properties:\n      TransitGatewayId: tgw-0123456789abcdef0\n      VpcId: !Ref VPC\n      SubnetIds:\n        - !Ref PrivateSubnet\n        - !Ref PrivateSubnet01\n",
+				"  TransitGatewayRoute00:\n    Type: AWS::EC2::Route\n    DependsOn: TransitGatewayAttachment\n    Properties:\n      RouteTableId: !Ref PrivateRouteTable\n",
+				"  TransitGatewayRoute01:\n    Type: AWS::EC2::Route\n    DependsOn: TransitGatewayAttachment\n    Properties:\n      RouteTableId: !Ref PrivateRouteTable01\n",
+				"      TransitGatewayId: tgw-0123456789abcdef0\n",
+			},
+			unexpectedElements: []string{
+				"VPCPeeringConnection",
+				"VPCPeeringRoute",
+			},
+		},
+		{
+			name: "case 7: IPv6",
+			azs:  []string{"eu-central-1a", "eu-central-1b"},
+			customObject: func(customObject *v1alpha1.AWSConfig) {
+				customObject.Spec.AWS.VPC.IPv6Enabled = true
+			},
+			expectedElements: []string{
+				"  VPCIPv6CidrBlock:\n    Type: AWS::EC2::VPCCidrBlock\n    Properties:\n      AmazonProvidedIpv6CidrBlock: true\n      VpcId: !Ref VPC\n",
+				"  PrivateSubnet:\n    Type: AWS::EC2::Subnet\n    DependsOn: VPCIPv6CidrBlock\n    Properties:\n      AssignIpv6AddressOnCreation: true\n",
+				"      Ipv6CidrBlock: !Select [ 0, !Cidr [ !Select [ 0, !GetAtt VPC.Ipv6CidrBlocks ], 4, 64 ] ]\n",
+				"      Ipv6CidrBlock: !Select [ 3, !Cidr [ !Select [ 0, !GetAtt VPC.Ipv6CidrBlocks ], 4, 64 ] ]\n",
+				"  InternetGatewayIPv6Route:\n",
+				"  EgressOnlyInternetGateway:\n    Type: AWS::EC2::EgressOnlyInternetGateway\n",
+				"  EgressOnlyRoute00:\n    Type: AWS::EC2::Route\n    Properties:\n      RouteTableId: !Ref PrivateRouteTable\n      DestinationIpv6CidrBlock: ::/0\n",
+				"  EgressOnlyRoute01:\n    Type: AWS::EC2::Route\n    Properties:\n      RouteTableId: !Ref PrivateRouteTable01\n      DestinationIpv6CidrBlock: ::/0\n",
+				"        CidrIpv6: ::/0\n",
+			},
+		},
+		{
+			// The stack state describes a guest cluster created before all EBS
+			// volumes got encrypted. Its etcd volume keeps the encryption it was
+			// created with.
+			name: "case 8: volume encryption",
+			stackState: func(customObject v1alpha1.AWSConfig, stackState *StackState) {
+				stackState.VolumeEncryptionKeyARN = keyARN
+				stackState.WorkerLaunchTemplate = false
+			},
+			config: func(config *Config) {
+				config.EncrypterBackend = "vault"
+			},
+			expectedElements: []string{
+				"VolumeEncryptionKeyARN:\n    Value: " + keyARN,
+			},
+			expectedCounts: map[string]int{
+				// The master root volume, the master docker volume and the worker
+				// root and docker volumes are encrypted with the KMS key.
+				"KmsKeyId: " + keyARN: 4,
+			},
+			unexpectedElements: []string{
+				"EtcdVolumeEncryptionKeyARN:",
+			},
+		},
+		{
+			name: "case 9: existing VPC",
+			azs:  []string{"eu-central-1a", "eu-central-1b"},
+			customObject: func(customObject *v1alpha1.AWSConfig) {
+				customObject.Spec.AWS.VPC = v1alpha1.AWSConfigSpecAWSVPC{
+					ID: "vpc-existing",
+					Subnets: []v1alpha1.AWSConfigSpecAWSVPCSubnet{
+						{AvailabilityZone: "eu-central-1b", PrivateID: "subnet-private-b", PublicID: "subnet-public-b"},
+						{AvailabilityZone: "eu-central-1a", PrivateID: "subnet-private-a", PublicID: "subnet-public-a"},
+					},
+				}
+			},
+			stackState: func(customObject v1alpha1.AWSConfig, stackState *StackState) {
+				stackState.VPCID = key.VPCID(customObject)
+			},
+			// Both private subnets share a route table, which gets the route
+			// towards the host cluster only once.
+			ec2: &EC2ExistingVPCMock{
+				routeTables: []*ec2.RouteTable{
+					{RouteTableId: awssdk.String("rtb-b")},
+					{RouteTableId: awssdk.String("rtb-a")},
+					{RouteTableId: awssdk.String("rtb-a")},
+				},
+			},
+			expectedElements: []string{
+				"  VPC:\n    Type: AWS::EC2::VPC::Id\n    Default: vpc-existing\n",
+				"  PublicSubnet:\n    Type: AWS::EC2::Subnet::Id\n    Default: subnet-public-a\n",
+				"  PublicSubnet01:\n    Type: AWS::EC2::Subnet::Id\n    Default: subnet-public-b\n",
+				"  PrivateSubnet:\n    Type: AWS::EC2::Subnet::Id\n    Default: subnet-private-a\n",
+				"  PrivateSubnet01:\n    Type: AWS::EC2::Subnet::Id\n    Default: subnet-private-b\n",
+				"  VPCPeeringRoute:\n    Type: AWS::EC2::Route\n    Properties:\n      RouteTableId: rtb-a\n",
+				"  VPCPeeringRoute01:\n    Type: AWS::EC2::Route\n    Properties:\n      RouteTableId: rtb-b\n",
+				"  VPCPeeringConnection:\n",
+				"  VPCID:\n    Value: vpc-existing\n",
+			},
+			unexpectedElements: []string{
+				"Type: AWS::EC2::VPC\n",
+				"Type: AWS::EC2::Subnet\n",
+				"Type: AWS::EC2::RouteTable\n",
+				"Type: AWS::EC2::SubnetRouteTableAssociation\n",
+				"Type: AWS::EC2::InternetGateway\n",
+				"Type: AWS::EC2::NatGateway\n",
+				"VPCGatewayAttachment",
+				"VPCPeeringRoute02:",
+			},
+		},
+		{
+			name: "case 10: VPC endpoints",
+			azs:  []string{"eu-central-1a", "eu-central-1b"},
+			config: func(config *Config) {
+				config.VPCEndpointsEnabled = true
+			},
+			expectedElements: []string{
+				"  VPCEndpointSecurityGroup:\n    Type: AWS::EC2::SecurityGroup\n",
+				"        CidrIp: 10.1.1.0/24\n",
+				"  S3VPCEndpoint:\n    Type: AWS::EC2::VPCEndpoint\n",
+				"      RouteTableIds:\n        - !Ref PrivateRouteTable\n        - !Ref PrivateRouteTable01\n      ServiceName: com.amazonaws.eu-central-1.s3\n      VpcEndpointType: Gateway\n",
+				"arn:aws:s3:::prod-eu-central-1-starport-layer-bucket/*",
+				"  EC2VPCEndpoint:\n",
+				"      ServiceName: com.amazonaws.eu-central-1.ec2\n      SubnetIds:\n        - !Ref PrivateSubnet\n        - !Ref PrivateSubnet01\n      VpcEndpointType: Interface\n",
+				"  ECRAPIVPCEndpoint:\n",
+				"  ECRDockerVPCEndpoint:\n",
+				"  ELBVPCEndpoint:\n",
+				"  STSVPCEndpoint:\n",
+			},
+		},
+		{
+			name: "case 11: VPC flow logs disabled",
+			unexpectedElements: []string{
+				"VPCFlowLog",
+			},
+		},
+		{
+			name: "case 12: VPC flow logs to cloudwatch",
+			config: func(config *Config) {
+				config.VPCFlowLogs = adapter.VPCFlowLogs{
+					Destination:            "cloudwatch",
+					MaxAggregationInterval: 600,
+					TrafficType:            "ALL",
+				}
+			},
+			expectedElements: []string{
+				"  VPCFlowLogsLogGroup:\n    Type: AWS::Logs::LogGroup\n    Properties:\n      LogGroupName: test-cluster-vpc-flow-logs\n",
+				"  VPCFlowLogsRole:\n    Type: \"AWS::IAM::Role\"\n    Properties:\n      RoleName: test-cluster-vpc-flow-logs\n",
+				"            Service: vpc-flow-logs.amazonaws.com\n",
+				"      DeliverLogsPermissionArn: !GetAtt VPCFlowLogsRole.Arn\n      LogDestinationType: cloud-watch-logs\n      LogGroupName: !Ref VPCFlowLogsLogGroup\n      MaxAggregationInterval: 600\n      ResourceId: !Ref VPC\n      ResourceType: VPC\n      TrafficType: ALL\n",
+			},
+			unexpectedElements: []string{
+				"VPCFlowLogsBucketPolicy",
+			},
+		},
+		{
+			name: "case 13: VPC flow logs to s3",
+			config: func(config *Config) {
+				config.VPCFlowLogs = adapter.VPCFlowLogs{
+					Destination:            "s3",
+					MaxAggregationInterval: 60,
+					TrafficType:            "REJECT",
+				}
+			},
+			expectedElements: []string{
+				"  VPCFlowLogsBucketPolicy:\n    Type: AWS::S3::BucketPolicy\n    Properties:\n      Bucket: test-cluster-g8s-access-logs\n",
+				"            Resource: \"arn:aws:s3:::test-cluster-g8s-access-logs/vpc-flow-logs/test-cluster/AWSLogs/000000000000/*\"\n",
+				"            Resource: \"arn:aws:s3:::test-cluster-g8s-access-logs\"\n",
+				"    DependsOn: VPCFlowLogsBucketPolicy\n    Properties:\n      LogDestination: arn:aws:s3:::test-cluster-g8s-access-logs/vpc-flow-logs/test-cluster/\n      LogDestinationType: s3\n      MaxAggregationInterval: 60\n      ResourceId: !Ref VPC\n      ResourceType: VPC\n      TrafficType: REJECT\n",
+			},
+			unexpectedElements: []string{
+				"VPCFlowLogsLogGroup",
+				"VPCFlowLogsRole",
+			},
+		},
+		{
+			name: "case 14: single NAT gateway",
+			azs:  []string{"eu-central-1a", "eu-central-1b"},
+			customObject: func(customObject *v1alpha1.AWSConfig) {
+				customObject.Spec.AWS.NATGateway = v1alpha1.AWSConfigSpecAWSNATGateway{
+					Strategy:      "single",
+					AllocationIDs: []string{"eipalloc-a"},
+				}
+			},
+			config: func(config *Config) {
+				config.APIWhitelist = adapter.APIWhitelist{
+					Enabled:    true,
+					SubnetList: "172.10.10.0/24",
+				}
+			},
+			ec2: &EC2NATGatewaysMock{
+				addresses: []*ec2.Address{
+					{AllocationId: awssdk.String("eipalloc-a"), PublicIp: awssdk.String("52.0.0.1")},
+				},
+			},
+			expectedElements: []string{
+				"  NATGateway:\n    Type: AWS::EC2::NatGateway\n    DependsOn:\n      - VPCGatewayAttachment\n    Properties:\n      AllocationId: eipalloc-a\n      SubnetId: !Ref PublicSubnet\n",
+				"  NATRoute:\n    Type: AWS::EC2::Route\n    Properties:\n      RouteTableId: !Ref PrivateRouteTable\n      DestinationCidrBlock: 0.0.0.0/0\n      NatGatewayId:\n        Ref: \"NATGateway\"\n",
+				"  NATRoute01:\n    Type: AWS::EC2::Route\n    Properties:\n      RouteTableId: !Ref PrivateRouteTable01\n      DestinationCidrBlock: 0.0.0.0/0\n      NatGatewayId:\n        Ref: \"NATGateway\"\n",
+				"        CidrIp: 52.0.0.1/32\n",
+				"  NATGatewayStrategy:\n    Value: single\n",
+				"  NATGatewayAllocationIDs:\n    Value: eipalloc-a\n",
+			},
+			unexpectedElements: []string{
+				"NATGateway01:",
+				"Type: AWS::EC2::EIP\n",
+			},
+		},
+		{
+			name: "case 15: API whitelist",
+			customObject: func(customObject *v1alpha1.AWSConfig) {
+				customObject.Spec.AWS.APIWhitelist = v1alpha1.AWSConfigSpecAWSAPIWhitelist{
+					APICIDRs:     []string{"212.145.136.84/32", "192.168.1.0/24"},
+					IngressCIDRs: []string{"10.0.0.0/8"},
+				}
+			},
+			stackState: func(customObject v1alpha1.AWSConfig, stackState *StackState) {
+				stackState.APIWhitelistCIDRs = key.APIWhitelistCIDRs(customObject)
+				stackState.IngressWhitelistCIDRs = key.IngressWhitelistCIDRs(customObject)
+			},
+			expectedElements: []string{
+				"        Description: Custom Whitelist CIDR.\n        IpProtocol: tcp\n        FromPort: 443\n        ToPort: 443\n        CidrIp: 212.145.136.84/32\n",
+				"        Description: Custom Whitelist CIDR.\n        IpProtocol: tcp\n        FromPort: 443\n        ToPort: 443\n        CidrIp: 192.168.1.0/24\n",
+				"        Description: Allow NAT gateway IP\n",
+				"        IpProtocol: tcp\n        FromPort: 80\n        ToPort: 80\n        CidrIp: 10.0.0.0/8\n",
+				"        IpProtocol: tcp\n        FromPort: 443\n        ToPort: 443\n        CidrIp: 10.0.0.0/8\n",
+				"  APIWhitelistCIDRs:\n    Value: 212.145.136.84/32,192.168.1.0/24\n",
+				"  IngressWhitelistCIDRs:\n    Value: 10.0.0.0/8\n",
+			},
+			unexpectedElements: []string{
+				"Allow all traffic to the master instance.",
+			},
+		},
+		{
+			name: "case 16: instance metadata service disabled",
+			unexpectedElements: []string{
+				"MetadataOptions:",
+			},
+		},
+		{
+			name: "case 17: instance metadata service with session tokens",
+			customObject: func(customObject *v1alpha1.AWSConfig) {
+				customObject.Spec.AWS.NodePools = []v1alpha1.AWSConfigSpecAWSNodePool{gpuNodePool}
+			},
+			config: func(config *Config) {
+				config.MetadataTokensRequired = true
+			},
+			expectedCounts: map[string]int{
+				// The launch templates of the worker ASG and the node pool.
+				"        MetadataOptions:\n          HttpEndpoint: enabled\n          HttpPutResponseHopLimit: 2\n          HttpTokens: required\n": 2,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			azs := tc.azs
+			if len(azs) == 0 {
+				azs = []string{"eu-central-1a"}
+			}
+			customObject := testGuestCustomObject(azs...)
+			if tc.customObject != nil {
+				tc.customObject(&customObject)
+			}
+
+			stackState := testGuestStackState(customObject)
+			if tc.stackState != nil {
+				tc.stackState(customObject, &stackState)
+			}
+
+			cfg := testConfig()
+			cfg.HostClients = &adapter.Clients{
+				EC2: &adapter.EC2ClientMock{},
+				IAM: &adapter.IAMClientMock{},
+				STS: &adapter.STSClientMock{},
+			}
+			cfg.Route53Enabled = true
+			if tc.config != nil {
+				tc.config(&cfg)
+			}
+			newResource, err := New(cfg)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			awsClients := aws.Clients{
+				EC2: &adapter.EC2ClientMock{},
+				IAM: &adapter.IAMClientMock{},
+				KMS: &adapter.KMSClientMock{},
+				ELB: &adapter.ELBClientMock{},
+				STS: &adapter.STSClientMock{},
+			}
+			if tc.ec2 != nil {
+				awsClients.EC2 = tc.ec2
+			}
+
+			ctx := context.TODO()
+			ctx = controllercontext.NewContext(ctx, controllercontext.Context{AWSClient: awsClients})
+
+			body, err := newResource.getMainGuestTemplateBody(ctx, customObject, stackState)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			for _, e := range tc.expectedElements {
+				if !strings.Contains(body, e) {
+					fmt.Println(body)
+					t.Fatalf("%q element not found", e)
+				}
+			}
+			for e, n := range tc.expectedCounts {
+				if strings.Count(body, e) != n {
+					fmt.Println(body)
+					t.Fatalf("expected %q element %d times, found %d", e, n, strings.Count(body, e))
+				}
+			}
+			for _, e := range tc.unexpectedElements {
+				if strings.Contains(body, e) {
+					fmt.Println(body)
+					t.Fatalf("%q element found", e)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package cloudformation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/aws-operator/service/controller/v21/maintenance"
+)
+
+// publishMaintenanceWindow writes the current or next maintenance window of
+// the tenant cluster and whether an update waits to be applied to the CR
+// status. The CR status is only updated in case any of it changed.
+func (r *Resource) publishMaintenanceWindow(ctx context.Context, customObject v1alpha1.AWSConfig, updatePending bool) error {
+	status := v1alpha1.AWSConfigStatusAWSMaintenanceWindow{
+		UpdatePending: updatePending,
+	}
+
+	w, err := maintenance.FromCustomObject(customObject)
+	if maintenance.IsInvalidConfig(err) {
+		r.logger.LogCtx(ctx, "level", "warning", "message", "maintenance window is invalid", "stack", fmt.Sprintf("%#v", err))
+	} else if err != nil {
+		return microerror.Mask(err)
+	} else if w != nil {
+		start, end, ok := w.Next(time.Now())
+		if ok {
+			status.Start = v1alpha1.DeepCopyTime{Time: start.UTC()}
+			status.End = v1alpha1.DeepCopyTime{Time: end.UTC()}
+		}
+	}
+
+	current := customObject.Status.AWS.MaintenanceWindow
+	if current.Start.Equal(status.Start.Time) && current.End.Equal(status.End.Time) && current.UpdatePending == status.UpdatePending {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "CR status already contains maintenance window")
+		return nil
+	}
+
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "updating CR status with maintenance window")
+
+		newObj, err := r.g8sClient.ProviderV1alpha1().AWSConfigs(customObject.GetNamespace()).Get(customObject.GetName(), metav1.GetOptions{})
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		newObj.Status.AWS.MaintenanceWindow = status
+
+		_, err = r.g8sClient.ProviderV1alpha1().AWSConfigs(newObj.GetNamespace()).UpdateStatus(newObj)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "updated CR status with maintenance window")
+	}
+
+	return nil
+}
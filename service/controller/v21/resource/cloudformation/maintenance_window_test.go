@@ -0,0 +1,72 @@
+package cloudformation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	versionedfake "github.com/giantswarm/apiextensions/pkg/clientset/versioned/fake"
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_Resource_Cloudformation_publishMaintenanceWindow(t *testing.T) {
+	t.Parallel()
+	customObject := &v1alpha1.AWSConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.AWSConfigSpec{
+			AWS: v1alpha1.AWSConfigSpecAWS{
+				MaintenanceWindow: v1alpha1.AWSConfigSpecAWSMaintenanceWindow{
+					Schedule: "0 2 * * 6",
+					Duration: "4h",
+					TimeZone: "Europe/Berlin",
+				},
+			},
+		},
+	}
+
+	g8sClient := versionedfake.NewSimpleClientset(customObject)
+
+	r := &Resource{
+		g8sClient: g8sClient,
+		logger:    microloggertest.New(),
+	}
+
+	err := r.publishMaintenanceWindow(context.TODO(), *customObject, true)
+	if err != nil {
+		t.Fatalf("expected nil got %#v", err)
+	}
+
+	updated, err := g8sClient.ProviderV1alpha1().AWSConfigs("default").Get("test-cluster", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected nil got %#v", err)
+	}
+
+	status := updated.Status.AWS.MaintenanceWindow
+	if !status.UpdatePending {
+		t.Fatalf("expected pending update")
+	}
+	if status.Start.IsZero() {
+		t.Fatalf("expected start of maintenance window")
+	}
+	if status.End.Sub(status.Start.Time) != 4*time.Hour {
+		t.Fatalf("expected maintenance window of %s got %s", 4*time.Hour, status.End.Sub(status.Start.Time))
+	}
+
+	// Publishing the same maintenance window again must not update the CR
+	// status.
+	actions := len(g8sClient.Actions())
+
+	err = r.publishMaintenanceWindow(context.TODO(), *updated, true)
+	if err != nil {
+		t.Fatalf("expected nil got %#v", err)
+	}
+
+	if len(g8sClient.Actions()) != actions {
+		t.Fatalf("expected %d actions got %d", actions, len(g8sClient.Actions()))
+	}
+}
@@ -4,11 +4,39 @@ import (
 	"context"
 
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
 
 	"github.com/giantswarm/aws-operator/service/controller/v21/ebs"
 )
 
+type AMICatalogMock struct {
+}
+
+func (a *AMICatalogMock) ImageID(ctx context.Context, customObject v1alpha1.AWSConfig) (string, error) {
+	return "ami-0e6601a88a9753474", nil
+}
+
+type EtcdMemberMock struct {
+	// unhealthy holds the indices of the etcd members reporting themselves
+	// unhealthy.
+	unhealthy []int
+}
+
+func (e *EtcdMemberMock) Healthy(ctx context.Context, customObject v1alpha1.AWSConfig, idx int) (bool, error) {
+	for _, i := range e.unhealthy {
+		if i == idx {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (e *EtcdMemberMock) VerifyPeerDomains(ctx context.Context, customObject v1alpha1.AWSConfig, masterCount int) error {
+	return nil
+}
+
 type EBSServiceMock struct {
 }
 
@@ -25,3 +53,37 @@ func (e *EBSServiceMock) DetachVolume(ctx context.Context, volumeID string, atta
 func (e *EBSServiceMock) ListVolumes(customObject v1alpha1.AWSConfig, filterFuncs ...func(t *ec2.Tag) bool) ([]ebs.Volume, error) {
 	return []ebs.Volume{{}}, nil
 }
+
+// EC2ExistingVPCMock returns the configured subnets and route tables of an
+// existing VPC regardless of the given filters.
+type EC2ExistingVPCMock struct {
+	ec2iface.EC2API
+
+	routeTables []*ec2.RouteTable
+	subnets     []*ec2.Subnet
+}
+
+func (e *EC2ExistingVPCMock) DescribeRouteTables(input *ec2.DescribeRouteTablesInput) (*ec2.DescribeRouteTablesOutput, error) {
+	return &ec2.DescribeRouteTablesOutput{RouteTables: e.routeTables}, nil
+}
+
+func (e *EC2ExistingVPCMock) DescribeSubnets(input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+	return &ec2.DescribeSubnetsOutput{Subnets: e.subnets}, nil
+}
+
+// EC2NATGatewaysMock returns the configured elastic IPs and NAT gateways
+// regardless of the given filters.
+type EC2NATGatewaysMock struct {
+	ec2iface.EC2API
+
+	addresses   []*ec2.Address
+	natGateways []*ec2.NatGateway
+}
+
+func (e *EC2NATGatewaysMock) DescribeAddresses(input *ec2.DescribeAddressesInput) (*ec2.DescribeAddressesOutput, error) {
+	return &ec2.DescribeAddressesOutput{Addresses: e.addresses}, nil
+}
+
+func (e *EC2NATGatewaysMock) DescribeNatGateways(input *ec2.DescribeNatGatewaysInput) (*ec2.DescribeNatGatewaysOutput, error) {
+	return &ec2.DescribeNatGatewaysOutput{NatGateways: e.natGateways}, nil
+}
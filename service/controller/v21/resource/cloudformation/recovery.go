@@ -0,0 +1,369 @@
+package cloudformation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/giantswarm/aws-operator/service/controller/v21/controllercontext"
+	"github.com/giantswarm/aws-operator/service/controller/v21/key"
+)
+
+const (
+	stackEventReasonContinuedRollback = "StackRollbackContinued"
+	stackEventReasonDeletedFailed     = "StackFailedDeleted"
+	stackEventReasonRetainedResources = "StackResourcesRetained"
+)
+
+// stackFailure describes why the tenant cluster main stack ended up in a
+// failed state.
+type stackFailure struct {
+	StackName string
+	Status    string
+	// Resources are the resources which failed during the last stack
+	// operation, newest first.
+	Resources []stackFailureResource
+}
+
+type stackFailureResource struct {
+	LogicalID string
+	Reason    string
+	Status    string
+	// Rollback is true when the resource failed while CloudFormation was
+	// rolling back the stack operation.
+	Rollback bool
+}
+
+// isFailedStackStatus returns true for the stack statuses the stack does not
+// leave without intervention.
+func isFailedStackStatus(status string) bool {
+	switch status {
+	case cloudformation.StackStatusDeleteFailed:
+		return true
+	case cloudformation.StackStatusRollbackComplete:
+		return true
+	case cloudformation.StackStatusUpdateRollbackFailed:
+		return true
+	}
+
+	return false
+}
+
+// isStackOperationStart returns true for the stack event starting the stack
+// operation which eventually failed.
+func isStackOperationStart(stackName string, e *cloudformation.StackEvent) bool {
+	if aws.StringValue(e.LogicalResourceId) != stackName {
+		return false
+	}
+
+	switch aws.StringValue(e.ResourceStatus) {
+	case cloudformation.ResourceStatusCreateInProgress:
+		return true
+	case cloudformation.ResourceStatusDeleteInProgress:
+		return true
+	case cloudformation.ResourceStatusUpdateInProgress:
+		return true
+	}
+
+	return false
+}
+
+// newStackFailure collects the failed resources of the last stack operation
+// from the given stack events. The events are expected to be ordered newest
+// first, the way the CloudFormation API returns them.
+func newStackFailure(stackName, status string, events []*cloudformation.StackEvent) stackFailure {
+	f := stackFailure{
+		StackName: stackName,
+		Status:    status,
+	}
+
+	// The newest events of rolled back stacks belong to the rollback. Once we
+	// see the stack level event starting the rollback, the remaining failures
+	// belong to the operation causing the rollback.
+	rollback := status == cloudformation.StackStatusRollbackComplete || status == cloudformation.StackStatusUpdateRollbackFailed
+
+	for _, e := range events {
+		if isStackOperationStart(stackName, e) {
+			break
+		}
+
+		logicalID := aws.StringValue(e.LogicalResourceId)
+		resourceStatus := aws.StringValue(e.ResourceStatus)
+
+		if logicalID == stackName {
+			if resourceStatus == cloudformation.StackStatusRollbackInProgress || resourceStatus == cloudformation.StackStatusUpdateRollbackInProgress {
+				rollback = false
+			}
+			continue
+		}
+
+		if !strings.HasSuffix(resourceStatus, "_FAILED") {
+			continue
+		}
+
+		r := stackFailureResource{
+			LogicalID: logicalID,
+			Reason:    aws.StringValue(e.ResourceStatusReason),
+			Status:    resourceStatus,
+			Rollback:  rollback,
+		}
+		f.Resources = append(f.Resources, r)
+	}
+
+	return f
+}
+
+// ResourcesToRetain returns the logical IDs of the resources which failed to
+// be deleted. Deleting a stack in DELETE_FAILED state while retaining these
+// resources lets the deletion succeed.
+func (f stackFailure) ResourcesToRetain() []string {
+	var ids []string
+
+	for _, r := range f.Resources {
+		if r.Status == cloudformation.ResourceStatusDeleteFailed {
+			ids = appendUnique(ids, r.LogicalID)
+		}
+	}
+
+	sort.Strings(ids)
+
+	return ids
+}
+
+// ResourcesToSkip returns the logical IDs of the resources which failed to be
+// rolled back. Continuing the rollback while skipping these resources lets
+// the stack return to UPDATE_ROLLBACK_COMPLETE.
+func (f stackFailure) ResourcesToSkip() []string {
+	var ids []string
+
+	for _, r := range f.Resources {
+		if r.Rollback && r.Status == cloudformation.ResourceStatusUpdateFailed {
+			ids = appendUnique(ids, r.LogicalID)
+		}
+	}
+
+	sort.Strings(ids)
+
+	return ids
+}
+
+func (f stackFailure) Summary() string {
+	if len(f.Resources) == 0 {
+		return fmt.Sprintf("stack %#q has status %#q without failed resources", f.StackName, f.Status)
+	}
+
+	var failures []string
+	for _, r := range f.Resources {
+		failures = append(failures, fmt.Sprintf("%#q %s: %s", r.LogicalID, r.Status, r.Reason))
+	}
+
+	return fmt.Sprintf("stack %#q has status %#q; failed resources: %s", f.StackName, f.Status, strings.Join(failures, "; "))
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, l := range list {
+		if l == s {
+			return list
+		}
+	}
+
+	return append(list, s)
+}
+
+// describeStackFailure fetches the stack events of the last stack operation
+// and returns the failures found in them.
+func describeStackFailure(client *cloudformation.CloudFormation, stackName, status string) (stackFailure, error) {
+	var events []*cloudformation.StackEvent
+	{
+		i := &cloudformation.DescribeStackEventsInput{
+			StackName: aws.String(stackName),
+		}
+
+		err := client.DescribeStackEventsPages(i, func(o *cloudformation.DescribeStackEventsOutput, lastPage bool) bool {
+			for _, e := range o.StackEvents {
+				events = append(events, e)
+
+				// There is no need to page through the whole history of the stack.
+				if isStackOperationStart(stackName, e) {
+					return false
+				}
+			}
+
+			return true
+		})
+		if err != nil {
+			return stackFailure{}, microerror.Mask(err)
+		}
+	}
+
+	return newStackFailure(stackName, status, events), nil
+}
+
+// recoverStack runs the recovery of the tenant cluster main stack in the
+// given failed status in case the recovery policy allows it. The failing
+// resources are logged in any case.
+func (r *Resource) recoverStack(ctx context.Context, customObject v1alpha1.AWSConfig, stackStatus string) error {
+	sc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	stackName := key.MainGuestStackName(customObject)
+
+	failure, err := describeStackFailure(sc.AWSClient.CloudFormation, stackName, stackStatus)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.logger.LogCtx(ctx, "level", "warning", "message", failure.Summary())
+
+	switch stackStatus {
+	case cloudformation.StackStatusUpdateRollbackFailed:
+		if !r.recoverUpdateRollbackFailed {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "not continuing the rollback of the guest cluster main stack because the recovery policy does not allow it")
+			return nil
+		}
+
+		resourcesToSkip := failure.ResourcesToSkip()
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("continuing the rollback of the guest cluster main stack skipping resources %#q", resourcesToSkip))
+
+		i := &cloudformation.ContinueUpdateRollbackInput{
+			StackName: aws.String(stackName),
+		}
+		if len(resourcesToSkip) > 0 {
+			i.ResourcesToSkip = aws.StringSlice(resourcesToSkip)
+		}
+
+		_, err = sc.AWSClient.CloudFormation.ContinueUpdateRollback(i)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		err = r.emitEvent(customObject, corev1.EventTypeWarning, stackEventReasonContinuedRollback, fmt.Sprintf("continued rollback skipping resources %#q; %s", resourcesToSkip, failure.Summary()))
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "continued the rollback of the guest cluster main stack")
+
+	case cloudformation.StackStatusRollbackComplete:
+		// A stack in ROLLBACK_COMPLETE failed to be created and can only be
+		// deleted. All its resources have been removed during the rollback
+		// already. Once the stack is gone, the stack is created again on one of
+		// the next resyncs.
+		if !r.recoverRollbackComplete {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "not deleting the rolled back guest cluster main stack because the recovery policy does not allow it")
+			return nil
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "deleting the rolled back guest cluster main stack in order to recreate it")
+
+		err = deleteStack(sc.AWSClient.CloudFormation, stackName)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		err = r.emitEvent(customObject, corev1.EventTypeWarning, stackEventReasonDeletedFailed, fmt.Sprintf("deleted stack in order to recreate it; %s", failure.Summary()))
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "deleted the rolled back guest cluster main stack in order to recreate it")
+
+	default:
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("not recovering the guest cluster main stack with status %#q", stackStatus))
+	}
+
+	return nil
+}
+
+// resourcesToRetain returns the resources to retain when deleting the tenant
+// cluster main stack. Resources are only retained in case the previous
+// deletion failed and the recovery policy allows it.
+func (r *Resource) resourcesToRetain(ctx context.Context, customObject v1alpha1.AWSConfig) ([]string, error) {
+	sc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	stackName := key.MainGuestStackName(customObject)
+
+	var stackStatus string
+	{
+		i := &cloudformation.DescribeStacksInput{
+			StackName: aws.String(stackName),
+		}
+
+		o, err := sc.AWSClient.CloudFormation.DescribeStacks(i)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		if len(o.Stacks) != 1 {
+			return nil, microerror.Maskf(executionFailedError, "expected 1 stack, got %d", len(o.Stacks))
+		}
+
+		stackStatus = aws.StringValue(o.Stacks[0].StackStatus)
+	}
+
+	if stackStatus != cloudformation.StackStatusDeleteFailed {
+		return nil, nil
+	}
+
+	failure, err := describeStackFailure(sc.AWSClient.CloudFormation, stackName, stackStatus)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	r.logger.LogCtx(ctx, "level", "warning", "message", failure.Summary())
+
+	if !r.recoverDeleteFailed {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not retaining failed resources of the guest cluster main stack because the recovery policy does not allow it")
+		return nil, nil
+	}
+
+	resourcesToRetain := failure.ResourcesToRetain()
+
+	err = r.emitEvent(customObject, corev1.EventTypeWarning, stackEventReasonRetainedResources, fmt.Sprintf("retaining resources %#q on deletion; %s", resourcesToRetain, failure.Summary()))
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return resourcesToRetain, nil
+}
+
+// deleteStack disables the termination protection of the given stack and
+// deletes it.
+func deleteStack(client *cloudformation.CloudFormation, stackName string) error {
+	{
+		i := &cloudformation.UpdateTerminationProtectionInput{
+			EnableTerminationProtection: aws.Bool(false),
+			StackName:                   aws.String(stackName),
+		}
+
+		_, err := client.UpdateTerminationProtection(i)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	{
+		i := &cloudformation.DeleteStackInput{
+			StackName: aws.String(stackName),
+		}
+
+		_, err := client.DeleteStack(i)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	return nil
+}
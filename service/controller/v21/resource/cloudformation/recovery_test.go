@@ -0,0 +1,127 @@
+package cloudformation
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awscloudformation "github.com/aws/aws-sdk-go/service/cloudformation"
+)
+
+func newStackEvent(logicalID, status, reason string) *awscloudformation.StackEvent {
+	return &awscloudformation.StackEvent{
+		LogicalResourceId:    aws.String(logicalID),
+		ResourceStatus:       aws.String(status),
+		ResourceStatusReason: aws.String(reason),
+	}
+}
+
+func Test_Resource_Cloudformation_newStackFailure(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description               string
+		status                    string
+		events                    []*awscloudformation.StackEvent
+		expectedResources         []stackFailureResource
+		expectedResourcesToRetain []string
+		expectedResourcesToSkip   []string
+	}{
+		{
+			description: "case 0: update rollback failed",
+			status:      awscloudformation.StackStatusUpdateRollbackFailed,
+			events: []*awscloudformation.StackEvent{
+				newStackEvent("test", awscloudformation.StackStatusUpdateRollbackFailed, "The following resource(s) failed to update: [MasterInstance]."),
+				newStackEvent("MasterInstance", awscloudformation.ResourceStatusUpdateFailed, "Instance not found"),
+				newStackEvent("test", awscloudformation.StackStatusUpdateRollbackInProgress, "The following resource(s) failed to update: [workerAutoScalingGroup]."),
+				newStackEvent("workerAutoScalingGroup", awscloudformation.ResourceStatusUpdateFailed, "Received 0 SUCCESS signal(s)"),
+				newStackEvent("workerAutoScalingGroup", awscloudformation.ResourceStatusUpdateInProgress, ""),
+				newStackEvent("test", awscloudformation.StackStatusUpdateInProgress, "User Initiated"),
+				newStackEvent("EtcdVolume", awscloudformation.ResourceStatusCreateFailed, "previous operation"),
+			},
+			expectedResources: []stackFailureResource{
+				{LogicalID: "MasterInstance", Reason: "Instance not found", Status: awscloudformation.ResourceStatusUpdateFailed, Rollback: true},
+				{LogicalID: "workerAutoScalingGroup", Reason: "Received 0 SUCCESS signal(s)", Status: awscloudformation.ResourceStatusUpdateFailed},
+			},
+			expectedResourcesToSkip: []string{"MasterInstance"},
+		},
+		{
+			description: "case 1: rollback complete after failed creation",
+			status:      awscloudformation.StackStatusRollbackComplete,
+			events: []*awscloudformation.StackEvent{
+				newStackEvent("test", awscloudformation.StackStatusRollbackComplete, ""),
+				newStackEvent("VPC", awscloudformation.ResourceStatusDeleteComplete, ""),
+				newStackEvent("test", awscloudformation.StackStatusRollbackInProgress, "The following resource(s) failed to create: [VPC]."),
+				newStackEvent("VPC", awscloudformation.ResourceStatusCreateFailed, "The CIDR is invalid"),
+				newStackEvent("test", awscloudformation.StackStatusCreateInProgress, "User Initiated"),
+			},
+			expectedResources: []stackFailureResource{
+				{LogicalID: "VPC", Reason: "The CIDR is invalid", Status: awscloudformation.ResourceStatusCreateFailed},
+			},
+		},
+		{
+			description: "case 2: delete failed",
+			status:      awscloudformation.StackStatusDeleteFailed,
+			events: []*awscloudformation.StackEvent{
+				newStackEvent("test", awscloudformation.StackStatusDeleteFailed, "The following resource(s) failed to delete: [VPC, InternetGateway]."),
+				newStackEvent("VPC", awscloudformation.ResourceStatusDeleteFailed, "has dependencies"),
+				newStackEvent("InternetGateway", awscloudformation.ResourceStatusDeleteFailed, "has dependencies"),
+				newStackEvent("VPC", awscloudformation.ResourceStatusDeleteFailed, "has dependencies"),
+				newStackEvent("test", awscloudformation.StackStatusDeleteInProgress, "User Initiated"),
+			},
+			expectedResources: []stackFailureResource{
+				{LogicalID: "VPC", Reason: "has dependencies", Status: awscloudformation.ResourceStatusDeleteFailed},
+				{LogicalID: "InternetGateway", Reason: "has dependencies", Status: awscloudformation.ResourceStatusDeleteFailed},
+				{LogicalID: "VPC", Reason: "has dependencies", Status: awscloudformation.ResourceStatusDeleteFailed},
+			},
+			expectedResourcesToRetain: []string{"InternetGateway", "VPC"},
+		},
+		{
+			description: "case 3: no failed resources",
+			status:      awscloudformation.StackStatusRollbackComplete,
+			events: []*awscloudformation.StackEvent{
+				newStackEvent("test", awscloudformation.StackStatusRollbackComplete, ""),
+				newStackEvent("test", awscloudformation.StackStatusRollbackInProgress, "Create cancelled"),
+				newStackEvent("test", awscloudformation.StackStatusCreateInProgress, "User Initiated"),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			f := newStackFailure("test", tc.status, tc.events)
+
+			if !reflect.DeepEqual(f.Resources, tc.expectedResources) {
+				t.Fatalf("expected resources %#v got %#v", tc.expectedResources, f.Resources)
+			}
+			if !reflect.DeepEqual(f.ResourcesToRetain(), tc.expectedResourcesToRetain) {
+				t.Fatalf("expected resources to retain %#v got %#v", tc.expectedResourcesToRetain, f.ResourcesToRetain())
+			}
+			if !reflect.DeepEqual(f.ResourcesToSkip(), tc.expectedResourcesToSkip) {
+				t.Fatalf("expected resources to skip %#v got %#v", tc.expectedResourcesToSkip, f.ResourcesToSkip())
+			}
+		})
+	}
+}
+
+func Test_Resource_Cloudformation_isFailedStackStatus(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		status   string
+		expected bool
+	}{
+		{status: awscloudformation.StackStatusCreateComplete, expected: false},
+		{status: awscloudformation.StackStatusDeleteFailed, expected: true},
+		{status: awscloudformation.StackStatusRollbackComplete, expected: true},
+		{status: awscloudformation.StackStatusUpdateInProgress, expected: false},
+		{status: awscloudformation.StackStatusUpdateRollbackComplete, expected: false},
+		{status: awscloudformation.StackStatusUpdateRollbackFailed, expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.status, func(t *testing.T) {
+			if isFailedStackStatus(tc.status) != tc.expected {
+				t.Fatalf("expected %t got %t", tc.expected, !tc.expected)
+			}
+		})
+	}
+}
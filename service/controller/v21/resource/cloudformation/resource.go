@@ -1,14 +1,21 @@
 package cloudformation
 
 import (
+	"net"
+	"strings"
+
 	awscloudformation "github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/apiextensions/pkg/clientset/versioned"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
+	"k8s.io/client-go/kubernetes"
 
 	"github.com/giantswarm/aws-operator/pkg/awstags"
 	"github.com/giantswarm/aws-operator/service/controller/v21/adapter"
+	"github.com/giantswarm/aws-operator/service/controller/v21/ami"
 	"github.com/giantswarm/aws-operator/service/controller/v21/encrypter"
+	"github.com/giantswarm/aws-operator/service/controller/v21/etcdmember"
 	"github.com/giantswarm/aws-operator/service/controller/v21/key"
 )
 
@@ -28,64 +35,141 @@ type AWSConfig struct {
 // Config represents the configuration used to create a new cloudformation
 // resource.
 type Config struct {
+	AMICatalog           ami.Interface
 	APIWhitelist         adapter.APIWhitelist
+	EtcdMember           etcdmember.Interface
+	G8sClient            versioned.Interface
 	HostClients          *adapter.Clients
+	K8sClient            kubernetes.Interface
 	Logger               micrologger.Logger
 	EncrypterRoleManager encrypter.RoleManager
 
-	AdvancedMonitoringEC2      bool
-	EncrypterBackend           string
-	GuestPrivateSubnetMaskBits int
-	GuestPublicSubnetMaskBits  int
-	InstallationName           string
-	PublicRouteTables          string
-	Route53Enabled             bool
+	AdvancedMonitoringEC2       bool
+	ChangeSetApprovalRequired   bool
+	EncrypterBackend            string
+	GuestPrivateSubnetMaskBits  int
+	GuestPublicSubnetMaskBits   int
+	IngressNLB                  adapter.IngressNLB
+	InstallationName            string
+	MetadataTokensRequired      bool
+	PublicRouteTables           string
+	RecoverDeleteFailed         bool
+	RecoverRollbackComplete     bool
+	RecoverUpdateRollbackFailed bool
+	Route53Enabled              bool
+	TransitGatewayID            string
+	VPCEndpointsEnabled         bool
+	VPCFlowLogs                 adapter.VPCFlowLogs
+	WorkerRolloutHealthGated    bool
 }
 
 // Resource implements the cloudformation resource.
 type Resource struct {
+	amiCatalog           ami.Interface
 	apiWhiteList         adapter.APIWhitelist
 	encrypterRoleManager encrypter.RoleManager
+	etcdMember           etcdmember.Interface
+	g8sClient            versioned.Interface
 	hostClients          *adapter.Clients
+	k8sClient            kubernetes.Interface
 	logger               micrologger.Logger
 
-	encrypterBackend           string
-	guestPrivateSubnetMaskBits int
-	guestPublicSubnetMaskBits  int
-	installationName           string
-	monitoring                 bool
-	publicRouteTables          string
-	route53Enabled             bool
+	changeSetApprovalRequired   bool
+	encrypterBackend            string
+	guestPrivateSubnetMaskBits  int
+	guestPublicSubnetMaskBits   int
+	ingressNLB                  adapter.IngressNLB
+	installationName            string
+	metadataTokensRequired      bool
+	monitoring                  bool
+	publicRouteTables           string
+	recoverDeleteFailed         bool
+	recoverRollbackComplete     bool
+	recoverUpdateRollbackFailed bool
+	route53Enabled              bool
+	transitGatewayID            string
+	vpcEndpointsEnabled         bool
+	vpcFlowLogs                 adapter.VPCFlowLogs
+	workerRolloutHealthGated    bool
 }
 
 // New creates a new configured cloudformation resource.
 func New(config Config) (*Resource, error) {
+	if config.AMICatalog == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.AMICatalog must not be empty", config)
+	}
+	if config.EtcdMember == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.EtcdMember must not be empty", config)
+	}
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	}
 	if config.HostClients == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.HostClients must not be empty", config)
 	}
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
 	if config.Logger == nil {
 		return nil, microerror.Maskf(invalidConfigError, "config.Logger must not be empty")
 	}
 	if config.EncrypterBackend == "" {
 		return nil, microerror.Maskf(invalidConfigError, "config.EncrypterBackend must not be empty")
 	}
+	if config.IngressNLB.SourceCIDRs != "" {
+		for _, c := range strings.Split(config.IngressNLB.SourceCIDRs, ",") {
+			ip, _, err := net.ParseCIDR(strings.TrimSpace(c))
+			if err != nil || ip.To4() == nil {
+				return nil, microerror.Maskf(invalidConfigError, "%T.IngressNLB.SourceCIDRs must be a comma separated list of IPv4 CIDRs, got %#q", config, c)
+			}
+		}
+	}
+	switch config.VPCFlowLogs.Destination {
+	case "", key.VPCFlowLogsDestinationCloudWatch, key.VPCFlowLogsDestinationS3:
+	default:
+		return nil, microerror.Maskf(invalidConfigError, "%T.VPCFlowLogs.Destination must be one of %#q, %#q or empty", config, key.VPCFlowLogsDestinationCloudWatch, key.VPCFlowLogsDestinationS3)
+	}
+	if config.VPCFlowLogs.Destination != "" {
+		if config.VPCFlowLogs.MaxAggregationInterval != 60 && config.VPCFlowLogs.MaxAggregationInterval != 600 {
+			return nil, microerror.Maskf(invalidConfigError, "%T.VPCFlowLogs.MaxAggregationInterval must be 60 or 600", config)
+		}
+		switch config.VPCFlowLogs.TrafficType {
+		case "ACCEPT", "ALL", "REJECT":
+		default:
+			return nil, microerror.Maskf(invalidConfigError, "%T.VPCFlowLogs.TrafficType must be one of ACCEPT, REJECT or ALL", config)
+		}
+	}
 	// GuestPrivateSubnetMaskBits && GuestPublicSubnetMaskBits has been
 	// validated on upper level because all IPAM related configuration
 	// information is present there.
 
 	newService := &Resource{
+		amiCatalog:           config.AMICatalog,
 		apiWhiteList:         config.APIWhitelist,
+		etcdMember:           config.EtcdMember,
+		g8sClient:            config.G8sClient,
 		hostClients:          config.HostClients,
+		k8sClient:            config.K8sClient,
 		logger:               config.Logger,
 		encrypterRoleManager: config.EncrypterRoleManager,
 
-		encrypterBackend:           config.EncrypterBackend,
-		guestPrivateSubnetMaskBits: config.GuestPrivateSubnetMaskBits,
-		guestPublicSubnetMaskBits:  config.GuestPublicSubnetMaskBits,
-		installationName:           config.InstallationName,
-		monitoring:                 config.AdvancedMonitoringEC2,
-		publicRouteTables:          config.PublicRouteTables,
-		route53Enabled:             config.Route53Enabled,
+		changeSetApprovalRequired:   config.ChangeSetApprovalRequired,
+		encrypterBackend:            config.EncrypterBackend,
+		guestPrivateSubnetMaskBits:  config.GuestPrivateSubnetMaskBits,
+		guestPublicSubnetMaskBits:   config.GuestPublicSubnetMaskBits,
+		ingressNLB:                  config.IngressNLB,
+		installationName:            config.InstallationName,
+		metadataTokensRequired:      config.MetadataTokensRequired,
+		monitoring:                  config.AdvancedMonitoringEC2,
+		publicRouteTables:           config.PublicRouteTables,
+		recoverDeleteFailed:         config.RecoverDeleteFailed,
+		recoverRollbackComplete:     config.RecoverRollbackComplete,
+		recoverUpdateRollbackFailed: config.RecoverUpdateRollbackFailed,
+		route53Enabled:              config.Route53Enabled,
+		transitGatewayID:            config.TransitGatewayID,
+		vpcEndpointsEnabled:         config.VPCEndpointsEnabled,
+		vpcFlowLogs:                 config.VPCFlowLogs,
+		workerRolloutHealthGated:    config.WorkerRolloutHealthGated,
 	}
 
 	return newService, nil
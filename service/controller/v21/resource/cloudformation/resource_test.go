@@ -7,7 +7,9 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	awscloudformation "github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	versionedfake "github.com/giantswarm/apiextensions/pkg/clientset/versioned/fake"
 	"github.com/giantswarm/micrologger/microloggertest"
+	"k8s.io/client-go/kubernetes/fake"
 
 	"github.com/giantswarm/aws-operator/service/controller/v21/adapter"
 )
@@ -56,6 +58,10 @@ func Test_Resource_Cloudformation_GetCloudFormationTags(t *testing.T) {
 
 	c := Config{}
 
+	c.AMICatalog = &AMICatalogMock{}
+	c.EtcdMember = &EtcdMemberMock{}
+	c.G8sClient = versionedfake.NewSimpleClientset()
+	c.K8sClient = fake.NewSimpleClientset()
 	c.HostClients = &adapter.Clients{
 		EC2:            &adapter.EC2ClientMock{},
 		CloudFormation: &adapter.CloudFormationMock{},
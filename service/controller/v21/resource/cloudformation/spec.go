@@ -22,15 +22,68 @@ type StackState struct {
 
 	HostedZoneNameServers string
 
+	// EncryptionKeyID is the ID of the encryption key created by the latest
+	// re-key of the guest cluster. Changing it replaces the masters and the
+	// workers so that no node relies on the previous encryption key anymore.
+	EncryptionKeyID string
+
+	// VolumeEncryptionKeyARN is the ARN of the KMS key the EBS volumes of the
+	// guest cluster are encrypted with. Changing it replaces the masters and
+	// the workers. EtcdVolumeEncryptionKeyARN is the ARN of the KMS key the etcd
+	// volumes of the masters are encrypted with. It is empty for guest clusters
+	// created before all EBS volumes got encrypted and never changes, because
+	// changing the encryption of the etcd volumes would replace them.
+	EtcdVolumeEncryptionKeyARN string
+	VolumeEncryptionKeyARN     string
+
+	// VPCID is the ID of the existing VPC the guest cluster is deployed into.
+	// It is empty in case the VPC is part of the guest cluster main stack. It
+	// never changes, because the VPC of existing guest clusters cannot be
+	// replaced.
+	VPCID string
+
+	// TransitGatewayID is the ID of the Transit Gateway the guest cluster VPC
+	// is attached to. It is empty in case the guest cluster VPC is peered with
+	// the host cluster VPC. It never changes, because switching existing guest
+	// clusters between peering and the Transit Gateway is not supported.
+	TransitGatewayID string
+
+	// NATGatewayStrategy and NATGatewayAllocationIDs describe the NAT gateways
+	// of the guest cluster VPC. They never change, because changing them would
+	// replace the NAT gateways and with them the egress IPs of existing guest
+	// clusters.
+	NATGatewayAllocationIDs []string
+	NATGatewayStrategy      string
+
+	// APIWhitelistCIDRs and IngressWhitelistCIDRs are the CIDRs whitelisted for
+	// the Kubernetes API and the ingress load balancer of the guest cluster.
+	// Changing them only updates the security groups of the guest cluster.
+	APIWhitelistCIDRs     []string
+	IngressWhitelistCIDRs []string
+
 	DockerVolumeResourceName   string
+	MasterCount                int
 	MasterImageID              string
 	MasterInstanceType         string
 	MasterInstanceResourceName string
 	MasterCloudConfigVersion   string
 	MasterInstanceMonitoring   bool
+	// Masters describes the masters of guest clusters running a highly
+	// available control plane. These masters are replaced one after another,
+	// which is why each of them tracks its own resources and the configuration
+	// it got launched with. Masters is empty for guest clusters running a
+	// single master.
+	Masters []StackStateMaster
+	// MasterToReplace is the resource name of the master instance replaced by
+	// an update. It is only set for update changes.
+	MasterToReplace string
 
 	ShouldScale  bool
 	ShouldUpdate bool
+	// UpdatePending is true in case the guest cluster main stack has to be
+	// updated but updates are not allowed, e.g. because the maintenance window
+	// of the guest cluster is closed. It is only set for update changes.
+	UpdatePending bool
 
 	WorkerCount              string
 	WorkerDockerVolumeSizeGB int
@@ -38,8 +91,56 @@ type StackState struct {
 	WorkerInstanceMonitoring bool
 	WorkerInstanceType       string
 	WorkerCloudConfigVersion string
+	// WorkerLaunchTemplate is true when the worker ASG launches its instances
+	// using an EC2 launch template instead of a launch configuration.
+	WorkerLaunchTemplate bool
+	// WorkerInstanceTypes, WorkerOnDemandBaseCapacity,
+	// WorkerSpotAllocationStrategy and WorkerSpotPercentage describe the mixed
+	// instances policy of the worker ASG. They are empty in case the workers are
+	// launched as on-demand instances of the worker instance type.
+	WorkerInstanceTypes          []string
+	WorkerOnDemandBaseCapacity   int
+	WorkerSpotAllocationStrategy string
+	WorkerSpotPercentage         int
+	// WorkerMaxSize and WorkerMinSize are the scaling bounds of the worker ASG
+	// in case the worker nodes are scaled by the cluster autoscaler. They are
+	// zero otherwise.
+	WorkerMaxSize int
+	WorkerMinSize int
+
+	// NodePools describes the node pools running next to the worker nodes of
+	// the worker ASG. Each node pool runs in its own ASG.
+	NodePools []StackStateNodePool
 
 	UpdateStackInput cloudformation.UpdateStackInput
 
 	VersionBundleVersion string
 }
+
+// StackStateMaster is the state representation of a single master of a highly
+// available control plane within the guest cluster main stack.
+type StackStateMaster struct {
+	CloudConfigVersion       string
+	DockerVolumeResourceName string
+	EncryptionKeyID          string
+	ImageID                  string
+	InstanceResourceName     string
+	InstanceType             string
+	VersionBundleVersion     string
+	VolumeEncryptionKeyARN   string
+}
+
+// StackStateNodePool is the state representation of a single node pool within
+// the guest cluster main stack.
+type StackStateNodePool struct {
+	Name string
+
+	// CloudConfig is the role the cloud config of the node pool is stored under
+	// in the S3 bucket. It changes together with the labels and taints of the
+	// node pool.
+	CloudConfig        string
+	DockerVolumeSizeGB int
+	InstanceType       string
+	MaxSize            int
+	MinSize            int
+}
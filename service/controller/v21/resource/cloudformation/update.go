@@ -3,6 +3,7 @@ package cloudformation
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/cloudformation"
@@ -27,6 +28,23 @@ func (r *Resource) ApplyUpdateChange(ctx context.Context, obj, updateChange inte
 		return microerror.Mask(err)
 	}
 
+	{
+		err := r.publishMaintenanceWindow(ctx, customObject, stackStateToUpdate.UpdatePending)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	// The egress IPs of the tenant cluster are the public IPs of its NAT
+	// gateways, which only exist once the main stack got created. We report
+	// them in the CR status.
+	{
+		err := r.publishEgressIPs(ctx, customObject)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
 	if stackStateToUpdate.Name != "" {
 		r.logger.LogCtx(ctx, "level", "debug", "message", "updating the guest cluster main stack")
 
@@ -35,58 +53,54 @@ func (r *Resource) ApplyUpdateChange(ctx context.Context, obj, updateChange inte
 			return microerror.Mask(err)
 		}
 
+		updateStackInput := stackStateToUpdate.UpdateStackInput
+		updateStackInput.Parameters = []*cloudformation.Parameter{
+			{
+				ParameterKey:   aws.String(versionBundleVersionParameterKey),
+				ParameterValue: aws.String(key.VersionBundleVersion(customObject)),
+			},
+		}
+
+		// We preview the update with a change set before anything is touched.
+		// This tells us which resources the update replaces and allows us to
+		// block updates replacing protected resources until they are approved.
+		cs, ok, err := r.ensureChangeSet(ctx, customObject, updateStackInput)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		if !ok {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "not updating the guest cluster main stack because the update does not contain any changes")
+			return nil
+		}
+		if r.isChangeSetBlocked(customObject, cs) {
+			r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("not updating the guest cluster main stack because change set %#q replaces protected resources %#q and is not approved", cs.Name, cs.Protected))
+			return nil
+		}
+
 		if stackStateToUpdate.ShouldUpdate && !stackStateToUpdate.ShouldScale {
 			{
-				// Fetch the etcd volume information.
-				filterFuncs := []func(t *ec2.Tag) bool{
-					ebs.NewDockerVolumeFilter(customObject),
-					ebs.NewEtcdVolumeFilter(customObject),
-				}
-				volumes, err := sc.EBSService.ListVolumes(customObject, filterFuncs...)
+				err := r.stopMasterInstance(ctx, customObject, stackStateToUpdate.MasterToReplace)
 				if err != nil {
 					return microerror.Mask(err)
 				}
-
-				// First shutdown the instances and wait for it to be stopped. Then detach
-				// the etcd and docker volume without forcing.
-				force := false
-				shutdown := true
-				wait := true
-
-				for _, v := range volumes {
-					for _, a := range v.Attachments {
-						err := sc.EBSService.DetachVolume(ctx, v.VolumeID, a, force, shutdown, wait)
-						if err != nil {
-							return microerror.Mask(err)
-						}
-					}
-				}
 			}
 
 			{
-				err := r.terminateOldMasterInstance(ctx, obj)
+				err := r.terminateOldMasterInstance(ctx, customObject, stackStateToUpdate.MasterToReplace)
 				if err != nil {
 					return microerror.Mask(err)
 				}
 			}
 		}
 
-		customObject, err := key.ToCustomObject(obj)
-		if err != nil {
-			return microerror.Mask(err)
-		}
-
-		updateStackInput := stackStateToUpdate.UpdateStackInput
-		updateStackInput.Parameters = []*cloudformation.Parameter{
-			{
-				ParameterKey:   aws.String(versionBundleVersionParameterKey),
-				ParameterValue: aws.String(key.VersionBundleVersion(customObject)),
-			},
-		}
-
 		// Once the etcd volume is cleaned up and the master instance is down we can
-		// go ahead to let CloudFormation do its job.
-		_, err = sc.AWSClient.CloudFormation.UpdateStack(&updateStackInput)
+		// go ahead to let CloudFormation do its job. Executing the change set
+		// guarantees that exactly the previewed changes are applied.
+		i := &cloudformation.ExecuteChangeSetInput{
+			ChangeSetName: aws.String(cs.Name),
+			StackName:     updateStackInput.StackName,
+		}
+		_, err = sc.AWSClient.CloudFormation.ExecuteChangeSet(i)
 		if err != nil {
 			return microerror.Mask(err)
 		}
@@ -149,6 +163,62 @@ func (r *Resource) newUpdateChange(ctx context.Context, obj, currentState, desir
 		return StackState{}, microerror.Mask(err)
 	}
 
+	if currentStackState.Name != "" {
+		err := r.validateClusterUpdate(ctx, customObject)
+		if err != nil {
+			return StackState{}, microerror.Mask(err)
+		}
+	}
+
+	// The etcd cluster of the masters is formed when the guest cluster is
+	// created. It cannot be reconfigured by replacing masters, which is why
+	// changing the number of masters of existing guest clusters is not
+	// supported. We keep the current number of masters in such cases.
+	if currentStackState.MasterCount != 0 && currentStackState.MasterCount != desiredStackState.MasterCount {
+		r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("not changing the number of masters from %d to %d since this is not supported for existing guest clusters", currentStackState.MasterCount, desiredStackState.MasterCount))
+		desiredStackState.MasterCount = currentStackState.MasterCount
+		desiredStackState.Masters = desiredMasters(desiredStackState)
+	}
+
+	// Changing the encryption of the etcd volumes replaces them and loses the
+	// etcd data, which is why the etcd volumes of existing guest clusters keep
+	// the encryption they were created with.
+	if currentStackState.Name != "" && currentStackState.EtcdVolumeEncryptionKeyARN != desiredStackState.EtcdVolumeEncryptionKeyARN {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("not changing the KMS key of the etcd volumes from %#q to %#q since this is not supported for existing guest clusters", currentStackState.EtcdVolumeEncryptionKeyARN, desiredStackState.EtcdVolumeEncryptionKeyARN))
+		desiredStackState.EtcdVolumeEncryptionKeyARN = currentStackState.EtcdVolumeEncryptionKeyARN
+	}
+
+	// Guest clusters are either deployed into an existing VPC or get their own
+	// VPC when they are created. Switching between both would replace the
+	// network of the guest cluster, which is why the VPC of existing guest
+	// clusters never changes.
+	if currentStackState.Name != "" && currentStackState.VPCID != desiredStackState.VPCID {
+		r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("not changing the VPC from %#q to %#q since this is not supported for existing guest clusters", currentStackState.VPCID, desiredStackState.VPCID))
+		desiredStackState.VPCID = currentStackState.VPCID
+	}
+
+	// Guest clusters are attached to the Transit Gateway when they are created.
+	// Existing guest clusters keep being peered with the host cluster VPC,
+	// because their peering connection is still used by the routes of the host
+	// cluster and the host pre stack owning it is never updated.
+	if currentStackState.Name != "" && currentStackState.TransitGatewayID != desiredStackState.TransitGatewayID {
+		r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("not changing the Transit Gateway from %#q to %#q since this is not supported for existing guest clusters", currentStackState.TransitGatewayID, desiredStackState.TransitGatewayID))
+		desiredStackState.TransitGatewayID = currentStackState.TransitGatewayID
+	}
+
+	// The NAT gateways of existing guest clusters are never replaced, because
+	// the egress IPs of the guest cluster might be whitelisted by customers.
+	if currentStackState.Name != "" && (currentStackState.NATGatewayStrategy != desiredStackState.NATGatewayStrategy || strings.Join(currentStackState.NATGatewayAllocationIDs, ",") != strings.Join(desiredStackState.NATGatewayAllocationIDs, ",")) {
+		r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("not changing the NAT gateway strategy from %#q to %#q and the allocation IDs from %#q to %#q since this is not supported for existing guest clusters", currentStackState.NATGatewayStrategy, desiredStackState.NATGatewayStrategy, currentStackState.NATGatewayAllocationIDs, desiredStackState.NATGatewayAllocationIDs))
+		desiredStackState.NATGatewayAllocationIDs = currentStackState.NATGatewayAllocationIDs
+		desiredStackState.NATGatewayStrategy = currentStackState.NATGatewayStrategy
+	}
+
+	// Updates are not allowed outside of the maintenance window of the tenant
+	// cluster. The update change tells whether an update waits for it, which is
+	// reported in the CR status when the change gets applied.
+	updatePending := !updateallowedcontext.IsUpdateAllowed(ctx) && currentStackState.Name != "" && (shouldUpdate(currentStackState, desiredStackState) || shouldUpdateMasters(currentStackState, desiredStackState) || shouldUpdateWorkers(currentStackState, desiredStackState))
+
 	// We enable/disable updates in order to enable them our test installations
 	// but disable them in production installations. That is useful until we have
 	// full confidence in updating guest clusters. Note that updates also manage
@@ -156,9 +226,45 @@ func (r *Resource) newUpdateChange(ctx context.Context, obj, currentState, desir
 	if updateallowedcontext.IsUpdateAllowed(ctx) {
 		r.logger.LogCtx(ctx, "level", "debug", "message", "finding out if the guest cluster main stack has to be updated")
 
-		if shouldUpdate(currentStackState, desiredStackState) {
+		if shouldUpdate(currentStackState, desiredStackState) || shouldUpdateMasters(currentStackState, desiredStackState) {
 			r.logger.LogCtx(ctx, "level", "debug", "message", "the guest cluster main stack has to be updated")
 
+			masterToReplace := currentStackState.MasterInstanceResourceName
+			shouldScale := false
+
+			// Masters of a highly available control plane are replaced one after
+			// another. All other masters keep their current state. The next
+			// master is only replaced once the etcd member of the master replaced
+			// before rejoined the etcd cluster. This way the etcd cluster never
+			// loses its quorum. Updates not replacing any master are processed
+			// like scaling to prevent the master instances from being stopped.
+			if len(currentStackState.Masters) > 1 {
+				masters := append([]StackStateMaster{}, currentStackState.Masters...)
+
+				idx := nextMasterToReplace(currentStackState, desiredStackState)
+				if idx < 0 {
+					masterToReplace = ""
+					shouldScale = true
+				} else {
+					healthy, err := r.etcdClusterHealthy(ctx, customObject, len(masters))
+					if err != nil {
+						return StackState{}, microerror.Mask(err)
+					}
+					if !healthy {
+						r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("not replacing master %d because the etcd cluster is not healthy", idx))
+						r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+						return StackState{}, nil
+					}
+
+					r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("replacing master %d", idx))
+
+					masters[idx] = desiredStackState.Masters[idx]
+					masterToReplace = currentStackState.Masters[idx].InstanceResourceName
+				}
+
+				desiredStackState.Masters = masters
+			}
+
 			updateStackInput, err := r.computeUpdateState(ctx, customObject, desiredStackState)
 			if err != nil {
 				return StackState{}, microerror.Mask(err)
@@ -166,8 +272,9 @@ func (r *Resource) newUpdateChange(ctx context.Context, obj, currentState, desir
 
 			updateState := StackState{
 				Name:             desiredStackState.Name,
-				ShouldScale:      false,
-				ShouldUpdate:     true,
+				MasterToReplace:  masterToReplace,
+				ShouldScale:      shouldScale,
+				ShouldUpdate:     !shouldScale,
 				UpdateStackInput: updateStackInput,
 			}
 
@@ -175,6 +282,37 @@ func (r *Resource) newUpdateChange(ctx context.Context, obj, currentState, desir
 		} else {
 			r.logger.LogCtx(ctx, "level", "debug", "message", "the guest cluster main stack does not have to be updated")
 		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "finding out if the guest cluster worker ASG has to be updated")
+
+		// Some updates only affect the worker ASGs, e.g. the migration of the
+		// worker launch configuration to a launch template, changes of the
+		// instance distribution of the workers or changes of a single node pool.
+		// We preserve the master resources and mark the update as scaling in such
+		// cases. This prevents the master instances from being stopped.
+		if shouldUpdateWorkers(currentStackState, desiredStackState) {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "the guest cluster worker ASG has to be updated")
+
+			desiredStackState.MasterInstanceResourceName = currentStackState.MasterInstanceResourceName
+			desiredStackState.DockerVolumeResourceName = currentStackState.DockerVolumeResourceName
+			desiredStackState.Masters = currentStackState.Masters
+
+			updateStackInput, err := r.computeUpdateState(ctx, customObject, desiredStackState)
+			if err != nil {
+				return StackState{}, microerror.Mask(err)
+			}
+
+			updateState := StackState{
+				Name:             desiredStackState.Name,
+				ShouldScale:      true,
+				ShouldUpdate:     false,
+				UpdateStackInput: updateStackInput,
+			}
+
+			return updateState, nil
+		} else {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "the guest cluster worker ASG does not have to be updated")
+		}
 	} else {
 		r.logger.LogCtx(ctx, "level", "debug", "message", "not computing update state of the guest cluster main stack because updates are not allowed")
 	}
@@ -195,6 +333,7 @@ func (r *Resource) newUpdateChange(ctx context.Context, obj, currentState, desir
 
 			desiredStackState.MasterInstanceResourceName = currentStackState.MasterInstanceResourceName
 			desiredStackState.DockerVolumeResourceName = currentStackState.DockerVolumeResourceName
+			desiredStackState.Masters = currentStackState.Masters
 
 			updateStackInput, err := r.computeUpdateState(ctx, customObject, desiredStackState)
 			if err != nil {
@@ -205,6 +344,7 @@ func (r *Resource) newUpdateChange(ctx context.Context, obj, currentState, desir
 				Name:             desiredStackState.Name,
 				ShouldScale:      true,
 				ShouldUpdate:     false,
+				UpdatePending:    updatePending,
 				UpdateStackInput: updateStackInput,
 			}
 
@@ -214,15 +354,60 @@ func (r *Resource) newUpdateChange(ctx context.Context, obj, currentState, desir
 		}
 	}
 
-	return StackState{}, nil
+	// Changes of the whitelisted CIDRs of the guest cluster only affect its
+	// security groups. They do not disrupt the guest cluster and are processed
+	// any time, just like scaling. We preserve the master resources and mark
+	// the update as scaling to prevent the master instances from being stopped.
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "finding out if the guest cluster security groups have to be updated")
+
+		if shouldUpdateSecurityGroups(currentStackState, desiredStackState) {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "the guest cluster security groups have to be updated")
+
+			desiredStackState.MasterInstanceResourceName = currentStackState.MasterInstanceResourceName
+			desiredStackState.DockerVolumeResourceName = currentStackState.DockerVolumeResourceName
+			desiredStackState.Masters = currentStackState.Masters
+
+			updateStackInput, err := r.computeUpdateState(ctx, customObject, desiredStackState)
+			if err != nil {
+				return StackState{}, microerror.Mask(err)
+			}
+
+			updateState := StackState{
+				Name:             desiredStackState.Name,
+				ShouldScale:      true,
+				ShouldUpdate:     false,
+				UpdatePending:    updatePending,
+				UpdateStackInput: updateStackInput,
+			}
+
+			return updateState, nil
+		} else {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "the guest cluster security groups do not have to be updated")
+		}
+	}
+
+	updateState := StackState{
+		UpdatePending: updatePending,
+	}
+
+	return updateState, nil
 }
 
 // shouldScale determines whether the reconciled guest cluster should be scaled.
-// A guest cluster is only allowed to scale in case nothing but the worker count
-// changes. In case anything else changes as well, scaling is not allowed, since
-// any other changes should be covered by general updates, which is a separate
-// step.
+// A guest cluster is only allowed to scale in case nothing but the worker count,
+// the scaling bounds or the node pool sizes change. In case anything else
+// changes as well, scaling is not allowed, since any other changes should be
+// covered by general updates, which is a separate step.
 func (r *Resource) shouldScale(ctx context.Context, currentState, desiredState StackState) bool {
+	if desiredState.EncryptionKeyID != "" && currentState.EncryptionKeyID != desiredState.EncryptionKeyID {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not scaling due to encryption key id")
+		return false
+	}
+	if desiredState.VolumeEncryptionKeyARN != "" && currentState.VolumeEncryptionKeyARN != desiredState.VolumeEncryptionKeyARN {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not scaling due to volume encryption key")
+		return false
+	}
 	if currentState.MasterImageID != desiredState.MasterImageID {
 		r.logger.LogCtx(ctx, "level", "debug", "message", "not scaling due to master image id")
 		return false
@@ -255,8 +440,41 @@ func (r *Resource) shouldScale(ctx context.Context, currentState, desiredState S
 		r.logger.LogCtx(ctx, "level", "debug", "message", "not scaling due to version bundle version")
 		return false
 	}
+	if currentState.WorkerLaunchTemplate != desiredState.WorkerLaunchTemplate {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not scaling due to worker launch template migration")
+		return false
+	}
+	if strings.Join(currentState.WorkerInstanceTypes, ",") != strings.Join(desiredState.WorkerInstanceTypes, ",") {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not scaling due to worker instance types")
+		return false
+	}
+	if currentState.WorkerOnDemandBaseCapacity != desiredState.WorkerOnDemandBaseCapacity {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not scaling due to worker on-demand base capacity")
+		return false
+	}
+	if currentState.WorkerSpotAllocationStrategy != desiredState.WorkerSpotAllocationStrategy {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not scaling due to worker spot allocation strategy")
+		return false
+	}
+	if currentState.WorkerSpotPercentage != desiredState.WorkerSpotPercentage {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not scaling due to worker spot percentage")
+		return false
+	}
+	if shouldUpdateNodePools(currentState, desiredState) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not scaling due to node pool configuration")
+		return false
+	}
 
-	if currentState.WorkerCount != desiredState.WorkerCount {
+	if currentState.WorkerMaxSize != desiredState.WorkerMaxSize || currentState.WorkerMinSize != desiredState.WorkerMinSize {
+		return true
+	}
+	// The number of worker nodes is managed by the cluster autoscaler in case
+	// scaling bounds are configured. Scaling the worker ASG to the configured
+	// number of workers would fight the cluster autoscaler.
+	if desiredState.WorkerMaxSize == 0 && currentState.WorkerCount != desiredState.WorkerCount {
+		return true
+	}
+	if shouldScaleNodePools(currentState, desiredState) {
 		return true
 	}
 
@@ -270,8 +488,16 @@ func (r *Resource) shouldScale(ctx context.Context, currentState, desiredState S
 //     The instance type of worker nodes changes (indicates updates).
 //     The size of a docker volume for worker nodes changes.
 //     The version bundle version changes (indicates updates).
+//     The guest cluster got re-keyed.
+//     The KMS key of the EBS volumes changes.
 //
 func shouldUpdate(currentState, desiredState StackState) bool {
+	if desiredState.EncryptionKeyID != "" && currentState.EncryptionKeyID != desiredState.EncryptionKeyID {
+		return true
+	}
+	if desiredState.VolumeEncryptionKeyARN != "" && currentState.VolumeEncryptionKeyARN != desiredState.VolumeEncryptionKeyARN {
+		return true
+	}
 	if currentState.MasterInstanceType != desiredState.MasterInstanceType {
 		return true
 	}
@@ -288,20 +514,299 @@ func shouldUpdate(currentState, desiredState StackState) bool {
 	return false
 }
 
-// Terminates the master instance of the cluster.
+// shouldUpdateMasters determines whether any master of a highly available
+// control plane has to be replaced. Masters are replaced one after another,
+// which is why the guest cluster main stack is updated for each of them.
+func shouldUpdateMasters(currentState, desiredState StackState) bool {
+	return nextMasterToReplace(currentState, desiredState) >= 0
+}
+
+// nextMasterToReplace returns the index of the first master of a highly
+// available control plane which has to be replaced. This is the case for the
+// same changes as for general updates. It returns -1 in case no master has to
+// be replaced.
+func nextMasterToReplace(currentState, desiredState StackState) int {
+	if len(currentState.Masters) != len(desiredState.Masters) {
+		return -1
+	}
+
+	for i, c := range currentState.Masters {
+		d := desiredState.Masters[i]
+
+		if d.EncryptionKeyID != "" && c.EncryptionKeyID != d.EncryptionKeyID {
+			return i
+		}
+		if d.VolumeEncryptionKeyARN != "" && c.VolumeEncryptionKeyARN != d.VolumeEncryptionKeyARN {
+			return i
+		}
+		if c.InstanceType != d.InstanceType {
+			return i
+		}
+		if c.VersionBundleVersion != d.VersionBundleVersion {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// shouldUpdateWorkers determines whether only the worker ASG of the reconciled
+// guest cluster has to be updated. These updates roll the worker nodes of the
+// guest cluster and are therefore only done when updates are allowed. The
+// worker ASG is updated in the following cases.
 //
-// To detect the old master instance we find the instance by its name and
-// the instance state "stopped". Within the upgrade process the master first
-// gets stopped and its volumes get detached. This function makes sure that
-// the stopped instance is also terminated.
-func (r *Resource) terminateOldMasterInstance(ctx context.Context, obj interface{}) error {
-	var result *ec2.DescribeInstancesOutput
+//     The worker launch configuration has to be migrated to a launch template.
+//     The acceptable instance types of worker nodes change.
+//     The on-demand base capacity of worker nodes changes.
+//     The spot allocation strategy of worker nodes changes.
+//     The spot percentage of worker nodes changes.
+//     The configuration of the worker nodes of a node pool changes.
+//
+func shouldUpdateWorkers(currentState, desiredState StackState) bool {
+	if currentState.Name == "" {
+		return false
+	}
 
-	customObject, err := key.ToCustomObject(obj)
+	if !currentState.WorkerLaunchTemplate && desiredState.WorkerLaunchTemplate {
+		return true
+	}
+	if strings.Join(currentState.WorkerInstanceTypes, ",") != strings.Join(desiredState.WorkerInstanceTypes, ",") {
+		return true
+	}
+	if currentState.WorkerOnDemandBaseCapacity != desiredState.WorkerOnDemandBaseCapacity {
+		return true
+	}
+	if currentState.WorkerSpotAllocationStrategy != desiredState.WorkerSpotAllocationStrategy {
+		return true
+	}
+	if currentState.WorkerSpotPercentage != desiredState.WorkerSpotPercentage {
+		return true
+	}
+	if shouldUpdateNodePools(currentState, desiredState) {
+		return true
+	}
+
+	return false
+}
+
+// shouldUpdateSecurityGroups determines whether only the security groups of the
+// reconciled guest cluster have to be updated. This is the case when the CIDRs
+// whitelisted for the Kubernetes API or the ingress load balancer change.
+// Security groups are not updated separately as long as any change affecting
+// the nodes of the guest cluster is pending, because the update would apply it
+// as well. The pending change covers the security groups once it is processed.
+func shouldUpdateSecurityGroups(currentState, desiredState StackState) bool {
+	if currentState.Name == "" {
+		return false
+	}
+
+	if strings.Join(currentState.APIWhitelistCIDRs, ",") == strings.Join(desiredState.APIWhitelistCIDRs, ",") && strings.Join(currentState.IngressWhitelistCIDRs, ",") == strings.Join(desiredState.IngressWhitelistCIDRs, ",") {
+		return false
+	}
+
+	if shouldUpdate(currentState, desiredState) || shouldUpdateMasters(currentState, desiredState) || shouldUpdateWorkers(currentState, desiredState) {
+		return false
+	}
+	if currentState.MasterImageID != desiredState.MasterImageID || currentState.MasterCloudConfigVersion != desiredState.MasterCloudConfigVersion {
+		return false
+	}
+	if currentState.WorkerImageID != desiredState.WorkerImageID || currentState.WorkerCloudConfigVersion != desiredState.WorkerCloudConfigVersion {
+		return false
+	}
+
+	return true
+}
+
+// shouldScaleNodePools determines whether the node pools of the reconciled
+// guest cluster have to be scaled. This is the case when node pools are added
+// or removed, or when the size of an existing node pool changes.
+func shouldScaleNodePools(currentState, desiredState StackState) bool {
+	if len(currentState.NodePools) != len(desiredState.NodePools) {
+		return true
+	}
+
+	current := nodePoolsByName(currentState.NodePools)
+	for _, d := range desiredState.NodePools {
+		c, ok := current[d.Name]
+		if !ok {
+			return true
+		}
+		if c.MaxSize != d.MaxSize || c.MinSize != d.MinSize {
+			return true
+		}
+	}
+
+	return false
+}
+
+// shouldUpdateNodePools determines whether the worker nodes of any node pool
+// existing in the current and the desired state have to be replaced. This is
+// the case when the instance type, the docker volume size or the cloud config
+// of the node pool changes. Node pools being added or removed are handled by
+// scaling.
+func shouldUpdateNodePools(currentState, desiredState StackState) bool {
+	current := nodePoolsByName(currentState.NodePools)
+	for _, d := range desiredState.NodePools {
+		c, ok := current[d.Name]
+		if !ok {
+			continue
+		}
+		if c.CloudConfig != d.CloudConfig {
+			return true
+		}
+		if c.DockerVolumeSizeGB != d.DockerVolumeSizeGB {
+			return true
+		}
+		if c.InstanceType != d.InstanceType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func nodePoolsByName(nodePools []StackStateNodePool) map[string]StackStateNodePool {
+	m := map[string]StackStateNodePool{}
+	for _, p := range nodePools {
+		m[p.Name] = p
+	}
+
+	return m
+}
+
+// etcdClusterHealthy returns whether all etcd members of a highly available
+// control plane are healthy.
+func (r *Resource) etcdClusterHealthy(ctx context.Context, customObject v1alpha1.AWSConfig, masterCount int) (bool, error) {
+	for i := 0; i < masterCount; i++ {
+		healthy, err := r.etcdMember.Healthy(ctx, customObject, i)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+
+		if !healthy {
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("etcd member %#q is not healthy", key.EtcdMemberName(i)))
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// findMasterInstances returns the IDs of the master instances of the cluster in
+// the given states. The master instances are found by their name. In case a
+// resource name is given, only the master instance created for this
+// CloudFormation resource is returned. Guest clusters with a highly available
+// control plane have multiple masters sharing the same name.
+func (r *Resource) findMasterInstances(ctx context.Context, customObject v1alpha1.AWSConfig, resourceName string, states ...string) ([]*string, error) {
+	sc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	i := &ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name: aws.String("tag:Name"),
+				Values: []*string{
+					aws.String(key.MasterInstanceName(customObject)),
+				},
+			},
+			{
+				Name:   aws.String("instance-state-name"),
+				Values: aws.StringSlice(states),
+			},
+			{
+				Name: aws.String("tag:giantswarm.io/cluster"),
+				Values: []*string{
+					aws.String(key.ClusterID(customObject)),
+				},
+			},
+		},
+	}
+	if resourceName != "" {
+		i.Filters = append(i.Filters, &ec2.Filter{
+			Name: aws.String("tag:aws:cloudformation:logical-id"),
+			Values: []*string{
+				aws.String(resourceName),
+			},
+		})
+	}
+
+	result, err := sc.AWSClient.EC2.DescribeInstances(i)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var instanceIDs []*string
+	for _, reservation := range result.Reservations {
+		for _, instance := range reservation.Instances {
+			instanceIDs = append(instanceIDs, instance.InstanceId)
+		}
+	}
+
+	return instanceIDs, nil
+}
+
+// stopMasterInstance stops the master instance replaced by the update and
+// detaches its etcd and docker volumes without forcing. In case no resource
+// name is given, all master instances are stopped.
+func (r *Resource) stopMasterInstance(ctx context.Context, customObject v1alpha1.AWSConfig, resourceName string) error {
+	sc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	instanceIDs, err := r.findMasterInstances(ctx, customObject, resourceName, "pending", "running", "stopping", "stopped")
 	if err != nil {
 		return microerror.Mask(err)
 	}
 
+	replaced := map[string]bool{}
+	for _, id := range instanceIDs {
+		replaced[*id] = true
+	}
+
+	// Fetch the etcd volume information.
+	filterFuncs := []func(t *ec2.Tag) bool{
+		ebs.NewDockerVolumeFilter(customObject),
+		ebs.NewEtcdVolumeFilter(customObject),
+	}
+	volumes, err := sc.EBSService.ListVolumes(customObject, filterFuncs...)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	// First shutdown the instance and wait for it to be stopped. Then detach
+	// the etcd and docker volume without forcing. The volumes of the other
+	// masters of a highly available control plane stay untouched.
+	force := false
+	shutdown := true
+	wait := true
+
+	for _, v := range volumes {
+		for _, a := range v.Attachments {
+			if resourceName != "" && !replaced[a.InstanceID] {
+				continue
+			}
+
+			err := sc.EBSService.DetachVolume(ctx, v.VolumeID, a, force, shutdown, wait)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Terminates the master instance replaced by the update.
+//
+// To detect the old master instance we find the instance by its name, its
+// resource name and the instance state "stopped". Within the upgrade process
+// the master first gets stopped and its volumes get detached. This function
+// makes sure that the stopped instance is also terminated. In case no resource
+// name is given, all stopped master instances are terminated.
+func (r *Resource) terminateOldMasterInstance(ctx context.Context, customObject v1alpha1.AWSConfig, resourceName string) error {
 	instanceName := key.MasterInstanceName(customObject)
 	instanceState := "stopped"
 
@@ -310,74 +815,43 @@ func (r *Resource) terminateOldMasterInstance(ctx context.Context, obj interface
 		return microerror.Mask(err)
 	}
 
+	var instanceIDs []*string
 	{
-		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("finding master instance with name %#q in state %#q", instanceName, instanceState))
-
-		i := &ec2.DescribeInstancesInput{
-			Filters: []*ec2.Filter{
-				{
-					Name: aws.String("tag:Name"),
-					Values: []*string{
-						aws.String(instanceName),
-					},
-				},
-				{
-					Name: aws.String("instance-state-name"),
-					Values: []*string{
-						aws.String(instanceState),
-					},
-				},
-				{
-					Name: aws.String("tag:giantswarm.io/cluster"),
-					Values: []*string{
-						aws.String(key.ClusterID(customObject)),
-					},
-				},
-			},
-		}
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("finding master instances with name %#q in state %#q", instanceName, instanceState))
 
-		result, err = sc.AWSClient.EC2.DescribeInstances(i)
+		instanceIDs, err = r.findMasterInstances(ctx, customObject, resourceName, instanceState)
 		if err != nil {
 			return microerror.Mask(err)
 		}
 
-		if len(result.Reservations) > 1 {
-			return microerror.Maskf(executionFailedError, "expected at most one master instance with name %#q in state %#q but got %d", instanceName, instanceState, len(result.Reservations))
-		}
-
-		if len(result.Reservations) < 1 {
-			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("did not find master instance with name %#q in state %#q", instanceName, instanceState))
-			return nil
+		maxInstances := key.MasterCountHA
+		if resourceName != "" {
+			maxInstances = 1
 		}
-
-		if len(result.Reservations[0].Instances) > 1 {
-			return microerror.Maskf(executionFailedError, "expected at most one master instance with name %#q in state %#q but got %d", instanceName, instanceState, len(result.Reservations))
+		if len(instanceIDs) > maxInstances {
+			return microerror.Maskf(executionFailedError, "expected at most %d master instances with name %#q in state %#q but got %d", maxInstances, instanceName, instanceState, len(instanceIDs))
 		}
 
-		if len(result.Reservations[0].Instances) < 1 {
-			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("did not find master instance with state `stopped` and name %#q", key.MasterInstanceName(customObject)))
+		if len(instanceIDs) < 1 {
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("did not find master instances with name %#q in state %#q", instanceName, instanceState))
 			return nil
 		}
 
-		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found master instance with name %#q in state %#q", instanceName, instanceState))
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found %d master instances with name %#q in state %#q", len(instanceIDs), instanceName, instanceState))
 	}
 
 	{
-		instanceID := *result.Reservations[0].Instances[0].InstanceId
-
-		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("terminating master instance with ID %#q", instanceID))
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("terminating master instances with IDs %#q", aws.StringValueSlice(instanceIDs)))
 
 		i := &ec2.TerminateInstancesInput{
-			InstanceIds: []*string{
-				aws.String(instanceID),
-			},
+			InstanceIds: instanceIDs,
 		}
 		_, err := sc.AWSClient.EC2.TerminateInstances(i)
 		if err != nil {
 			return microerror.Mask(err)
 		}
 
-		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("terminated master instance with ID %#q", instanceID))
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("terminated master instances with IDs %#q", aws.StringValueSlice(instanceIDs)))
 	}
 
 	return nil
@@ -2,17 +2,22 @@ package cloudformation
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws"
 	awscloudformation "github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	versionedfake "github.com/giantswarm/apiextensions/pkg/clientset/versioned/fake"
 	"github.com/giantswarm/micrologger/microloggertest"
 	"github.com/giantswarm/operatorkit/controller/context/updateallowedcontext"
+	"k8s.io/client-go/kubernetes/fake"
 
 	awsclient "github.com/giantswarm/aws-operator/client/aws"
 	"github.com/giantswarm/aws-operator/service/controller/v21/adapter"
 	"github.com/giantswarm/aws-operator/service/controller/v21/controllercontext"
+	"github.com/giantswarm/aws-operator/service/controller/v21/key"
 )
 
 func Test_Resource_Cloudformation_newUpdateChange_updatesAllowed(t *testing.T) {
@@ -389,6 +394,290 @@ func Test_Resource_Cloudformation_newUpdateChange_updatesAllowed(t *testing.T) {
 				StackName: aws.String("desired"),
 			},
 		},
+		{
+			description: "case 11, current state not empty, desired state not empty, worker launch configuration not migrated, expected desired state",
+			currentState: StackState{
+				Name: "current",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+				WorkerLaunchTemplate:     false,
+
+				VersionBundleVersion: "1.0.0",
+			},
+			desiredState: StackState{
+				Name: "desired",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+				WorkerLaunchTemplate:     true,
+
+				VersionBundleVersion: "1.0.0",
+			},
+			expectedChange: awscloudformation.UpdateStackInput{
+				StackName: aws.String("desired"),
+			},
+		},
+		{
+			description: "case 12, current state not empty, desired state not empty, different worker instance distribution, expected desired state",
+			currentState: StackState{
+				Name: "current",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+				WorkerLaunchTemplate:     true,
+
+				WorkerInstanceTypes:          []string{"m3.large"},
+				WorkerOnDemandBaseCapacity:   0,
+				WorkerSpotAllocationStrategy: "lowest-price",
+				WorkerSpotPercentage:         50,
+
+				VersionBundleVersion: "1.0.0",
+			},
+			desiredState: StackState{
+				Name: "desired",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+				WorkerLaunchTemplate:     true,
+
+				WorkerInstanceTypes:          []string{"m3.large", "m4.large"},
+				WorkerOnDemandBaseCapacity:   0,
+				WorkerSpotAllocationStrategy: "lowest-price",
+				WorkerSpotPercentage:         100,
+
+				VersionBundleVersion: "1.0.0",
+			},
+			expectedChange: awscloudformation.UpdateStackInput{
+				StackName: aws.String("desired"),
+			},
+		},
+		{
+			description: "case 13, current state not empty, desired state not empty, different node pool instance type, expected desired state",
+			currentState: StackState{
+				Name: "current",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+				WorkerLaunchTemplate:     true,
+
+				NodePools: []StackStateNodePool{
+					{
+						Name: "gpu",
+
+						CloudConfig:        "worker-gpu-1234abcd",
+						DockerVolumeSizeGB: 100,
+						InstanceType:       "p3.2xlarge",
+						MaxSize:            4,
+						MinSize:            1,
+					},
+				},
+
+				VersionBundleVersion: "1.0.0",
+			},
+			desiredState: StackState{
+				Name: "desired",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+				WorkerLaunchTemplate:     true,
+
+				NodePools: []StackStateNodePool{
+					{
+						Name: "gpu",
+
+						CloudConfig:        "worker-gpu-1234abcd",
+						DockerVolumeSizeGB: 100,
+						InstanceType:       "p3.8xlarge",
+						MaxSize:            4,
+						MinSize:            1,
+					},
+				},
+
+				VersionBundleVersion: "1.0.0",
+			},
+			expectedChange: awscloudformation.UpdateStackInput{
+				StackName: aws.String("desired"),
+			},
+		},
+		{
+			description: "case 14, current state not empty, desired state not empty, additional node pool, expected desired state",
+			currentState: StackState{
+				Name: "current",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+				WorkerLaunchTemplate:     true,
+
+				NodePools: []StackStateNodePool{
+					{
+						Name: "gpu",
+
+						CloudConfig:        "worker-gpu-1234abcd",
+						DockerVolumeSizeGB: 100,
+						InstanceType:       "p3.2xlarge",
+						MaxSize:            4,
+						MinSize:            1,
+					},
+				},
+
+				VersionBundleVersion: "1.0.0",
+			},
+			desiredState: StackState{
+				Name: "desired",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+				WorkerLaunchTemplate:     true,
+
+				NodePools: []StackStateNodePool{
+					{
+						Name: "gpu",
+
+						CloudConfig:        "worker-gpu-1234abcd",
+						DockerVolumeSizeGB: 100,
+						InstanceType:       "p3.2xlarge",
+						MaxSize:            4,
+						MinSize:            1,
+					},
+					{
+						Name: "memory",
+
+						CloudConfig:        "worker-memory-1234abcd",
+						DockerVolumeSizeGB: 100,
+						InstanceType:       "r5.xlarge",
+						MaxSize:            4,
+						MinSize:            3,
+					},
+				},
+
+				VersionBundleVersion: "1.0.0",
+			},
+			expectedChange: awscloudformation.UpdateStackInput{
+				StackName: aws.String("desired"),
+			},
+		},
+		{
+			description: "case 15, current state not empty, desired state not empty, guest cluster re-keyed, expected desired state",
+			currentState: StackState{
+				Name: "current",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+
+				VersionBundleVersion: "1.0.0",
+			},
+			desiredState: StackState{
+				Name: "desired",
+
+				EncryptionKeyID: "new-key",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+
+				VersionBundleVersion: "1.0.0",
+			},
+			expectedChange: awscloudformation.UpdateStackInput{
+				StackName: aws.String("desired"),
+			},
+		},
+		{
+			description: "case 16, current state not empty, desired state not empty, volumes not yet encrypted, expected desired state",
+			currentState: StackState{
+				Name: "current",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+
+				VersionBundleVersion: "1.0.0",
+			},
+			desiredState: StackState{
+				Name: "desired",
+
+				EtcdVolumeEncryptionKeyARN: "arn:aws:kms:eu-central-1:000000000000:key/test-key",
+				VolumeEncryptionKeyARN:     "arn:aws:kms:eu-central-1:000000000000:key/test-key",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+
+				VersionBundleVersion: "1.0.0",
+			},
+			expectedChange: awscloudformation.UpdateStackInput{
+				StackName: aws.String("desired"),
+			},
+		},
 	}
 
 	var err error
@@ -396,6 +685,10 @@ func Test_Resource_Cloudformation_newUpdateChange_updatesAllowed(t *testing.T) {
 	{
 		c := Config{}
 
+		c.AMICatalog = &AMICatalogMock{}
+		c.EtcdMember = &EtcdMemberMock{}
+		c.G8sClient = versionedfake.NewSimpleClientset()
+		c.K8sClient = fake.NewSimpleClientset()
 		c.HostClients = &adapter.Clients{
 			IAM: &adapter.IAMClientMock{},
 			EC2: &adapter.EC2ClientMock{},
@@ -848,33 +1141,441 @@ func Test_Resource_Cloudformation_newUpdateChange_updatesNotAllowed(t *testing.T
 				StackName: aws.String(""),
 			},
 		},
-	}
+		{
+			description: "case 12, current state not empty, desired state not empty, different number of workers and worker launch configuration not migrated, expected empty state",
+			currentState: StackState{
+				Name: "current",
 
-	var err error
-	var newResource *Resource
-	{
-		c := Config{}
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
 
-		c.HostClients = &adapter.Clients{
-			IAM: &adapter.IAMClientMock{},
-			EC2: &adapter.EC2ClientMock{},
-			STS: &adapter.STSClientMock{},
-		}
-		c.Logger = microloggertest.New()
-		c.EncrypterBackend = "kms"
-		c.GuestPrivateSubnetMaskBits = 25
-		c.GuestPublicSubnetMaskBits = 25
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+				WorkerLaunchTemplate:     false,
 
-		newResource, err = New(c)
-		if err != nil {
-			t.Fatal("expected", nil, "got", err)
-		}
-	}
+				VersionBundleVersion: "1.0.0",
+			},
+			desiredState: StackState{
+				Name: "desired",
 
-	awsClients := awsclient.Clients{
-		EC2: &adapter.EC2ClientMock{},
-		IAM: &adapter.IAMClientMock{},
-		KMS: &adapter.KMSClientMock{},
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "8",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+				WorkerLaunchTemplate:     true,
+
+				VersionBundleVersion: "1.0.0",
+			},
+			expectedChange: awscloudformation.UpdateStackInput{
+				StackName: aws.String(""),
+			},
+		},
+		{
+			description: "case 13, current state not empty, desired state not empty, different number of workers and worker instance distribution, expected empty state",
+			currentState: StackState{
+				Name: "current",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+				WorkerLaunchTemplate:     true,
+
+				WorkerInstanceTypes:          []string{"m3.large"},
+				WorkerOnDemandBaseCapacity:   0,
+				WorkerSpotAllocationStrategy: "lowest-price",
+				WorkerSpotPercentage:         50,
+
+				VersionBundleVersion: "1.0.0",
+			},
+			desiredState: StackState{
+				Name: "desired",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "8",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+				WorkerLaunchTemplate:     true,
+
+				WorkerInstanceTypes:          []string{"m3.large", "m4.large"},
+				WorkerOnDemandBaseCapacity:   0,
+				WorkerSpotAllocationStrategy: "lowest-price",
+				WorkerSpotPercentage:         100,
+
+				VersionBundleVersion: "1.0.0",
+			},
+			expectedChange: awscloudformation.UpdateStackInput{
+				StackName: aws.String(""),
+			},
+		},
+		{
+			description: "case 14, current state not empty, desired state not empty, different node pool size, expected desired state",
+			currentState: StackState{
+				Name: "current",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+				WorkerLaunchTemplate:     true,
+
+				NodePools: []StackStateNodePool{
+					{
+						Name: "gpu",
+
+						CloudConfig:        "worker-gpu-1234abcd",
+						DockerVolumeSizeGB: 100,
+						InstanceType:       "p3.2xlarge",
+						MaxSize:            4,
+						MinSize:            1,
+					},
+				},
+
+				VersionBundleVersion: "1.0.0",
+			},
+			desiredState: StackState{
+				Name: "desired",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+				WorkerLaunchTemplate:     true,
+
+				NodePools: []StackStateNodePool{
+					{
+						Name: "gpu",
+
+						CloudConfig:        "worker-gpu-1234abcd",
+						DockerVolumeSizeGB: 100,
+						InstanceType:       "p3.2xlarge",
+						MaxSize:            8,
+						MinSize:            2,
+					},
+				},
+
+				VersionBundleVersion: "1.0.0",
+			},
+			expectedChange: awscloudformation.UpdateStackInput{
+				StackName: aws.String("desired"),
+			},
+		},
+		{
+			description: "case 15, current state not empty, desired state not empty, different node pool size and node pool labels, expected empty state",
+			currentState: StackState{
+				Name: "current",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+				WorkerLaunchTemplate:     true,
+
+				NodePools: []StackStateNodePool{
+					{
+						Name: "gpu",
+
+						CloudConfig:        "worker-gpu-1234abcd",
+						DockerVolumeSizeGB: 100,
+						InstanceType:       "p3.2xlarge",
+						MaxSize:            4,
+						MinSize:            1,
+					},
+				},
+
+				VersionBundleVersion: "1.0.0",
+			},
+			desiredState: StackState{
+				Name: "desired",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+				WorkerLaunchTemplate:     true,
+
+				NodePools: []StackStateNodePool{
+					{
+						Name: "gpu",
+
+						CloudConfig:        "worker-gpu-5678abcd",
+						DockerVolumeSizeGB: 100,
+						InstanceType:       "p3.2xlarge",
+						MaxSize:            8,
+						MinSize:            2,
+					},
+				},
+
+				VersionBundleVersion: "1.0.0",
+			},
+			expectedChange: awscloudformation.UpdateStackInput{
+				StackName: aws.String(""),
+			},
+		},
+		{
+			description: "case 16, current state not empty, desired state not empty, different number of workers scaled by the cluster autoscaler, expected empty state",
+			currentState: StackState{
+				Name: "current",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+				WorkerLaunchTemplate:     true,
+				WorkerMaxSize:            10,
+				WorkerMinSize:            3,
+
+				VersionBundleVersion: "1.0.0",
+			},
+			desiredState: StackState{
+				Name: "desired",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "6",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+				WorkerLaunchTemplate:     true,
+				WorkerMaxSize:            10,
+				WorkerMinSize:            3,
+
+				VersionBundleVersion: "1.0.0",
+			},
+			expectedChange: awscloudformation.UpdateStackInput{
+				StackName: aws.String(""),
+			},
+		},
+		{
+			description: "case 17, current state not empty, desired state not empty, different scaling bounds, expected desired state",
+			currentState: StackState{
+				Name: "current",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+				WorkerLaunchTemplate:     true,
+				WorkerMaxSize:            10,
+				WorkerMinSize:            3,
+
+				VersionBundleVersion: "1.0.0",
+			},
+			desiredState: StackState{
+				Name: "desired",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+				WorkerLaunchTemplate:     true,
+				WorkerMaxSize:            20,
+				WorkerMinSize:            5,
+
+				VersionBundleVersion: "1.0.0",
+			},
+			expectedChange: awscloudformation.UpdateStackInput{
+				StackName: aws.String("desired"),
+			},
+		},
+		{
+			description: "case 18, current state not empty, desired state not empty, cluster autoscaler enabled, expected desired state",
+			currentState: StackState{
+				Name: "current",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+				WorkerLaunchTemplate:     true,
+				WorkerMaxSize:            0,
+				WorkerMinSize:            0,
+
+				VersionBundleVersion: "1.0.0",
+			},
+			desiredState: StackState{
+				Name: "desired",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+				WorkerLaunchTemplate:     true,
+				WorkerMaxSize:            10,
+				WorkerMinSize:            3,
+
+				VersionBundleVersion: "1.0.0",
+			},
+			expectedChange: awscloudformation.UpdateStackInput{
+				StackName: aws.String("desired"),
+			},
+		},
+		{
+			description: "case 19, current state not empty, desired state not empty, different whitelisted CIDRs, expected desired state",
+			currentState: StackState{
+				Name: "current",
+
+				APIWhitelistCIDRs: []string{"212.145.136.84/32"},
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+				WorkerLaunchTemplate:     true,
+
+				VersionBundleVersion: "1.0.0",
+			},
+			desiredState: StackState{
+				Name: "desired",
+
+				APIWhitelistCIDRs:     []string{"212.145.136.84/32", "192.168.1.0/24"},
+				IngressWhitelistCIDRs: []string{"10.0.0.0/8"},
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+				WorkerLaunchTemplate:     true,
+
+				VersionBundleVersion: "1.0.0",
+			},
+			expectedChange: awscloudformation.UpdateStackInput{
+				StackName: aws.String("desired"),
+			},
+		},
+		{
+			description: "case 20, current state not empty, desired state not empty, different whitelisted CIDRs and worker image, expected empty state",
+			currentState: StackState{
+				Name: "current",
+
+				APIWhitelistCIDRs: []string{"212.145.136.84/32"},
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+				WorkerLaunchTemplate:     true,
+
+				VersionBundleVersion: "1.0.0",
+			},
+			desiredState: StackState{
+				Name: "desired",
+
+				APIWhitelistCIDRs:     []string{"212.145.136.84/32", "192.168.1.0/24"},
+				IngressWhitelistCIDRs: []string{"10.0.0.0/8"},
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-456",
+				WorkerInstanceType:       "m3.large",
+				WorkerLaunchTemplate:     true,
+
+				VersionBundleVersion: "1.0.0",
+			},
+			expectedChange: awscloudformation.UpdateStackInput{
+				StackName: aws.String(""),
+			},
+		},
+	}
+
+	var err error
+	var newResource *Resource
+	{
+		c := Config{}
+
+		c.AMICatalog = &AMICatalogMock{}
+		c.EtcdMember = &EtcdMemberMock{}
+		c.G8sClient = versionedfake.NewSimpleClientset()
+		c.K8sClient = fake.NewSimpleClientset()
+		c.HostClients = &adapter.Clients{
+			IAM: &adapter.IAMClientMock{},
+			EC2: &adapter.EC2ClientMock{},
+			STS: &adapter.STSClientMock{},
+		}
+		c.Logger = microloggertest.New()
+		c.EncrypterBackend = "kms"
+		c.GuestPrivateSubnetMaskBits = 25
+		c.GuestPublicSubnetMaskBits = 25
+
+		newResource, err = New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	awsClients := awsclient.Clients{
+		EC2: &adapter.EC2ClientMock{},
+		IAM: &adapter.IAMClientMock{},
+		KMS: &adapter.KMSClientMock{},
 		STS: &adapter.STSClientMock{},
 	}
 
@@ -897,3 +1598,461 @@ func Test_Resource_Cloudformation_newUpdateChange_updatesNotAllowed(t *testing.T
 		})
 	}
 }
+
+func Test_Resource_Cloudformation_newUpdateChange_replacesMastersOneAfterAnother(t *testing.T) {
+	t.Parallel()
+	master := v1alpha1.AWSConfigSpecAWSNode{
+		ImageID:      "ami-1234-master",
+		InstanceType: "m3.large",
+	}
+	customObject := v1alpha1.AWSConfig{
+		Spec: v1alpha1.AWSConfigSpec{
+			Cluster: v1alpha1.Cluster{
+				ID: "test-cluster",
+				Kubernetes: v1alpha1.ClusterKubernetes{
+					API: v1alpha1.ClusterKubernetesAPI{
+						Domain:     "api.domain",
+						SecurePort: 443,
+					},
+					IngressController: v1alpha1.ClusterKubernetesIngressController{
+						Domain:       "ingress.domain",
+						InsecurePort: 30010,
+						SecurePort:   30011,
+					},
+				},
+				Etcd: v1alpha1.ClusterEtcd{
+					Domain: "etcd.domain",
+				},
+			},
+			AWS: v1alpha1.AWSConfigSpecAWS{
+				Region:            "eu-central-1",
+				AZ:                "eu-central-1a",
+				AvailabilityZones: 3,
+				Masters:           []v1alpha1.AWSConfigSpecAWSNode{master, master, master},
+				Workers: []v1alpha1.AWSConfigSpecAWSNode{
+					{
+						ImageID:      "ami-1234-worker",
+						InstanceType: "m3.large",
+					},
+				},
+			},
+		},
+		Status: statusWithAllocatedSubnet("10.1.1.0/24", []string{"eu-central-1a", "eu-central-1b", "eu-central-1c"}),
+	}
+
+	stackState := func(versionBundleVersion string, masterVersionBundleVersions ...string) StackState {
+		s := StackState{
+			Name: "cluster-test-cluster-guest-main",
+
+			DockerVolumeResourceName:   "DockerVolume" + versionBundleVersion,
+			MasterCount:                3,
+			MasterCloudConfigVersion:   "1.0.0",
+			MasterImageID:              "ami-123",
+			MasterInstanceResourceName: "MasterInstance" + versionBundleVersion,
+			MasterInstanceType:         "m3.large",
+
+			WorkerCloudConfigVersion: "1.0.0",
+			WorkerCount:              "1",
+			WorkerImageID:            "ami-123",
+			WorkerInstanceType:       "m3.large",
+			WorkerLaunchTemplate:     true,
+
+			VersionBundleVersion: versionBundleVersion,
+		}
+
+		for i, v := range masterVersionBundleVersions {
+			s.Masters = append(s.Masters, StackStateMaster{
+				CloudConfigVersion:       "1.0.0",
+				DockerVolumeResourceName: key.MasterResourceName("DockerVolume"+v, i),
+				ImageID:                  "ami-123",
+				InstanceResourceName:     key.MasterResourceName("MasterInstance"+v, i),
+				InstanceType:             "m3.large",
+				VersionBundleVersion:     v,
+			})
+		}
+
+		return s
+	}
+
+	workerInstanceTypeChanged := stackState("2.0.0", "2.0.0", "2.0.0", "2.0.0")
+	workerInstanceTypeChanged.WorkerInstanceType = "m4.xlarge"
+
+	testCases := []struct {
+		description             string
+		currentState            StackState
+		desiredState            StackState
+		unhealthy               []int
+		expectedMasterToReplace string
+		expectedShouldScale     bool
+		expectedShouldUpdate    bool
+		expectedMasters         []string
+		unexpectedMasters       []string
+	}{
+		{
+			description:             "case 0, version bundle version changes, replace first master",
+			currentState:            stackState("1.0.0", "1.0.0", "1.0.0", "1.0.0"),
+			desiredState:            stackState("2.0.0", "2.0.0", "2.0.0", "2.0.0"),
+			expectedMasterToReplace: "MasterInstance1.0.0",
+			expectedShouldUpdate:    true,
+			expectedMasters:         []string{"MasterInstance2.0.0", "MasterInstance1.0.001", "MasterInstance1.0.002"},
+			unexpectedMasters:       []string{"MasterInstance1.0.0", "MasterInstance2.0.001", "MasterInstance2.0.002"},
+		},
+		{
+			description:             "case 1, first master replaced, replace second master",
+			currentState:            stackState("2.0.0", "2.0.0", "1.0.0", "1.0.0"),
+			desiredState:            stackState("2.0.0", "2.0.0", "2.0.0", "2.0.0"),
+			expectedMasterToReplace: "MasterInstance1.0.001",
+			expectedShouldUpdate:    true,
+			expectedMasters:         []string{"MasterInstance2.0.0", "MasterInstance2.0.001", "MasterInstance1.0.002"},
+			unexpectedMasters:       []string{"MasterInstance1.0.001", "MasterInstance2.0.002"},
+		},
+		{
+			description:  "case 2, first master replaced, etcd member of first master did not rejoin yet, do not update",
+			currentState: stackState("2.0.0", "2.0.0", "1.0.0", "1.0.0"),
+			desiredState: stackState("2.0.0", "2.0.0", "2.0.0", "2.0.0"),
+			unhealthy:    []int{0},
+		},
+		{
+			description:          "case 3, all masters replaced, worker instance type changes, keep masters",
+			currentState:         stackState("2.0.0", "2.0.0", "2.0.0", "2.0.0"),
+			desiredState:         workerInstanceTypeChanged,
+			expectedShouldScale:  true,
+			expectedShouldUpdate: false,
+			expectedMasters:      []string{"MasterInstance2.0.0", "MasterInstance2.0.001", "MasterInstance2.0.002"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			c := testConfig()
+			c.EtcdMember = &EtcdMemberMock{unhealthy: tc.unhealthy}
+			c.HostClients = &adapter.Clients{
+				EC2: &adapter.EC2ClientMock{},
+				IAM: &adapter.IAMClientMock{},
+				STS: &adapter.STSClientMock{},
+			}
+			c.Route53Enabled = true
+
+			newResource, err := New(c)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			awsClients := awsclient.Clients{
+				EC2: &adapter.EC2ClientMock{},
+				ELB: &adapter.ELBClientMock{},
+				IAM: &adapter.IAMClientMock{},
+				KMS: &adapter.KMSClientMock{},
+				STS: &adapter.STSClientMock{},
+			}
+
+			ctx := updateallowedcontext.NewContext(context.Background(), make(chan struct{}))
+			ctx = controllercontext.NewContext(ctx, controllercontext.Context{AWSClient: awsClients})
+			updateallowedcontext.SetUpdateAllowed(ctx)
+
+			result, err := newResource.newUpdateChange(ctx, &customObject, tc.currentState, tc.desiredState)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			updateChange, ok := result.(StackState)
+			if !ok {
+				t.Fatalf("expected '%T', got '%T'", updateChange, result)
+			}
+
+			if updateChange.MasterToReplace != tc.expectedMasterToReplace {
+				t.Fatalf("expected master to replace %#q, got %#q", tc.expectedMasterToReplace, updateChange.MasterToReplace)
+			}
+			if updateChange.ShouldScale != tc.expectedShouldScale {
+				t.Fatalf("expected should scale %t, got %t", tc.expectedShouldScale, updateChange.ShouldScale)
+			}
+			if updateChange.ShouldUpdate != tc.expectedShouldUpdate {
+				t.Fatalf("expected should update %t, got %t", tc.expectedShouldUpdate, updateChange.ShouldUpdate)
+			}
+
+			body := aws.StringValue(updateChange.UpdateStackInput.TemplateBody)
+			for _, m := range tc.expectedMasters {
+				if !strings.Contains(body, "  "+m+":\n") {
+					t.Fatalf("expected master %#q in template body", m)
+				}
+			}
+			for _, m := range tc.unexpectedMasters {
+				if strings.Contains(body, "  "+m+":\n") {
+					t.Fatalf("expected master %#q not to be in template body", m)
+				}
+			}
+		})
+	}
+}
+
+func Test_Resource_Cloudformation_newUpdateChange_keepsTransitGateway(t *testing.T) {
+	t.Parallel()
+	customObject := testGuestCustomObject("eu-central-1a")
+
+	testCases := []struct {
+		description        string
+		currentTGW         string
+		desiredTGW         string
+		expectedElements   []string
+		unexpectedElements []string
+	}{
+		{
+			description: "case 0, peered guest cluster, transit gateway configured, keep peering",
+			currentTGW:  "",
+			desiredTGW:  "tgw-0123456789abcdef0",
+			expectedElements: []string{
+				"  VPCPeeringConnection:\n",
+			},
+			unexpectedElements: []string{
+				"TransitGatewayAttachment",
+				"  TransitGatewayID:\n",
+			},
+		},
+		{
+			description: "case 1, guest cluster attached to transit gateway, transit gateway removed, keep transit gateway",
+			currentTGW:  "tgw-0123456789abcdef0",
+			desiredTGW:  "",
+			expectedElements: []string{
+				"  TransitGatewayAttachment:\n",
+				"  TransitGatewayID:\n    Value: tgw-0123456789abcdef0\n",
+			},
+			unexpectedElements: []string{
+				"VPCPeeringConnection",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			c := testConfig()
+			c.HostClients = &adapter.Clients{
+				EC2: &adapter.EC2ClientMock{},
+				IAM: &adapter.IAMClientMock{},
+				STS: &adapter.STSClientMock{},
+			}
+			c.Route53Enabled = true
+			c.TransitGatewayID = tc.desiredTGW
+
+			newResource, err := New(c)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			awsClients := awsclient.Clients{
+				EC2: &adapter.EC2ClientMock{},
+				ELB: &adapter.ELBClientMock{},
+				IAM: &adapter.IAMClientMock{},
+				KMS: &adapter.KMSClientMock{},
+				STS: &adapter.STSClientMock{},
+			}
+
+			ctx := updateallowedcontext.NewContext(context.Background(), make(chan struct{}))
+			ctx = controllercontext.NewContext(ctx, controllercontext.Context{AWSClient: awsClients})
+			updateallowedcontext.SetUpdateAllowed(ctx)
+
+			currentState := testGuestStackState(customObject)
+			currentState.TransitGatewayID = tc.currentTGW
+			currentState.VersionBundleVersion = "1.0.0"
+
+			desiredState := testGuestStackState(customObject)
+			desiredState.TransitGatewayID = tc.desiredTGW
+
+			result, err := newResource.newUpdateChange(ctx, &customObject, currentState, desiredState)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			updateChange, ok := result.(StackState)
+			if !ok {
+				t.Fatalf("expected '%T', got '%T'", updateChange, result)
+			}
+			if !updateChange.ShouldUpdate {
+				t.Fatalf("expected should update %t, got %t", true, updateChange.ShouldUpdate)
+			}
+
+			body := aws.StringValue(updateChange.UpdateStackInput.TemplateBody)
+			for _, e := range tc.expectedElements {
+				if !strings.Contains(body, e) {
+					t.Fatalf("expected %#q in template body", e)
+				}
+			}
+			for _, e := range tc.unexpectedElements {
+				if strings.Contains(body, e) {
+					t.Fatalf("expected %#q not to be in template body", e)
+				}
+			}
+		})
+	}
+}
+
+func Test_Resource_Cloudformation_newUpdateChange_keepsNATGateway(t *testing.T) {
+	t.Parallel()
+	customObject := testGuestCustomObject("eu-central-1a", "eu-central-1b")
+
+	testCases := []struct {
+		description        string
+		currentNATGateway  v1alpha1.AWSConfigSpecAWSNATGateway
+		desiredNATGateway  v1alpha1.AWSConfigSpecAWSNATGateway
+		expectedElements   []string
+		unexpectedElements []string
+	}{
+		{
+			description:       "case 0, NAT gateway in each availability zone, single NAT gateway configured, keep NAT gateways",
+			currentNATGateway: v1alpha1.AWSConfigSpecAWSNATGateway{},
+			desiredNATGateway: v1alpha1.AWSConfigSpecAWSNATGateway{
+				Strategy:      "single",
+				AllocationIDs: []string{"eipalloc-a"},
+			},
+			expectedElements: []string{
+				"  NATGateway01:\n",
+				"  NATEIP01:\n",
+				"  NATGatewayStrategy:\n    Value: perAZ\n",
+			},
+			unexpectedElements: []string{
+				"eipalloc-a",
+			},
+		},
+		{
+			description: "case 1, single NAT gateway with pre-allocated elastic IP, default configured, keep NAT gateway",
+			currentNATGateway: v1alpha1.AWSConfigSpecAWSNATGateway{
+				Strategy:      "single",
+				AllocationIDs: []string{"eipalloc-a"},
+			},
+			desiredNATGateway: v1alpha1.AWSConfigSpecAWSNATGateway{},
+			expectedElements: []string{
+				"      AllocationId: eipalloc-a\n",
+				"  NATGatewayStrategy:\n    Value: single\n",
+				"  NATGatewayAllocationIDs:\n    Value: eipalloc-a\n",
+			},
+			unexpectedElements: []string{
+				"NATGateway01:",
+				"NATEIP",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			c := testConfig()
+			c.HostClients = &adapter.Clients{
+				EC2: &adapter.EC2ClientMock{},
+				IAM: &adapter.IAMClientMock{},
+				STS: &adapter.STSClientMock{},
+			}
+			c.Route53Enabled = true
+
+			newResource, err := New(c)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			awsClients := awsclient.Clients{
+				EC2: &EC2NATGatewaysMock{
+					addresses: []*ec2.Address{
+						{AllocationId: aws.String("eipalloc-a"), PublicIp: aws.String("52.0.0.1")},
+					},
+				},
+				ELB: &adapter.ELBClientMock{},
+				IAM: &adapter.IAMClientMock{},
+				KMS: &adapter.KMSClientMock{},
+				STS: &adapter.STSClientMock{},
+			}
+
+			ctx := updateallowedcontext.NewContext(context.Background(), make(chan struct{}))
+			ctx = controllercontext.NewContext(ctx, controllercontext.Context{AWSClient: awsClients})
+			updateallowedcontext.SetUpdateAllowed(ctx)
+
+			currentObject := customObject
+			currentObject.Spec.AWS.NATGateway = tc.currentNATGateway
+			currentState := testGuestStackState(currentObject)
+			currentState.VersionBundleVersion = "1.0.0"
+
+			desiredObject := customObject
+			desiredObject.Spec.AWS.NATGateway = tc.desiredNATGateway
+			desiredState := testGuestStackState(desiredObject)
+
+			result, err := newResource.newUpdateChange(ctx, &desiredObject, currentState, desiredState)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			updateChange, ok := result.(StackState)
+			if !ok {
+				t.Fatalf("expected '%T', got '%T'", updateChange, result)
+			}
+			if !updateChange.ShouldUpdate {
+				t.Fatalf("expected should update %t, got %t", true, updateChange.ShouldUpdate)
+			}
+
+			body := aws.StringValue(updateChange.UpdateStackInput.TemplateBody)
+			for _, e := range tc.expectedElements {
+				if !strings.Contains(body, e) {
+					t.Fatalf("expected %#q in template body", e)
+				}
+			}
+			for _, e := range tc.unexpectedElements {
+				if strings.Contains(body, e) {
+					t.Fatalf("expected %#q not to be in template body", e)
+				}
+			}
+		})
+	}
+}
+
+func Test_Resource_Cloudformation_newUpdateChange_updatePending(t *testing.T) {
+	t.Parallel()
+	customObject := testGuestCustomObject("eu-central-1a")
+
+	testCases := []struct {
+		description           string
+		currentVersion        string
+		expectedUpdatePending bool
+	}{
+		{
+			description:           "case 0, updates not allowed, nothing to update, expected no pending update",
+			currentVersion:        "",
+			expectedUpdatePending: false,
+		},
+		{
+			description:           "case 1, updates not allowed, different version bundle version, expected pending update",
+			currentVersion:        "1.0.0",
+			expectedUpdatePending: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			newResource, err := New(testConfig())
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			awsClients := awsclient.Clients{
+				EC2: &adapter.EC2ClientMock{},
+				IAM: &adapter.IAMClientMock{},
+				KMS: &adapter.KMSClientMock{},
+				STS: &adapter.STSClientMock{},
+			}
+
+			ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{AWSClient: awsClients})
+
+			currentState := testGuestStackState(customObject)
+			if tc.currentVersion != "" {
+				currentState.VersionBundleVersion = tc.currentVersion
+			}
+			desiredState := testGuestStackState(customObject)
+
+			result, err := newResource.newUpdateChange(ctx, &customObject, currentState, desiredState)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			updateChange, ok := result.(StackState)
+			if !ok {
+				t.Fatalf("expected '%T', got '%T'", updateChange, result)
+			}
+			if updateChange.Name != "" {
+				t.Fatalf("expected empty update change, got %#q", updateChange.Name)
+			}
+			if updateChange.UpdatePending != tc.expectedUpdatePending {
+				t.Fatalf("expected update pending %t, got %t", tc.expectedUpdatePending, updateChange.UpdatePending)
+			}
+		})
+	}
+}
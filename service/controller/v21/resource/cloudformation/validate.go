@@ -1,23 +1,47 @@
 package cloudformation
 
 import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
 	"github.com/giantswarm/microerror"
 
+	"github.com/giantswarm/aws-operator/service/controller/v21/controllercontext"
+	"github.com/giantswarm/aws-operator/service/controller/v21/etcdmember"
 	"github.com/giantswarm/aws-operator/service/controller/v21/key"
 )
 
-type validator func(v1alpha1.AWSConfig) error
+// masterSecurityGroupBaseRules is the number of inbound rules of the master
+// security group of guest clusters with a whitelisted Kubernetes API which do
+// not depend on the whitelist. These are the rules for the host and guest
+// cluster CIDRs reaching the Kubernetes API and the six rules for the host
+// cluster scraping, backing up and accessing the masters.
+const masterSecurityGroupBaseRules = 8
+
+var nodePoolNameRegexp = regexp.MustCompile("^[a-z][a-z0-9]*$")
+
+type validator func(context.Context, v1alpha1.AWSConfig) error
 
-func (r *Resource) validateCluster(cluster v1alpha1.AWSConfig) error {
+func (r *Resource) validateCluster(ctx context.Context, cluster v1alpha1.AWSConfig) error {
 	validators := []validator{
+		r.validateAPIWhitelist,
+		r.validateExistingVPC,
 		r.validateHostPeeringRoutes,
+		r.validateInstanceDistribution,
+		r.validateMasters,
+		r.validateNATGateway,
+		r.validateNodePools,
+		r.validateScaling,
 	}
 
 	for _, v := range validators {
-		if err := v(cluster); err != nil {
+		if err := v(ctx, cluster); err != nil {
 			return microerror.Mask(err)
 		}
 	}
@@ -25,7 +49,221 @@ func (r *Resource) validateCluster(cluster v1alpha1.AWSConfig) error {
 	return nil
 }
 
-func (r *Resource) validateHostPeeringRoutes(cluster v1alpha1.AWSConfig) error {
+// validateClusterUpdate validates the parts of the guest cluster
+// configuration which may change for existing guest clusters. Invalid values
+// would only be rejected by CloudFormation and roll back the whole update,
+// which is why we validate them upfront.
+func (r *Resource) validateClusterUpdate(ctx context.Context, cluster v1alpha1.AWSConfig) error {
+	validators := []validator{
+		r.validateAPIWhitelist,
+		r.validateInstanceDistribution,
+		r.validateNodePools,
+		r.validateScaling,
+	}
+
+	for _, v := range validators {
+		if err := v(ctx, cluster); err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	return nil
+}
+
+// validateAPIWhitelist ensures the CIDRs whitelisted for the Kubernetes API
+// and the ingress load balancer of the guest cluster are valid IPv4 CIDRs and
+// fit into the security groups of the guest cluster. Whitelisting the ingress
+// load balancer requires a classic Elastic Load Balancer, because Network Load
+// Balancers do not have security groups.
+//
+// AWS limits the IPv4 and the IPv6 inbound rules of a security group
+// separately. The IPv6 rules of dual-stack guest clusters only duplicate the
+// rules allowing traffic from everywhere, which whitelisted security groups do
+// not have. Only the IPv4 rules are therefore counted against the limit.
+func (r *Resource) validateAPIWhitelist(ctx context.Context, cluster v1alpha1.AWSConfig) error {
+	for _, c := range key.APIWhitelistCIDRs(cluster) {
+		if !isIPv4CIDR(c) {
+			return microerror.Maskf(invalidConfigError, "API whitelist CIDR %#q must be a valid IPv4 CIDR", c)
+		}
+	}
+
+	if (r.apiWhiteList.Enabled || len(key.APIWhitelistCIDRs(cluster)) > 0) && !key.PrivateAPIEndpoint(cluster) {
+		ruleCount, err := r.masterSecurityGroupIPv4Rules(cluster)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		if ruleCount > key.MaxSecurityGroupRules {
+			return microerror.Maskf(invalidConfigError, "master security group requires %d IPv4 rules but at most %d are allowed", ruleCount, key.MaxSecurityGroupRules)
+		}
+	}
+
+	if len(key.IngressWhitelistCIDRs(cluster)) == 0 {
+		return nil
+	}
+	if key.IngressLoadBalancerType(cluster) == key.LoadBalancerTypeNetwork {
+		return microerror.Maskf(invalidConfigError, "ingress whitelist requires ingress load balancer type %#q but got %#q", key.LoadBalancerTypeClassic, key.LoadBalancerTypeNetwork)
+	}
+	for _, c := range key.IngressWhitelistCIDRs(cluster) {
+		if !isIPv4CIDR(c) {
+			return microerror.Maskf(invalidConfigError, "ingress whitelist CIDR %#q must be a valid IPv4 CIDR", c)
+		}
+	}
+
+	// The ingress load balancer gets a http and a https rule for each
+	// whitelisted CIDR.
+	{
+		ruleCount := 2 * len(uniqueCIDRs(key.IngressWhitelistCIDRs(cluster)))
+		if ruleCount > key.MaxSecurityGroupRules {
+			return microerror.Maskf(invalidConfigError, "ingress security group requires %d IPv4 rules but at most %d are allowed", ruleCount, key.MaxSecurityGroupRules)
+		}
+	}
+
+	return nil
+}
+
+// masterSecurityGroupIPv4Rules returns the number of IPv4 inbound rules of the
+// master security group of a guest cluster with a whitelisted Kubernetes API.
+func (r *Resource) masterSecurityGroupIPv4Rules(cluster v1alpha1.AWSConfig) (int, error) {
+	ruleCount := masterSecurityGroupBaseRules
+	if key.EtcdLoadBalancerType(cluster) == key.LoadBalancerTypeNetwork {
+		ruleCount++
+	}
+
+	var cidrs []string
+	if r.apiWhiteList.Enabled {
+		cidrs = append(cidrs, strings.Split(r.apiWhiteList.SubnetList, ",")...)
+	}
+	cidrs = append(cidrs, key.APIWhitelistCIDRs(cluster)...)
+	ruleCount += len(uniqueCIDRs(cidrs))
+
+	// The public IPs of the host cluster NAT gateways are whitelisted as well.
+	{
+		i := &ec2.DescribeAddressesInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String(fmt.Sprintf("tag:%s", key.InstallationTagName)),
+					Values: aws.StringSlice([]string{r.installationName}),
+				},
+			},
+		}
+		o, err := r.hostClients.EC2.DescribeAddresses(i)
+		if err != nil {
+			return 0, microerror.Mask(err)
+		}
+
+		ruleCount += len(o.Addresses)
+	}
+
+	// The public IPs of the guest cluster NAT gateways are whitelisted in case
+	// the NAT gateways are part of the guest cluster main stack. Existing guest
+	// clusters keep their NAT gateways regardless of the configured strategy,
+	// which is why we count a NAT gateway in each availability zone.
+	if key.VPCID(cluster) == "" {
+		ruleCount += len(key.StatusAvailabilityZones(cluster))
+	}
+
+	return ruleCount, nil
+}
+
+// validateExistingVPC ensures the existing VPC a guest cluster is deployed
+// into fits the guest cluster. There has to be a private and a public subnet
+// of the VPC in each availability zone and the routes towards the host cluster
+// are added to the route tables explicitly associated with the private
+// subnets.
+func (r *Resource) validateExistingVPC(ctx context.Context, cluster v1alpha1.AWSConfig) error {
+	if key.VPCID(cluster) == "" {
+		return nil
+	}
+
+	sc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if key.IPv6Enabled(cluster) {
+		return microerror.Maskf(invalidConfigError, "existing VPC %#q cannot be used together with IPv6", key.VPCID(cluster))
+	}
+
+	subnets := key.VPCSubnets(cluster)
+	if len(subnets) == 0 {
+		return microerror.Maskf(invalidConfigError, "existing VPC %#q requires at least one availability zone with existing subnets", key.VPCID(cluster))
+	}
+
+	var subnetIDs []*string
+	var privateSubnetIDs []*string
+	zones := map[string]string{}
+	for i, s := range subnets {
+		if i > 0 && subnets[i-1].AvailabilityZone == s.AvailabilityZone {
+			return microerror.Maskf(invalidConfigError, "availability zone %#q has more than one pair of existing subnets", s.AvailabilityZone)
+		}
+		if s.PrivateID == "" || s.PublicID == "" {
+			return microerror.Maskf(invalidConfigError, "availability zone %#q requires a private and a public subnet", s.AvailabilityZone)
+		}
+
+		subnetIDs = append(subnetIDs, aws.String(s.PrivateID), aws.String(s.PublicID))
+		privateSubnetIDs = append(privateSubnetIDs, aws.String(s.PrivateID))
+		zones[s.PrivateID] = s.AvailabilityZone
+		zones[s.PublicID] = s.AvailabilityZone
+	}
+
+	{
+		i := &ec2.DescribeSubnetsInput{
+			SubnetIds: subnetIDs,
+		}
+		o, err := sc.AWSClient.EC2.DescribeSubnets(i)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		found := map[string]bool{}
+		for _, s := range o.Subnets {
+			id := aws.StringValue(s.SubnetId)
+			if aws.StringValue(s.VpcId) != key.VPCID(cluster) {
+				return microerror.Maskf(invalidConfigError, "subnet %#q does not belong to existing VPC %#q", id, key.VPCID(cluster))
+			}
+			if aws.StringValue(s.AvailabilityZone) != zones[id] {
+				return microerror.Maskf(invalidConfigError, "subnet %#q is not in availability zone %#q", id, zones[id])
+			}
+			found[id] = true
+		}
+		for id := range zones {
+			if !found[id] {
+				return microerror.Maskf(invalidConfigError, "subnet %#q does not exist", id)
+			}
+		}
+	}
+
+	{
+		i := &ec2.DescribeRouteTablesInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("association.subnet-id"),
+					Values: privateSubnetIDs,
+				},
+			},
+		}
+		o, err := sc.AWSClient.EC2.DescribeRouteTables(i)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		associated := map[string]bool{}
+		for _, rt := range o.RouteTables {
+			for _, a := range rt.Associations {
+				associated[aws.StringValue(a.SubnetId)] = true
+			}
+		}
+		for _, id := range privateSubnetIDs {
+			if !associated[aws.StringValue(id)] {
+				return microerror.Maskf(invalidConfigError, "private subnet %#q has no explicitly associated route table", aws.StringValue(id))
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *Resource) validateHostPeeringRoutes(ctx context.Context, cluster v1alpha1.AWSConfig) error {
 	input := &ec2.DescribeRouteTablesInput{
 		Filters: []*ec2.Filter{
 			{
@@ -49,3 +287,165 @@ func (r *Resource) validateHostPeeringRoutes(cluster v1alpha1.AWSConfig) error {
 
 	return nil
 }
+
+// validateInstanceDistribution ensures the worker instance distribution of
+// the guest cluster can be used for the mixed instances policy of the worker
+// ASG.
+func (r *Resource) validateInstanceDistribution(ctx context.Context, cluster v1alpha1.AWSConfig) error {
+	instanceTypes := cluster.Spec.AWS.WorkerInstanceDistribution.InstanceTypes
+
+	if len(instanceTypes) > key.MaxWorkerInstanceTypes {
+		return microerror.Maskf(invalidConfigError, "at most %d worker instance types allowed, found %d", key.MaxWorkerInstanceTypes, len(instanceTypes))
+	}
+	for _, t := range instanceTypes {
+		if t == "" {
+			return microerror.Maskf(invalidConfigError, "worker instance types must not be empty")
+		}
+	}
+	if key.WorkerOnDemandBaseCapacity(cluster) < 0 {
+		return microerror.Maskf(invalidConfigError, "worker on-demand base capacity must not be negative, found %d", key.WorkerOnDemandBaseCapacity(cluster))
+	}
+	if key.WorkerSpotPercentage(cluster) < 0 || key.WorkerSpotPercentage(cluster) > 100 {
+		return microerror.Maskf(invalidConfigError, "worker spot percentage must be between 0 and 100, found %d", key.WorkerSpotPercentage(cluster))
+	}
+
+	switch key.WorkerSpotAllocationStrategy(cluster) {
+	case key.SpotAllocationStrategyLowestPrice, key.SpotAllocationStrategyCapacityOptimized:
+	default:
+		return microerror.Maskf(invalidConfigError, "unknown worker spot allocation strategy %#q", key.WorkerSpotAllocationStrategy(cluster))
+	}
+
+	return nil
+}
+
+// validateMasters ensures the guest cluster either runs a single master or a
+// highly available control plane. The masters of a highly available control
+// plane are spread across availability zones and their etcd members find each
+// other using record sets in the guest cluster's hosted zone.
+func (r *Resource) validateMasters(ctx context.Context, cluster v1alpha1.AWSConfig) error {
+	masterCount := key.MasterCount(cluster)
+
+	if masterCount == 1 {
+		return nil
+	}
+	if masterCount != key.MasterCountHA {
+		return microerror.Maskf(invalidConfigError, "master count must be 1 or %d but got %d", key.MasterCountHA, masterCount)
+	}
+
+	if len(key.StatusAvailabilityZones(cluster)) < masterCount {
+		return microerror.Maskf(invalidConfigError, "highly available control plane requires %d availability zones but got %d", masterCount, len(key.StatusAvailabilityZones(cluster)))
+	}
+	if !r.route53Enabled {
+		return microerror.Maskf(invalidConfigError, "highly available control plane requires route53 to be enabled")
+	}
+
+	// The etcd members talk to their peers using the domains of the individual
+	// masters. The etcd cluster cannot be formed in case the etcd certificate
+	// does not cover them.
+	err := r.etcdMember.VerifyPeerDomains(ctx, cluster, masterCount)
+	if etcdmember.IsPeerDomainNotCovered(err) {
+		return microerror.Maskf(invalidConfigError, "highly available control plane requires the etcd certificate to cover the etcd member domains: %s", err.Error())
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// validateNATGateway ensures the NAT gateway strategy of the guest cluster is
+// known and that there is a pre-allocated elastic IP for each NAT gateway in
+// case any are given. Guest clusters deployed into an existing VPC do not get
+// NAT gateways, which is why they must not configure them.
+func (r *Resource) validateNATGateway(ctx context.Context, cluster v1alpha1.AWSConfig) error {
+	if key.VPCID(cluster) != "" {
+		if cluster.Spec.AWS.NATGateway.Strategy != "" || len(key.NATGatewayAllocationIDs(cluster)) != 0 {
+			return microerror.Maskf(invalidConfigError, "NAT gateways cannot be configured for guest clusters deployed into existing VPC %#q", key.VPCID(cluster))
+		}
+
+		return nil
+	}
+
+	switch key.NATGatewayStrategy(cluster) {
+	case key.NATGatewayStrategyPerAZ, key.NATGatewayStrategySingle:
+	default:
+		return microerror.Maskf(invalidConfigError, "NAT gateway strategy must be %#q or %#q but got %#q", key.NATGatewayStrategyPerAZ, key.NATGatewayStrategySingle, key.NATGatewayStrategy(cluster))
+	}
+
+	allocationIDs := key.NATGatewayAllocationIDs(cluster)
+	if len(allocationIDs) != 0 && len(allocationIDs) != key.NATGatewayCount(cluster) {
+		return microerror.Maskf(invalidConfigError, "NAT gateway strategy %#q requires %d allocation IDs but got %d", key.NATGatewayStrategy(cluster), key.NATGatewayCount(cluster), len(allocationIDs))
+	}
+
+	return nil
+}
+
+// validateNodePools ensures the node pools of the guest cluster can be
+// rendered into uniquely named worker ASGs.
+func (r *Resource) validateNodePools(ctx context.Context, cluster v1alpha1.AWSConfig) error {
+	names := map[string]bool{}
+
+	for _, p := range key.NodePools(cluster) {
+		if !nodePoolNameRegexp.MatchString(p.Name) {
+			return microerror.Maskf(invalidConfigError, "node pool name %#q must consist of lower case alphanumeric characters and start with a letter", p.Name)
+		}
+		if names[p.Name] {
+			return microerror.Maskf(invalidConfigError, "node pool name %#q must be unique", p.Name)
+		}
+		names[p.Name] = true
+
+		if p.InstanceType == "" {
+			return microerror.Maskf(invalidConfigError, "node pool %#q instance type must not be empty", p.Name)
+		}
+		if p.MinSize < 0 {
+			return microerror.Maskf(invalidConfigError, "node pool %#q min size must not be negative, found %d", p.Name, p.MinSize)
+		}
+		if p.MaxSize <= p.MinSize {
+			return microerror.Maskf(invalidConfigError, "node pool %#q max size must be greater than min size %d, found %d", p.Name, p.MinSize, p.MaxSize)
+		}
+	}
+
+	return nil
+}
+
+// validateScaling ensures the scaling bounds of guest clusters scaled by the
+// cluster autoscaler can be used as the bounds of the worker ASG.
+func (r *Resource) validateScaling(ctx context.Context, cluster v1alpha1.AWSConfig) error {
+	if !key.ClusterAutoscalerEnabled(cluster) {
+		return nil
+	}
+
+	if key.ScalingMin(cluster) < 1 {
+		return microerror.Maskf(invalidConfigError, "scaling min must be at least 1, found %d", key.ScalingMin(cluster))
+	}
+	if key.ScalingMax(cluster) < key.ScalingMin(cluster) {
+		return microerror.Maskf(invalidConfigError, "scaling max must not be less than scaling min %d, found %d", key.ScalingMin(cluster), key.ScalingMax(cluster))
+	}
+
+	return nil
+}
+
+// uniqueCIDRs returns the given CIDRs without empty and duplicated entries.
+func uniqueCIDRs(cidrs []string) []string {
+	var unique []string
+	seen := map[string]bool{}
+	for _, c := range cidrs {
+		c = strings.TrimSpace(c)
+		if c == "" || seen[c] {
+			continue
+		}
+
+		seen[c] = true
+		unique = append(unique, c)
+	}
+
+	return unique
+}
+
+func isIPv4CIDR(s string) bool {
+	ip, _, err := net.ParseCIDR(s)
+	if err != nil {
+		return false
+	}
+
+	return ip.To4() != nil
+}
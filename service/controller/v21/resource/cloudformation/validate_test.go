@@ -1,12 +1,19 @@
 package cloudformation
 
 import (
+	"context"
+	"fmt"
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	versionedfake "github.com/giantswarm/apiextensions/pkg/clientset/versioned/fake"
 	"github.com/giantswarm/micrologger/microloggertest"
+	"k8s.io/client-go/kubernetes/fake"
 
 	"github.com/giantswarm/aws-operator/service/controller/v21/adapter"
+	"github.com/giantswarm/aws-operator/service/controller/v21/controllercontext"
 )
 
 func Test_validateHostPeeringRoutes(t *testing.T) {
@@ -52,6 +59,10 @@ func Test_validateHostPeeringRoutes(t *testing.T) {
 
 			c := Config{}
 
+			c.AMICatalog = &AMICatalogMock{}
+			c.EtcdMember = &EtcdMemberMock{}
+			c.G8sClient = versionedfake.NewSimpleClientset()
+			c.K8sClient = fake.NewSimpleClientset()
 			c.HostClients = &adapter.Clients{
 				EC2:            ec2Mock,
 				CloudFormation: &adapter.CloudFormationMock{},
@@ -68,7 +79,7 @@ func Test_validateHostPeeringRoutes(t *testing.T) {
 		}
 
 		t.Run(tc.description, func(t *testing.T) {
-			err := newResource.validateHostPeeringRoutes(customObject)
+			err := newResource.validateHostPeeringRoutes(context.Background(), customObject)
 			if tc.expectedError && err == nil {
 				t.Fatalf("expected error didn't happen")
 			}
@@ -78,3 +89,699 @@ func Test_validateHostPeeringRoutes(t *testing.T) {
 		})
 	}
 }
+
+func Test_validateInstanceDistribution(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description          string
+		instanceDistribution v1alpha1.AWSConfigSpecAWSWorkerInstanceDistribution
+		expectedError        bool
+	}{
+		{
+			description:   "no instance distribution, do not expect error",
+			expectedError: false,
+		},
+		{
+			description: "instance types and spot percentage, do not expect error",
+			instanceDistribution: v1alpha1.AWSConfigSpecAWSWorkerInstanceDistribution{
+				InstanceTypes:          []string{"m5.large", "m4.large"},
+				OnDemandBaseCapacity:   2,
+				SpotAllocationStrategy: "capacity-optimized",
+				SpotPercentage:         60,
+			},
+			expectedError: false,
+		},
+		{
+			description: "empty instance type, expect error",
+			instanceDistribution: v1alpha1.AWSConfigSpecAWSWorkerInstanceDistribution{
+				InstanceTypes: []string{"m5.large", ""},
+			},
+			expectedError: true,
+		},
+		{
+			description: "spot percentage above 100, expect error",
+			instanceDistribution: v1alpha1.AWSConfigSpecAWSWorkerInstanceDistribution{
+				InstanceTypes:  []string{"m5.large"},
+				SpotPercentage: 101,
+			},
+			expectedError: true,
+		},
+		{
+			description: "negative on-demand base capacity, expect error",
+			instanceDistribution: v1alpha1.AWSConfigSpecAWSWorkerInstanceDistribution{
+				InstanceTypes:        []string{"m5.large"},
+				OnDemandBaseCapacity: -1,
+			},
+			expectedError: true,
+		},
+		{
+			description: "unknown spot allocation strategy, expect error",
+			instanceDistribution: v1alpha1.AWSConfigSpecAWSWorkerInstanceDistribution{
+				InstanceTypes:          []string{"m5.large"},
+				SpotAllocationStrategy: "cheapest",
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			customObject := v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						WorkerInstanceDistribution: tc.instanceDistribution,
+					},
+				},
+			}
+
+			r := &Resource{}
+
+			err := r.validateInstanceDistribution(context.Background(), customObject)
+			if tc.expectedError && !IsInvalidConfig(err) {
+				t.Fatalf("expected invalid config error got %v", err)
+			}
+			if !tc.expectedError && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func Test_validateMasters(t *testing.T) {
+	t.Parallel()
+
+	threeAZs := []v1alpha1.AWSConfigStatusAWSAvailabilityZone{
+		{Name: "eu-central-1a"},
+		{Name: "eu-central-1b"},
+		{Name: "eu-central-1c"},
+	}
+
+	testCases := []struct {
+		description       string
+		masterCount       int
+		availabilityZones []v1alpha1.AWSConfigStatusAWSAvailabilityZone
+		route53Enabled    bool
+		expectedError     bool
+	}{
+		{
+			description:       "single master, do not expect error",
+			masterCount:       1,
+			availabilityZones: threeAZs[:1],
+			route53Enabled:    false,
+			expectedError:     false,
+		},
+		{
+			description:       "three masters in three availability zones, do not expect error",
+			masterCount:       3,
+			availabilityZones: threeAZs,
+			route53Enabled:    true,
+			expectedError:     false,
+		},
+		{
+			description:       "no masters, expect error",
+			masterCount:       0,
+			availabilityZones: threeAZs,
+			route53Enabled:    true,
+			expectedError:     true,
+		},
+		{
+			description:       "two masters, expect error",
+			masterCount:       2,
+			availabilityZones: threeAZs,
+			route53Enabled:    true,
+			expectedError:     true,
+		},
+		{
+			description:       "three masters in two availability zones, expect error",
+			masterCount:       3,
+			availabilityZones: threeAZs[:2],
+			route53Enabled:    true,
+			expectedError:     true,
+		},
+		{
+			description:       "three masters without route53, expect error",
+			masterCount:       3,
+			availabilityZones: threeAZs,
+			route53Enabled:    false,
+			expectedError:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			customObject := v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						Masters: make([]v1alpha1.AWSConfigSpecAWSNode, tc.masterCount),
+					},
+				},
+				Status: v1alpha1.AWSConfigStatus{
+					AWS: v1alpha1.AWSConfigStatusAWS{
+						AvailabilityZones: tc.availabilityZones,
+					},
+				},
+			}
+
+			r := &Resource{
+				etcdMember:     &EtcdMemberMock{},
+				route53Enabled: tc.route53Enabled,
+			}
+
+			err := r.validateMasters(context.Background(), customObject)
+			if tc.expectedError && !IsInvalidConfig(err) {
+				t.Fatalf("expected invalid config error got %v", err)
+			}
+			if !tc.expectedError && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func Test_validateExistingVPC(t *testing.T) {
+	t.Parallel()
+
+	subnet := func(id, vpcID, az string) *ec2.Subnet {
+		return &ec2.Subnet{
+			AvailabilityZone: aws.String(az),
+			SubnetId:         aws.String(id),
+			VpcId:            aws.String(vpcID),
+		}
+	}
+	routeTable := func(subnetIDs ...string) *ec2.RouteTable {
+		rt := &ec2.RouteTable{}
+		for _, id := range subnetIDs {
+			rt.Associations = append(rt.Associations, &ec2.RouteTableAssociation{SubnetId: aws.String(id)})
+		}
+		return rt
+	}
+
+	twoAZs := []v1alpha1.AWSConfigSpecAWSVPCSubnet{
+		{AvailabilityZone: "eu-central-1b", PrivateID: "subnet-private-b", PublicID: "subnet-public-b"},
+		{AvailabilityZone: "eu-central-1a", PrivateID: "subnet-private-a", PublicID: "subnet-public-a"},
+	}
+	twoAZsSubnets := []*ec2.Subnet{
+		subnet("subnet-private-a", "vpc-existing", "eu-central-1a"),
+		subnet("subnet-public-a", "vpc-existing", "eu-central-1a"),
+		subnet("subnet-private-b", "vpc-existing", "eu-central-1b"),
+		subnet("subnet-public-b", "vpc-existing", "eu-central-1b"),
+	}
+
+	testCases := []struct {
+		description   string
+		vpcID         string
+		ipv6Enabled   bool
+		vpcSubnets    []v1alpha1.AWSConfigSpecAWSVPCSubnet
+		subnets       []*ec2.Subnet
+		routeTables   []*ec2.RouteTable
+		expectedError bool
+	}{
+		{
+			description:   "no existing VPC, do not expect error",
+			expectedError: false,
+		},
+		{
+			description:   "existing VPC with subnets in two availability zones, do not expect error",
+			vpcID:         "vpc-existing",
+			vpcSubnets:    twoAZs,
+			subnets:       twoAZsSubnets,
+			routeTables:   []*ec2.RouteTable{routeTable("subnet-private-a", "subnet-private-b")},
+			expectedError: false,
+		},
+		{
+			description:   "existing VPC with IPv6, expect error",
+			vpcID:         "vpc-existing",
+			ipv6Enabled:   true,
+			vpcSubnets:    twoAZs,
+			subnets:       twoAZsSubnets,
+			routeTables:   []*ec2.RouteTable{routeTable("subnet-private-a", "subnet-private-b")},
+			expectedError: true,
+		},
+		{
+			description:   "existing VPC without subnets, expect error",
+			vpcID:         "vpc-existing",
+			expectedError: true,
+		},
+		{
+			description: "existing VPC with two subnet pairs in one availability zone, expect error",
+			vpcID:       "vpc-existing",
+			vpcSubnets: []v1alpha1.AWSConfigSpecAWSVPCSubnet{
+				{AvailabilityZone: "eu-central-1a", PrivateID: "subnet-private-a", PublicID: "subnet-public-a"},
+				{AvailabilityZone: "eu-central-1a", PrivateID: "subnet-private-b", PublicID: "subnet-public-b"},
+			},
+			subnets:       twoAZsSubnets,
+			routeTables:   []*ec2.RouteTable{routeTable("subnet-private-a", "subnet-private-b")},
+			expectedError: true,
+		},
+		{
+			description:   "existing subnet not found, expect error",
+			vpcID:         "vpc-existing",
+			vpcSubnets:    twoAZs,
+			subnets:       twoAZsSubnets[:3],
+			routeTables:   []*ec2.RouteTable{routeTable("subnet-private-a", "subnet-private-b")},
+			expectedError: true,
+		},
+		{
+			description: "existing subnet of another VPC, expect error",
+			vpcID:       "vpc-existing",
+			vpcSubnets:  twoAZs,
+			subnets: []*ec2.Subnet{
+				subnet("subnet-private-a", "vpc-existing", "eu-central-1a"),
+				subnet("subnet-public-a", "vpc-other", "eu-central-1a"),
+				subnet("subnet-private-b", "vpc-existing", "eu-central-1b"),
+				subnet("subnet-public-b", "vpc-existing", "eu-central-1b"),
+			},
+			routeTables:   []*ec2.RouteTable{routeTable("subnet-private-a", "subnet-private-b")},
+			expectedError: true,
+		},
+		{
+			description: "existing subnet in another availability zone, expect error",
+			vpcID:       "vpc-existing",
+			vpcSubnets:  twoAZs,
+			subnets: []*ec2.Subnet{
+				subnet("subnet-private-a", "vpc-existing", "eu-central-1a"),
+				subnet("subnet-public-a", "vpc-existing", "eu-central-1a"),
+				subnet("subnet-private-b", "vpc-existing", "eu-central-1c"),
+				subnet("subnet-public-b", "vpc-existing", "eu-central-1b"),
+			},
+			routeTables:   []*ec2.RouteTable{routeTable("subnet-private-a", "subnet-private-b")},
+			expectedError: true,
+		},
+		{
+			description:   "private subnet without route table, expect error",
+			vpcID:         "vpc-existing",
+			vpcSubnets:    twoAZs,
+			subnets:       twoAZsSubnets,
+			routeTables:   []*ec2.RouteTable{routeTable("subnet-private-a")},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			customObject := v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						VPC: v1alpha1.AWSConfigSpecAWSVPC{
+							ID:          tc.vpcID,
+							IPv6Enabled: tc.ipv6Enabled,
+							Subnets:     tc.vpcSubnets,
+						},
+					},
+				},
+			}
+
+			c := controllercontext.Context{}
+			c.AWSClient.EC2 = &EC2ExistingVPCMock{
+				routeTables: tc.routeTables,
+				subnets:     tc.subnets,
+			}
+			ctx := controllercontext.NewContext(context.Background(), c)
+
+			r := &Resource{}
+
+			err := r.validateExistingVPC(ctx, customObject)
+			if tc.expectedError && !IsInvalidConfig(err) {
+				t.Fatalf("expected invalid config error got %v", err)
+			}
+			if !tc.expectedError && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func Test_validateNATGateway(t *testing.T) {
+	t.Parallel()
+
+	twoAZs := []v1alpha1.AWSConfigStatusAWSAvailabilityZone{
+		{Name: "eu-central-1a"},
+		{Name: "eu-central-1b"},
+	}
+
+	testCases := []struct {
+		description   string
+		natGateway    v1alpha1.AWSConfigSpecAWSNATGateway
+		vpcID         string
+		expectedError bool
+	}{
+		{
+			description:   "default strategy, do not expect error",
+			expectedError: false,
+		},
+		{
+			description: "single strategy, do not expect error",
+			natGateway: v1alpha1.AWSConfigSpecAWSNATGateway{
+				Strategy: "single",
+			},
+			expectedError: false,
+		},
+		{
+			description: "allocation ID for each availability zone, do not expect error",
+			natGateway: v1alpha1.AWSConfigSpecAWSNATGateway{
+				Strategy:      "perAZ",
+				AllocationIDs: []string{"eipalloc-a", "eipalloc-b"},
+			},
+			expectedError: false,
+		},
+		{
+			description: "single allocation ID for single strategy, do not expect error",
+			natGateway: v1alpha1.AWSConfigSpecAWSNATGateway{
+				Strategy:      "single",
+				AllocationIDs: []string{"eipalloc-a"},
+			},
+			expectedError: false,
+		},
+		{
+			description: "unknown strategy, expect error",
+			natGateway: v1alpha1.AWSConfigSpecAWSNATGateway{
+				Strategy: "none",
+			},
+			expectedError: true,
+		},
+		{
+			description: "allocation ID missing for an availability zone, expect error",
+			natGateway: v1alpha1.AWSConfigSpecAWSNATGateway{
+				AllocationIDs: []string{"eipalloc-a"},
+			},
+			expectedError: true,
+		},
+		{
+			description: "too many allocation IDs for single strategy, expect error",
+			natGateway: v1alpha1.AWSConfigSpecAWSNATGateway{
+				Strategy:      "single",
+				AllocationIDs: []string{"eipalloc-a", "eipalloc-b"},
+			},
+			expectedError: true,
+		},
+		{
+			description: "NAT gateway configured for existing VPC, expect error",
+			natGateway: v1alpha1.AWSConfigSpecAWSNATGateway{
+				Strategy: "single",
+			},
+			vpcID:         "vpc-existing",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			customObject := v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						NATGateway: tc.natGateway,
+						VPC: v1alpha1.AWSConfigSpecAWSVPC{
+							ID: tc.vpcID,
+						},
+					},
+				},
+				Status: v1alpha1.AWSConfigStatus{
+					AWS: v1alpha1.AWSConfigStatusAWS{
+						AvailabilityZones: twoAZs,
+					},
+				},
+			}
+
+			r := &Resource{}
+
+			err := r.validateNATGateway(context.Background(), customObject)
+			if tc.expectedError && !IsInvalidConfig(err) {
+				t.Fatalf("expected invalid config error got %v", err)
+			}
+			if !tc.expectedError && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func Test_validateNodePools(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description   string
+		nodePools     []v1alpha1.AWSConfigSpecAWSNodePool
+		expectedError bool
+	}{
+		{
+			description:   "no node pools, do not expect error",
+			expectedError: false,
+		},
+		{
+			description: "two node pools, do not expect error",
+			nodePools: []v1alpha1.AWSConfigSpecAWSNodePool{
+				{Name: "gpu", InstanceType: "p3.2xlarge", MaxSize: 2, MinSize: 0},
+				{Name: "memory2", InstanceType: "r5.xlarge", MaxSize: 6, MinSize: 5},
+			},
+			expectedError: false,
+		},
+		{
+			description: "invalid node pool name, expect error",
+			nodePools: []v1alpha1.AWSConfigSpecAWSNodePool{
+				{Name: "GPU-pool", InstanceType: "p3.2xlarge", MaxSize: 2, MinSize: 1},
+			},
+			expectedError: true,
+		},
+		{
+			description: "duplicate node pool name, expect error",
+			nodePools: []v1alpha1.AWSConfigSpecAWSNodePool{
+				{Name: "gpu", InstanceType: "p3.2xlarge", MaxSize: 2, MinSize: 1},
+				{Name: "gpu", InstanceType: "p3.8xlarge", MaxSize: 2, MinSize: 1},
+			},
+			expectedError: true,
+		},
+		{
+			description: "empty instance type, expect error",
+			nodePools: []v1alpha1.AWSConfigSpecAWSNodePool{
+				{Name: "gpu", MaxSize: 2, MinSize: 1},
+			},
+			expectedError: true,
+		},
+		{
+			description: "max size not greater than min size, expect error",
+			nodePools: []v1alpha1.AWSConfigSpecAWSNodePool{
+				{Name: "gpu", InstanceType: "p3.2xlarge", MaxSize: 2, MinSize: 2},
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			customObject := v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						NodePools: tc.nodePools,
+					},
+				},
+			}
+
+			r := &Resource{}
+
+			err := r.validateNodePools(context.Background(), customObject)
+			if tc.expectedError && !IsInvalidConfig(err) {
+				t.Fatalf("expected invalid config error got %v", err)
+			}
+			if !tc.expectedError && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func Test_validateScaling(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description   string
+		scaling       v1alpha1.ClusterScaling
+		expectedError bool
+	}{
+		{
+			description:   "no scaling bounds, do not expect error",
+			expectedError: false,
+		},
+		{
+			description: "valid scaling bounds, do not expect error",
+			scaling: v1alpha1.ClusterScaling{
+				Max: 10,
+				Min: 3,
+			},
+			expectedError: false,
+		},
+		{
+			description: "scaling max less than scaling min, expect error",
+			scaling: v1alpha1.ClusterScaling{
+				Max: 2,
+				Min: 3,
+			},
+			expectedError: true,
+		},
+		{
+			description: "scaling min of zero, expect error",
+			scaling: v1alpha1.ClusterScaling{
+				Max: 2,
+				Min: 0,
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			customObject := v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					Cluster: v1alpha1.Cluster{
+						Scaling: tc.scaling,
+					},
+				},
+			}
+
+			r := &Resource{}
+
+			err := r.validateScaling(context.Background(), customObject)
+			if tc.expectedError && !IsInvalidConfig(err) {
+				t.Fatalf("expected invalid config error got %v", err)
+			}
+			if !tc.expectedError && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func Test_validateAPIWhitelist(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description             string
+		apiWhitelist            v1alpha1.AWSConfigSpecAWSAPIWhitelist
+		installationWhitelist   adapter.APIWhitelist
+		ingressLoadBalancerType string
+		expectedError           bool
+	}{
+		{
+			description:   "no whitelisted CIDRs, do not expect error",
+			expectedError: false,
+		},
+		{
+			description: "valid API and ingress CIDRs, do not expect error",
+			apiWhitelist: v1alpha1.AWSConfigSpecAWSAPIWhitelist{
+				APICIDRs:     []string{"212.145.136.84/32", "192.168.1.0/24"},
+				IngressCIDRs: []string{"10.0.0.0/8"},
+			},
+			expectedError: false,
+		},
+		{
+			description: "API CIDR without prefix length, expect error",
+			apiWhitelist: v1alpha1.AWSConfigSpecAWSAPIWhitelist{
+				APICIDRs: []string{"212.145.136.84"},
+			},
+			expectedError: true,
+		},
+		{
+			description: "IPv6 API CIDR, expect error",
+			apiWhitelist: v1alpha1.AWSConfigSpecAWSAPIWhitelist{
+				APICIDRs: []string{"2001:db8::/32"},
+			},
+			expectedError: true,
+		},
+		{
+			description: "malformed ingress CIDR, expect error",
+			apiWhitelist: v1alpha1.AWSConfigSpecAWSAPIWhitelist{
+				IngressCIDRs: []string{"10.0.0.0/33"},
+			},
+			expectedError: true,
+		},
+		{
+			description: "ingress CIDRs with network load balancer, expect error",
+			apiWhitelist: v1alpha1.AWSConfigSpecAWSAPIWhitelist{
+				IngressCIDRs: []string{"10.0.0.0/8"},
+			},
+			ingressLoadBalancerType: "nlb",
+			expectedError:           true,
+		},
+		{
+			description: "API CIDRs filling the master security group, do not expect error",
+			apiWhitelist: v1alpha1.AWSConfigSpecAWSAPIWhitelist{
+				APICIDRs: testCIDRs("172.16.%d.0/24", 50),
+			},
+			expectedError: false,
+		},
+		{
+			description: "too many API CIDRs for the master security group, expect error",
+			apiWhitelist: v1alpha1.AWSConfigSpecAWSAPIWhitelist{
+				APICIDRs: testCIDRs("172.16.%d.0/24", 51),
+			},
+			expectedError: true,
+		},
+		{
+			description: "API CIDRs merged with the installation whitelist filling the master security group, do not expect error",
+			apiWhitelist: v1alpha1.AWSConfigSpecAWSAPIWhitelist{
+				APICIDRs: testCIDRs("172.16.%d.0/24", 48),
+			},
+			installationWhitelist: adapter.APIWhitelist{
+				Enabled:    true,
+				SubnetList: "10.1.0.0/16,10.2.0.0/16,172.16.0.0/24",
+			},
+			expectedError: false,
+		},
+		{
+			description: "too many ingress CIDRs for the ingress security group, expect error",
+			apiWhitelist: v1alpha1.AWSConfigSpecAWSAPIWhitelist{
+				IngressCIDRs: testCIDRs("172.16.%d.0/24", 31),
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			customObject := v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						APIWhitelist: tc.apiWhitelist,
+						LoadBalancers: v1alpha1.AWSConfigSpecAWSLoadBalancers{
+							Ingress: v1alpha1.AWSConfigSpecAWSLoadBalancer{
+								Type: tc.ingressLoadBalancerType,
+							},
+						},
+					},
+				},
+				Status: v1alpha1.AWSConfigStatus{
+					AWS: v1alpha1.AWSConfigStatusAWS{
+						AvailabilityZones: []v1alpha1.AWSConfigStatusAWSAvailabilityZone{
+							{Name: "eu-central-1a"},
+							{Name: "eu-central-1b"},
+						},
+					},
+				},
+			}
+
+			r := &Resource{
+				apiWhiteList: tc.installationWhitelist,
+				hostClients: &adapter.Clients{
+					EC2: &adapter.EC2ClientMock{},
+				},
+			}
+
+			err := r.validateAPIWhitelist(context.Background(), customObject)
+			if tc.expectedError && !IsInvalidConfig(err) {
+				t.Fatalf("expected invalid config error got %v", err)
+			}
+			if !tc.expectedError && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func testCIDRs(format string, n int) []string {
+	var cidrs []string
+	for i := 0; i < n; i++ {
+		cidrs = append(cidrs, fmt.Sprintf(format, i))
+	}
+
+	return cidrs
+}
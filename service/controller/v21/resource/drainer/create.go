@@ -30,21 +30,23 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 		return microerror.Mask(err)
 	}
 
-	workerASGName := controllerCtx.Status.Drainer.WorkerASGName
-	if workerASGName == "" {
-		r.logger.LogCtx(ctx, "level", "debug", "message", "worker ASG name is not available yet")
+	workerASGNames := controllerCtx.Status.Drainer.WorkerASGNames
+	if len(workerASGNames) == 0 {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "worker ASG names are not available yet")
 		r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
 		return nil
 	}
 
+	// instanceASGNames maps the IDs of the instances to drain to the names of
+	// the ASGs they belong to. The drainfinisher resource needs the ASG name to
+	// complete the lifecycle hook of the instance.
 	var instances []*autoscaling.Instance
+	instanceASGNames := map[string]string{}
 	{
 		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("finding the guest cluster nodes being in state %#q", autoscaling.LifecycleStateTerminatingWait))
 
 		i := &autoscaling.DescribeAutoScalingGroupsInput{
-			AutoScalingGroupNames: []*string{
-				aws.String(workerASGName),
-			},
+			AutoScalingGroupNames: aws.StringSlice(workerASGNames),
 		}
 
 		o, err := controllerCtx.AWSClient.AutoScaling.DescribeAutoScalingGroups(i)
@@ -56,6 +58,7 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 			for _, i := range g.Instances {
 				if *i.LifecycleState == autoscaling.LifecycleStateTerminatingWait {
 					instances = append(instances, i)
+					instanceASGNames[*i.InstanceId] = *g.AutoScalingGroupName
 				}
 			}
 		}
@@ -98,7 +101,7 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 			if errors.IsNotFound(err) {
 				r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("did not find drainer config for guest cluster node %#q", *instance.InstanceId))
 
-				err := r.createDrainerConfig(ctx, customObject, *instance.InstanceId, privateDNS)
+				err := r.createDrainerConfig(ctx, customObject, *instance.InstanceId, instanceASGNames[*instance.InstanceId], privateDNS)
 				if err != nil {
 					return microerror.Mask(err)
 				}
@@ -116,14 +119,15 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 	return nil
 }
 
-func (r *Resource) createDrainerConfig(ctx context.Context, customObject providerv1alpha1.AWSConfig, instanceID, privateDNS string) error {
+func (r *Resource) createDrainerConfig(ctx context.Context, customObject providerv1alpha1.AWSConfig, instanceID, asgName, privateDNS string) error {
 	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("creating drainer config for guest cluster nodes %#q", instanceID))
 
 	n := customObject.GetNamespace()
 	c := &corev1alpha1.DrainerConfig{
 		ObjectMeta: metav1.ObjectMeta{
 			Annotations: map[string]string{
-				key.InstanceIDAnnotation: instanceID,
+				key.AutoScalingGroupAnnotation: asgName,
+				key.InstanceIDAnnotation:       instanceID,
 			},
 			Labels: map[string]string{
 				key.ClusterIDLabel: key.ClusterID(customObject),
@@ -28,9 +28,9 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 		return microerror.Mask(err)
 	}
 
-	workerASGName := controllerCtx.Status.Drainer.WorkerASGName
-	if workerASGName == "" {
-		r.logger.LogCtx(ctx, "level", "debug", "message", "worker ASG name is not available yet")
+	workerASGNames := controllerCtx.Status.Drainer.WorkerASGNames
+	if len(workerASGNames) == 0 {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "worker ASG names are not available yet")
 		r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
 		return nil
 	}
@@ -84,7 +84,15 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 				return microerror.Mask(err)
 			}
 
-			err = r.completeLifecycleHook(ctx, instanceID, workerASGName)
+			// Drainer configs created before the introduction of node pools do not
+			// carry the ASG name annotation. Their instances belong to the worker
+			// ASG, which is always the first of the worker ASG names.
+			asgName := asgNameFromAnnotations(drainerConfig.GetAnnotations())
+			if asgName == "" {
+				asgName = workerASGNames[0]
+			}
+
+			err = r.completeLifecycleHook(ctx, instanceID, asgName)
 			if err != nil {
 				return microerror.Mask(err)
 			}
@@ -144,6 +152,10 @@ func (r *Resource) deleteDrainerConfig(ctx context.Context, drainerConfig corev1
 	return nil
 }
 
+func asgNameFromAnnotations(annotations map[string]string) string {
+	return annotations[key.AutoScalingGroupAnnotation]
+}
+
 func instanceIDFromAnnotations(annotations map[string]string) (string, error) {
 	instanceID, ok := annotations[key.InstanceIDAnnotation]
 	if !ok {
@@ -0,0 +1,265 @@
+package ebsencryption
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/aws-operator/pkg/awstags"
+	"github.com/giantswarm/aws-operator/service/controller/v21/controllercontext"
+	"github.com/giantswarm/aws-operator/service/controller/v21/key"
+)
+
+const (
+	autoScalingServiceName = "autoscaling.amazonaws.com"
+)
+
+// autoScalingGrantOperations are the operations the EC2 Auto Scaling service
+// needs to launch instances with EBS volumes encrypted by a customer managed
+// KMS key.
+//
+// https://docs.aws.amazon.com/autoscaling/ec2/userguide/key-policy-requirements-EBS-encryption.html
+var autoScalingGrantOperations = []string{
+	kms.GrantOperationCreateGrant,
+	kms.GrantOperationDecrypt,
+	kms.GrantOperationDescribeKey,
+	kms.GrantOperationEncrypt,
+	kms.GrantOperationGenerateDataKey,
+	kms.GrantOperationGenerateDataKeyWithoutPlaintext,
+	kms.GrantOperationReEncryptFrom,
+	kms.GrantOperationReEncryptTo,
+}
+
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	controllerCtx, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	keyARN := r.keyARN
+	if keyARN == "" {
+		keyARN, err = r.ensureClusterKey(ctx, customObject)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	} else {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("using installation KMS key %#q for EBS volumes", keyARN))
+	}
+
+	err = r.ensureAutoScalingGrant(ctx, customObject, keyARN)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	controllerCtx.Status.Cluster.VolumeEncryption.KeyARN = keyARN
+
+	return nil
+}
+
+// ensureClusterKey ensures the KMS key of the guest cluster for EBS volumes
+// exists and returns its ARN.
+func (r *Resource) ensureClusterKey(ctx context.Context, customObject v1alpha1.AWSConfig) (string, error) {
+	controllerCtx, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	var oldKeyScheduledForDeletion bool
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "finding KMS key for EBS volumes")
+
+		i := &kms.DescribeKeyInput{
+			KeyId: aws.String(key.VolumeEncryptionKeyAlias(customObject)),
+		}
+
+		o, err := controllerCtx.AWSClient.KMS.DescribeKey(i)
+		if isKeyNotFound(err) {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "did not find KMS key for EBS volumes")
+		} else if err != nil {
+			return "", microerror.Mask(err)
+		} else if o.KeyMetadata.DeletionDate != nil {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "found KMS key for EBS volumes")
+			r.logger.LogCtx(ctx, "level", "debug", "message", "KMS key for EBS volumes is scheduled for deletion and will be recreated")
+			oldKeyScheduledForDeletion = true
+		} else {
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found KMS key %#q for EBS volumes", *o.KeyMetadata.Arn))
+			return *o.KeyMetadata.Arn, nil
+		}
+	}
+
+	if oldKeyScheduledForDeletion {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "deleting alias of old KMS key for EBS volumes")
+
+		i := &kms.DeleteAliasInput{
+			AliasName: aws.String(key.VolumeEncryptionKeyAlias(customObject)),
+		}
+
+		_, err := controllerCtx.AWSClient.KMS.DeleteAlias(i)
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "deleted alias of old KMS key for EBS volumes")
+	}
+
+	var keyMetadata *kms.KeyMetadata
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "creating KMS key for EBS volumes")
+
+		tags := key.ClusterTags(customObject, r.installationName)
+
+		i := &kms.CreateKeyInput{
+			Description: aws.String(fmt.Sprintf("EBS volumes of guest cluster %s", key.ClusterID(customObject))),
+			Tags:        awstags.NewKMS(tags),
+		}
+
+		o, err := controllerCtx.AWSClient.KMS.CreateKey(i)
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+
+		keyMetadata = o.KeyMetadata
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("created KMS key %#q for EBS volumes", *keyMetadata.Arn))
+	}
+
+	// Rotation is enabled before the alias is created, so that finding the key
+	// by its alias guarantees rotation to be enabled.
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "enabling rotation of KMS key for EBS volumes")
+
+		i := &kms.EnableKeyRotationInput{
+			KeyId: keyMetadata.KeyId,
+		}
+
+		_, err := controllerCtx.AWSClient.KMS.EnableKeyRotation(i)
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "enabled rotation of KMS key for EBS volumes")
+	}
+
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "creating alias of KMS key for EBS volumes")
+
+		i := &kms.CreateAliasInput{
+			AliasName:   aws.String(key.VolumeEncryptionKeyAlias(customObject)),
+			TargetKeyId: keyMetadata.KeyId,
+		}
+
+		_, err := controllerCtx.AWSClient.KMS.CreateAlias(i)
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "created alias of KMS key for EBS volumes")
+	}
+
+	return *keyMetadata.Arn, nil
+}
+
+// ensureAutoScalingGrant ensures the service-linked role of the EC2 Auto
+// Scaling service is allowed to use the given KMS key. Without the grant the
+// worker ASG fails to launch instances with encrypted EBS volumes. The role is
+// created on demand, because AWS only creates it with the first ASG of the
+// account.
+func (r *Resource) ensureAutoScalingGrant(ctx context.Context, customObject v1alpha1.AWSConfig, keyARN string) error {
+	controllerCtx, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	accountID, err := controllerCtx.AWSService.GetAccountID()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	roleARN := key.AutoScalingServiceLinkedRoleARN(customObject, accountID)
+
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "finding EC2 Auto Scaling service-linked role")
+
+		i := &iam.GetRoleInput{
+			RoleName: aws.String(key.AutoScalingServiceLinkedRoleName),
+		}
+
+		_, err := controllerCtx.AWSClient.IAM.GetRole(i)
+		if isRoleNotFound(err) {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "did not find EC2 Auto Scaling service-linked role")
+			r.logger.LogCtx(ctx, "level", "debug", "message", "creating EC2 Auto Scaling service-linked role")
+
+			i := &iam.CreateServiceLinkedRoleInput{
+				AWSServiceName: aws.String(autoScalingServiceName),
+			}
+
+			_, err := controllerCtx.AWSClient.IAM.CreateServiceLinkedRole(i)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+
+			r.logger.LogCtx(ctx, "level", "debug", "message", "created EC2 Auto Scaling service-linked role")
+		} else if err != nil {
+			return microerror.Mask(err)
+		} else {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "found EC2 Auto Scaling service-linked role")
+		}
+	}
+
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "finding KMS grant for EC2 Auto Scaling service-linked role")
+
+		i := &kms.ListGrantsInput{
+			KeyId: aws.String(keyARN),
+		}
+
+		for {
+			o, err := controllerCtx.AWSClient.KMS.ListGrants(i)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+
+			for _, g := range o.Grants {
+				if aws.StringValue(g.GranteePrincipal) == roleARN {
+					r.logger.LogCtx(ctx, "level", "debug", "message", "found KMS grant for EC2 Auto Scaling service-linked role")
+					return nil
+				}
+			}
+
+			if !aws.BoolValue(o.Truncated) {
+				break
+			}
+			i.Marker = o.NextMarker
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "did not find KMS grant for EC2 Auto Scaling service-linked role")
+	}
+
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "creating KMS grant for EC2 Auto Scaling service-linked role")
+
+		i := &kms.CreateGrantInput{
+			GranteePrincipal: aws.String(roleARN),
+			KeyId:            aws.String(keyARN),
+			Operations:       aws.StringSlice(autoScalingGrantOperations),
+		}
+
+		_, err := controllerCtx.AWSClient.KMS.CreateGrant(i)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "created KMS grant for EC2 Auto Scaling service-linked role")
+	}
+
+	return nil
+}
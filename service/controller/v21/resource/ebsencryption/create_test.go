@@ -0,0 +1,236 @@
+package ebsencryption
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+
+	awsclient "github.com/giantswarm/aws-operator/client/aws"
+	awsservice "github.com/giantswarm/aws-operator/service/aws"
+	"github.com/giantswarm/aws-operator/service/controller/v21/controllercontext"
+)
+
+const (
+	testRoleARN = "arn:aws:iam::123456789012:role/aws-service-role/autoscaling.amazonaws.com/AWSServiceRoleForAutoScaling"
+)
+
+type iamMock struct {
+	iamiface.IAMAPI
+
+	roleExists  bool
+	roleCreated bool
+}
+
+func (i *iamMock) GetRole(*iam.GetRoleInput) (*iam.GetRoleOutput, error) {
+	if !i.roleExists {
+		return nil, awserr.New(iam.ErrCodeNoSuchEntityException, "role not found", nil)
+	}
+
+	return &iam.GetRoleOutput{}, nil
+}
+
+func (i *iamMock) CreateServiceLinkedRole(*iam.CreateServiceLinkedRoleInput) (*iam.CreateServiceLinkedRoleOutput, error) {
+	i.roleCreated = true
+
+	return &iam.CreateServiceLinkedRoleOutput{}, nil
+}
+
+type kmsMock struct {
+	kmsiface.KMSAPI
+
+	keyARN            string
+	keyDeletionDate   *time.Time
+	grantees          []string
+	keyCreated        bool
+	aliasCreated      bool
+	rotationEnabled   bool
+	createdGrantKeyID string
+}
+
+func (k *kmsMock) DescribeKey(*kms.DescribeKeyInput) (*kms.DescribeKeyOutput, error) {
+	if k.keyARN == "" {
+		return nil, awserr.New(kms.ErrCodeNotFoundException, "key not found", nil)
+	}
+
+	o := &kms.DescribeKeyOutput{
+		KeyMetadata: &kms.KeyMetadata{
+			Arn:          aws.String(k.keyARN),
+			DeletionDate: k.keyDeletionDate,
+			KeyId:        aws.String("key-id"),
+		},
+	}
+
+	return o, nil
+}
+
+func (k *kmsMock) CreateKey(*kms.CreateKeyInput) (*kms.CreateKeyOutput, error) {
+	k.keyCreated = true
+
+	o := &kms.CreateKeyOutput{
+		KeyMetadata: &kms.KeyMetadata{
+			Arn:   aws.String("arn:aws:kms:eu-central-1:123456789012:key/new-key-id"),
+			KeyId: aws.String("new-key-id"),
+		},
+	}
+
+	return o, nil
+}
+
+func (k *kmsMock) CreateAlias(*kms.CreateAliasInput) (*kms.CreateAliasOutput, error) {
+	k.aliasCreated = true
+
+	return &kms.CreateAliasOutput{}, nil
+}
+
+func (k *kmsMock) DeleteAlias(*kms.DeleteAliasInput) (*kms.DeleteAliasOutput, error) {
+	return &kms.DeleteAliasOutput{}, nil
+}
+
+func (k *kmsMock) EnableKeyRotation(*kms.EnableKeyRotationInput) (*kms.EnableKeyRotationOutput, error) {
+	k.rotationEnabled = true
+
+	return &kms.EnableKeyRotationOutput{}, nil
+}
+
+func (k *kmsMock) ListGrants(*kms.ListGrantsInput) (*kms.ListGrantsResponse, error) {
+	o := &kms.ListGrantsResponse{}
+	for _, g := range k.grantees {
+		o.Grants = append(o.Grants, &kms.GrantListEntry{GranteePrincipal: aws.String(g)})
+	}
+
+	return o, nil
+}
+
+func (k *kmsMock) CreateGrant(i *kms.CreateGrantInput) (*kms.CreateGrantOutput, error) {
+	k.createdGrantKeyID = *i.KeyId
+	k.grantees = append(k.grantees, *i.GranteePrincipal)
+
+	return &kms.CreateGrantOutput{}, nil
+}
+
+func Test_Resource_EBSEncryption_EnsureCreated(t *testing.T) {
+	deletionDate := time.Now().Add(7 * 24 * time.Hour)
+
+	testCases := []struct {
+		name                    string
+		installationKeyARN      string
+		kms                     *kmsMock
+		iam                     *iamMock
+		expectedKeyARN          string
+		expectedKeyCreated      bool
+		expectedGrantKeyID      string
+		expectedRoleCreated     bool
+		expectedRotationEnabled bool
+	}{
+		{
+			name: "case 0: existing cluster key with grant",
+			kms: &kmsMock{
+				keyARN:   "arn:aws:kms:eu-central-1:123456789012:key/key-id",
+				grantees: []string{testRoleARN},
+			},
+			iam:            &iamMock{roleExists: true},
+			expectedKeyARN: "arn:aws:kms:eu-central-1:123456789012:key/key-id",
+		},
+		{
+			name:                    "case 1: missing cluster key and grant",
+			kms:                     &kmsMock{},
+			iam:                     &iamMock{roleExists: true},
+			expectedKeyARN:          "arn:aws:kms:eu-central-1:123456789012:key/new-key-id",
+			expectedKeyCreated:      true,
+			expectedGrantKeyID:      "arn:aws:kms:eu-central-1:123456789012:key/new-key-id",
+			expectedRotationEnabled: true,
+		},
+		{
+			name: "case 2: cluster key scheduled for deletion gets recreated",
+			kms: &kmsMock{
+				keyARN:          "arn:aws:kms:eu-central-1:123456789012:key/key-id",
+				keyDeletionDate: &deletionDate,
+				grantees:        []string{testRoleARN},
+			},
+			iam:                     &iamMock{roleExists: true},
+			expectedKeyARN:          "arn:aws:kms:eu-central-1:123456789012:key/new-key-id",
+			expectedKeyCreated:      true,
+			expectedRotationEnabled: true,
+		},
+		{
+			name:                "case 3: installation key without service-linked role",
+			installationKeyARN:  "arn:aws:kms:eu-central-1:123456789012:key/installation",
+			kms:                 &kmsMock{},
+			iam:                 &iamMock{},
+			expectedKeyARN:      "arn:aws:kms:eu-central-1:123456789012:key/installation",
+			expectedGrantKeyID:  "arn:aws:kms:eu-central-1:123456789012:key/installation",
+			expectedRoleCreated: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			customObject := v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						Region: "eu-central-1",
+					},
+					Cluster: v1alpha1.Cluster{
+						ID: "al9qy",
+					},
+				},
+			}
+
+			c := controllercontext.Context{
+				AWSClient: awsclient.Clients{
+					IAM: tc.iam,
+					KMS: tc.kms,
+				},
+				AWSService: awsservice.AwsServiceMock{
+					AccountID: "123456789012",
+				},
+			}
+			ctx := controllercontext.NewContext(context.Background(), c)
+
+			r, err := New(Config{
+				Logger: microloggertest.New(),
+
+				InstallationName: "test-installation",
+				KeyARN:           tc.installationKeyARN,
+			})
+			if err != nil {
+				t.Fatalf("expected nil got %#v", err)
+			}
+
+			err = r.EnsureCreated(ctx, &customObject)
+			if err != nil {
+				t.Fatalf("expected nil got %#v", err)
+			}
+
+			controllerCtx, err := controllercontext.FromContext(ctx)
+			if err != nil {
+				t.Fatalf("expected nil got %#v", err)
+			}
+
+			if controllerCtx.Status.Cluster.VolumeEncryption.KeyARN != tc.expectedKeyARN {
+				t.Fatalf("expected key ARN %#q got %#q", tc.expectedKeyARN, controllerCtx.Status.Cluster.VolumeEncryption.KeyARN)
+			}
+			if tc.kms.keyCreated != tc.expectedKeyCreated || tc.kms.aliasCreated != tc.expectedKeyCreated {
+				t.Fatalf("expected key created %t got key %t alias %t", tc.expectedKeyCreated, tc.kms.keyCreated, tc.kms.aliasCreated)
+			}
+			if tc.kms.rotationEnabled != tc.expectedRotationEnabled {
+				t.Fatalf("expected rotation enabled %t got %t", tc.expectedRotationEnabled, tc.kms.rotationEnabled)
+			}
+			if tc.kms.createdGrantKeyID != tc.expectedGrantKeyID {
+				t.Fatalf("expected grant for key %#q got %#q", tc.expectedGrantKeyID, tc.kms.createdGrantKeyID)
+			}
+			if tc.iam.roleCreated != tc.expectedRoleCreated {
+				t.Fatalf("expected role created %t got %t", tc.expectedRoleCreated, tc.iam.roleCreated)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package ebsencryption
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/aws-operator/service/controller/v21/controllercontext"
+	"github.com/giantswarm/aws-operator/service/controller/v21/key"
+)
+
+// EnsureDeleted schedules the deletion of the KMS key of the guest cluster for
+// EBS volumes. The installation KMS key is shared by all guest clusters and
+// therefore kept.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if r.keyARN != "" {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not deleting installation KMS key for EBS volumes")
+		r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+		return nil
+	}
+
+	controllerCtx, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	var keyID *string
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "finding KMS key for EBS volumes")
+
+		i := &kms.DescribeKeyInput{
+			KeyId: aws.String(key.VolumeEncryptionKeyAlias(customObject)),
+		}
+
+		o, err := controllerCtx.AWSClient.KMS.DescribeKey(i)
+		if isKeyNotFound(err) {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "did not find KMS key for EBS volumes")
+			r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+			return nil
+		} else if err != nil {
+			return microerror.Mask(err)
+		} else if o.KeyMetadata.DeletionDate != nil {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "KMS key for EBS volumes is scheduled for deletion")
+			r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+			return nil
+		}
+
+		keyID = o.KeyMetadata.KeyId
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found KMS key %#q for EBS volumes", *o.KeyMetadata.Arn))
+	}
+
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "scheduling deletion of KMS key for EBS volumes")
+
+		// 7 days is the smallest possible pending window. The volumes of the guest
+		// cluster are deleted together with the guest cluster main stack well
+		// within it.
+		i := &kms.ScheduleKeyDeletionInput{
+			KeyId:               keyID,
+			PendingWindowInDays: aws.Int64(7),
+		}
+
+		_, err := controllerCtx.AWSClient.KMS.ScheduleKeyDeletion(i)
+		if isKeyNotFound(err) || isInvalidState(err) {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "KMS key for EBS volumes is already deleted or scheduled for deletion")
+			return nil
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "scheduled deletion of KMS key for EBS volumes")
+	}
+
+	return nil
+}
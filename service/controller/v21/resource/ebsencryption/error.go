@@ -0,0 +1,36 @@
+package ebsencryption
+
+import (
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/giantswarm/microerror"
+)
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+// isKeyNotFound asserts the KMS not found error.
+func isKeyNotFound(err error) bool {
+	aerr, ok := microerror.Cause(err).(awserr.Error)
+	return ok && aerr.Code() == kms.ErrCodeNotFoundException
+}
+
+// isInvalidState asserts the KMS invalid state error, e.g. returned when
+// scheduling the deletion of a key which is already pending deletion.
+func isInvalidState(err error) bool {
+	aerr, ok := microerror.Cause(err).(awserr.Error)
+	return ok && aerr.Code() == kms.ErrCodeInvalidStateException
+}
+
+// isRoleNotFound asserts the IAM no such entity error.
+func isRoleNotFound(err error) bool {
+	aerr, ok := microerror.Cause(err).(awserr.Error)
+	return ok && aerr.Code() == iam.ErrCodeNoSuchEntityException
+}
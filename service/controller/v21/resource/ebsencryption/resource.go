@@ -0,0 +1,57 @@
+package ebsencryption
+
+import (
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+)
+
+const (
+	name = "ebsencryptionv21"
+)
+
+// Config represents the configuration used to create a new ebsencryption
+// resource.
+type Config struct {
+	Logger micrologger.Logger
+
+	InstallationName string
+	// KeyARN is the ARN of the KMS key the EBS volumes of all guest clusters
+	// are encrypted with. Each guest cluster gets its own KMS key in case it is
+	// empty.
+	KeyARN string
+}
+
+// Resource ensures the KMS key the EBS volumes of the guest cluster are
+// encrypted with and puts its ARN into the controller context, so that the
+// cloudformation resource is able to reference it in the guest cluster main
+// stack.
+type Resource struct {
+	logger micrologger.Logger
+
+	installationName string
+	keyARN           string
+}
+
+// New creates a new configured ebsencryption resource.
+func New(config Config) (*Resource, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.InstallationName == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.InstallationName must not be empty", config)
+	}
+
+	r := &Resource{
+		logger: config.Logger,
+
+		installationName: config.InstallationName,
+		keyARN:           config.KeyARN,
+	}
+
+	return r, nil
+}
+
+func (r *Resource) Name() string {
+	return name
+}
@@ -0,0 +1,267 @@
+package encryptionrekey
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/controller/context/updateallowedcontext"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cloudformationservice "github.com/giantswarm/aws-operator/service/controller/v21/cloudformation"
+	"github.com/giantswarm/aws-operator/service/controller/v21/controllercontext"
+	"github.com/giantswarm/aws-operator/service/controller/v21/key"
+)
+
+// EnsureCreated advances the re-key of the guest cluster by one phase at most
+// and records the progress in the CR status.
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if !customObject.Status.Cluster.HasCreatedCondition() {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not re-keying the guest cluster because it is not yet created")
+		r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+		return nil
+	}
+
+	current := customObject.Status.AWS.EncryptionKey
+
+	var desired v1alpha1.AWSConfigStatusAWSEncryptionKey
+	switch current.Phase {
+	case "":
+		desired, err = r.startRekey(ctx, customObject)
+	case PhaseRolling:
+		desired, err = r.finishRolling(ctx, customObject)
+	case PhaseRetiring:
+		desired, err = r.retirePreviousKey(ctx, customObject)
+	default:
+		return microerror.Maskf(executionFailedError, "unknown re-key phase %#q", current.Phase)
+	}
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if reflect.DeepEqual(current, desired) {
+		return nil
+	}
+
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "updating CR status with encryption key")
+
+		newObj, err := r.g8sClient.ProviderV1alpha1().AWSConfigs(customObject.GetNamespace()).Get(customObject.GetName(), metav1.GetOptions{})
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		newObj.Status.AWS.EncryptionKey = desired
+
+		_, err = r.g8sClient.ProviderV1alpha1().AWSConfigs(newObj.GetNamespace()).UpdateStatus(newObj)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "updated CR status with encryption key")
+	}
+
+	return nil
+}
+
+// startRekey rotates the encryption key of the guest cluster in case it
+// reached the configured age. The re-key is only started when updates are
+// allowed, because the nodes are replaced right afterwards.
+func (r *Resource) startRekey(ctx context.Context, customObject v1alpha1.AWSConfig) (v1alpha1.AWSConfigStatusAWSEncryptionKey, error) {
+	current := customObject.Status.AWS.EncryptionKey
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", "finding out if the guest cluster has to be re-keyed")
+
+	state, err := r.encrypter.CurrentState(ctx, customObject)
+	if err != nil {
+		return v1alpha1.AWSConfigStatusAWSEncryptionKey{}, microerror.Mask(err)
+	}
+
+	if state.KeyID == "" {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "did not find encryption key")
+		r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+		return current, nil
+	}
+	if time.Since(state.CreationTime) < r.interval {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("the guest cluster does not have to be re-keyed before %s", state.CreationTime.Add(r.interval).Format(time.RFC3339)))
+		return current, nil
+	}
+	if !updateallowedcontext.IsUpdateAllowed(ctx) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not re-keying the guest cluster because updates are not allowed")
+		r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+		return current, nil
+	}
+
+	r.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("re-keying the guest cluster because encryption key %#q is older than %s", state.KeyID, r.interval))
+
+	newState, err := r.encrypter.RotateEncryptionKey(ctx, customObject)
+	if err != nil {
+		return v1alpha1.AWSConfigStatusAWSEncryptionKey{}, microerror.Mask(err)
+	}
+
+	r.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("re-keyed the guest cluster with encryption key %#q", newState.KeyID))
+
+	desired := v1alpha1.AWSConfigStatusAWSEncryptionKey{
+		ID:         newState.KeyID,
+		Phase:      PhaseRolling,
+		PhaseTime:  v1alpha1.DeepCopyTime{Time: time.Now()},
+		PreviousID: state.KeyID,
+	}
+
+	return desired, nil
+}
+
+// finishRolling finds out if all nodes of the guest cluster got replaced
+// after the re-key. This is the case once the guest cluster main stack got
+// updated with the new encryption key, no worker rollout is in progress and
+// all worker instances are tagged with the new encryption key.
+func (r *Resource) finishRolling(ctx context.Context, customObject v1alpha1.AWSConfig) (v1alpha1.AWSConfigStatusAWSEncryptionKey, error) {
+	current := customObject.Status.AWS.EncryptionKey
+
+	controllerCtx, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return v1alpha1.AWSConfigStatusAWSEncryptionKey{}, microerror.Mask(err)
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", "finding out if the nodes of the guest cluster got replaced")
+
+	outputs, stackStatus, err := controllerCtx.CloudFormation.DescribeOutputsAndStatus(key.MainGuestStackName(customObject))
+	if cloudformationservice.IsStackNotFound(err) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "the guest cluster main stack is not yet created")
+		r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+		return current, nil
+	} else if cloudformationservice.IsOutputsNotAccessible(err) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("the guest cluster main stack output values are not accessible due to stack status '%s'", stackStatus))
+		r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+		return current, nil
+	} else if err != nil {
+		return v1alpha1.AWSConfigStatusAWSEncryptionKey{}, microerror.Mask(err)
+	}
+
+	keyID, err := controllerCtx.CloudFormation.GetOutputValue(outputs, key.EncryptionKeyIDKey)
+	if cloudformationservice.IsOutputNotFound(err) {
+		keyID = ""
+	} else if err != nil {
+		return v1alpha1.AWSConfigStatusAWSEncryptionKey{}, microerror.Mask(err)
+	}
+
+	if keyID != current.ID {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "the guest cluster main stack is not yet updated with the new encryption key")
+		return current, nil
+	}
+	if len(customObject.Status.AWS.WorkerRollouts) > 0 {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "the worker nodes of the guest cluster are still being replaced")
+		return current, nil
+	}
+
+	// Updating the stack only replaces the launch templates of the workers. The
+	// worker instances launched before keep running with assets encrypted with
+	// the previous key until they got replaced as well.
+	outdated, err := outdatedWorkerInstances(controllerCtx.AWSClient.EC2, customObject, current.ID)
+	if err != nil {
+		return v1alpha1.AWSConfigStatusAWSEncryptionKey{}, microerror.Mask(err)
+	}
+	if len(outdated) > 0 {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("worker instances %#q of the guest cluster are not yet replaced", outdated))
+		return current, nil
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", "the nodes of the guest cluster got replaced")
+
+	desired := current
+	desired.Phase = PhaseRetiring
+	desired.PhaseTime = v1alpha1.DeepCopyTime{Time: time.Now()}
+
+	return desired, nil
+}
+
+// retirePreviousKey schedules the deletion of the encryption key replaced by
+// the re-key once the grace period passed.
+func (r *Resource) retirePreviousKey(ctx context.Context, customObject v1alpha1.AWSConfig) (v1alpha1.AWSConfigStatusAWSEncryptionKey, error) {
+	current := customObject.Status.AWS.EncryptionKey
+
+	if time.Since(current.PhaseTime.Time) < r.gracePeriod {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("not deleting previous encryption key %#q before %s", current.PreviousID, current.PhaseTime.Add(r.gracePeriod).Format(time.RFC3339)))
+		return current, nil
+	}
+
+	err := r.encrypter.ScheduleEncryptionKeyDeletion(ctx, current.PreviousID)
+	if err != nil {
+		return v1alpha1.AWSConfigStatusAWSEncryptionKey{}, microerror.Mask(err)
+	}
+
+	desired := v1alpha1.AWSConfigStatusAWSEncryptionKey{
+		ID:        current.ID,
+		PhaseTime: v1alpha1.DeepCopyTime{Time: time.Now()},
+	}
+
+	return desired, nil
+}
+
+// outdatedWorkerInstances returns the IDs of the pending and running worker
+// instances of the guest cluster which are not tagged with the given
+// encryption key. Worker instances are launched by the worker ASGs, which
+// tag them with the encryption key of their launch template.
+func outdatedWorkerInstances(ec2Client ec2iface.EC2API, customObject v1alpha1.AWSConfig, keyID string) ([]string, error) {
+	i := &ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name: aws.String(fmt.Sprintf("tag:%s", key.ClusterTagName)),
+				Values: []*string{
+					aws.String(key.ClusterID(customObject)),
+				},
+			},
+			{
+				Name: aws.String("tag-key"),
+				Values: []*string{
+					aws.String("aws:autoscaling:groupName"),
+				},
+			},
+			{
+				Name: aws.String("instance-state-name"),
+				Values: []*string{
+					aws.String(ec2.InstanceStateNamePending),
+					aws.String(ec2.InstanceStateNameRunning),
+				},
+			},
+		},
+	}
+
+	var outdated []string
+	err := ec2Client.DescribeInstancesPages(i, func(o *ec2.DescribeInstancesOutput, lastPage bool) bool {
+		for _, r := range o.Reservations {
+			for _, instance := range r.Instances {
+				if instanceTag(instance, key.EncryptionKeyTagName) != keyID {
+					outdated = append(outdated, aws.StringValue(instance.InstanceId))
+				}
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return outdated, nil
+}
+
+func instanceTag(instance *ec2.Instance, name string) string {
+	for _, t := range instance.Tags {
+		if aws.StringValue(t.Key) == name {
+			return aws.StringValue(t.Value)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,248 @@
+package encryptionrekey
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	versionedfake "github.com/giantswarm/apiextensions/pkg/clientset/versioned/fake"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/giantswarm/operatorkit/controller/context/updateallowedcontext"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/aws-operator/service/controller/v21/encrypter"
+)
+
+type keyRotatorMock struct {
+	state encrypter.EncryptionKeyState
+
+	rotated bool
+	deleted []string
+}
+
+func (k *keyRotatorMock) CurrentState(context.Context, v1alpha1.AWSConfig) (encrypter.EncryptionKeyState, error) {
+	return k.state, nil
+}
+
+func (k *keyRotatorMock) RotateEncryptionKey(context.Context, v1alpha1.AWSConfig) (encrypter.EncryptionKeyState, error) {
+	k.rotated = true
+	k.state = encrypter.EncryptionKeyState{CreationTime: time.Now(), KeyID: "new-key"}
+
+	return k.state, nil
+}
+
+func (k *keyRotatorMock) ScheduleEncryptionKeyDeletion(ctx context.Context, keyID string) error {
+	k.deleted = append(k.deleted, keyID)
+
+	return nil
+}
+
+type ec2Mock struct {
+	ec2iface.EC2API
+
+	instances []*ec2.Instance
+}
+
+func (e *ec2Mock) DescribeInstancesPages(input *ec2.DescribeInstancesInput, fn func(*ec2.DescribeInstancesOutput, bool) bool) error {
+	// Every instance is returned on its own page.
+	for i, instance := range e.instances {
+		o := &ec2.DescribeInstancesOutput{
+			Reservations: []*ec2.Reservation{
+				{Instances: []*ec2.Instance{instance}},
+			},
+		}
+
+		if !fn(o, i == len(e.instances)-1) {
+			break
+		}
+	}
+
+	return nil
+}
+
+func newInstance(id, keyID string) *ec2.Instance {
+	instance := &ec2.Instance{
+		InstanceId: aws.String(id),
+	}
+	if keyID != "" {
+		instance.Tags = []*ec2.Tag{
+			{Key: aws.String("giantswarm.io/encryption-key"), Value: aws.String(keyID)},
+		}
+	}
+
+	return instance
+}
+
+func Test_Resource_EncryptionRekey_EnsureCreated(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+
+	testCases := []struct {
+		name            string
+		status          v1alpha1.AWSConfigStatusAWSEncryptionKey
+		keyCreationTime time.Time
+		updateAllowed   bool
+		expectedPhase   string
+		expectedID      string
+		expectedRotated bool
+		expectedDeleted []string
+	}{
+		{
+			name:            "case 0: encryption key is not yet due",
+			keyCreationTime: now.Add(-24 * time.Hour),
+			updateAllowed:   true,
+		},
+		{
+			name:            "case 1: encryption key is due but updates are not allowed",
+			keyCreationTime: now.Add(-400 * 24 * time.Hour),
+		},
+		{
+			name:            "case 2: encryption key is due",
+			keyCreationTime: now.Add(-400 * 24 * time.Hour),
+			updateAllowed:   true,
+			expectedPhase:   PhaseRolling,
+			expectedID:      "new-key",
+			expectedRotated: true,
+		},
+		{
+			name: "case 3: previous encryption key is within the grace period",
+			status: v1alpha1.AWSConfigStatusAWSEncryptionKey{
+				ID:         "new-key",
+				Phase:      PhaseRetiring,
+				PhaseTime:  v1alpha1.DeepCopyTime{Time: now.Add(-time.Hour)},
+				PreviousID: "old-key",
+			},
+			keyCreationTime: now.Add(-time.Hour),
+			expectedPhase:   PhaseRetiring,
+			expectedID:      "new-key",
+		},
+		{
+			name: "case 4: grace period of the previous encryption key passed",
+			status: v1alpha1.AWSConfigStatusAWSEncryptionKey{
+				ID:         "new-key",
+				Phase:      PhaseRetiring,
+				PhaseTime:  v1alpha1.DeepCopyTime{Time: now.Add(-48 * time.Hour)},
+				PreviousID: "old-key",
+			},
+			keyCreationTime: now.Add(-48 * time.Hour),
+			expectedID:      "new-key",
+			expectedDeleted: []string{"old-key"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			customObject := &v1alpha1.AWSConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-cluster",
+					Namespace: "default",
+				},
+				Status: v1alpha1.AWSConfigStatus{
+					AWS: v1alpha1.AWSConfigStatusAWS{
+						EncryptionKey: tc.status,
+					},
+					Cluster: v1alpha1.StatusCluster{
+						Conditions: []v1alpha1.StatusClusterCondition{
+							{Status: v1alpha1.StatusClusterStatusTrue, Type: v1alpha1.StatusClusterTypeCreated},
+						},
+					},
+				},
+			}
+
+			g8sClient := versionedfake.NewSimpleClientset(customObject)
+			keyRotator := &keyRotatorMock{
+				state: encrypter.EncryptionKeyState{CreationTime: tc.keyCreationTime, KeyID: "old-key"},
+			}
+
+			c := Config{
+				Encrypter: keyRotator,
+				G8sClient: g8sClient,
+				Logger:    microloggertest.New(),
+
+				GracePeriod: DefaultGracePeriod,
+				Interval:    365 * 24 * time.Hour,
+			}
+
+			r, err := New(c)
+			if err != nil {
+				t.Fatalf("expected nil got %#v", err)
+			}
+
+			ctx := updateallowedcontext.NewContext(context.Background(), make(chan struct{}))
+			if tc.updateAllowed {
+				updateallowedcontext.SetUpdateAllowed(ctx)
+			}
+
+			err = r.EnsureCreated(ctx, customObject)
+			if err != nil {
+				t.Fatalf("expected nil got %#v", err)
+			}
+
+			updated, err := g8sClient.ProviderV1alpha1().AWSConfigs("default").Get("test-cluster", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("expected nil got %#v", err)
+			}
+
+			status := updated.Status.AWS.EncryptionKey
+			if status.Phase != tc.expectedPhase {
+				t.Fatalf("expected phase %#q got %#q", tc.expectedPhase, status.Phase)
+			}
+			if status.ID != tc.expectedID {
+				t.Fatalf("expected ID %#q got %#q", tc.expectedID, status.ID)
+			}
+			if tc.expectedRotated && status.PreviousID != "old-key" {
+				t.Fatalf("expected previous ID %#q got %#q", "old-key", status.PreviousID)
+			}
+			if keyRotator.rotated != tc.expectedRotated {
+				t.Fatalf("expected rotated %t got %t", tc.expectedRotated, keyRotator.rotated)
+			}
+			if !reflect.DeepEqual(keyRotator.deleted, tc.expectedDeleted) {
+				t.Fatalf("expected deleted keys %#v got %#v", tc.expectedDeleted, keyRotator.deleted)
+			}
+		})
+	}
+}
+
+func Test_Resource_EncryptionRekey_outdatedWorkerInstances(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name             string
+		instances        []*ec2.Instance
+		expectedOutdated []string
+	}{
+		{
+			name: "case 0: all workers got replaced",
+			instances: []*ec2.Instance{
+				newInstance("i-1", "new-key"),
+				newInstance("i-2", "new-key"),
+			},
+			expectedOutdated: nil,
+		},
+		{
+			name: "case 1: workers with the previous key or without key are outdated",
+			instances: []*ec2.Instance{
+				newInstance("i-1", "new-key"),
+				newInstance("i-2", "old-key"),
+				newInstance("i-3", ""),
+			},
+			expectedOutdated: []string{"i-2", "i-3"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			outdated, err := outdatedWorkerInstances(&ec2Mock{instances: tc.instances}, v1alpha1.AWSConfig{}, "new-key")
+			if err != nil {
+				t.Fatalf("expected nil got %#v", err)
+			}
+			if !reflect.DeepEqual(outdated, tc.expectedOutdated) {
+				t.Fatalf("expected outdated instances %#v got %#v", tc.expectedOutdated, outdated)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package encryptionrekey
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/aws-operator/service/controller/v21/key"
+)
+
+// EnsureDeleted schedules the deletion of the encryption key replaced by a
+// re-key in progress. The current encryption key is deleted by the
+// encryptionkey resource.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	previousID := customObject.Status.AWS.EncryptionKey.PreviousID
+	if previousID == "" {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "no re-key in progress")
+		r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+		return nil
+	}
+
+	err = r.encrypter.ScheduleEncryptionKeyDeletion(ctx, previousID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package encryptionrekey
+
+import (
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/clientset/versioned"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/aws-operator/service/controller/v21/encrypter"
+)
+
+const (
+	Name = "encryptionrekeyv21"
+)
+
+const (
+	// DefaultGracePeriod is the grace period used in case the installation
+	// does not configure one.
+	DefaultGracePeriod = 24 * time.Hour
+)
+
+const (
+	// PhaseRolling is the phase of a re-key in which the nodes of the guest
+	// cluster are replaced after the assets got encrypted with the new key.
+	PhaseRolling = "Rolling"
+	// PhaseRetiring is the phase of a re-key in which the previous encryption
+	// key waits for its deletion.
+	PhaseRetiring = "Retiring"
+)
+
+type Config struct {
+	Encrypter encrypter.KeyRotator
+	G8sClient versioned.Interface
+	Logger    micrologger.Logger
+
+	// GracePeriod is the duration the previous encryption key is kept after
+	// all nodes got replaced, before its deletion is scheduled.
+	GracePeriod time.Duration
+	// Interval is the age of the encryption key of a guest cluster after which
+	// the guest cluster is re-keyed.
+	Interval time.Duration
+}
+
+// Resource re-keys guest clusters once their encryption key reaches the
+// configured age. A re-key creates a new encryption key, which the s3object
+// resource uses to encrypt the assets of the guest cluster from then on. The
+// cloudformation resource then replaces the masters and workers. Once all
+// nodes are replaced, the deletion of the previous encryption key is scheduled
+// after the grace period. The progress is tracked in the CR status.
+type Resource struct {
+	encrypter encrypter.KeyRotator
+	g8sClient versioned.Interface
+	logger    micrologger.Logger
+
+	gracePeriod time.Duration
+	interval    time.Duration
+}
+
+func New(config Config) (*Resource, error) {
+	if config.Encrypter == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Encrypter must not be empty", config)
+	}
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.GracePeriod < 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GracePeriod must not be negative", config)
+	}
+	if config.Interval <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Interval must be greater than 0", config)
+	}
+
+	r := &Resource{
+		encrypter: config.Encrypter,
+		g8sClient: config.G8sClient,
+		logger:    config.Logger,
+
+		gracePeriod: config.GracePeriod,
+		interval:    config.Interval,
+	}
+
+	return r, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
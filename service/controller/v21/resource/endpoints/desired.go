@@ -2,6 +2,7 @@ package endpoints
 
 import (
 	"context"
+	"sort"
 
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/operatorkit/controller/context/resourcecanceledcontext"
@@ -18,9 +19,9 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 	}
 
 	instanceName := key.MasterInstanceName(customObject)
-	masterInstance, err := r.findMasterInstance(ctx, instanceName)
+	masterInstances, err := r.findMasterInstances(ctx, instanceName)
 	if IsNotFound(err) {
-		// During updates the master instance is shut down and thus cannot be found.
+		// During updates the master instances are shut down and thus cannot be found.
 		// In such cases we cancel the reconciliation for the endpoint resource.
 		// This should be ok since all endpoints should be created and up to date
 		// already. In case we miss an update it will be done on the next resync
@@ -38,6 +39,16 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 		return nil, microerror.Mask(err)
 	}
 
+	// The addresses are sorted to not cause updates of the endpoints in case
+	// the AWS API returns the master instances in a different order.
+	var addresses []v1.EndpointAddress
+	for _, i := range masterInstances {
+		addresses = append(addresses, v1.EndpointAddress{IP: *i.PrivateIpAddress})
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		return addresses[i].IP < addresses[j].IP
+	})
+
 	endpoints := &v1.Endpoints{
 		ObjectMeta: apismetav1.ObjectMeta{
 			Name:      masterEndpointsName,
@@ -50,11 +61,7 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 		},
 		Subsets: []v1.EndpointSubset{
 			{
-				Addresses: []v1.EndpointAddress{
-					{
-						IP: *masterInstance.PrivateIpAddress,
-					},
-				},
+				Addresses: addresses,
 				Ports: []v1.EndpointPort{
 					{
 						Port: httpsPort,
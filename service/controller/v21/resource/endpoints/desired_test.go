@@ -2,6 +2,7 @@ package endpoints
 
 import (
 	"context"
+	"reflect"
 	"testing"
 
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
@@ -16,12 +17,13 @@ import (
 func Test_Resource_Endpoints_GetDesiredState(t *testing.T) {
 	t.Parallel()
 	testCases := []struct {
-		description       string
-		obj               interface{}
-		expectedNamespace string
-		expectedName      string
-		expectedIPAddress string
-		expectedPort      int
+		description         string
+		obj                 interface{}
+		privateIPAddresses  []string
+		expectedNamespace   string
+		expectedName        string
+		expectedIPAddresses []string
+		expectedPort        int
 	}{
 		{
 			description: "basic match",
@@ -32,10 +34,26 @@ func Test_Resource_Endpoints_GetDesiredState(t *testing.T) {
 					},
 				},
 			},
-			expectedNamespace: "al9qy",
-			expectedName:      "master",
-			expectedIPAddress: "10.1.1.1",
-			expectedPort:      443,
+			privateIPAddresses:  []string{"10.1.1.1"},
+			expectedNamespace:   "al9qy",
+			expectedName:        "master",
+			expectedIPAddresses: []string{"10.1.1.1"},
+			expectedPort:        443,
+		},
+		{
+			description: "highly available control plane",
+			obj: &v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					Cluster: v1alpha1.Cluster{
+						ID: "al9qy",
+					},
+				},
+			},
+			privateIPAddresses:  []string{"10.1.2.1", "10.1.1.1", "10.1.3.1"},
+			expectedNamespace:   "al9qy",
+			expectedName:        "master",
+			expectedIPAddresses: []string{"10.1.1.1", "10.1.2.1", "10.1.3.1"},
+			expectedPort:        443,
 		},
 	}
 
@@ -55,7 +73,7 @@ func Test_Resource_Endpoints_GetDesiredState(t *testing.T) {
 
 			awsClients := aws.Clients{
 				EC2: EC2ClientMock{
-					privateIPAddress: tc.expectedIPAddress,
+					privateIPAddresses: tc.privateIPAddresses,
 				},
 			}
 
@@ -83,8 +101,12 @@ func Test_Resource_Endpoints_GetDesiredState(t *testing.T) {
 				t.Fatalf("expected port %q got %q", int32(tc.expectedPort), desiredEndpoints.Subsets[0].Ports[0].Port)
 			}
 
-			if tc.expectedIPAddress != desiredEndpoints.Subsets[0].Addresses[0].IP {
-				t.Fatalf("expected ip address %s got %s", tc.expectedIPAddress, desiredEndpoints.Subsets[0].Addresses[0].IP)
+			var ipAddresses []string
+			for _, a := range desiredEndpoints.Subsets[0].Addresses {
+				ipAddresses = append(ipAddresses, a.IP)
+			}
+			if !reflect.DeepEqual(tc.expectedIPAddresses, ipAddresses) {
+				t.Fatalf("expected ip addresses %v got %v", tc.expectedIPAddresses, ipAddresses)
 			}
 		})
 	}
@@ -9,6 +9,7 @@ import (
 	"github.com/giantswarm/microerror"
 
 	"github.com/giantswarm/aws-operator/service/controller/v21/controllercontext"
+	"github.com/giantswarm/aws-operator/service/controller/v21/key"
 )
 
 const (
@@ -17,7 +18,10 @@ const (
 	tagKeyName      = "Name"
 )
 
-func (r Resource) findMasterInstance(ctx context.Context, instanceName string) (*ec2.Instance, error) {
+// findMasterInstances returns all running master instances of the guest
+// cluster. Guest clusters with a highly available control plane run multiple
+// masters sharing the same instance name.
+func (r Resource) findMasterInstances(ctx context.Context, instanceName string) ([]*ec2.Instance, error) {
 
 	sc, err := controllercontext.FromContext(ctx)
 	if err != nil {
@@ -40,23 +44,21 @@ func (r Resource) findMasterInstance(ctx context.Context, instanceName string) (
 		return nil, microerror.Mask(err)
 	}
 
-	var masterInstance *ec2.Instance
-	var instancesFound int
+	var masterInstances []*ec2.Instance
 	for _, reservation := range output.Reservations {
 		for _, instance := range reservation.Instances {
 			if *instance.State.Code == ec2RunningState {
-				masterInstance = instance
-				instancesFound++
+				masterInstances = append(masterInstances, instance)
 			}
 		}
 	}
 
-	if instancesFound < 1 {
+	if len(masterInstances) < 1 {
 		return nil, microerror.Maskf(notFoundError, "instance: %s", instanceName)
 	}
-	if instancesFound > 1 {
+	if len(masterInstances) > key.MasterCountHA {
 		return nil, microerror.Maskf(tooManyResultsError, "instances: %s", instanceName)
 	}
 
-	return masterInstance, nil
+	return masterInstances, nil
 }
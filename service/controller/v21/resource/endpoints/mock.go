@@ -11,8 +11,8 @@ import (
 type EC2ClientMock struct {
 	ec2iface.EC2API
 
-	isError          bool
-	privateIPAddress string
+	isError            bool
+	privateIPAddresses []string
 }
 
 func (e EC2ClientMock) DescribeInstances(*ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
@@ -20,19 +20,19 @@ func (e EC2ClientMock) DescribeInstances(*ec2.DescribeInstancesInput) (*ec2.Desc
 		return nil, fmt.Errorf("error!!")
 	}
 
-	output := &ec2.DescribeInstancesOutput{
-		Reservations: []*ec2.Reservation{
-			{
-				Instances: []*ec2.Instance{
-					{
-						PrivateIpAddress: aws.String(e.privateIPAddress),
-						State: &ec2.InstanceState{
-							Code: aws.Int64(ec2RunningState),
-						},
+	output := &ec2.DescribeInstancesOutput{}
+	for _, ip := range e.privateIPAddresses {
+		r := &ec2.Reservation{
+			Instances: []*ec2.Instance{
+				{
+					PrivateIpAddress: aws.String(ip),
+					State: &ec2.InstanceState{
+						Code: aws.Int64(ec2RunningState),
 					},
 				},
 			},
-		},
+		}
+		output.Reservations = append(output.Reservations, r)
 	}
 
 	return output, nil
@@ -0,0 +1,173 @@
+package etcdbackup
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/aws-operator/service/controller/v21/controllercontext"
+	"github.com/giantswarm/aws-operator/service/controller/v21/key"
+)
+
+// deleteObjectsLimit is the maximum number of objects S3 deletes within a
+// single request.
+const deleteObjectsLimit = 1000
+
+// EnsureCreated records the latest etcd snapshot of the guest cluster in the
+// CR status and deletes snapshots exceeding the retention.
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	controllerCtx, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	accountID, err := controllerCtx.AWSService.GetAccountID()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	bucketName := key.BucketName(customObject, accountID)
+
+	var snapshots []*s3.Object
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "finding etcd snapshots")
+
+		i := &s3.ListObjectsV2Input{
+			Bucket: aws.String(bucketName),
+			Prefix: aws.String(key.EtcdBackupPrefix),
+		}
+
+		err = controllerCtx.AWSClient.S3.ListObjectsV2Pages(i, func(o *s3.ListObjectsV2Output, lastPage bool) bool {
+			snapshots = append(snapshots, o.Contents...)
+			return true
+		})
+		if IsBucketNotFound(err) {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "did not find etcd snapshots")
+			r.logger.LogCtx(ctx, "level", "debug", "message", "the guest cluster S3 bucket is not yet created")
+			r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+			return nil
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		if len(snapshots) == 0 {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "did not find etcd snapshots")
+			r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+			return nil
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found %d etcd snapshots", len(snapshots)))
+	}
+
+	sortSnapshots(snapshots)
+
+	{
+		latest := snapshots[len(snapshots)-1]
+
+		if customObject.Status.AWS.EtcdBackup.LastSnapshot != *latest.Key {
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("updating CR status with latest etcd snapshot %#q", *latest.Key))
+
+			newObj, err := r.g8sClient.ProviderV1alpha1().AWSConfigs(customObject.GetNamespace()).Get(customObject.GetName(), metav1.GetOptions{})
+			if err != nil {
+				return microerror.Mask(err)
+			}
+
+			newObj.Status.AWS.EtcdBackup = v1alpha1.AWSConfigStatusAWSEtcdBackup{
+				LastSnapshot: *latest.Key,
+				LastSnapshotTime: v1alpha1.DeepCopyTime{
+					Time: aws.TimeValue(latest.LastModified),
+				},
+			}
+
+			_, err = r.g8sClient.ProviderV1alpha1().AWSConfigs(newObj.GetNamespace()).UpdateStatus(newObj)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+
+			r.logger.LogCtx(ctx, "level", "debug", "message", "updated CR status")
+		} else {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "CR status already contains latest etcd snapshot")
+		}
+	}
+
+	{
+		expired := expiredSnapshots(snapshots, r.retention)
+
+		if len(expired) > 0 {
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("deleting %d expired etcd snapshots", len(expired)))
+
+			for len(expired) > 0 {
+				n := len(expired)
+				if n > deleteObjectsLimit {
+					n = deleteObjectsLimit
+				}
+
+				var objects []*s3.ObjectIdentifier
+				for _, s := range expired[:n] {
+					objects = append(objects, &s3.ObjectIdentifier{Key: s.Key})
+				}
+
+				i := &s3.DeleteObjectsInput{
+					Bucket: aws.String(bucketName),
+					Delete: &s3.Delete{
+						Objects: objects,
+						Quiet:   aws.Bool(true),
+					},
+				}
+
+				o, err := controllerCtx.AWSClient.S3.DeleteObjects(i)
+				if err != nil {
+					return microerror.Mask(err)
+				}
+				for _, e := range o.Errors {
+					r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("failed deleting expired etcd snapshot %#q: %s", aws.StringValue(e.Key), aws.StringValue(e.Message)))
+				}
+
+				expired = expired[n:]
+			}
+
+			r.logger.LogCtx(ctx, "level", "debug", "message", "deleted expired etcd snapshots")
+		} else {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "did not find expired etcd snapshots")
+		}
+	}
+
+	return nil
+}
+
+// expiredSnapshots returns the snapshots exceeding the given retention. The
+// given snapshots must be sorted from the oldest to the latest, see
+// sortSnapshots.
+func expiredSnapshots(snapshots []*s3.Object, retention int) []*s3.Object {
+	if len(snapshots) <= retention {
+		return nil
+	}
+
+	return snapshots[:len(snapshots)-retention]
+}
+
+// sortSnapshots sorts the given snapshots from the oldest to the latest.
+// Snapshot names start with the time they were taken, so they are used to
+// order snapshots uploaded within the same second.
+func sortSnapshots(snapshots []*s3.Object) {
+	sort.Slice(snapshots, func(i, j int) bool {
+		ti := aws.TimeValue(snapshots[i].LastModified)
+		tj := aws.TimeValue(snapshots[j].LastModified)
+
+		if ti.Equal(tj) {
+			return aws.StringValue(snapshots[i].Key) < aws.StringValue(snapshots[j].Key)
+		}
+
+		return ti.Before(tj)
+	})
+}
@@ -0,0 +1,78 @@
+package etcdbackup
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func Test_expiredSnapshots(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		description  string
+		snapshots    []*s3.Object
+		retention    int
+		expectedKeys []string
+	}{
+		{
+			description:  "no snapshots",
+			snapshots:    nil,
+			retention:    2,
+			expectedKeys: nil,
+		},
+		{
+			description: "snapshots within retention",
+			snapshots: []*s3.Object{
+				{Key: aws.String("etcd-backup/20181001110000-master.db.enc"), LastModified: aws.Time(now.Add(-time.Hour))},
+				{Key: aws.String("etcd-backup/20181001120000-master.db.enc"), LastModified: aws.Time(now)},
+			},
+			retention:    2,
+			expectedKeys: nil,
+		},
+		{
+			description: "unsorted snapshots exceeding retention",
+			snapshots: []*s3.Object{
+				{Key: aws.String("etcd-backup/20181001120000-master.db.enc"), LastModified: aws.Time(now)},
+				{Key: aws.String("etcd-backup/20181001090000-master.db.enc"), LastModified: aws.Time(now.Add(-3 * time.Hour))},
+				{Key: aws.String("etcd-backup/20181001110000-master.db.enc"), LastModified: aws.Time(now.Add(-time.Hour))},
+				{Key: aws.String("etcd-backup/20181001100000-master.db.enc"), LastModified: aws.Time(now.Add(-2 * time.Hour))},
+			},
+			retention: 2,
+			expectedKeys: []string{
+				"etcd-backup/20181001090000-master.db.enc",
+				"etcd-backup/20181001100000-master.db.enc",
+			},
+		},
+		{
+			description: "snapshots uploaded within the same second",
+			snapshots: []*s3.Object{
+				{Key: aws.String("etcd-backup/20181001120000-master-b.db.enc"), LastModified: aws.Time(now)},
+				{Key: aws.String("etcd-backup/20181001120000-master-a.db.enc"), LastModified: aws.Time(now)},
+			},
+			retention: 1,
+			expectedKeys: []string{
+				"etcd-backup/20181001120000-master-a.db.enc",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			sortSnapshots(tc.snapshots)
+
+			var keys []string
+			for _, s := range expiredSnapshots(tc.snapshots, tc.retention) {
+				keys = append(keys, *s.Key)
+			}
+
+			if !reflect.DeepEqual(keys, tc.expectedKeys) {
+				t.Fatalf("expected %#v got %#v", tc.expectedKeys, keys)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package etcdbackup
+
+import (
+	"context"
+)
+
+// EnsureDeleted is a no-op. The etcd snapshots are deleted together with the S3
+// bucket of the cluster by the s3bucket resource.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	return nil
+}
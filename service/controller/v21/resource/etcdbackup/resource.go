@@ -0,0 +1,56 @@
+package etcdbackup
+
+import (
+	"github.com/giantswarm/apiextensions/pkg/clientset/versioned"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+)
+
+const (
+	Name = "etcdbackupv21"
+)
+
+type Config struct {
+	G8sClient versioned.Interface
+	Logger    micrologger.Logger
+
+	// Retention is the number of etcd snapshots kept in the S3 bucket of a
+	// cluster. Older snapshots are deleted.
+	Retention int
+}
+
+// Resource tracks the etcd snapshots masters upload to the S3 bucket of their
+// cluster. It records the latest snapshot in the status of the custom object
+// and deletes snapshots exceeding the configured retention.
+type Resource struct {
+	g8sClient versioned.Interface
+	logger    micrologger.Logger
+
+	retention int
+}
+
+func New(config Config) (*Resource, error) {
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.Retention < 1 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Retention must not be lower than 1", config)
+	}
+
+	r := &Resource{
+		g8sClient: config.G8sClient,
+		logger:    config.Logger,
+
+		retention: config.Retention,
+	}
+
+	return r, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
@@ -1,6 +1,10 @@
 package hostedzone
 
-import "github.com/giantswarm/microerror"
+import (
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/giantswarm/microerror"
+)
 
 var invalidConfigError = &microerror.Error{
 	Kind: "invalidConfigError",
@@ -19,3 +23,17 @@ var hostedZoneNotFoundError = &microerror.Error{
 func IsHostedZoneNotFound(err error) bool {
 	return microerror.Cause(err) == hostedZoneNotFoundError
 }
+
+// IsVPCAssociationNotFound asserts the VPC association not found error from
+// upstream's API code.
+func IsVPCAssociationNotFound(err error) bool {
+	aerr, ok := microerror.Cause(err).(awserr.Error)
+	if !ok {
+		return false
+	}
+	if aerr.Code() == route53.ErrCodeVPCAssociationNotFound {
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,58 @@
+package hostedzone
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+type route53ClientMock struct {
+	hostedZones []*route53.HostedZone
+	vpcs        []*route53.VPC
+	// vpcAssociated makes DisassociateVPCFromHostedZone succeed. Otherwise the
+	// VPC association not found error of the AWS API is returned.
+	vpcAssociated bool
+
+	associated              bool
+	authorizationCreated    bool
+	authorizationDeleted    bool
+	disassociated           bool
+	listHostedZonesByNameIn *route53.ListHostedZonesByNameInput
+}
+
+func (m *route53ClientMock) AssociateVPCWithHostedZone(*route53.AssociateVPCWithHostedZoneInput) (*route53.AssociateVPCWithHostedZoneOutput, error) {
+	m.associated = true
+	return &route53.AssociateVPCWithHostedZoneOutput{}, nil
+}
+
+func (m *route53ClientMock) CreateVPCAssociationAuthorization(*route53.CreateVPCAssociationAuthorizationInput) (*route53.CreateVPCAssociationAuthorizationOutput, error) {
+	m.authorizationCreated = true
+	return &route53.CreateVPCAssociationAuthorizationOutput{}, nil
+}
+
+func (m *route53ClientMock) DeleteVPCAssociationAuthorization(*route53.DeleteVPCAssociationAuthorizationInput) (*route53.DeleteVPCAssociationAuthorizationOutput, error) {
+	m.authorizationDeleted = true
+	return &route53.DeleteVPCAssociationAuthorizationOutput{}, nil
+}
+
+func (m *route53ClientMock) DisassociateVPCFromHostedZone(*route53.DisassociateVPCFromHostedZoneInput) (*route53.DisassociateVPCFromHostedZoneOutput, error) {
+	if !m.vpcAssociated {
+		return nil, awserr.New(route53.ErrCodeVPCAssociationNotFound, "vpc association not found", nil)
+	}
+
+	m.disassociated = true
+	return &route53.DisassociateVPCFromHostedZoneOutput{}, nil
+}
+
+func (m *route53ClientMock) GetHostedZone(*route53.GetHostedZoneInput) (*route53.GetHostedZoneOutput, error) {
+	return &route53.GetHostedZoneOutput{VPCs: m.vpcs}, nil
+}
+
+func (m *route53ClientMock) ListHostedZones(*route53.ListHostedZonesInput) (*route53.ListHostedZonesOutput, error) {
+	return &route53.ListHostedZonesOutput{HostedZones: m.hostedZones, IsTruncated: aws.Bool(false)}, nil
+}
+
+func (m *route53ClientMock) ListHostedZonesByName(i *route53.ListHostedZonesByNameInput) (*route53.ListHostedZonesByNameOutput, error) {
+	m.listHostedZonesByNameIn = i
+	return &route53.ListHostedZonesByNameOutput{HostedZones: m.hostedZones}, nil
+}
@@ -0,0 +1,183 @@
+package hostedzone
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/aws-operator/service/controller/v21/key"
+)
+
+// ensureHostVPCAssociation associates the host cluster VPC with the private
+// API hosted zone of the tenant cluster so the host cluster is able to resolve
+// the private Kubernetes API. The zone is owned by the tenant cluster account
+// and the VPC by the host cluster account. The association therefore has to be
+// authorized by the tenant cluster account first. The returned boolean is
+// false in case the private API hosted zone is not yet created.
+func (r *Resource) ensureHostVPCAssociation(ctx context.Context, guestRoute53 Route53Client, customObject v1alpha1.AWSConfig) (bool, error) {
+	hostVPC := &route53.VPC{
+		VPCId:     aws.String(key.PeerID(customObject)),
+		VPCRegion: aws.String(key.Region(customObject)),
+	}
+
+	var err error
+	var zoneID string
+	var zoneVPCs []*route53.VPC
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "finding private API hosted zone")
+
+		zoneID, zoneVPCs, err = findPrivateHostedZone(guestRoute53, key.PrivateAPIHostedZoneName(customObject))
+		if IsHostedZoneNotFound(err) {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "did not find private API hosted zone")
+			r.logger.LogCtx(ctx, "level", "debug", "message", "the tenant cluster main stack is not yet created")
+			return false, nil
+		} else if err != nil {
+			return false, microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found private API hosted zone %#q", zoneID))
+	}
+
+	for _, v := range zoneVPCs {
+		if aws.StringValue(v.VPCId) == aws.StringValue(hostVPC.VPCId) {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "host cluster VPC already associated with private API hosted zone")
+			return true, nil
+		}
+	}
+
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "associating host cluster VPC with private API hosted zone")
+
+		authorization := &route53.CreateVPCAssociationAuthorizationInput{
+			HostedZoneId: aws.String(zoneID),
+			VPC:          hostVPC,
+		}
+		_, err := guestRoute53.CreateVPCAssociationAuthorization(authorization)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+
+		association := &route53.AssociateVPCWithHostedZoneInput{
+			HostedZoneId: aws.String(zoneID),
+			VPC:          hostVPC,
+		}
+		_, err = r.hostRoute53.AssociateVPCWithHostedZone(association)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+
+		// The authorization is not needed anymore once the VPC is associated.
+		deletion := &route53.DeleteVPCAssociationAuthorizationInput{
+			HostedZoneId: aws.String(zoneID),
+			VPC:          hostVPC,
+		}
+		_, err = guestRoute53.DeleteVPCAssociationAuthorization(deletion)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "associated host cluster VPC with private API hosted zone")
+	}
+
+	return true, nil
+}
+
+// ensureHostVPCDisassociation disassociates the host cluster VPC from the
+// private API hosted zone of the tenant cluster so the zone can be deleted
+// together with the tenant cluster main stack.
+func (r *Resource) ensureHostVPCDisassociation(ctx context.Context, guestRoute53 Route53Client, customObject v1alpha1.AWSConfig) error {
+	r.logger.LogCtx(ctx, "level", "debug", "message", "disassociating host cluster VPC from private API hosted zone")
+
+	zoneID, _, err := findPrivateHostedZone(guestRoute53, key.PrivateAPIHostedZoneName(customObject))
+	if IsHostedZoneNotFound(err) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "did not find private API hosted zone")
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	i := &route53.DisassociateVPCFromHostedZoneInput{
+		HostedZoneId: aws.String(zoneID),
+		VPC: &route53.VPC{
+			VPCId:     aws.String(key.PeerID(customObject)),
+			VPCRegion: aws.String(key.Region(customObject)),
+		},
+	}
+	_, err = r.hostRoute53.DisassociateVPCFromHostedZone(i)
+	if IsVPCAssociationNotFound(err) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "host cluster VPC already disassociated from private API hosted zone")
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", "disassociated host cluster VPC from private API hosted zone")
+
+	return nil
+}
+
+// ensureAPIEndpointStatus records the mode of the Kubernetes API endpoint in
+// the CR status so users can tell whether the API is private. It is only
+// called for private clusters, so public clusters keep their status untouched.
+func (r *Resource) ensureAPIEndpointStatus(ctx context.Context, customObject v1alpha1.AWSConfig) error {
+	mode := key.APIEndpointMode(customObject)
+
+	if customObject.Status.AWS.APIEndpoint.Mode == mode {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "CR status already contains API endpoint mode")
+		return nil
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("updating CR status with API endpoint mode %#q", mode))
+
+	newObj, err := r.g8sClient.ProviderV1alpha1().AWSConfigs(customObject.GetNamespace()).Get(customObject.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	newObj.Status.AWS.APIEndpoint.Mode = mode
+
+	_, err = r.g8sClient.ProviderV1alpha1().AWSConfigs(newObj.GetNamespace()).UpdateStatus(newObj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", "updated CR status")
+
+	return nil
+}
+
+// findPrivateHostedZone returns the ID and the associated VPCs of the private
+// hosted zone with the given name.
+func findPrivateHostedZone(client Route53Client, name string) (string, []*route53.VPC, error) {
+	i := &route53.ListHostedZonesByNameInput{
+		DNSName: aws.String(name),
+	}
+	o, err := client.ListHostedZonesByName(i)
+	if err != nil {
+		return "", nil, microerror.Mask(err)
+	}
+
+	for _, hz := range o.HostedZones {
+		if hz.Config == nil || !aws.BoolValue(hz.Config.PrivateZone) {
+			continue
+		}
+		if strings.TrimSuffix(aws.StringValue(hz.Name), ".") != name {
+			continue
+		}
+
+		o, err := client.GetHostedZone(&route53.GetHostedZoneInput{Id: hz.Id})
+		if err != nil {
+			return "", nil, microerror.Mask(err)
+		}
+
+		return aws.StringValue(hz.Id), o.VPCs, nil
+	}
+
+	return "", nil, microerror.Maskf(hostedZoneNotFoundError, "zone = %q", name)
+}
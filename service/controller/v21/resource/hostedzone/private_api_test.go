@@ -0,0 +1,259 @@
+package hostedzone
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	versionedfake "github.com/giantswarm/apiextensions/pkg/clientset/versioned/fake"
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/aws-operator/service/controller/v21/key"
+)
+
+const (
+	testPrivateZoneName = "api.test-cluster.k8s.installation.eu-central-1.aws.gigantic.io"
+)
+
+func testCustomObject(mode string) v1alpha1.AWSConfig {
+	return v1alpha1.AWSConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.AWSConfigSpec{
+			AWS: v1alpha1.AWSConfigSpecAWS{
+				APIEndpoint: v1alpha1.AWSConfigSpecAWSAPIEndpoint{
+					Mode: mode,
+				},
+				HostedZones: v1alpha1.AWSConfigSpecAWSHostedZones{
+					API: v1alpha1.AWSConfigSpecAWSHostedZonesZone{
+						Name: "installation.eu-central-1.aws.gigantic.io",
+					},
+				},
+				Region: "eu-central-1",
+				VPC: v1alpha1.AWSConfigSpecAWSVPC{
+					PeerID: "vpc-host",
+				},
+			},
+			Cluster: v1alpha1.Cluster{
+				ID: "test-cluster",
+			},
+		},
+	}
+}
+
+func testResource(t *testing.T, customObject v1alpha1.AWSConfig, hostRoute53 Route53Client) *Resource {
+	c := Config{
+		G8sClient:   versionedfake.NewSimpleClientset(&customObject),
+		HostRoute53: hostRoute53,
+		Logger:      microloggertest.New(),
+	}
+
+	r, err := New(c)
+	if err != nil {
+		t.Fatalf("expected %#v got %#v", nil, err)
+	}
+
+	return r
+}
+
+func testPrivateZone(name string) *route53.HostedZone {
+	return &route53.HostedZone{
+		Config: &route53.HostedZoneConfig{
+			PrivateZone: aws.Bool(true),
+		},
+		Id:   aws.String("/hostedzone/Z123"),
+		Name: aws.String(name + "."),
+	}
+}
+
+func Test_Resource_ensureHostVPCAssociation(t *testing.T) {
+	testCases := []struct {
+		name               string
+		guestRoute53       *route53ClientMock
+		expectedAssociated bool
+		expectedCalls      bool
+	}{
+		{
+			name:               "case 0: private API hosted zone not yet created",
+			guestRoute53:       &route53ClientMock{},
+			expectedAssociated: false,
+			expectedCalls:      false,
+		},
+		{
+			name: "case 1: public hosted zone with the same name is ignored",
+			guestRoute53: &route53ClientMock{
+				hostedZones: []*route53.HostedZone{
+					{
+						Config: &route53.HostedZoneConfig{
+							PrivateZone: aws.Bool(false),
+						},
+						Id:   aws.String("/hostedzone/Z456"),
+						Name: aws.String(testPrivateZoneName + "."),
+					},
+				},
+			},
+			expectedAssociated: false,
+			expectedCalls:      false,
+		},
+		{
+			name: "case 2: host cluster VPC already associated",
+			guestRoute53: &route53ClientMock{
+				hostedZones: []*route53.HostedZone{
+					testPrivateZone(testPrivateZoneName),
+				},
+				vpcs: []*route53.VPC{
+					{VPCId: aws.String("vpc-guest")},
+					{VPCId: aws.String("vpc-host")},
+				},
+			},
+			expectedAssociated: true,
+			expectedCalls:      false,
+		},
+		{
+			name: "case 3: host cluster VPC gets associated",
+			guestRoute53: &route53ClientMock{
+				hostedZones: []*route53.HostedZone{
+					testPrivateZone(testPrivateZoneName),
+				},
+				vpcs: []*route53.VPC{
+					{VPCId: aws.String("vpc-guest")},
+				},
+			},
+			expectedAssociated: true,
+			expectedCalls:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			customObject := testCustomObject(key.APIEndpointModePrivate)
+			hostRoute53 := &route53ClientMock{}
+			r := testResource(t, customObject, hostRoute53)
+
+			associated, err := r.ensureHostVPCAssociation(context.Background(), tc.guestRoute53, customObject)
+			if err != nil {
+				t.Fatalf("expected %#v got %#v", nil, err)
+			}
+
+			if associated != tc.expectedAssociated {
+				t.Fatalf("expected associated %t got %t", tc.expectedAssociated, associated)
+			}
+			if aws.StringValue(tc.guestRoute53.listHostedZonesByNameIn.DNSName) != testPrivateZoneName {
+				t.Fatalf("expected hosted zone lookup of %#q got %#q", testPrivateZoneName, aws.StringValue(tc.guestRoute53.listHostedZonesByNameIn.DNSName))
+			}
+			// The association is authorized and cleaned up by the tenant cluster
+			// account, while the host cluster account associates its VPC.
+			if tc.guestRoute53.authorizationCreated != tc.expectedCalls {
+				t.Fatalf("expected authorization created %t got %t", tc.expectedCalls, tc.guestRoute53.authorizationCreated)
+			}
+			if hostRoute53.associated != tc.expectedCalls {
+				t.Fatalf("expected host VPC associated %t got %t", tc.expectedCalls, hostRoute53.associated)
+			}
+			if tc.guestRoute53.authorizationDeleted != tc.expectedCalls {
+				t.Fatalf("expected authorization deleted %t got %t", tc.expectedCalls, tc.guestRoute53.authorizationDeleted)
+			}
+		})
+	}
+}
+
+func Test_Resource_ensureHostVPCDisassociation(t *testing.T) {
+	testCases := []struct {
+		name                  string
+		guestRoute53          *route53ClientMock
+		hostRoute53           *route53ClientMock
+		expectedDisassociated bool
+	}{
+		{
+			name:                  "case 0: private API hosted zone already deleted",
+			guestRoute53:          &route53ClientMock{},
+			hostRoute53:           &route53ClientMock{vpcAssociated: true},
+			expectedDisassociated: false,
+		},
+		{
+			name: "case 1: host cluster VPC already disassociated",
+			guestRoute53: &route53ClientMock{
+				hostedZones: []*route53.HostedZone{
+					testPrivateZone(testPrivateZoneName),
+				},
+			},
+			hostRoute53:           &route53ClientMock{},
+			expectedDisassociated: false,
+		},
+		{
+			name: "case 2: host cluster VPC gets disassociated",
+			guestRoute53: &route53ClientMock{
+				hostedZones: []*route53.HostedZone{
+					testPrivateZone(testPrivateZoneName),
+				},
+			},
+			hostRoute53:           &route53ClientMock{vpcAssociated: true},
+			expectedDisassociated: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			customObject := testCustomObject(key.APIEndpointModePrivate)
+			r := testResource(t, customObject, tc.hostRoute53)
+
+			err := r.ensureHostVPCDisassociation(context.Background(), tc.guestRoute53, customObject)
+			if err != nil {
+				t.Fatalf("expected %#v got %#v", nil, err)
+			}
+
+			if tc.hostRoute53.disassociated != tc.expectedDisassociated {
+				t.Fatalf("expected disassociated %t got %t", tc.expectedDisassociated, tc.hostRoute53.disassociated)
+			}
+		})
+	}
+}
+
+func Test_Resource_EnsureCreated_APIEndpointStatus(t *testing.T) {
+	testCases := []struct {
+		name         string
+		mode         string
+		expectedMode string
+	}{
+		{
+			name:         "case 0: default mode does not touch the status",
+			mode:         "",
+			expectedMode: "",
+		},
+		{
+			name:         "case 1: public mode does not touch the status",
+			mode:         key.APIEndpointModePublic,
+			expectedMode: "",
+		},
+		{
+			name:         "case 2: private mode is recorded in the status",
+			mode:         key.APIEndpointModePrivate,
+			expectedMode: key.APIEndpointModePrivate,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			customObject := testCustomObject(tc.mode)
+			r := testResource(t, customObject, &route53ClientMock{})
+
+			err := r.EnsureCreated(context.Background(), &customObject)
+			if err != nil {
+				t.Fatalf("expected %#v got %#v", nil, err)
+			}
+
+			obj, err := r.g8sClient.ProviderV1alpha1().AWSConfigs(customObject.GetNamespace()).Get(customObject.GetName(), metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("expected %#v got %#v", nil, err)
+			}
+
+			if obj.Status.AWS.APIEndpoint.Mode != tc.expectedMode {
+				t.Fatalf("expected status API endpoint mode %#q got %#q", tc.expectedMode, obj.Status.AWS.APIEndpoint.Mode)
+			}
+		})
+	}
+}
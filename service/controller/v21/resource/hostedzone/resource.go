@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/giantswarm/apiextensions/pkg/clientset/versioned"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 
@@ -18,20 +20,29 @@ const (
 )
 
 type Config struct {
-	HostRoute53 *route53.Route53
+	G8sClient   versioned.Interface
+	HostRoute53 Route53Client
 	Logger      micrologger.Logger
 
 	Route53Enabled bool
 }
 
+// Resource finds the host cluster hosted zones the tenant cluster record sets
+// are delegated from. In case the Kubernetes API of the tenant cluster is
+// private it also associates the host cluster VPC with the private API hosted
+// zone and records the API endpoint mode in the CR status.
 type Resource struct {
-	hostRoute53 *route53.Route53
+	g8sClient   versioned.Interface
+	hostRoute53 Route53Client
 	logger      micrologger.Logger
 
 	route53Enabled bool
 }
 
 func New(config Config) (*Resource, error) {
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	}
 	if config.HostRoute53 == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.HostRoute53 must not be empty", config)
 	}
@@ -40,6 +51,7 @@ func New(config Config) (*Resource, error) {
 	}
 
 	r := &Resource{
+		g8sClient:   config.G8sClient,
 		hostRoute53: config.HostRoute53,
 		logger:      config.Logger,
 
@@ -59,6 +71,39 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 		return microerror.Mask(err)
 	}
 
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if !key.PrivateAPIEndpoint(customObject) {
+		return nil
+	}
+
+	// The private API endpoint mode is only recorded in the CR status once the
+	// host cluster is able to resolve the private Kubernetes API.
+	if r.route53Enabled {
+		controllerCtx, err := controllercontext.FromContext(ctx)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		associated, err := r.ensureHostVPCAssociation(ctx, controllerCtx.AWSClient.Route53, customObject)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		if !associated {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+			return nil
+		}
+	}
+
+	err = r.ensureAPIEndpointStatus(ctx, customObject)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
 	return nil
 }
 
@@ -68,6 +113,23 @@ func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
 		return microerror.Mask(err)
 	}
 
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if r.route53Enabled && key.PrivateAPIEndpoint(customObject) {
+		controllerCtx, err := controllercontext.FromContext(ctx)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		err = r.ensureHostVPCDisassociation(ctx, controllerCtx.AWSClient.Route53, customObject)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
 	return nil
 }
 
@@ -116,6 +178,11 @@ func (r *Resource) setStatus(ctx context.Context, obj interface{}) error {
 			if hz.Name == nil || hz.Id == nil {
 				continue
 			}
+			// Private hosted zones of the host cluster are not used for
+			// delegating tenant cluster record sets.
+			if hz.Config != nil && aws.BoolValue(hz.Config.PrivateZone) {
+				continue
+			}
 
 			hzName := *hz.Name
 			hzName = strings.TrimSuffix(hzName, ".")
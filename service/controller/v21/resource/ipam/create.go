@@ -84,6 +84,16 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 
 			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("reused allocated cluster CIDR %#q", subnetCIDR))
 
+		} else if key.VPCID(customResource) != "" {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "using network of existing VPC")
+
+			subnetCIDR, statusAZs, err = r.findExistingNetwork(ctx, customResource)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("using network %#q of existing VPC", subnetCIDR))
+
 		} else {
 			r.logger.LogCtx(ctx, "level", "debug", "message", "allocating cluster subnet CIDR")
 
@@ -127,6 +137,13 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 
 	} else {
 		r.logger.LogCtx(ctx, "level", "debug", "message", "found out subnet doesn't need to be allocated for cluster")
+
+		if key.IPv6Enabled(customResource) {
+			err = r.ensureIPv6Allocations(ctx, customResource)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		}
 	}
 
 	return nil
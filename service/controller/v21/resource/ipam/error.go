@@ -19,3 +19,12 @@ var invalidParameterError = &microerror.Error{
 func IsInvalidParameter(err error) bool {
 	return microerror.Cause(err) == invalidParameterError
 }
+
+var notFoundError = &microerror.Error{
+	Kind: "not found",
+}
+
+// IsNotFound asserts notFoundError.
+func IsNotFound(err error) bool {
+	return microerror.Cause(err) == notFoundError
+}
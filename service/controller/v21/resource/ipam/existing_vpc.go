@@ -0,0 +1,123 @@
+package ipam
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/aws-operator/service/controller/v21/controllercontext"
+	"github.com/giantswarm/aws-operator/service/controller/v21/key"
+)
+
+// findExistingNetwork looks up the network of the existing VPC the tenant
+// cluster is deployed into. Nothing is allocated in this case. The CIDR of the
+// VPC and the blocks of its existing subnets are recorded in the CR status
+// instead, so that the rest of the operator finds them where it expects
+// allocated networks.
+func (r *Resource) findExistingNetwork(ctx context.Context, customResource v1alpha1.AWSConfig) (net.IPNet, []v1alpha1.AWSConfigStatusAWSAvailabilityZone, error) {
+	ctlCtx, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return net.IPNet{}, nil, microerror.Mask(err)
+	}
+
+	var vpcCIDR net.IPNet
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("finding CIDR of existing VPC %#q", key.VPCID(customResource)))
+
+		i := &ec2.DescribeVpcsInput{
+			VpcIds: []*string{
+				aws.String(key.VPCID(customResource)),
+			},
+		}
+
+		o, err := ctlCtx.AWSClient.EC2.DescribeVpcs(i)
+		if err != nil {
+			return net.IPNet{}, nil, microerror.Mask(err)
+		}
+		if len(o.Vpcs) != 1 {
+			return net.IPNet{}, nil, microerror.Maskf(notFoundError, "VPC %#q", key.VPCID(customResource))
+		}
+
+		_, c, err := net.ParseCIDR(aws.StringValue(o.Vpcs[0].CidrBlock))
+		if err != nil {
+			return net.IPNet{}, nil, microerror.Mask(err)
+		}
+		vpcCIDR = *c
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found CIDR %#q of existing VPC %#q", vpcCIDR.String(), key.VPCID(customResource)))
+	}
+
+	var subnetCIDRs map[string]string
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "finding CIDRs of existing subnets")
+
+		var ids []*string
+		for _, s := range key.VPCSubnets(customResource) {
+			ids = append(ids, aws.String(s.PrivateID), aws.String(s.PublicID))
+		}
+
+		i := &ec2.DescribeSubnetsInput{
+			SubnetIds: ids,
+		}
+
+		o, err := ctlCtx.AWSClient.EC2.DescribeSubnets(i)
+		if err != nil {
+			return net.IPNet{}, nil, microerror.Mask(err)
+		}
+
+		subnetCIDRs = map[string]string{}
+		for _, s := range o.Subnets {
+			subnetCIDRs[aws.StringValue(s.SubnetId)] = aws.StringValue(s.CidrBlock)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "found CIDRs of existing subnets")
+	}
+
+	statusAZs, err := existingSubnetsToStatusAZs(key.VPCSubnets(customResource), subnetCIDRs)
+	if err != nil {
+		return net.IPNet{}, nil, microerror.Mask(err)
+	}
+
+	return vpcCIDR, statusAZs, nil
+}
+
+// existingSubnetsToStatusAZs maps the existing subnets of each availability
+// zone to their CIDRs found in AWS.
+func existingSubnetsToStatusAZs(subnets []v1alpha1.AWSConfigSpecAWSVPCSubnet, cidrs map[string]string) ([]v1alpha1.AWSConfigStatusAWSAvailabilityZone, error) {
+	if len(subnets) == 0 {
+		return nil, microerror.Maskf(invalidConfigError, "existing VPC requires at least one availability zone with existing subnets")
+	}
+
+	var statusAZs []v1alpha1.AWSConfigStatusAWSAvailabilityZone
+	for _, s := range subnets {
+		private, ok := cidrs[s.PrivateID]
+		if !ok {
+			return nil, microerror.Maskf(notFoundError, "subnet %#q", s.PrivateID)
+		}
+		public, ok := cidrs[s.PublicID]
+		if !ok {
+			return nil, microerror.Maskf(notFoundError, "subnet %#q", s.PublicID)
+		}
+
+		statusAZ := v1alpha1.AWSConfigStatusAWSAvailabilityZone{
+			Name: s.AvailabilityZone,
+			Subnet: v1alpha1.AWSConfigStatusAWSAvailabilityZoneSubnet{
+				Private: v1alpha1.AWSConfigStatusAWSAvailabilityZoneSubnetPrivate{
+					CIDR: private,
+				},
+				Public: v1alpha1.AWSConfigStatusAWSAvailabilityZoneSubnetPublic{
+					CIDR: public,
+				},
+			},
+		}
+
+		statusAZs = append(statusAZs, statusAZ)
+	}
+
+	return statusAZs, nil
+}
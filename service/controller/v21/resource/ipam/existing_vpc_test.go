@@ -0,0 +1,81 @@
+package ipam
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+)
+
+func Test_existingSubnetsToStatusAZs(t *testing.T) {
+	cidrs := map[string]string{
+		"subnet-private-a": "10.10.0.0/24",
+		"subnet-public-a":  "10.10.1.0/24",
+		"subnet-private-b": "10.10.2.0/24",
+		"subnet-public-b":  "10.10.3.0/24",
+	}
+
+	testCases := []struct {
+		name              string
+		subnets           []v1alpha1.AWSConfigSpecAWSVPCSubnet
+		expectedStatusAZs []v1alpha1.AWSConfigStatusAWSAvailabilityZone
+		errorMatcher      func(error) bool
+	}{
+		{
+			name: "case 0: subnets of one AZ",
+			subnets: []v1alpha1.AWSConfigSpecAWSVPCSubnet{
+				{AvailabilityZone: "eu-central-1a", PrivateID: "subnet-private-a", PublicID: "subnet-public-a"},
+			},
+			expectedStatusAZs: []v1alpha1.AWSConfigStatusAWSAvailabilityZone{
+				newStatusAZ("eu-central-1a", "10.10.0.0/24", "10.10.1.0/24", "", ""),
+			},
+		},
+		{
+			name: "case 1: subnets of two AZs",
+			subnets: []v1alpha1.AWSConfigSpecAWSVPCSubnet{
+				{AvailabilityZone: "eu-central-1a", PrivateID: "subnet-private-a", PublicID: "subnet-public-a"},
+				{AvailabilityZone: "eu-central-1b", PrivateID: "subnet-private-b", PublicID: "subnet-public-b"},
+			},
+			expectedStatusAZs: []v1alpha1.AWSConfigStatusAWSAvailabilityZone{
+				newStatusAZ("eu-central-1a", "10.10.0.0/24", "10.10.1.0/24", "", ""),
+				newStatusAZ("eu-central-1b", "10.10.2.0/24", "10.10.3.0/24", "", ""),
+			},
+		},
+		{
+			name:         "case 2: error for no subnets",
+			errorMatcher: IsInvalidConfig,
+		},
+		{
+			name: "case 3: error for unknown subnet",
+			subnets: []v1alpha1.AWSConfigSpecAWSVPCSubnet{
+				{AvailabilityZone: "eu-central-1c", PrivateID: "subnet-private-c", PublicID: "subnet-public-a"},
+			},
+			errorMatcher: IsNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			statusAZs, err := existingSubnetsToStatusAZs(tc.subnets, cidrs)
+
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if tc.errorMatcher != nil {
+				return
+			}
+
+			if !reflect.DeepEqual(statusAZs, tc.expectedStatusAZs) {
+				t.Fatalf("statusAZs == %#v, want %#v", statusAZs, tc.expectedStatusAZs)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package ipam
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/aws-operator/service/controller/v21/controllercontext"
+	"github.com/giantswarm/aws-operator/service/controller/v21/key"
+)
+
+const (
+	// ipv6SubnetMaskBits is the size of the IPv6 blocks of the tenant cluster
+	// subnets. AWS only supports /64 blocks for subnets.
+	ipv6SubnetMaskBits = 64
+)
+
+// ensureIPv6Allocations records the IPv6 blocks of the dual-stack subnets
+// next to their IPv4 blocks in the CR status. The VPC gets its IPv6 block
+// assigned by Amazon when the tenant cluster main stack is created. The subnet
+// blocks are carved from it by the stack in a deterministic order, which is
+// reproduced here.
+func (r *Resource) ensureIPv6Allocations(ctx context.Context, customResource v1alpha1.AWSConfig) error {
+	r.logger.LogCtx(ctx, "level", "debug", "message", "finding out if IPv6 subnets need to be recorded for cluster")
+
+	if hasIPv6Allocations(customResource) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "found out IPv6 subnets don't need to be recorded for cluster")
+		return nil
+	}
+
+	var vpcCIDR net.IPNet
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "finding IPv6 block of tenant cluster VPC")
+
+		var err error
+		vpcCIDR, err = getVPCIPv6CIDR(ctx, key.ClusterID(customResource))
+		if IsNotFound(err) {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "did not find IPv6 block of tenant cluster VPC")
+			r.logger.LogCtx(ctx, "level", "debug", "message", "the tenant cluster main stack is not yet created")
+			return nil
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found IPv6 block %#q of tenant cluster VPC", vpcCIDR.String()))
+	}
+
+	statusAZs, err := splitIPv6NetworkToStatusAZs(vpcCIDR, customResource.Status.AWS.AvailabilityZones)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "updating CR status")
+
+		customResource.Status.AWS.AvailabilityZones = statusAZs
+
+		_, err = r.g8sClient.ProviderV1alpha1().AWSConfigs(customResource.Namespace).UpdateStatus(&customResource)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "updated CR status")
+	}
+
+	return nil
+}
+
+func getVPCIPv6CIDR(ctx context.Context, clusterID string) (net.IPNet, error) {
+	ctlCtx, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return net.IPNet{}, microerror.Mask(err)
+	}
+
+	i := &ec2.DescribeVpcsInput{
+		Filters: []*ec2.Filter{
+			{
+				Name: aws.String("tag:Name"),
+				Values: []*string{
+					aws.String(clusterID),
+				},
+			},
+		},
+	}
+	o, err := ctlCtx.AWSClient.EC2.DescribeVpcs(i)
+	if err != nil {
+		return net.IPNet{}, microerror.Mask(err)
+	}
+
+	for _, vpc := range o.Vpcs {
+		for _, a := range vpc.Ipv6CidrBlockAssociationSet {
+			if a.Ipv6CidrBlockState == nil || aws.StringValue(a.Ipv6CidrBlockState.State) != ec2.VpcCidrBlockStateCodeAssociated {
+				continue
+			}
+
+			_, n, err := net.ParseCIDR(aws.StringValue(a.Ipv6CidrBlock))
+			if err != nil {
+				return net.IPNet{}, microerror.Mask(err)
+			}
+
+			return *n, nil
+		}
+	}
+
+	return net.IPNet{}, microerror.Maskf(notFoundError, "IPv6 block of VPC %#q", clusterID)
+}
+
+func hasIPv6Allocations(customResource v1alpha1.AWSConfig) bool {
+	for _, az := range key.StatusAvailabilityZones(customResource) {
+		if az.Subnet.Private.IPv6CIDR == "" || az.Subnet.Public.IPv6CIDR == "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// splitIPv6NetworkToStatusAZs assigns each AZ a private and a public /64
+// block of network. The AZs are ordered by name and the private block of an
+// AZ precedes its public block, the same way the guest subnets adapter
+// carves the blocks in the tenant cluster main stack.
+func splitIPv6NetworkToStatusAZs(network net.IPNet, statusAZs []v1alpha1.AWSConfigStatusAWSAvailabilityZone) ([]v1alpha1.AWSConfigStatusAWSAvailabilityZone, error) {
+	var names []string
+	for _, az := range statusAZs {
+		names = append(names, az.Name)
+	}
+	sort.Strings(names)
+
+	indices := map[string]int{}
+	for i, n := range names {
+		indices[n] = i
+	}
+
+	var result []v1alpha1.AWSConfigStatusAWSAvailabilityZone
+	for _, az := range statusAZs {
+		private, err := ipv6Subnet(network, indices[az.Name]*2)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		public, err := ipv6Subnet(network, indices[az.Name]*2+1)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		az.Subnet.Private.IPv6CIDR = private.String()
+		az.Subnet.Public.IPv6CIDR = public.String()
+
+		result = append(result, az)
+	}
+
+	return result, nil
+}
+
+// ipv6Subnet returns the /64 block with the given index within network.
+func ipv6Subnet(network net.IPNet, idx int) (net.IPNet, error) {
+	ones, maskBits := network.Mask.Size()
+	if maskBits != net.IPv6len*8 {
+		return net.IPNet{}, microerror.Maskf(invalidParameterError, "network %s is not an IPv6 network", network.String())
+	}
+	if ones > ipv6SubnetMaskBits {
+		return net.IPNet{}, microerror.Maskf(invalidParameterError, "network %s is smaller than /%d", network.String(), ipv6SubnetMaskBits)
+	}
+	if idx < 0 || uint64(idx) >= uint64(1)<<uint(ipv6SubnetMaskBits-ones) {
+		return net.IPNet{}, microerror.Maskf(invalidParameterError, "no room in network %s for /%d block %d", network.String(), ipv6SubnetMaskBits, idx)
+	}
+
+	ip := make(net.IP, net.IPv6len)
+	copy(ip, network.IP.To16())
+
+	prefix := binary.BigEndian.Uint64(ip[:8]) + uint64(idx)
+	binary.BigEndian.PutUint64(ip[:8], prefix)
+
+	subnet := net.IPNet{
+		IP:   ip,
+		Mask: net.CIDRMask(ipv6SubnetMaskBits, net.IPv6len*8),
+	}
+
+	return subnet, nil
+}
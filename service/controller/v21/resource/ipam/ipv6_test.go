@@ -0,0 +1,100 @@
+package ipam
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+)
+
+func Test_splitIPv6NetworkToStatusAZs(t *testing.T) {
+	testCases := []struct {
+		name              string
+		network           string
+		statusAZs         []v1alpha1.AWSConfigStatusAWSAvailabilityZone
+		expectedStatusAZs []v1alpha1.AWSConfigStatusAWSAvailabilityZone
+		errorMatcher      func(error) bool
+	}{
+		{
+			name:    "case 0: split /56 for one AZ",
+			network: "2a05:d014:b16:4d00::/56",
+			statusAZs: []v1alpha1.AWSConfigStatusAWSAvailabilityZone{
+				newStatusAZ("eu-central-1a", "10.100.3.0/25", "10.100.3.128/25", "", ""),
+			},
+			expectedStatusAZs: []v1alpha1.AWSConfigStatusAWSAvailabilityZone{
+				newStatusAZ("eu-central-1a", "10.100.3.0/25", "10.100.3.128/25", "2a05:d014:b16:4d00::/64", "2a05:d014:b16:4d01::/64"),
+			},
+		},
+		{
+			name:    "case 1: split /56 for three unsorted AZs",
+			network: "2a05:d014:b16:4d00::/56",
+			statusAZs: []v1alpha1.AWSConfigStatusAWSAvailabilityZone{
+				newStatusAZ("eu-central-1c", "10.100.3.128/27", "10.100.3.160/27", "", ""),
+				newStatusAZ("eu-central-1a", "10.100.3.0/27", "10.100.3.32/27", "", ""),
+				newStatusAZ("eu-central-1b", "10.100.3.64/27", "10.100.3.96/27", "", ""),
+			},
+			expectedStatusAZs: []v1alpha1.AWSConfigStatusAWSAvailabilityZone{
+				newStatusAZ("eu-central-1c", "10.100.3.128/27", "10.100.3.160/27", "2a05:d014:b16:4d04::/64", "2a05:d014:b16:4d05::/64"),
+				newStatusAZ("eu-central-1a", "10.100.3.0/27", "10.100.3.32/27", "2a05:d014:b16:4d00::/64", "2a05:d014:b16:4d01::/64"),
+				newStatusAZ("eu-central-1b", "10.100.3.64/27", "10.100.3.96/27", "2a05:d014:b16:4d02::/64", "2a05:d014:b16:4d03::/64"),
+			},
+		},
+		{
+			name:    "case 2: error for /64 network with more than one subnet",
+			network: "2a05:d014:b16:4d00::/64",
+			statusAZs: []v1alpha1.AWSConfigStatusAWSAvailabilityZone{
+				newStatusAZ("eu-central-1a", "10.100.3.0/25", "10.100.3.128/25", "", ""),
+			},
+			errorMatcher: IsInvalidParameter,
+		},
+		{
+			name:    "case 3: error for IPv4 network",
+			network: "10.100.3.0/24",
+			statusAZs: []v1alpha1.AWSConfigStatusAWSAvailabilityZone{
+				newStatusAZ("eu-central-1a", "10.100.3.0/25", "10.100.3.128/25", "", ""),
+			},
+			errorMatcher: IsInvalidParameter,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			statusAZs, err := splitIPv6NetworkToStatusAZs(mustParseCIDR(tc.network), tc.statusAZs)
+
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if tc.errorMatcher != nil {
+				return
+			}
+
+			if !reflect.DeepEqual(statusAZs, tc.expectedStatusAZs) {
+				t.Fatalf("statusAZs == %#v, want %#v", statusAZs, tc.expectedStatusAZs)
+			}
+		})
+	}
+}
+
+func newStatusAZ(name, privateCIDR, publicCIDR, privateIPv6CIDR, publicIPv6CIDR string) v1alpha1.AWSConfigStatusAWSAvailabilityZone {
+	return v1alpha1.AWSConfigStatusAWSAvailabilityZone{
+		Name: name,
+		Subnet: v1alpha1.AWSConfigStatusAWSAvailabilityZoneSubnet{
+			Private: v1alpha1.AWSConfigStatusAWSAvailabilityZoneSubnetPrivate{
+				CIDR:     privateCIDR,
+				IPv6CIDR: privateIPv6CIDR,
+			},
+			Public: v1alpha1.AWSConfigStatusAWSAvailabilityZoneSubnetPublic{
+				CIDR:     publicCIDR,
+				IPv6CIDR: publicIPv6CIDR,
+			},
+		},
+	}
+}
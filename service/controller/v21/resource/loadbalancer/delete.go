@@ -6,14 +6,15 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elbv2"
 	"github.com/giantswarm/microerror"
 
 	"github.com/giantswarm/aws-operator/service/controller/v21/controllercontext"
 	"github.com/giantswarm/aws-operator/service/controller/v21/key"
 )
 
-// EnsureDeleted ensures that any ELBs from Kubernetes LoadBalancer services
-// are deleted. This is needed because the use the VPC public subnet.
+// EnsureDeleted ensures that any ELBs and NLBs from Kubernetes LoadBalancer
+// services are deleted. This is needed because the use the VPC public subnet.
 func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
 	customObject, err := key.ToCustomObject(obj)
 	if err != nil {
@@ -47,5 +48,27 @@ func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
 		r.logger.LogCtx(ctx, "level", "debug", "message", "not deleting load balancers because there aren't any")
 	}
 
+	if lbState != nil && len(lbState.LoadBalancerARNs) > 0 {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("deleting %d network load balancers", len(lbState.LoadBalancerARNs)))
+
+		sc, err := controllercontext.FromContext(ctx)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, lbARN := range lbState.LoadBalancerARNs {
+			_, err := sc.AWSClient.ELBV2.DeleteLoadBalancer(&elbv2.DeleteLoadBalancerInput{
+				LoadBalancerArn: aws.String(lbARN),
+			})
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("deleted %d network load balancers", len(lbState.LoadBalancerARNs)))
+	} else {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not deleting network load balancers because there aren't any")
+	}
+
 	return nil
 }
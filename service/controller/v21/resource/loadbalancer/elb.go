@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elbv2"
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
 	"github.com/giantswarm/microerror"
 
@@ -59,6 +60,38 @@ func (r *Resource) clusterLoadBalancers(ctx context.Context, customObject v1alph
 
 	lbState.LoadBalancerNames = clusterLBNames
 
+	// Kubernetes LoadBalancer services annotated to use Network Load Balancers
+	// are backed by the ELBV2 API, so we look them up separately.
+	clusterLBARNs := []string{}
+	{
+		output, err := sc.AWSClient.ELBV2.DescribeLoadBalancers(&elbv2.DescribeLoadBalancersInput{})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		allLBARNs := []*string{}
+		for _, lb := range output.LoadBalancers {
+			allLBARNs = append(allLBARNs, lb.LoadBalancerArn)
+		}
+
+		for _, lbARNs := range splitLoadBalancers(allLBARNs, loadBalancerTagChunkSize) {
+			tagsOutput, err := sc.AWSClient.ELBV2.DescribeTags(&elbv2.DescribeTagsInput{
+				ResourceArns: lbARNs,
+			})
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+
+			for _, lb := range tagsOutput.TagDescriptions {
+				if containsClusterTagV2(lb.Tags, customObject) && containsServiceTagV2(lb.Tags) {
+					clusterLBARNs = append(clusterLBARNs, *lb.ResourceArn)
+				}
+			}
+		}
+	}
+
+	lbState.LoadBalancerARNs = clusterLBARNs
+
 	return lbState, nil
 }
 
@@ -98,3 +131,24 @@ func containsServiceTag(tags []*elb.Tag) bool {
 
 	return false
 }
+
+func containsClusterTagV2(tags []*elbv2.Tag, customObject v1alpha1.AWSConfig) bool {
+	tagKey := key.ClusterCloudProviderTag(customObject)
+
+	for _, tag := range tags {
+		if *tag.Key == tagKey && *tag.Value == cloudProviderClusterTagValue {
+			return true
+		}
+	}
+	return false
+}
+
+func containsServiceTagV2(tags []*elbv2.Tag) bool {
+	for _, tag := range tags {
+		if *tag.Key == cloudProviderServiceTagKey {
+			return true
+		}
+	}
+
+	return false
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elbv2"
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
 	"github.com/giantswarm/micrologger/microloggertest"
 
@@ -25,15 +26,17 @@ func Test_clusterLoadBalancers(t *testing.T) {
 	}
 
 	testCases := []struct {
-		description   string
-		obj           v1alpha1.AWSConfig
-		expectedState *LoadBalancerState
-		loadBalancers []LoadBalancerMock
+		description          string
+		obj                  v1alpha1.AWSConfig
+		expectedState        *LoadBalancerState
+		loadBalancers        []LoadBalancerMock
+		networkLoadBalancers []NetworkLoadBalancerMock
 	}{
 		{
 			description: "basic match with no load balancers",
 			obj:         customObject,
 			expectedState: &LoadBalancerState{
+				LoadBalancerARNs:  []string{},
 				LoadBalancerNames: []string{},
 			},
 		},
@@ -41,6 +44,7 @@ func Test_clusterLoadBalancers(t *testing.T) {
 			description: "basic match with load balancer",
 			obj:         customObject,
 			expectedState: &LoadBalancerState{
+				LoadBalancerARNs: []string{},
 				LoadBalancerNames: []string{
 					"test-elb",
 				},
@@ -65,6 +69,7 @@ func Test_clusterLoadBalancers(t *testing.T) {
 			description: "no matching load balancer",
 			obj:         customObject,
 			expectedState: &LoadBalancerState{
+				LoadBalancerARNs:  []string{},
 				LoadBalancerNames: []string{},
 			},
 			loadBalancers: []LoadBalancerMock{
@@ -87,6 +92,7 @@ func Test_clusterLoadBalancers(t *testing.T) {
 			description: "multiple load balancers",
 			obj:         customObject,
 			expectedState: &LoadBalancerState{
+				LoadBalancerARNs: []string{},
 				LoadBalancerNames: []string{
 					"test-elb",
 					"test-elb-2",
@@ -125,6 +131,7 @@ func Test_clusterLoadBalancers(t *testing.T) {
 			description: "multiple load balancers some not matching",
 			obj:         customObject,
 			expectedState: &LoadBalancerState{
+				LoadBalancerARNs: []string{},
 				LoadBalancerNames: []string{
 					"test-elb",
 					"test-elb-2",
@@ -172,10 +179,75 @@ func Test_clusterLoadBalancers(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "network load balancers some not matching",
+			obj:         customObject,
+			expectedState: &LoadBalancerState{
+				LoadBalancerARNs: []string{
+					"arn:aws:elasticloadbalancing:eu-central-1:123456789012:loadbalancer/net/test-nlb/0123456789abcdef",
+				},
+				LoadBalancerNames: []string{
+					"test-elb",
+				},
+			},
+			loadBalancers: []LoadBalancerMock{
+				{
+					loadBalancerName: "test-elb",
+					loadBalancerTags: []*elb.Tag{
+						{
+							Key:   aws.String("kubernetes.io/cluster/test-cluster"),
+							Value: aws.String("owned"),
+						},
+						{
+							Key:   aws.String("kubernetes.io/service-name"),
+							Value: aws.String("hello-world"),
+						},
+					},
+				},
+			},
+			networkLoadBalancers: []NetworkLoadBalancerMock{
+				{
+					loadBalancerARN: "arn:aws:elasticloadbalancing:eu-central-1:123456789012:loadbalancer/net/test-nlb/0123456789abcdef",
+					loadBalancerTags: []*elbv2.Tag{
+						{
+							Key:   aws.String("kubernetes.io/cluster/test-cluster"),
+							Value: aws.String("owned"),
+						},
+						{
+							Key:   aws.String("kubernetes.io/service-name"),
+							Value: aws.String("hello-world-nlb"),
+						},
+					},
+				},
+				{
+					loadBalancerARN: "arn:aws:elasticloadbalancing:eu-central-1:123456789012:loadbalancer/net/other-nlb/0123456789abcdef",
+					loadBalancerTags: []*elbv2.Tag{
+						{
+							Key:   aws.String("kubernetes.io/cluster/another-cluster"),
+							Value: aws.String("owned"),
+						},
+						{
+							Key:   aws.String("kubernetes.io/service-name"),
+							Value: aws.String("hello-world-nlb"),
+						},
+					},
+				},
+				{
+					loadBalancerARN: "arn:aws:elasticloadbalancing:eu-central-1:123456789012:loadbalancer/net/test-cluster-api-nlb/0123456789abcdef",
+					loadBalancerTags: []*elbv2.Tag{
+						{
+							Key:   aws.String("kubernetes.io/cluster/test-cluster"),
+							Value: aws.String("owned"),
+						},
+					},
+				},
+			},
+		},
 		{
 			description: "missing service tag",
 			obj:         customObject,
 			expectedState: &LoadBalancerState{
+				LoadBalancerARNs:  []string{},
 				LoadBalancerNames: []string{},
 			},
 			loadBalancers: []LoadBalancerMock{
@@ -208,6 +280,9 @@ func Test_clusterLoadBalancers(t *testing.T) {
 				ELB: &ELBClientMock{
 					loadBalancers: tc.loadBalancers,
 				},
+				ELBV2: &ELBV2ClientMock{
+					loadBalancers: tc.networkLoadBalancers,
+				},
 			}
 			ctx := context.TODO()
 			ctx = controllercontext.NewContext(ctx, controllercontext.Context{AWSClient: awsClients})
@@ -4,6 +4,8 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/elb"
 	"github.com/aws/aws-sdk-go/service/elb/elbiface"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
 )
 
 type ELBClientMock struct {
@@ -51,3 +53,47 @@ func (e *ELBClientMock) DescribeTags(*elb.DescribeTagsInput) (*elb.DescribeTagsO
 
 	return output, nil
 }
+
+type ELBV2ClientMock struct {
+	elbv2iface.ELBV2API
+
+	loadBalancers []NetworkLoadBalancerMock
+}
+
+type NetworkLoadBalancerMock struct {
+	loadBalancerARN  string
+	loadBalancerTags []*elbv2.Tag
+}
+
+func (e *ELBV2ClientMock) DeleteLoadBalancer(*elbv2.DeleteLoadBalancerInput) (*elbv2.DeleteLoadBalancerOutput, error) {
+	return nil, nil
+}
+
+func (e *ELBV2ClientMock) DescribeLoadBalancers(*elbv2.DescribeLoadBalancersInput) (*elbv2.DescribeLoadBalancersOutput, error) {
+	output := &elbv2.DescribeLoadBalancersOutput{}
+	lbs := []*elbv2.LoadBalancer{}
+
+	for _, lb := range e.loadBalancers {
+		lbs = append(lbs, &elbv2.LoadBalancer{
+			LoadBalancerArn: aws.String(lb.loadBalancerARN),
+		})
+	}
+	output.SetLoadBalancers(lbs)
+
+	return output, nil
+}
+
+func (e *ELBV2ClientMock) DescribeTags(*elbv2.DescribeTagsInput) (*elbv2.DescribeTagsOutput, error) {
+	output := &elbv2.DescribeTagsOutput{}
+	tagDescs := []*elbv2.TagDescription{}
+
+	for _, lb := range e.loadBalancers {
+		tagDescs = append(tagDescs, &elbv2.TagDescription{
+			ResourceArn: aws.String(lb.loadBalancerARN),
+			Tags:        lb.loadBalancerTags,
+		})
+	}
+	output.SetTagDescriptions(tagDescs)
+
+	return output, nil
+}
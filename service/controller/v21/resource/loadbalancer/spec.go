@@ -2,10 +2,12 @@ package loadbalancer
 
 import (
 	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elbv2"
 )
 
 type Clients struct {
-	ELB ELBClient
+	ELB   ELBClient
+	ELBV2 ELBV2Client
 }
 
 // ELBClient describes the methods required to be implemented by an ELB AWS
@@ -16,6 +18,15 @@ type ELBClient interface {
 	DescribeTags(*elb.DescribeTagsInput) (*elb.DescribeTagsOutput, error)
 }
 
+// ELBV2Client describes the methods required to be implemented by an ELBV2
+// AWS client. The ELBV2 API provides support for Network Load Balancers.
+type ELBV2Client interface {
+	DeleteLoadBalancer(*elbv2.DeleteLoadBalancerInput) (*elbv2.DeleteLoadBalancerOutput, error)
+	DescribeLoadBalancers(*elbv2.DescribeLoadBalancersInput) (*elbv2.DescribeLoadBalancersOutput, error)
+	DescribeTags(*elbv2.DescribeTagsInput) (*elbv2.DescribeTagsOutput, error)
+}
+
 type LoadBalancerState struct {
+	LoadBalancerARNs  []string
 	LoadBalancerNames []string
 }
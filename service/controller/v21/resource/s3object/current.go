@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
@@ -50,6 +51,13 @@ func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) (interf
 
 	output := map[string]BucketObjectState{}
 	for _, object := range result.Contents {
+		// Etcd snapshots are managed by the masters and the etcdbackup resource.
+		// They are not part of the desired state of this resource and must not be
+		// fetched here.
+		if strings.HasPrefix(*object.Key, key.EtcdBackupPrefix) {
+			continue
+		}
+
 		body, err := r.getBucketObjectBody(ctx, bucketName, *object.Key)
 		if err != nil {
 			return output, microerror.Mask(err)
@@ -75,5 +75,18 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 		}
 	}
 
+	for _, p := range key.NodePools(customObject) {
+		b, err := r.cloudConfig.NewNodePoolTemplate(ctx, customObject, clusterCerts, p)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		k := key.BucketObjectName(customObject, key.NodePoolCloudConfigRole(p))
+		output[k] = BucketObjectState{
+			Bucket: key.BucketName(customObject, accountID),
+			Body:   b,
+			Key:    k,
+		}
+	}
+
 	return output, nil
 }
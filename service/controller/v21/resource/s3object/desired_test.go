@@ -27,23 +27,48 @@ func Test_DesiredState(t *testing.T) {
 		},
 	}
 
+	nodePoolTpo := &v1alpha1.AWSConfig{
+		Spec: v1alpha1.AWSConfigSpec{
+			Cluster: v1alpha1.Cluster{
+				ID: "test-cluster",
+			},
+			AWS: v1alpha1.AWSConfigSpecAWS{
+				NodePools: []v1alpha1.AWSConfigSpecAWSNodePool{
+					{Name: "gpu"},
+					{Name: "memory"},
+				},
+			},
+		},
+	}
+
 	masterKeyPattern := "cloudconfig/v[\\d_]+/master"
+	nodePoolKeyPattern := "cloudconfig/v[\\d_]+/worker-[a-z0-9]+-[0-9a-f]{8}"
 	workerKeyPattern := "cloudconfig/v[\\d_]+/worker"
 
 	masterKeyRegexp := regexp.MustCompile(masterKeyPattern)
+	nodePoolKeyRegexp := regexp.MustCompile(nodePoolKeyPattern)
 	workerKeyRegexp := regexp.MustCompile(workerKeyPattern)
 
 	testCases := []struct {
-		obj            *v1alpha1.AWSConfig
-		description    string
-		expectedBucket string
-		expectedBody   string
+		obj             *v1alpha1.AWSConfig
+		description     string
+		expectedBucket  string
+		expectedBody    string
+		expectedObjects int
 	}{
 		{
-			description:    "basic match",
-			obj:            clusterTpo,
-			expectedBody:   "mybody-",
-			expectedBucket: "myaccountid-g8s-test-cluster",
+			description:     "basic match",
+			obj:             clusterTpo,
+			expectedBody:    "mybody-",
+			expectedBucket:  "myaccountid-g8s-test-cluster",
+			expectedObjects: 2,
+		},
+		{
+			description:     "node pools",
+			obj:             nodePoolTpo,
+			expectedBody:    "mybody-",
+			expectedBucket:  "myaccountid-g8s-test-cluster",
+			expectedObjects: 4,
 		},
 	}
 
@@ -96,8 +121,8 @@ func Test_DesiredState(t *testing.T) {
 				t.Fatalf("expected '%T', got '%T'", desiredState, result)
 			}
 
-			if len(desiredState) != 2 {
-				t.Fatalf("expected 2 objects, got %d", len(desiredState))
+			if len(desiredState) != tc.expectedObjects {
+				t.Fatalf("expected %d objects, got %d", tc.expectedObjects, len(desiredState))
 			}
 
 			for key, bucketObjectState := range desiredState {
@@ -113,6 +138,10 @@ func Test_DesiredState(t *testing.T) {
 					if !masterKeyRegexp.MatchString(key) {
 						t.Fatalf("expected key %q, to match pattern %q", key, masterKeyPattern)
 					}
+				} else if strings.Contains(key, "/worker-") {
+					if !nodePoolKeyRegexp.MatchString(key) {
+						t.Fatalf("expected key %q, to match pattern %q", key, nodePoolKeyPattern)
+					}
 				} else if strings.HasSuffix(key, "worker") {
 					if !workerKeyRegexp.MatchString(key) {
 						t.Fatalf("expected key %q, to match pattern %q", key, workerKeyPattern)
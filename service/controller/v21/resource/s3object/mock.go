@@ -75,6 +75,10 @@ func (c *CloudConfigMock) NewMasterTemplate(ctx context.Context, customObject v1
 	return c.template, nil
 }
 
+func (c *CloudConfigMock) NewNodePoolTemplate(ctx context.Context, customObject v1alpha1.AWSConfig, clusterCerts certs.Cluster, nodePool v1alpha1.AWSConfigSpecAWSNodePool) (string, error) {
+	return c.template, nil
+}
+
 func (c *CloudConfigMock) NewWorkerTemplate(ctx context.Context, customObject v1alpha1.AWSConfig, clusterCerts certs.Cluster) (string, error) {
 	return c.template, nil
 }
@@ -0,0 +1,300 @@
+package stackdrift
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cloudformationservice "github.com/giantswarm/aws-operator/service/controller/v21/cloudformation"
+	"github.com/giantswarm/aws-operator/service/controller/v21/controllercontext"
+	"github.com/giantswarm/aws-operator/service/controller/v21/key"
+)
+
+const (
+	// conditionTypeDrifted is the type of the status condition telling whether
+	// any stack of the cluster drifted.
+	conditionTypeDrifted = "Drifted"
+
+	namedIAMCapability = "CAPABILITY_NAMED_IAM"
+)
+
+type stack struct {
+	client *cloudformation.CloudFormation
+	name   string
+}
+
+// EnsureCreated starts drift detections of the stacks of the cluster once the
+// configured interval passed and reports the results of finished detections
+// with the Drifted condition in the CR status. The drifted resources of a stack
+// are only logged.
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	// Stacks being created or updated cannot be checked for drift. Drift is
+	// only of interest once the cluster is up anyway.
+	if !customObject.Status.Cluster.HasCreatedCondition() {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not detecting stack drift because the cluster is not yet created")
+		r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+		return nil
+	}
+
+	controllerCtx, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	stacks := []stack{
+		{
+			client: controllerCtx.AWSClient.CloudFormation,
+			name:   key.MainGuestStackName(customObject),
+		},
+		{
+			client: r.hostCloudFormation,
+			name:   key.MainHostPreStackName(customObject),
+		},
+		{
+			client: r.hostCloudFormation,
+			name:   key.MainHostPostStackName(customObject),
+		},
+	}
+
+	var finished bool
+	for _, s := range stacks {
+		drifted, ok, err := r.detectDrift(ctx, s)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		if !ok {
+			continue
+		}
+
+		finished = true
+
+		if r.remediation && drifted {
+			err := r.remediateDrift(ctx, s)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		}
+	}
+
+	if !finished {
+		return nil
+	}
+
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "updating CR status with stack drift")
+
+		newObj, err := r.g8sClient.ProviderV1alpha1().AWSConfigs(customObject.GetNamespace()).Get(customObject.GetName(), metav1.GetOptions{})
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		newObj.Status.Cluster.Conditions = withDriftedCondition(newObj.Status.Cluster.Conditions, r.isDrifted(stacks), time.Now())
+
+		_, err = r.g8sClient.ProviderV1alpha1().AWSConfigs(newObj.GetNamespace()).UpdateStatus(newObj)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "updated CR status with stack drift")
+	}
+
+	return nil
+}
+
+// detectDrift drives the drift detection of the given stack. It starts a new
+// detection once the interval since the previous one passed and returns whether
+// the stack drifted when the detection finished. The second returned boolean
+// is false in case no detection finished.
+func (r *Resource) detectDrift(ctx context.Context, s stack) (bool, bool, error) {
+	r.mutex.Lock()
+	d := r.detections[s.name]
+	r.mutex.Unlock()
+
+	if d.id == "" {
+		if time.Since(d.finished) < r.interval {
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("not detecting drift of stack %#q because the previous detection finished less than %s ago", s.name, r.interval))
+			return false, false, nil
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("starting drift detection of stack %#q", s.name))
+
+		i := &cloudformation.DetectStackDriftInput{
+			StackName: aws.String(s.name),
+		}
+
+		o, err := s.client.DetectStackDrift(i)
+		if cloudformationservice.IsStackNotFound(err) {
+			// The host-pre stack does for instance not exist in case tenant
+			// cluster VPCs are attached to a Transit Gateway.
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("did not find stack %#q", s.name))
+			return false, false, nil
+		} else if err != nil {
+			return false, false, microerror.Mask(err)
+		}
+
+		id := aws.StringValue(o.StackDriftDetectionId)
+
+		r.mutex.Lock()
+		r.detections[s.name] = detection{id: id, finished: d.finished, drifted: d.drifted}
+		r.mutex.Unlock()
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("started drift detection %#q of stack %#q", id, s.name))
+
+		return false, false, nil
+	}
+
+	i := &cloudformation.DescribeStackDriftDetectionStatusInput{
+		StackDriftDetectionId: aws.String(d.id),
+	}
+
+	status, err := s.client.DescribeStackDriftDetectionStatus(i)
+	if err != nil {
+		return false, false, microerror.Mask(err)
+	}
+
+	if aws.StringValue(status.DetectionStatus) == cloudformation.StackDriftDetectionStatusDetectionInProgress {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("drift detection %#q of stack %#q is still in progress", d.id, s.name))
+		return false, false, nil
+	}
+
+	// Failed detections still report drift for the resources CloudFormation was
+	// able to check, so we treat them the same way.
+	if aws.StringValue(status.DetectionStatus) == cloudformation.StackDriftDetectionStatusDetectionFailed {
+		r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("drift detection %#q of stack %#q failed: %s", d.id, s.name, aws.StringValue(status.DetectionStatusReason)))
+	}
+
+	// Resources being in sync are omitted, only drifted ones are logged.
+	var resourceDrifts []*cloudformation.StackResourceDrift
+	{
+		i := &cloudformation.DescribeStackResourceDriftsInput{
+			StackName: aws.String(s.name),
+			StackResourceDriftStatusFilters: []*string{
+				aws.String(cloudformation.StackResourceDriftStatusDeleted),
+				aws.String(cloudformation.StackResourceDriftStatusModified),
+			},
+		}
+
+		err := s.client.DescribeStackResourceDriftsPages(i, func(o *cloudformation.DescribeStackResourceDriftsOutput, lastPage bool) bool {
+			resourceDrifts = append(resourceDrifts, o.StackResourceDrifts...)
+			return true
+		})
+		if err != nil {
+			return false, false, microerror.Mask(err)
+		}
+	}
+
+	drifted := aws.StringValue(status.StackDriftStatus) == cloudformation.StackDriftStatusDrifted
+
+	r.mutex.Lock()
+	r.detections[s.name] = detection{finished: time.Now(), drifted: drifted}
+	r.mutex.Unlock()
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("stack %#q has drift status %#q with %d drifted resources", s.name, aws.StringValue(status.StackDriftStatus), len(resourceDrifts)))
+
+	for _, rd := range resourceDrifts {
+		r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("resource %#q of type %#q with physical ID %#q of stack %#q has drift status %#q", aws.StringValue(rd.LogicalResourceId), aws.StringValue(rd.ResourceType), aws.StringValue(rd.PhysicalResourceId), s.name, aws.StringValue(rd.StackResourceDriftStatus)))
+	}
+
+	return drifted, true, nil
+}
+
+// remediateDrift re-applies the current template of the given stack. Note that
+// CloudFormation only acts on differences between the deployed and the given
+// template. Drift CloudFormation does not reconcile this way has to be resolved
+// manually and is still reported by the next detection.
+func (r *Resource) remediateDrift(ctx context.Context, s stack) error {
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("re-applying template of drifted stack %#q", s.name))
+
+	o, err := s.client.DescribeStacks(&cloudformation.DescribeStacksInput{StackName: aws.String(s.name)})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if len(o.Stacks) != 1 {
+		return microerror.Maskf(executionFailedError, "expected 1 stack with name %#q got %d", s.name, len(o.Stacks))
+	}
+
+	var parameters []*cloudformation.Parameter
+	for _, p := range o.Stacks[0].Parameters {
+		parameters = append(parameters, &cloudformation.Parameter{
+			ParameterKey:     p.ParameterKey,
+			UsePreviousValue: aws.Bool(true),
+		})
+	}
+
+	i := &cloudformation.UpdateStackInput{
+		Capabilities: []*string{
+			aws.String(namedIAMCapability),
+		},
+		Parameters:          parameters,
+		StackName:           aws.String(s.name),
+		UsePreviousTemplate: aws.Bool(true),
+	}
+	_, err = s.client.UpdateStack(i)
+	if IsNoUpdates(err) {
+		r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("did not re-apply template of drifted stack %#q because CloudFormation does not see any changes", s.name))
+		r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("drift of stack %#q has to be resolved manually", s.name))
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("re-applied template of drifted stack %#q", s.name))
+
+	return nil
+}
+
+// isDrifted returns whether the latest finished detection of any of the given
+// stacks found drift.
+func (r *Resource) isDrifted(stacks []stack) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, s := range stacks {
+		if r.detections[s.name].drifted {
+			return true
+		}
+	}
+
+	return false
+}
+
+// withDriftedCondition sets the Drifted condition in the given conditions. The
+// transition time is only updated in case the status of the condition changes.
+func withDriftedCondition(conditions []v1alpha1.StatusClusterCondition, drifted bool, t time.Time) []v1alpha1.StatusClusterCondition {
+	status := v1alpha1.StatusClusterStatusFalse
+	if drifted {
+		status = v1alpha1.StatusClusterStatusTrue
+	}
+
+	condition := v1alpha1.StatusClusterCondition{
+		LastHeartbeatTime:  v1alpha1.DeepCopyTime{Time: t},
+		LastTransitionTime: v1alpha1.DeepCopyTime{Time: t},
+		Status:             status,
+		Type:               conditionTypeDrifted,
+	}
+
+	var newConditions []v1alpha1.StatusClusterCondition
+	for _, c := range conditions {
+		if c.Type != conditionTypeDrifted {
+			newConditions = append(newConditions, c)
+			continue
+		}
+
+		if c.Status == status {
+			condition.LastTransitionTime = c.LastTransitionTime
+		}
+	}
+
+	return append(newConditions, condition)
+}
@@ -0,0 +1,150 @@
+package stackdrift
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+)
+
+func Test_isDrifted(t *testing.T) {
+	t.Parallel()
+	stacks := []stack{
+		{name: "cluster-guest-main"},
+		{name: "cluster-host-setup"},
+	}
+
+	testCases := []struct {
+		description     string
+		detections      map[string]detection
+		expectedDrifted bool
+	}{
+		{
+			description:     "case 0: no finished detection",
+			detections:      map[string]detection{},
+			expectedDrifted: false,
+		},
+		{
+			description: "case 1: no stack drifted",
+			detections: map[string]detection{
+				"cluster-guest-main": {},
+				"cluster-host-setup": {id: "detection-id"},
+			},
+			expectedDrifted: false,
+		},
+		{
+			description: "case 2: one stack drifted",
+			detections: map[string]detection{
+				"cluster-guest-main": {},
+				"cluster-host-setup": {id: "detection-id", drifted: true},
+			},
+			expectedDrifted: true,
+		},
+		{
+			description: "case 3: stack of another cluster drifted",
+			detections: map[string]detection{
+				"cluster-guest-main": {},
+				"other-guest-main":   {drifted: true},
+			},
+			expectedDrifted: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			r := &Resource{
+				detections: tc.detections,
+			}
+
+			drifted := r.isDrifted(stacks)
+			if drifted != tc.expectedDrifted {
+				t.Fatalf("expected %t got %t", tc.expectedDrifted, drifted)
+			}
+		})
+	}
+}
+
+func Test_withDriftedCondition(t *testing.T) {
+	t.Parallel()
+	before := time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC)
+	now := before.Add(time.Hour)
+
+	created := v1alpha1.StatusClusterCondition{
+		Status: v1alpha1.StatusClusterStatusTrue,
+		Type:   v1alpha1.StatusClusterTypeCreated,
+	}
+
+	testCases := []struct {
+		description        string
+		conditions         []v1alpha1.StatusClusterCondition
+		drifted            bool
+		expectedConditions []v1alpha1.StatusClusterCondition
+	}{
+		{
+			description: "case 0: add condition",
+			conditions:  []v1alpha1.StatusClusterCondition{created},
+			drifted:     true,
+			expectedConditions: []v1alpha1.StatusClusterCondition{
+				created,
+				{
+					LastHeartbeatTime:  v1alpha1.DeepCopyTime{Time: now},
+					LastTransitionTime: v1alpha1.DeepCopyTime{Time: now},
+					Status:             v1alpha1.StatusClusterStatusTrue,
+					Type:               conditionTypeDrifted,
+				},
+			},
+		},
+		{
+			description: "case 1: keep transition time of unchanged condition",
+			conditions: []v1alpha1.StatusClusterCondition{
+				{
+					LastHeartbeatTime:  v1alpha1.DeepCopyTime{Time: before},
+					LastTransitionTime: v1alpha1.DeepCopyTime{Time: before},
+					Status:             v1alpha1.StatusClusterStatusTrue,
+					Type:               conditionTypeDrifted,
+				},
+				created,
+			},
+			drifted: true,
+			expectedConditions: []v1alpha1.StatusClusterCondition{
+				created,
+				{
+					LastHeartbeatTime:  v1alpha1.DeepCopyTime{Time: now},
+					LastTransitionTime: v1alpha1.DeepCopyTime{Time: before},
+					Status:             v1alpha1.StatusClusterStatusTrue,
+					Type:               conditionTypeDrifted,
+				},
+			},
+		},
+		{
+			description: "case 2: transition condition",
+			conditions: []v1alpha1.StatusClusterCondition{
+				{
+					LastHeartbeatTime:  v1alpha1.DeepCopyTime{Time: before},
+					LastTransitionTime: v1alpha1.DeepCopyTime{Time: before},
+					Status:             v1alpha1.StatusClusterStatusTrue,
+					Type:               conditionTypeDrifted,
+				},
+			},
+			drifted: false,
+			expectedConditions: []v1alpha1.StatusClusterCondition{
+				{
+					LastHeartbeatTime:  v1alpha1.DeepCopyTime{Time: now},
+					LastTransitionTime: v1alpha1.DeepCopyTime{Time: now},
+					Status:             v1alpha1.StatusClusterStatusFalse,
+					Type:               conditionTypeDrifted,
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			conditions := withDriftedCondition(tc.conditions, tc.drifted, now)
+			if !reflect.DeepEqual(conditions, tc.expectedConditions) {
+				t.Fatalf("expected %#v got %#v", tc.expectedConditions, conditions)
+			}
+		})
+	}
+}
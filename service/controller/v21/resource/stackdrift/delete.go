@@ -0,0 +1,27 @@
+package stackdrift
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/aws-operator/service/controller/v21/key"
+)
+
+// EnsureDeleted forgets about the drift detections of the stacks of the
+// deleted cluster.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.detections, key.MainGuestStackName(customObject))
+	delete(r.detections, key.MainHostPreStackName(customObject))
+	delete(r.detections, key.MainHostPostStackName(customObject))
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package stackdrift
+
+import (
+	"strings"
+
+	"github.com/giantswarm/microerror"
+)
+
+var executionFailedError = &microerror.Error{
+	Kind: "executionFailedError",
+}
+
+// IsExecutionFailed asserts executionFailedError.
+func IsExecutionFailed(err error) bool {
+	return microerror.Cause(err) == executionFailedError
+}
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+// IsNoUpdates asserts the validation error CloudFormation returns in case a
+// stack update does not contain any changes. There is no error code constant
+// for it in the AWS SDK.
+func IsNoUpdates(err error) bool {
+	c := microerror.Cause(err)
+
+	if c == nil {
+		return false
+	}
+
+	return strings.Contains(c.Error(), "No updates are to be performed")
+}
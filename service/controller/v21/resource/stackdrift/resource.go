@@ -0,0 +1,93 @@
+package stackdrift
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/giantswarm/apiextensions/pkg/clientset/versioned"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+)
+
+const (
+	Name = "stackdriftv21"
+)
+
+const (
+	// DefaultInterval is the drift detection interval used in case the
+	// installation does not configure one.
+	DefaultInterval = time.Hour
+)
+
+type Config struct {
+	G8sClient          versioned.Interface
+	HostCloudFormation *cloudformation.CloudFormation
+	Logger             micrologger.Logger
+
+	// Interval is the minimum duration between two drift detections of a
+	// stack.
+	Interval time.Duration
+	// Remediation enables re-applying the template of drifted stacks.
+	Remediation bool
+}
+
+// Resource periodically detects drift of the guest, host-pre and host-post
+// stacks of a cluster. Drift detection runs asynchronously in CloudFormation.
+// The resource therefore starts a detection in one reconciliation and reports
+// its result in the status of the custom object in a later one.
+type Resource struct {
+	g8sClient          versioned.Interface
+	hostCloudFormation *cloudformation.CloudFormation
+	logger             micrologger.Logger
+
+	interval    time.Duration
+	remediation bool
+
+	// detections tracks the drift detections by stack name.
+	detections map[string]detection
+	mutex      sync.Mutex
+}
+
+type detection struct {
+	// id is the ID of the running drift detection. It is empty in case no
+	// detection is running.
+	id string
+	// finished is the time the previous drift detection finished.
+	finished time.Time
+	// drifted is whether the previous drift detection found drift.
+	drifted bool
+}
+
+func New(config Config) (*Resource, error) {
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	}
+	if config.HostCloudFormation == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.HostCloudFormation must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.Interval <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Interval must be greater than 0", config)
+	}
+
+	r := &Resource{
+		g8sClient:          config.G8sClient,
+		hostCloudFormation: config.HostCloudFormation,
+		logger:             config.Logger,
+
+		interval:    config.Interval,
+		remediation: config.Remediation,
+
+		detections: map[string]detection{},
+	}
+
+	return r, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
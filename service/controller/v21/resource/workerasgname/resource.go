@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 
@@ -40,7 +41,8 @@ func (r *Resource) Name() string {
 	return Name
 }
 
-// EnsureCreated retrieves worker ASG name from CF stack when it is ready.
+// EnsureCreated retrieves the worker ASG name and the ASG names of all node
+// pools from CF stack when it is ready.
 func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 	customObject, err := key.ToCustomObject(obj)
 	if err != nil {
@@ -52,11 +54,13 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 		return microerror.Mask(err)
 	}
 
-	var workerASGName string
+	var workerASGNames []string
+	var stackOutputs []*cloudformation.Output
 	{
 		r.logger.LogCtx(ctx, "level", "debug", "message", "finding the guest cluster worker ASG name in the cloud formation stack")
 
-		stackOutputs, stackStatus, err := controllerCtx.CloudFormation.DescribeOutputsAndStatus(key.MainGuestStackName(customObject))
+		var stackStatus string
+		stackOutputs, stackStatus, err = controllerCtx.CloudFormation.DescribeOutputsAndStatus(key.MainGuestStackName(customObject))
 		if cloudformationservice.IsStackNotFound(err) {
 			r.logger.LogCtx(ctx, "level", "debug", "message", "did not find the guest cluster worker ASG name in the cloud formation stack")
 			r.logger.LogCtx(ctx, "level", "debug", "message", "the guest cluster main stack is not yet created")
@@ -73,7 +77,7 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 			return microerror.Mask(err)
 		}
 
-		workerASGName, err = controllerCtx.CloudFormation.GetOutputValue(stackOutputs, key.WorkerASGKey)
+		workerASGName, err := controllerCtx.CloudFormation.GetOutputValue(stackOutputs, key.WorkerASGKey)
 		if cloudformationservice.IsOutputNotFound(err) {
 			// Since we are transitioning between versions we will have situations in
 			// which old clusters are updated to new versions and miss the ASG name in
@@ -93,9 +97,25 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 		}
 
 		r.logger.LogCtx(ctx, "level", "debug", "message", "found the guest cluster worker ASG name in the cloud formation stack")
+
+		workerASGNames = append(workerASGNames, workerASGName)
+	}
+
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "finding the guest cluster node pool ASG names in the cloud formation stack")
+
+		for _, o := range stackOutputs {
+			if key.NodePoolNameFromASGKey(*o.OutputKey) == "" {
+				continue
+			}
+
+			workerASGNames = append(workerASGNames, *o.OutputValue)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found %d guest cluster node pool ASG names in the cloud formation stack", len(workerASGNames)-1))
 	}
 
-	controllerCtx.Status.Drainer.WorkerASGName = workerASGName
+	controllerCtx.Status.Drainer.WorkerASGNames = workerASGNames
 
 	return nil
 }
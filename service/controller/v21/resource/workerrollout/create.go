@@ -0,0 +1,266 @@
+package workerrollout
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/errors/guest"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/controller/context/updateallowedcontext"
+	"github.com/giantswarm/tenantcluster"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	awsclient "github.com/giantswarm/aws-operator/client/aws"
+	cloudformationservice "github.com/giantswarm/aws-operator/service/controller/v21/cloudformation"
+	"github.com/giantswarm/aws-operator/service/controller/v21/controllercontext"
+	"github.com/giantswarm/aws-operator/service/controller/v21/key"
+)
+
+// EnsureCreated replaces the next batch of outdated worker instances of each
+// worker ASG once all instances of the previous batch are ready in the tenant
+// cluster, and records the state of the rollouts in the CR status.
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if !customObject.Status.Cluster.HasCreatedCondition() {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not rolling out worker instances because the cluster is not yet created")
+		r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+		return nil
+	}
+	if !updateallowedcontext.IsUpdateAllowed(ctx) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not rolling out worker instances because updates are not allowed")
+		r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+		return nil
+	}
+
+	controllerCtx, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	var asgNames []string
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "finding the guest cluster worker ASG names in the cloud formation stack")
+
+		outputs, stackStatus, err := controllerCtx.CloudFormation.DescribeOutputsAndStatus(key.MainGuestStackName(customObject))
+		if cloudformationservice.IsStackNotFound(err) {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "did not find the guest cluster worker ASG names in the cloud formation stack")
+			r.logger.LogCtx(ctx, "level", "debug", "message", "the guest cluster main stack is not yet created")
+			r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+			return nil
+
+		} else if cloudformationservice.IsOutputsNotAccessible(err) {
+			// The stack being updated most likely changes the launch templates of
+			// the ASGs. We wait for the update to finish before replacing any
+			// instances.
+			r.logger.LogCtx(ctx, "level", "debug", "message", "did not find the guest cluster worker ASG names in the cloud formation stack")
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("the guest cluster main stack output values are not accessible due to stack status '%s'", stackStatus))
+			r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+			return nil
+
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, o := range outputs {
+			if *o.OutputKey == key.WorkerASGKey || key.NodePoolNameFromASGKey(*o.OutputKey) != "" {
+				asgNames = append(asgNames, *o.OutputValue)
+			}
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found %d guest cluster worker ASG names in the cloud formation stack", len(asgNames)))
+	}
+
+	if len(asgNames) == 0 {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+		return nil
+	}
+
+	var ready map[string]bool
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "finding ready nodes in the tenant cluster")
+
+		k8sClient, err := r.tenantCluster.NewK8sClient(ctx, key.ClusterID(customObject), key.ClusterAPIEndpoint(customObject))
+		if tenantcluster.IsTimeout(err) {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "did not create Kubernetes client for tenant cluster")
+			r.logger.LogCtx(ctx, "level", "debug", "message", "waiting for certificates timed out")
+			r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+			return nil
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		list, err := k8sClient.CoreV1().Nodes().List(metav1.ListOptions{})
+		if guest.IsAPINotAvailable(err) {
+			// Without knowing which nodes are ready we must not replace any
+			// instances.
+			r.logger.LogCtx(ctx, "level", "debug", "message", "tenant cluster is not available")
+			r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+			return nil
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		ready = readyInstances(list.Items)
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found %d ready nodes in the tenant cluster", len(ready)))
+	}
+
+	var groups []*autoscaling.Group
+	{
+		i := &autoscaling.DescribeAutoScalingGroupsInput{
+			AutoScalingGroupNames: aws.StringSlice(asgNames),
+		}
+
+		o, err := controllerCtx.AWSClient.AutoScaling.DescribeAutoScalingGroups(i)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		groups = o.AutoScalingGroups
+	}
+
+	latestVersions, err := latestLaunchTemplateVersions(controllerCtx.AWSClient, groups)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	current := map[string]v1alpha1.AWSConfigStatusAWSWorkerRollout{}
+	for _, c := range customObject.Status.AWS.WorkerRollouts {
+		current[c.ASGName] = c
+	}
+
+	var rollouts []v1alpha1.AWSConfigStatusAWSWorkerRollout
+	for _, g := range groups {
+		asgName := *g.AutoScalingGroupName
+
+		var instances []instance
+		for _, i := range g.Instances {
+			instances = append(instances, instance{
+				id:        *i.InstanceId,
+				inService: *i.LifecycleState == autoscaling.LifecycleStateInService,
+				outdated:  isOutdated(i, latestVersions),
+				ready:     ready[*i.InstanceId],
+			})
+		}
+
+		rollout, terminate := nextBatch(current[asgName], asgName, instances, time.Now(), r.batchTimeout)
+		if rollout.Paused && !current[asgName].Paused {
+			r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("pausing rollout of ASG %#q: %s", asgName, rollout.Reason))
+		}
+
+		for _, id := range terminate {
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("terminating outdated worker instance %#q of ASG %#q", id, asgName))
+
+			i := &autoscaling.TerminateInstanceInAutoScalingGroupInput{
+				InstanceId:                     aws.String(id),
+				ShouldDecrementDesiredCapacity: aws.Bool(false),
+			}
+
+			_, err := controllerCtx.AWSClient.AutoScaling.TerminateInstanceInAutoScalingGroup(i)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("terminated outdated worker instance %#q of ASG %#q", id, asgName))
+		}
+
+		rollouts = append(rollouts, rollout)
+	}
+
+	rollouts = activeRollouts(rollouts)
+
+	if reflect.DeepEqual(rollouts, activeRollouts(customObject.Status.AWS.WorkerRollouts)) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "CR status already contains worker rollouts")
+		return nil
+	}
+
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "updating CR status with worker rollouts")
+
+		newObj, err := r.g8sClient.ProviderV1alpha1().AWSConfigs(customObject.GetNamespace()).Get(customObject.GetName(), metav1.GetOptions{})
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		newObj.Status.AWS.WorkerRollouts = rollouts
+
+		_, err = r.g8sClient.ProviderV1alpha1().AWSConfigs(newObj.GetNamespace()).UpdateStatus(newObj)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "updated CR status with worker rollouts")
+	}
+
+	return nil
+}
+
+// latestLaunchTemplateVersions returns the latest version of the launch
+// templates of the instances of the given ASGs by launch template ID. The
+// guest main stack always configures the ASGs with the latest version.
+func latestLaunchTemplateVersions(clients awsclient.Clients, groups []*autoscaling.Group) (map[string]string, error) {
+	var ids []*string
+	{
+		seen := map[string]bool{}
+		for _, g := range groups {
+			for _, i := range g.Instances {
+				if i.LaunchTemplate == nil || i.LaunchTemplate.LaunchTemplateId == nil {
+					continue
+				}
+				if seen[*i.LaunchTemplate.LaunchTemplateId] {
+					continue
+				}
+
+				seen[*i.LaunchTemplate.LaunchTemplateId] = true
+				ids = append(ids, i.LaunchTemplate.LaunchTemplateId)
+			}
+		}
+	}
+
+	versions := map[string]string{}
+	if len(ids) == 0 {
+		return versions, nil
+	}
+
+	i := &ec2.DescribeLaunchTemplatesInput{
+		LaunchTemplateIds: ids,
+	}
+
+	o, err := clients.EC2.DescribeLaunchTemplates(i)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	for _, t := range o.LaunchTemplates {
+		versions[*t.LaunchTemplateId] = strconv.FormatInt(*t.LatestVersionNumber, 10)
+	}
+
+	return versions, nil
+}
+
+// isOutdated returns true in case the given instance was not launched with the
+// latest version of its launch template. Instances launched from a launch
+// configuration predate the launch templates and are outdated as well.
+func isOutdated(i *autoscaling.Instance, latestVersions map[string]string) bool {
+	if i.LaunchTemplate == nil || i.LaunchTemplate.LaunchTemplateId == nil || i.LaunchTemplate.Version == nil {
+		return true
+	}
+
+	latest, ok := latestVersions[*i.LaunchTemplate.LaunchTemplateId]
+	if !ok {
+		return false
+	}
+
+	return *i.LaunchTemplate.Version != latest
+}
@@ -0,0 +1,11 @@
+package workerrollout
+
+import (
+	"context"
+)
+
+// EnsureDeleted is a no-op, because worker instances of deleted clusters are
+// removed together with the guest main stack.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	return nil
+}
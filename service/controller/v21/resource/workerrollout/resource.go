@@ -0,0 +1,73 @@
+package workerrollout
+
+import (
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/clientset/versioned"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/giantswarm/tenantcluster"
+)
+
+const (
+	Name = "workerrolloutv21"
+)
+
+const (
+	// DefaultBatchTimeout is the batch timeout used in case the installation
+	// does not configure one.
+	DefaultBatchTimeout = 20 * time.Minute
+)
+
+type Config struct {
+	G8sClient     versioned.Interface
+	Logger        micrologger.Logger
+	TenantCluster tenantcluster.Interface
+
+	// BatchTimeout is the duration the instances of a batch have to become
+	// ready in the tenant cluster before the rollout is paused.
+	BatchTimeout time.Duration
+}
+
+// Resource replaces outdated worker instances in batches. In contrast to the
+// rolling update policy of the ASGs, the next batch is only started once all
+// nodes of the previous batch are Ready in the tenant cluster. In case a batch
+// does not become ready within the batch timeout, the rollout is paused and
+// reported in the CR status.
+type Resource struct {
+	g8sClient     versioned.Interface
+	logger        micrologger.Logger
+	tenantCluster tenantcluster.Interface
+
+	batchTimeout time.Duration
+}
+
+func New(config Config) (*Resource, error) {
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.TenantCluster == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.TenantCluster must not be empty", config)
+	}
+
+	if config.BatchTimeout <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.BatchTimeout must be greater than 0", config)
+	}
+
+	r := &Resource{
+		g8sClient:     config.G8sClient,
+		logger:        config.Logger,
+		tenantCluster: config.TenantCluster,
+
+		batchTimeout: config.BatchTimeout,
+	}
+
+	return r, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
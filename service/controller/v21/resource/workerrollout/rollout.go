@@ -0,0 +1,136 @@
+package workerrollout
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// maxBatchSizeRatio is the ratio of instances of an ASG replaced in one
+	// batch. It matches the ratio used for the rolling update policy of the
+	// ASGs.
+	maxBatchSizeRatio = 0.3
+)
+
+// instance is a worker instance of an ASG as seen by the rollout.
+type instance struct {
+	id string
+	// inService is true in case the lifecycle state of the instance is
+	// InService. Instances being launched or terminated are not in service.
+	inService bool
+	// outdated is true in case the instance was not launched with the latest
+	// version of the launch template of the ASG.
+	outdated bool
+	// ready is true in case the node of the instance is Ready in the tenant
+	// cluster.
+	ready bool
+}
+
+// nextBatch computes the rollout state of the given ASG and the instances to
+// terminate next. Instances are only terminated in case all up to date
+// instances are ready and no instance is being launched or terminated. In
+// case the current batch does not become ready within the batch timeout, the
+// rollout is paused until it does.
+func nextBatch(current v1alpha1.AWSConfigStatusAWSWorkerRollout, asgName string, instances []instance, now time.Time, batchTimeout time.Duration) (v1alpha1.AWSConfigStatusAWSWorkerRollout, []string) {
+	var outdated []string
+	var pending []string
+	for _, i := range instances {
+		if i.inService && i.outdated {
+			outdated = append(outdated, i.id)
+		}
+		if !i.inService || (!i.outdated && !i.ready) {
+			pending = append(pending, i.id)
+		}
+	}
+	sort.Strings(outdated)
+	sort.Strings(pending)
+
+	rollout := v1alpha1.AWSConfigStatusAWSWorkerRollout{
+		ASGName:           asgName,
+		BatchStartTime:    current.BatchStartTime,
+		OutdatedInstances: len(outdated),
+	}
+
+	if len(pending) != 0 {
+		// Instances which are not ready without the rollout having started a
+		// batch are launched by the ASG itself, e.g. when scaling out. They
+		// delay the rollout but are not subject to the batch timeout.
+		if !current.BatchStartTime.IsZero() && now.Sub(current.BatchStartTime.Time) > batchTimeout {
+			rollout.Paused = true
+			rollout.Reason = fmt.Sprintf("instances %s did not become ready within %s", strings.Join(pending, ", "), batchTimeout)
+		}
+
+		return rollout, nil
+	}
+
+	// All instances are up to date and ready, so the rollout is done.
+	if len(outdated) == 0 {
+		return v1alpha1.AWSConfigStatusAWSWorkerRollout{ASGName: asgName}, nil
+	}
+
+	batchSize := int(math.Floor(float64(len(instances)) * maxBatchSizeRatio))
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	if batchSize > len(outdated) {
+		batchSize = len(outdated)
+	}
+
+	rollout.BatchStartTime = v1alpha1.DeepCopyTime{Time: now}
+
+	return rollout, outdated[:batchSize]
+}
+
+// readyInstances returns the IDs of the EC2 instances of all Ready nodes. The
+// instance ID is the last segment of the provider ID of a node, e.g.
+// aws:///eu-central-1a/i-0123456789abcdef0.
+func readyInstances(nodes []corev1.Node) map[string]bool {
+	ready := map[string]bool{}
+
+	for _, n := range nodes {
+		if n.Spec.ProviderID == "" {
+			continue
+		}
+
+		var isReady bool
+		for _, c := range n.Status.Conditions {
+			if c.Type == corev1.NodeReady && c.Status == corev1.ConditionTrue {
+				isReady = true
+				break
+			}
+		}
+		if !isReady {
+			continue
+		}
+
+		segments := strings.Split(n.Spec.ProviderID, "/")
+		ready[segments[len(segments)-1]] = true
+	}
+
+	return ready
+}
+
+// activeRollouts returns the rollout state of the ASGs with outdated
+// instances or a batch in progress, sorted by ASG name.
+func activeRollouts(rollouts []v1alpha1.AWSConfigStatusAWSWorkerRollout) []v1alpha1.AWSConfigStatusAWSWorkerRollout {
+	var active []v1alpha1.AWSConfigStatusAWSWorkerRollout
+	for _, r := range rollouts {
+		if r.OutdatedInstances == 0 && r.BatchStartTime.IsZero() {
+			continue
+		}
+
+		active = append(active, r)
+	}
+
+	sort.Slice(active, func(i, j int) bool {
+		return active[i].ASGName < active[j].ASGName
+	})
+
+	return active
+}
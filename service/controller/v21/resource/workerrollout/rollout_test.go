@@ -0,0 +1,198 @@
+package workerrollout
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_nextBatch(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2019, 1, 1, 12, 0, 0, 0, time.UTC)
+	batchTimeout := 20 * time.Minute
+
+	testCases := []struct {
+		description       string
+		current           v1alpha1.AWSConfigStatusAWSWorkerRollout
+		instances         []instance
+		expectedRollout   v1alpha1.AWSConfigStatusAWSWorkerRollout
+		expectedTerminate []string
+	}{
+		{
+			description: "case 0: all instances up to date",
+			instances: []instance{
+				{id: "i-1", inService: true, ready: true},
+				{id: "i-2", inService: true, ready: true},
+			},
+			expectedRollout: v1alpha1.AWSConfigStatusAWSWorkerRollout{ASGName: "asg"},
+		},
+		{
+			description: "case 1: start the first batch",
+			instances: []instance{
+				{id: "i-4", inService: true, outdated: true, ready: true},
+				{id: "i-3", inService: true, outdated: true, ready: true},
+				{id: "i-2", inService: true, outdated: true, ready: true},
+				{id: "i-1", inService: true, outdated: true, ready: true},
+				{id: "i-0", inService: true, outdated: true, ready: true},
+				{id: "i-5", inService: true, outdated: true, ready: true},
+				{id: "i-6", inService: true, outdated: true, ready: true},
+			},
+			expectedRollout: v1alpha1.AWSConfigStatusAWSWorkerRollout{
+				ASGName:           "asg",
+				BatchStartTime:    v1alpha1.DeepCopyTime{Time: now},
+				OutdatedInstances: 7,
+			},
+			expectedTerminate: []string{"i-0", "i-1"},
+		},
+		{
+			description: "case 2: replacement of the batch is not yet ready",
+			current: v1alpha1.AWSConfigStatusAWSWorkerRollout{
+				ASGName:           "asg",
+				BatchStartTime:    v1alpha1.DeepCopyTime{Time: now.Add(-5 * time.Minute)},
+				OutdatedInstances: 2,
+			},
+			instances: []instance{
+				{id: "i-1", inService: true, outdated: true, ready: true},
+				{id: "i-2", inService: true, ready: false},
+			},
+			expectedRollout: v1alpha1.AWSConfigStatusAWSWorkerRollout{
+				ASGName:           "asg",
+				BatchStartTime:    v1alpha1.DeepCopyTime{Time: now.Add(-5 * time.Minute)},
+				OutdatedInstances: 1,
+			},
+		},
+		{
+			description: "case 3: replacement of the batch did not become ready in time",
+			current: v1alpha1.AWSConfigStatusAWSWorkerRollout{
+				ASGName:           "asg",
+				BatchStartTime:    v1alpha1.DeepCopyTime{Time: now.Add(-30 * time.Minute)},
+				OutdatedInstances: 2,
+			},
+			instances: []instance{
+				{id: "i-1", inService: true, outdated: true, ready: true},
+				{id: "i-2", inService: true, ready: false},
+			},
+			expectedRollout: v1alpha1.AWSConfigStatusAWSWorkerRollout{
+				ASGName:           "asg",
+				BatchStartTime:    v1alpha1.DeepCopyTime{Time: now.Add(-30 * time.Minute)},
+				OutdatedInstances: 1,
+				Paused:            true,
+				Reason:            "instances i-2 did not become ready within 20m0s",
+			},
+		},
+		{
+			description: "case 4: paused rollout continues once the batch is ready",
+			current: v1alpha1.AWSConfigStatusAWSWorkerRollout{
+				ASGName:           "asg",
+				BatchStartTime:    v1alpha1.DeepCopyTime{Time: now.Add(-30 * time.Minute)},
+				OutdatedInstances: 1,
+				Paused:            true,
+				Reason:            "instances i-2 did not become ready within 20m0s",
+			},
+			instances: []instance{
+				{id: "i-1", inService: true, outdated: true, ready: true},
+				{id: "i-2", inService: true, ready: true},
+			},
+			expectedRollout: v1alpha1.AWSConfigStatusAWSWorkerRollout{
+				ASGName:           "asg",
+				BatchStartTime:    v1alpha1.DeepCopyTime{Time: now},
+				OutdatedInstances: 1,
+			},
+			expectedTerminate: []string{"i-1"},
+		},
+		{
+			description: "case 5: instance launched by the ASG is not subject to the batch timeout",
+			instances: []instance{
+				{id: "i-1", inService: true, outdated: true, ready: true},
+				{id: "i-2", inService: false},
+			},
+			expectedRollout: v1alpha1.AWSConfigStatusAWSWorkerRollout{
+				ASGName:           "asg",
+				OutdatedInstances: 1,
+			},
+		},
+		{
+			description: "case 6: last batch is ready",
+			current: v1alpha1.AWSConfigStatusAWSWorkerRollout{
+				ASGName:        "asg",
+				BatchStartTime: v1alpha1.DeepCopyTime{Time: now.Add(-5 * time.Minute)},
+			},
+			instances: []instance{
+				{id: "i-1", inService: true, ready: true},
+			},
+			expectedRollout: v1alpha1.AWSConfigStatusAWSWorkerRollout{ASGName: "asg"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			rollout, terminate := nextBatch(tc.current, "asg", tc.instances, now, batchTimeout)
+			if !reflect.DeepEqual(rollout, tc.expectedRollout) {
+				t.Fatalf("expected %#v got %#v", tc.expectedRollout, rollout)
+			}
+			if !reflect.DeepEqual(terminate, tc.expectedTerminate) {
+				t.Fatalf("expected %#v got %#v", tc.expectedTerminate, terminate)
+			}
+		})
+	}
+}
+
+func Test_readyInstances(t *testing.T) {
+	t.Parallel()
+	nodes := []corev1.Node{
+		{
+			Spec: corev1.NodeSpec{ProviderID: "aws:///eu-central-1a/i-1"},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{
+					{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+				},
+			},
+		},
+		{
+			Spec: corev1.NodeSpec{ProviderID: "aws:///eu-central-1b/i-2"},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{
+					{Type: corev1.NodeReady, Status: corev1.ConditionFalse},
+				},
+			},
+		},
+		{
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{
+					{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+				},
+			},
+		},
+	}
+
+	ready := readyInstances(nodes)
+
+	expected := map[string]bool{"i-1": true}
+	if !reflect.DeepEqual(ready, expected) {
+		t.Fatalf("expected %#v got %#v", expected, ready)
+	}
+}
+
+func Test_activeRollouts(t *testing.T) {
+	t.Parallel()
+	rollouts := []v1alpha1.AWSConfigStatusAWSWorkerRollout{
+		{ASGName: "cluster-al9qy-worker-gpu", OutdatedInstances: 1},
+		{ASGName: "cluster-al9qy-worker-memory"},
+		{ASGName: "cluster-al9qy-worker", BatchStartTime: v1alpha1.DeepCopyTime{Time: time.Now()}},
+	}
+
+	active := activeRollouts(rollouts)
+
+	var asgNames []string
+	for _, a := range active {
+		asgNames = append(asgNames, a.ASGName)
+	}
+
+	expected := []string{"cluster-al9qy-worker", "cluster-al9qy-worker-gpu"}
+	if !reflect.DeepEqual(asgNames, expected) {
+		t.Fatalf("expected %#v got %#v", expected, asgNames)
+	}
+}
@@ -131,12 +131,22 @@ rkt run \
     for encKey in $(find /etc/kubernetes/encryption -name "*.enc"); do
       echo decrypting $encKey
       f=$(mktemp $encKey.XXXXXXXX)
+{{- if eq .EncrypterType "ssm" }}
+      /usr/bin/aws \
+        --region {{.AWS.Region}} ssm get-parameter \
+        --name "$(cat $encKey)" \
+        --with-decryption \
+        --output text \
+        --query Parameter.Value \
+      | base64 -d > $f
+{{- else }}
       /usr/bin/aws \
         --region {{.AWS.Region}} kms decrypt \
         --ciphertext-blob fileb://$encKey \
         --output text \
         --query Plaintext \
       | base64 -d > $f
+{{- end }}
       mv -f $f ${encKey%.enc}
     done;
     echo done.'
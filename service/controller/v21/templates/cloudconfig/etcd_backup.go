@@ -0,0 +1,81 @@
+package cloudconfig
+
+// EtcdBackupScript takes a snapshot of the local etcd member, encrypts it with
+// the etcd backup passphrase of the cluster and uploads it to the S3 bucket of
+// the cluster. Snapshot names start with a UTC timestamp so they sort in the
+// order they were taken.
+//
+// Snapshots are not encrypted with the configured encrypter backend directly.
+// The encrypter.Interface only runs in the operator, which never sees the
+// snapshots taken on the masters. On top of that KMS encrypts at most 4KB and
+// SSM parameters hold at most 8KB, while snapshots easily grow to hundreds of
+// megabytes. Instead the passphrase is delivered to the masters like all other
+// key assets, i.e. encrypted with the encrypter backend and decrypted by the
+// decrypt-keys-assets service on boot.
+const EtcdBackupScript = `#!/bin/bash -e
+snapshot_dir=/var/lib/etcd-backup
+passphrase_file=/etc/kubernetes/encryption/etcd-backup-passphrase
+snapshot_name=$(date -u +%Y%m%d%H%M%S)-$(hostname).db.enc
+
+mkdir -p ${snapshot_dir}
+trap "rm -f ${snapshot_dir}/snapshot.db ${snapshot_dir}/snapshot.db.enc" EXIT
+
+echo saving etcd snapshot
+/usr/bin/docker run --rm \
+  -v /etc/kubernetes/ssl/etcd/:/etc/etcd \
+  -v ${snapshot_dir}:${snapshot_dir} \
+  --net=host \
+  -e ETCDCTL_API=3 \
+  {{ .EtcdBackup.EtcdImage }} \
+  etcdctl \
+  --endpoints https://127.0.0.1:2379 \
+  --cacert /etc/etcd/server-ca.pem \
+  --cert /etc/etcd/server-crt.pem \
+  --key /etc/etcd/server-key.pem \
+  snapshot save ${snapshot_dir}/snapshot.db
+
+echo encrypting etcd snapshot
+openssl enc -aes-256-cbc -md sha256 -salt \
+  -pass file:${passphrase_file} \
+  -in ${snapshot_dir}/snapshot.db \
+  -out ${snapshot_dir}/snapshot.db.enc
+
+echo uploading etcd snapshot ${snapshot_name}
+rkt run \
+  --volume=backup,kind=host,source=${snapshot_dir},readOnly=true \
+  --mount=volume=backup,target=${snapshot_dir} \
+  --uuid-file-save=/var/run/coreos/etcd-backup.uuid \
+  --volume=dns,kind=host,source=/etc/resolv.conf,readOnly=true --mount volume=dns,target=/etc/resolv.conf \
+  --net=host \
+  --trust-keys-from-https \
+  quay.io/coreos/awscli:025a357f05242fdad6a81e8a6b520098aa65a600 --exec=/usr/bin/aws -- \
+    --region {{ .AWS.Region }} s3 cp \
+    ${snapshot_dir}/snapshot.db.enc \
+    s3://{{ .EtcdBackup.Bucket }}/{{ .EtcdBackup.Prefix }}${snapshot_name}
+
+rkt rm --uuid-file=/var/run/coreos/etcd-backup.uuid || :
+echo done.
+`
+
+const EtcdBackupService = `
+[Unit]
+Description=Upload an encrypted etcd snapshot to S3
+Requires=docker.service etcd3.service
+After=docker.service etcd3.service decrypt-keys-assets.service
+
+[Service]
+Type=oneshot
+ExecStart=/opt/bin/etcd-backup
+`
+
+const EtcdBackupTimer = `
+[Unit]
+Description=Periodically upload an encrypted etcd snapshot to S3
+
+[Timer]
+OnBootSec={{ .EtcdBackup.Interval }}
+OnUnitActiveSec={{ .EtcdBackup.Interval }}
+
+[Install]
+WantedBy=timers.target
+`
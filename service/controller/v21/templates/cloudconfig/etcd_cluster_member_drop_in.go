@@ -0,0 +1,35 @@
+package cloudconfig
+
+// EtcdClusterMemberDropIn overrides the single member etcd cluster configured
+// by k8scloudconfig with the member of the master it is written to. It is only
+// used for highly available control planes. The IMAGE, NAME and DEFAULT_IPV4
+// environment variables are defined by the etcd3 unit itself.
+const EtcdClusterMemberDropIn = `[Service]
+ExecStart=
+ExecStart=/usr/bin/docker run \
+    -v /etc/ssl/certs/ca-certificates.crt:/etc/ssl/certs/ca-certificates.crt \
+    -v /etc/kubernetes/ssl/etcd/:/etc/etcd \
+    -v /var/lib/etcd/:/var/lib/etcd  \
+    --net=host  \
+    --name $NAME \
+    $IMAGE \
+    etcd \
+    --name {{ .Name }} \
+    --trusted-ca-file /etc/etcd/server-ca.pem \
+    --cert-file /etc/etcd/server-crt.pem \
+    --key-file /etc/etcd/server-key.pem\
+    --client-cert-auth=true \
+    --peer-trusted-ca-file /etc/etcd/server-ca.pem \
+    --peer-cert-file /etc/etcd/server-crt.pem \
+    --peer-key-file /etc/etcd/server-key.pem \
+    --peer-client-cert-auth=true \
+    --advertise-client-urls=https://{{ .ClientDomain }}:{{ .ClientPort }} \
+    --initial-advertise-peer-urls=https://{{ .PeerDomain }}:{{ .PeerPort }} \
+    --listen-client-urls=https://0.0.0.0:{{ .ClientPort }} \
+    --listen-peer-urls=https://${DEFAULT_IPV4}:{{ .PeerPort }} \
+    --initial-cluster-token k8s-etcd-cluster \
+    --initial-cluster {{ .InitialCluster }} \
+    --initial-cluster-state new \
+    --data-dir=/var/lib/etcd \
+    --enable-v2
+`
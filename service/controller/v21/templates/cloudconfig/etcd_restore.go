@@ -0,0 +1,142 @@
+package cloudconfig
+
+// EtcdRestoreScript restores the etcd data directory from the latest snapshot
+// uploaded by EtcdBackupScript. It is only executed when the etcd volume does
+// not contain any data yet, e.g. after the master and its volumes got
+// replaced. When there is no snapshot, like on the very first boot of a new
+// cluster, etcd starts with an empty data directory.
+//
+// Masters of highly available control planes get the member they restore from
+// the environment file rendered from EtcdRestoreEnvironment. They only restore
+// a snapshot when none of their peers is healthy, i.e. when the whole etcd
+// cluster got lost. Otherwise the member rejoins its peers. Single masters
+// restore the member etcd0 configured by k8scloudconfig.
+const EtcdRestoreScript = `#!/bin/bash -e
+restore_dir=/var/lib/etcd-restore
+passphrase_file=/etc/kubernetes/encryption/etcd-backup-passphrase
+
+ETCD_MEMBER_NAME=etcd0
+ETCD_MEMBER_PEER_URL=https://127.0.0.1:2380
+ETCD_INITIAL_CLUSTER=etcd0=https://127.0.0.1:2380
+ETCD_PEER_ENDPOINTS=""
+if [ -f {{ .EtcdBackup.RestoreEnvironmentPath }} ]; then
+  . {{ .EtcdBackup.RestoreEnvironmentPath }}
+fi
+
+for endpoint in ${ETCD_PEER_ENDPOINTS}; do
+  if /usr/bin/docker run --rm \
+    -v /etc/kubernetes/ssl/etcd/:/etc/etcd \
+    --net=host \
+    -e ETCDCTL_API=3 \
+    {{ .EtcdBackup.EtcdImage }} \
+    etcdctl \
+    --endpoints ${endpoint} \
+    --cacert /etc/etcd/server-ca.pem \
+    --cert /etc/etcd/server-crt.pem \
+    --key /etc/etcd/server-key.pem \
+    endpoint health; then
+    echo etcd peer ${endpoint} is healthy, ${ETCD_MEMBER_NAME} rejoins its peers instead of restoring a snapshot
+    exit 0
+  fi
+done
+
+mkdir -p ${restore_dir}
+trap "rm -rf ${restore_dir}" EXIT
+
+download_latest_snapshot() {
+  rkt run \
+    --volume=restore,kind=host,source=${restore_dir},readOnly=false \
+    --mount=volume=restore,target=${restore_dir} \
+    --uuid-file-save=/var/run/coreos/etcd-restore.uuid \
+    --volume=dns,kind=host,source=/etc/resolv.conf,readOnly=true --mount volume=dns,target=/etc/resolv.conf \
+    --net=host \
+    --trust-keys-from-https \
+    quay.io/coreos/awscli:025a357f05242fdad6a81e8a6b520098aa65a600 --exec=/bin/bash -- \
+      -ec \
+      'latest=$(/usr/bin/aws --region {{ .AWS.Region }} s3 ls s3://{{ .EtcdBackup.Bucket }}/{{ .EtcdBackup.Prefix }} | awk "{print \$NF}" | sort | tail -n 1)
+      if [ -z "$latest" ]; then
+        echo no etcd snapshot found
+        exit 0
+      fi
+      echo downloading etcd snapshot $latest
+      /usr/bin/aws --region {{ .AWS.Region }} s3 cp s3://{{ .EtcdBackup.Bucket }}/{{ .EtcdBackup.Prefix }}$latest '${restore_dir}'/snapshot.db.enc'
+  local result=$?
+
+  rkt rm --uuid-file=/var/run/coreos/etcd-restore.uuid || :
+  return ${result}
+}
+
+for i in $(seq 10); do
+  if download_latest_snapshot; then
+    break
+  fi
+  if [ "$i" -eq 10 ]; then
+    echo failed to download etcd snapshot
+    exit 1
+  fi
+  echo failed to download etcd snapshot, retrying...
+  sleep 15
+done
+
+if [ ! -f ${restore_dir}/snapshot.db.enc ]; then
+  echo nothing to restore
+  exit 0
+fi
+
+echo decrypting etcd snapshot
+openssl enc -d -aes-256-cbc -md sha256 \
+  -pass file:${passphrase_file} \
+  -in ${restore_dir}/snapshot.db.enc \
+  -out ${restore_dir}/snapshot.db
+
+echo restoring etcd snapshot
+rm -rf /var/lib/etcd/restore
+/usr/bin/docker run --rm \
+  -v ${restore_dir}:${restore_dir} \
+  -v /var/lib/etcd/:/var/lib/etcd \
+  -e ETCDCTL_API=3 \
+  {{ .EtcdBackup.EtcdImage }} \
+  etcdctl snapshot restore ${restore_dir}/snapshot.db \
+  --name ${ETCD_MEMBER_NAME} \
+  --initial-cluster ${ETCD_INITIAL_CLUSTER} \
+  --initial-cluster-token k8s-etcd-cluster \
+  --initial-advertise-peer-urls ${ETCD_MEMBER_PEER_URL} \
+  --data-dir /var/lib/etcd/restore
+
+mv /var/lib/etcd/restore/member /var/lib/etcd/member
+rm -rf /var/lib/etcd/restore
+echo done.
+`
+
+const EtcdRestoreService = `
+[Unit]
+Description=Restore etcd data from the latest snapshot in S3
+Requires=docker.service var-lib-etcd.mount
+After=docker.service var-lib-etcd.mount decrypt-keys-assets.service
+Before=etcd3.service
+ConditionPathExists=!/var/lib/etcd/member
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+ExecStart=/opt/bin/etcd-restore
+
+[Install]
+WantedBy=multi-user.target
+`
+
+// EtcdRestoreEnvironment configures the etcd member restored on the master of a
+// highly available control plane it is written to. ETCD_PEER_ENDPOINTS holds
+// the client URLs of all other members of the etcd cluster.
+const EtcdRestoreEnvironment = `ETCD_MEMBER_NAME={{ .Name }}
+ETCD_MEMBER_PEER_URL={{ .PeerURL }}
+ETCD_INITIAL_CLUSTER={{ .InitialCluster }}
+ETCD_PEER_ENDPOINTS="{{ .PeerEndpoints }}"
+`
+
+// EtcdRestoreDropIn makes etcd wait for the restore to finish and prevents it
+// from starting with an empty data directory when the restore failed.
+const EtcdRestoreDropIn = `[Unit]
+Requires=etcd-restore.service
+After=etcd-restore.service
+`
@@ -10,6 +10,21 @@ const Small = `{
         }
       ]
     }
+  }{{ if .Files }},
+  "storage": {
+    "files": [
+      {{- range $i, $f := .Files }}{{ if $i }},{{ end }}
+      {
+        "filesystem": "root",
+        "path": "{{ $f.Path }}",
+        "mode": 420,
+        "contents": {
+          "source": "{{ $f.Source }}"
+        }
+      }
+      {{- end }}
+    ]
   }
+  {{- end }}
 }
 `
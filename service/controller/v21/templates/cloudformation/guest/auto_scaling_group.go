@@ -2,6 +2,14 @@ package guest
 
 const AutoScalingGroup = `{{define "autoscaling_group"}}
 {{- $v := .Guest.AutoScalingGroup }}
+  {{- template "autoscaling_group_resource" $v }}
+  {{- range $p := $v.NodePools }}
+  {{- template "autoscaling_group_resource" $p }}
+  {{- end }}
+{{end}}
+
+{{define "autoscaling_group_resource"}}
+{{- $v := . }}
   {{ $v.ASGType }}AutoScalingGroup:
     Type: "AWS::AutoScaling::AutoScalingGroup"
     Properties:
@@ -13,19 +21,58 @@ const AutoScalingGroup = `{{define "autoscaling_group"}}
       {{- range $az := $v.WorkerAZs }}
         - {{ $az }}
       {{end}}
+      {{- if not $v.ClusterAutoscaler }}
       DesiredCapacity: {{ $v.ASGMinSize }}
+      {{- end }}
       MinSize: {{ $v.ASGMinSize }}
       MaxSize: {{ $v.ASGMaxSize }}
-      LaunchConfigurationName: !Ref {{ $v.ASGType }}LaunchConfiguration
+      {{- if $v.MixedInstances }}
+      MixedInstancesPolicy:
+        InstancesDistribution:
+          OnDemandBaseCapacity: {{ $v.InstanceDistribution.OnDemandBaseCapacity }}
+          OnDemandPercentageAboveBaseCapacity: {{ $v.InstanceDistribution.OnDemandPercentageAboveBaseCapacity }}
+          SpotAllocationStrategy: {{ $v.InstanceDistribution.SpotAllocationStrategy }}
+        LaunchTemplate:
+          LaunchTemplateSpecification:
+            LaunchTemplateId: !Ref {{ $v.ASGType }}LaunchTemplate
+            Version: !GetAtt {{ $v.ASGType }}LaunchTemplate.LatestVersionNumber
+          Overrides:
+          {{- range $t := $v.InstanceDistribution.InstanceTypes }}
+          - InstanceType: {{ $t }}
+          {{- end }}
+      {{- else }}
+      LaunchTemplate:
+        LaunchTemplateId: !Ref {{ $v.ASGType }}LaunchTemplate
+        Version: !GetAtt {{ $v.ASGType }}LaunchTemplate.LatestVersionNumber
+      {{- end }}
+      {{- if $v.LoadBalancers }}
       LoadBalancerNames:
-        - !Ref IngressLoadBalancer
+      {{- range $l := $v.LoadBalancers }}
+        - !Ref {{ $l }}
+      {{- end }}
+      {{- end }}
+      {{- if $v.TargetGroups }}
+      TargetGroupARNs:
+      {{- range $t := $v.TargetGroups }}
+        - !Ref {{ $t }}
+      {{- end }}
+      {{- end }}
       HealthCheckGracePeriod: {{ $v.HealthCheckGracePeriod }}
       MetricsCollection:
         - Granularity: "1Minute"
       Tags:
         - Key: Name
-          Value: {{ $v.ClusterID }}-{{ $v.ASGType }}
+          Value: {{ $v.ClusterID }}-{{ $v.Name }}
           PropagateAtLaunch: true
+        {{- if $v.ClusterAutoscaler }}
+        - Key: k8s.io/cluster-autoscaler/enabled
+          Value: "true"
+          PropagateAtLaunch: false
+        - Key: k8s.io/cluster-autoscaler/{{ $v.ClusterID }}
+          Value: owned
+          PropagateAtLaunch: false
+        {{- end }}
+    {{- if $v.RollingUpdate }}
     UpdatePolicy:
       AutoScalingRollingUpdate:
         # minimum amount of instances that must always be running during a rolling update
@@ -34,4 +81,5 @@ const AutoScalingGroup = `{{define "autoscaling_group"}}
         MaxBatchSize: {{ $v.MaxBatchSize }}
         # after creating a new instance, pause operations on the ASG for this amount of time
         PauseTime: {{ $v.RollingUpdatePauseTime }}
+    {{- end }}
 {{end}}`
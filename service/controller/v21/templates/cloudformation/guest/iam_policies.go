@@ -29,6 +29,11 @@ const IAMPolicies = `{{define "iam_policies"}}
           - Effect: "Allow"
             Action: "kms:Decrypt"
             Resource: "{{ $v.KMSKeyARN }}"
+{{ end }}
+{{ if $v.SSMParameterARN }}
+          - Effect: "Allow"
+            Action: "ssm:GetParameter"
+            Resource: "{{ $v.SSMParameterARN }}"
 {{ end }}
           - Effect: "Allow"
             Action:
@@ -44,6 +49,10 @@ const IAMPolicies = `{{define "iam_policies"}}
             Action: "s3:GetObject"
             Resource: "arn:{{ $v.RegionARN }}:s3:::{{ $v.S3Bucket }}/*"
 
+          - Effect: "Allow"
+            Action: "s3:PutObject"
+            Resource: "arn:{{ $v.RegionARN }}:s3:::{{ $v.S3Bucket }}/{{ $v.EtcdBackupPrefix }}*"
+
           - Effect: "Allow"
             Action: "elasticloadbalancing:*"
             Resource: "*"
@@ -108,6 +117,11 @@ const IAMPolicies = `{{define "iam_policies"}}
           - Effect: "Allow"
             Action: "kms:Decrypt"
             Resource: "{{ $v.KMSKeyARN }}"
+{{ end }}
+{{ if $v.SSMParameterARN }}
+          - Effect: "Allow"
+            Action: "ssm:GetParameter"
+            Resource: "{{ $v.SSMParameterARN }}"
 {{ end }}
           - Effect: "Allow"
             Action:
@@ -134,6 +148,24 @@ const IAMPolicies = `{{define "iam_policies"}}
               - "ecr:BatchGetImage"
             Resource: "*"
 
+          - Effect: "Allow"
+            Action:
+              - "autoscaling:DescribeAutoScalingGroups"
+              - "autoscaling:DescribeAutoScalingInstances"
+              - "autoscaling:DescribeTags"
+              - "autoscaling:DescribeLaunchConfigurations"
+              - "ec2:DescribeLaunchTemplateVersions"
+            Resource: "*"
+{{ if $v.ClusterAutoscaler }}
+          - Effect: "Allow"
+            Action:
+              - "autoscaling:SetDesiredCapacity"
+              - "autoscaling:TerminateInstanceInAutoScalingGroup"
+            Resource: "*"
+            Condition:
+              StringEquals:
+                autoscaling:ResourceTag/giantswarm.io/cluster: "{{ $v.ClusterID }}"
+{{ end }}
   WorkerInstanceProfile:
     Type: "AWS::IAM::InstanceProfile"
     Properties:
@@ -2,59 +2,75 @@ package guest
 
 const Instance = `{{ define "instance" }}
 {{- $v := .Guest.Instance }}
-  {{ $v.Master.Instance.ResourceName }}:
+{{- range $m := $v.Masters }}
+  {{ $m.Instance.ResourceName }}:
     Type: "AWS::EC2::Instance"
     Description: Master instance
     DependsOn:
-    - {{ $v.Master.DockerVolume.ResourceName }}
-    - EtcdVolume
+    - {{ $m.DockerVolume.ResourceName }}
+    - {{ $m.EtcdVolume.ResourceName }}
     Properties:
-      AvailabilityZone: {{ $v.Master.AZ }}
+      AvailabilityZone: {{ $m.AZ }}
+      {{- if $m.RootVolume.EncryptionKeyARN }}
+      BlockDeviceMappings:
+      - DeviceName: {{ $m.RootVolume.DeviceName }}
+        Ebs:
+          DeleteOnTermination: true
+          Encrypted: true
+          KmsKeyId: {{ $m.RootVolume.EncryptionKeyARN }}
+      {{- end }}
       IamInstanceProfile: !Ref MasterInstanceProfile
-      ImageId: {{ $v.Image.ID }}
-      InstanceType: {{ $v.Master.Instance.Type }}
-      Monitoring: {{ $v.Master.Instance.Monitoring }}
+      ImageId: {{ $m.ImageID }}
+      InstanceType: {{ $m.Instance.Type }}
+      Monitoring: {{ $m.Instance.Monitoring }}
       SecurityGroupIds:
       - !Ref MasterSecurityGroup
-      SubnetId: !Ref {{ $v.Master.PrivateSubnet }}
-      UserData: {{ $v.Master.CloudConfig }}
+      SubnetId: !Ref {{ $m.PrivateSubnet }}
+      UserData: {{ $m.CloudConfig }}
       Tags:
       - Key: Name
         Value: {{ $v.Cluster.ID }}-master
-  {{ $v.Master.DockerVolume.ResourceName }}:
+  {{ $m.DockerVolume.ResourceName }}:
     Type: AWS::EC2::Volume
     Properties:
-{{ if eq $v.Master.EncrypterBackend "kms" }}
+{{ if $m.DockerVolume.EncryptionKeyARN }}
+      Encrypted: true
+      KmsKeyId: {{ $m.DockerVolume.EncryptionKeyARN }}
+{{ else if or (eq $m.EncrypterBackend "kms") (eq $m.EncrypterBackend "ssm") }}
       Encrypted: true
 {{ end }}
       Size: 50
       VolumeType: gp2
-      AvailabilityZone: {{ $v.Master.AZ }}
+      AvailabilityZone: {{ $m.AZ }}
       Tags:
       - Key: Name
-        Value: {{ $v.Master.DockerVolume.Name }}
-  EtcdVolume:
+        Value: {{ $m.DockerVolume.Name }}
+  {{ $m.EtcdVolume.ResourceName }}:
     Type: AWS::EC2::Volume
     Properties:
-{{ if eq $v.Master.EncrypterBackend "kms" }}
+{{ if $m.EtcdVolume.EncryptionKeyARN }}
+      Encrypted: true
+      KmsKeyId: {{ $m.EtcdVolume.EncryptionKeyARN }}
+{{ else if or (eq $m.EncrypterBackend "kms") (eq $m.EncrypterBackend "ssm") }}
       Encrypted: true
 {{ end }}
       Size: 100
       VolumeType: gp2
-      AvailabilityZone: {{ $v.Master.AZ }}
+      AvailabilityZone: {{ $m.AZ }}
       Tags:
       - Key: Name
-        Value: {{ $v.Master.EtcdVolume.Name }}
-  {{ $v.Master.Instance.ResourceName }}DockerMountPoint:
+        Value: {{ $m.EtcdVolume.Name }}
+  {{ $m.Instance.ResourceName }}DockerMountPoint:
     Type: AWS::EC2::VolumeAttachment
     Properties:
-      InstanceId: !Ref {{ $v.Master.Instance.ResourceName }}
-      VolumeId: !Ref {{ $v.Master.DockerVolume.ResourceName }}
+      InstanceId: !Ref {{ $m.Instance.ResourceName }}
+      VolumeId: !Ref {{ $m.DockerVolume.ResourceName }}
       Device: /dev/xvdc
-  {{ $v.Master.Instance.ResourceName }}EtcdMountPoint:
+  {{ $m.Instance.ResourceName }}EtcdMountPoint:
     Type: AWS::EC2::VolumeAttachment
     Properties:
-      InstanceId: !Ref {{ $v.Master.Instance.ResourceName }}
-      VolumeId: !Ref EtcdVolume
+      InstanceId: !Ref {{ $m.Instance.ResourceName }}
+      VolumeId: !Ref {{ $m.EtcdVolume.ResourceName }}
       Device: /dev/xvdh
+{{- end }}
 {{ end }}`
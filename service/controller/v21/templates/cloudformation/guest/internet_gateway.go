@@ -2,6 +2,7 @@ package guest
 
 const InternetGateway = `{{define "internet_gateway"}}
 {{- $v := .Guest.InternetGateway }}
+  {{- if $v.Managed }}
   InternetGateway:
     Type: AWS::EC2::InternetGateway
     Properties:
@@ -30,4 +31,32 @@ const InternetGateway = `{{define "internet_gateway"}}
       DestinationCidrBlock: 0.0.0.0/0
       GatewayId:
         Ref: InternetGateway
+
+  {{- if $v.IPv6Enabled }}
+  InternetGatewayIPv6Route:
+    Type: AWS::EC2::Route
+    DependsOn:
+      - VPCGatewayAttachment
+    Properties:
+      RouteTableId: !Ref PublicRouteTable
+      DestinationIpv6CidrBlock: ::/0
+      GatewayId:
+        Ref: InternetGateway
+
+  EgressOnlyInternetGateway:
+    Type: AWS::EC2::EgressOnlyInternetGateway
+    Properties:
+      VpcId: !Ref VPC
+
+  {{- range $v.EgressOnlyRoutes }}
+  {{ .Name }}:
+    Type: AWS::EC2::Route
+    Properties:
+      RouteTableId: !Ref {{ .RouteTableName }}
+      DestinationIpv6CidrBlock: ::/0
+      EgressOnlyInternetGatewayId:
+        Ref: EgressOnlyInternetGateway
+  {{- end }}
+  {{- end }}
+  {{- end }}
 {{end}}`
@@ -0,0 +1,56 @@
+package guest
+
+const LaunchTemplate = `{{define "launch_template"}}
+{{- $v := .Guest.LaunchConfiguration }}
+  {{- template "launch_template_resource" $v }}
+  {{- range $p := $v.NodePools }}
+  {{- template "launch_template_resource" $p }}
+  {{- end }}
+{{end}}
+
+{{define "launch_template_resource"}}
+{{- $v := . }}
+  {{ $v.ASGType }}LaunchTemplate:
+    Type: "AWS::EC2::LaunchTemplate"
+    Properties:
+      LaunchTemplateData:
+        ImageId: {{ $v.WorkerImageID }}
+        InstanceType: {{ $v.WorkerInstanceType }}
+        Monitoring:
+          Enabled: {{ $v.WorkerInstanceMonitoring }}
+        IamInstanceProfile:
+          Name: !Ref WorkerInstanceProfile
+        {{- if $v.WorkerMetadataTokensRequired }}
+        MetadataOptions:
+          HttpEndpoint: enabled
+          HttpPutResponseHopLimit: 2
+          HttpTokens: required
+        {{- end }}
+        NetworkInterfaces:
+        - DeviceIndex: 0
+          AssociatePublicIpAddress: {{ $v.WorkerAssociatePublicIPAddress }}
+          Groups:
+          - !Ref WorkerSecurityGroup
+        BlockDeviceMappings:
+        {{ range $v.WorkerBlockDeviceMappings }}
+        - DeviceName: "{{ .DeviceName }}"
+          Ebs:
+            DeleteOnTermination: {{ .DeleteOnTermination }}
+            {{- if .EncryptionKeyARN }}
+            Encrypted: true
+            KmsKeyId: {{ .EncryptionKeyARN }}
+            {{- end }}
+            {{- if .VolumeSize }}
+            VolumeSize: {{ .VolumeSize }}
+            {{- end }}
+            VolumeType: {{ .VolumeType }}
+        {{ end }}
+        {{- if $v.EncryptionKeyID }}
+        TagSpecifications:
+        - ResourceType: instance
+          Tags:
+          - Key: giantswarm.io/encryption-key
+            Value: {{ $v.EncryptionKeyID }}
+        {{- end }}
+        UserData: {{ $v.WorkerSmallCloudConfig }}
+{{end}}`
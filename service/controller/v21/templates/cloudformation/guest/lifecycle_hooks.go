@@ -2,13 +2,21 @@ package guest
 
 const LifecycleHooks = `{{ define "lifecycle_hooks" }}
 {{- $v := .Guest.LifecycleHooks }}
-  {{ $v.Worker.LifecycleHook.Name }}LifecycleHook:
+  {{- template "lifecycle_hook_resource" $v.Worker }}
+  {{- range $p := $v.NodePools }}
+  {{- template "lifecycle_hook_resource" $p }}
+  {{- end }}
+{{ end }}
+
+{{ define "lifecycle_hook_resource" }}
+{{- $v := . }}
+  {{ $v.LifecycleHook.ResourceName }}:
     Type: "AWS::AutoScaling::LifecycleHook"
     Properties:
       AutoScalingGroupName:
-        Ref: {{ $v.Worker.ASG.Ref }}
+        Ref: {{ $v.ASG.Ref }}
       DefaultResult: CONTINUE
       HeartbeatTimeout: 3600
-      LifecycleHookName: {{ $v.Worker.LifecycleHook.Name }}
+      LifecycleHookName: {{ $v.LifecycleHook.Name }}
       LifecycleTransition: "autoscaling:EC2_INSTANCE_TERMINATING"
 {{ end }}`
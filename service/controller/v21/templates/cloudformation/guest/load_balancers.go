@@ -2,10 +2,73 @@ package guest
 
 const LoadBalancers = `{{define "load_balancers"}}
 {{- $v := .Guest.LoadBalancers }}
+{{- if eq $v.APIElbType "nlb" }}
+  {{- range $m := $v.APIElbSubnetMappings }}
+  {{ $m.EIPName }}:
+    Type: AWS::EC2::EIP
+    {{- if $v.InternetGatewayManaged }}
+    DependsOn:
+      - VPCGatewayAttachment
+    {{- end }}
+    Properties:
+      Domain: vpc
+  {{- end }}
+  {{ $v.APIElbResourceName }}:
+    Type: AWS::ElasticLoadBalancingV2::LoadBalancer
+    {{- if $v.InternetGatewayManaged }}
+    DependsOn:
+      - VPCGatewayAttachment
+    {{- end }}
+    Properties:
+      Name: {{ $v.APIElbName }}
+      Scheme: {{ $v.APIElbScheme }}
+      {{- if $v.APIElbSubnetMappings }}
+      SubnetMappings:
+      {{- range $m := $v.APIElbSubnetMappings }}
+      - AllocationId: !GetAtt {{ $m.EIPName }}.AllocationId
+        SubnetId: !Ref {{ $m.SubnetName }}
+      {{- end }}
+      {{- else }}
+      Subnets:
+      {{- range $s := $v.APIElbSubnets }}
+        - !Ref {{ $s }}
+      {{- end }}
+      {{- end }}
+      Type: network
+  {{- range $v.APIElbPortsToOpen }}
+  {{ .ListenerResourceName }}:
+    Type: AWS::ElasticLoadBalancingV2::Listener
+    Properties:
+      DefaultActions:
+      - TargetGroupArn: !Ref {{ .TargetGroupResourceName }}
+        Type: forward
+      LoadBalancerArn: !Ref {{ $v.APIElbResourceName }}
+      Port: {{ .PortELB }}
+      Protocol: TCP
+  {{ .TargetGroupResourceName }}:
+    Type: AWS::ElasticLoadBalancingV2::TargetGroup
+    Properties:
+      HealthCheckIntervalSeconds: {{ $v.NLBHealthCheckInterval }}
+      HealthCheckPort: {{ $v.APIElbHealthCheckPort }}
+      HealthCheckProtocol: {{ $v.NLBHealthCheckProtocol }}
+      HealthyThresholdCount: {{ $v.NLBHealthCheckThreshold }}
+      Port: {{ .PortInstance }}
+      Protocol: TCP
+      Targets:
+      {{- range $n := $v.MasterInstanceResourceNames }}
+      - Id: !Ref {{ $n }}
+      {{- end }}
+      TargetType: instance
+      UnhealthyThresholdCount: {{ $v.NLBHealthCheckThreshold }}
+      VpcId: !Ref VPC
+  {{- end }}
+{{- else }}
   ApiLoadBalancer:
     Type: AWS::ElasticLoadBalancing::LoadBalancer
+    {{- if $v.InternetGatewayManaged }}
     DependsOn:
       - VPCGatewayAttachment
+    {{- end }}
     Properties:
       ConnectionSettings:
         IdleTimeout: 1200
@@ -16,7 +79,9 @@ const LoadBalancers = `{{define "load_balancers"}}
         Timeout: {{ $v.ELBHealthCheckTimeout }}
         UnhealthyThreshold: {{ $v.ELBHealthCheckUnhealthyThreshold }}
       Instances:
-      - !Ref {{ $v.MasterInstanceResourceName }}
+      {{- range $n := $v.MasterInstanceResourceNames }}
+      - !Ref {{ $n }}
+      {{- end }}
       Listeners:
       {{ range $v.APIElbPortsToOpen}}
       - InstancePort: {{ .PortInstance }}
@@ -29,10 +94,50 @@ const LoadBalancers = `{{define "load_balancers"}}
       SecurityGroups:
         - !Ref MasterSecurityGroup
       Subnets:
-      {{- range $s := $v.PublicSubnets }}
+      {{- range $s := $v.APIElbSubnets }}
         - !Ref {{ $s }}
       {{end}}
+{{- end }}
 
+{{- if eq $v.EtcdElbType "nlb" }}
+  {{ $v.EtcdElbResourceName }}:
+    Type: AWS::ElasticLoadBalancingV2::LoadBalancer
+    Properties:
+      Name: {{ $v.EtcdElbName }}
+      Scheme: {{ $v.EtcdElbScheme }}
+      Subnets:
+      {{- range $s := $v.PrivateSubnets }}
+        - !Ref {{ $s }}
+      {{- end }}
+      Type: network
+  {{- range $v.EtcdElbPortsToOpen }}
+  {{ .ListenerResourceName }}:
+    Type: AWS::ElasticLoadBalancingV2::Listener
+    Properties:
+      DefaultActions:
+      - TargetGroupArn: !Ref {{ .TargetGroupResourceName }}
+        Type: forward
+      LoadBalancerArn: !Ref {{ $v.EtcdElbResourceName }}
+      Port: {{ .PortELB }}
+      Protocol: TCP
+  {{ .TargetGroupResourceName }}:
+    Type: AWS::ElasticLoadBalancingV2::TargetGroup
+    Properties:
+      HealthCheckIntervalSeconds: {{ $v.NLBHealthCheckInterval }}
+      HealthCheckPort: {{ $v.EtcdElbHealthCheckPort }}
+      HealthCheckProtocol: {{ $v.NLBHealthCheckProtocol }}
+      HealthyThresholdCount: {{ $v.NLBHealthCheckThreshold }}
+      Port: {{ .PortInstance }}
+      Protocol: TCP
+      Targets:
+      {{- range $n := $v.MasterInstanceResourceNames }}
+      - Id: !Ref {{ $n }}
+      {{- end }}
+      TargetType: instance
+      UnhealthyThresholdCount: {{ $v.NLBHealthCheckThreshold }}
+      VpcId: !Ref VPC
+  {{- end }}
+{{- else }}
   EtcdLoadBalancer:
     Type: AWS::ElasticLoadBalancing::LoadBalancer
     Properties:
@@ -45,7 +150,9 @@ const LoadBalancers = `{{define "load_balancers"}}
         Timeout: {{ $v.ELBHealthCheckTimeout }}
         UnhealthyThreshold: {{ $v.ELBHealthCheckUnhealthyThreshold }}
       Instances:
-      - !Ref {{ $v.MasterInstanceResourceName }}
+      {{- range $n := $v.MasterInstanceResourceNames }}
+      - !Ref {{ $n }}
+      {{- end }}
       Listeners:
       {{ range $v.EtcdElbPortsToOpen}}
       - InstancePort: {{ .PortInstance }}
@@ -61,12 +168,59 @@ const LoadBalancers = `{{define "load_balancers"}}
       {{- range $s := $v.PrivateSubnets }}
         - !Ref {{ $s }}
       {{end}}
+{{- end }}
 
-
+{{- if eq $v.IngressElbType "nlb" }}
+  {{ $v.IngressElbResourceName }}:
+    Type: AWS::ElasticLoadBalancingV2::LoadBalancer
+    {{- if $v.InternetGatewayManaged }}
+    DependsOn:
+      - VPCGatewayAttachment
+    {{- end }}
+    Properties:
+      Name: {{ $v.IngressElbName }}
+      Scheme: {{ $v.IngressElbScheme }}
+      Subnets:
+      {{- range $s := $v.PublicSubnets }}
+        - !Ref {{ $s }}
+      {{- end }}
+      Type: network
+  {{- range $v.IngressElbPortsToOpen }}
+  {{ .ListenerResourceName }}:
+    Type: AWS::ElasticLoadBalancingV2::Listener
+    Properties:
+      DefaultActions:
+      - TargetGroupArn: !Ref {{ .TargetGroupResourceName }}
+        Type: forward
+      LoadBalancerArn: !Ref {{ $v.IngressElbResourceName }}
+      Port: {{ .PortELB }}
+      Protocol: TCP
+  # The worker ASGs register their instances with the ingress target groups.
+  {{ .TargetGroupResourceName }}:
+    Type: AWS::ElasticLoadBalancingV2::TargetGroup
+    Properties:
+      HealthCheckIntervalSeconds: {{ $v.NLBHealthCheckInterval }}
+      HealthCheckPort: {{ $v.IngressElbHealthCheckPort }}
+      HealthCheckProtocol: {{ $v.NLBHealthCheckProtocol }}
+      HealthyThresholdCount: {{ $v.NLBHealthCheckThreshold }}
+      Port: {{ .PortInstance }}
+      Protocol: TCP
+      {{- if $v.IngressElbProxyProtocol }}
+      TargetGroupAttributes:
+      - Key: proxy_protocol_v2.enabled
+        Value: "true"
+      {{- end }}
+      TargetType: instance
+      UnhealthyThresholdCount: {{ $v.NLBHealthCheckThreshold }}
+      VpcId: !Ref VPC
+  {{- end }}
+{{- else }}
   IngressLoadBalancer:
     Type: AWS::ElasticLoadBalancing::LoadBalancer
+    {{- if $v.InternetGatewayManaged }}
     DependsOn:
       - VPCGatewayAttachment
+    {{- end }}
     Properties:
       ConnectionSettings:
         IdleTimeout: 60
@@ -101,4 +255,5 @@ const LoadBalancers = `{{define "load_balancers"}}
       {{- range $s := $v.PublicSubnets }}
         - !Ref {{ $s }}
       {{end}}
+{{- end }}
 {{end}}`
@@ -6,6 +6,24 @@ Parameters:
   VersionBundleVersionParameter:
     Type: String
     Description: Sets the VersionBundleVersion used to generate the template. 
+  {{- if .Guest.VPC.ID }}
+  VPC:
+    Type: AWS::EC2::VPC::Id
+    Default: {{ .Guest.VPC.ID }}
+    Description: Existing VPC the guest cluster is deployed into.
+  {{- range .Guest.Subnets.PublicSubnets }}
+  {{ .Name }}:
+    Type: AWS::EC2::Subnet::Id
+    Default: {{ .ID }}
+    Description: Existing public subnet in {{ .AvailabilityZone }}.
+  {{- end }}
+  {{- range .Guest.Subnets.PrivateSubnets }}
+  {{ .Name }}:
+    Type: AWS::EC2::Subnet::Id
+    Default: {{ .ID }}
+    Description: Existing private subnet in {{ .AvailabilityZone }}.
+  {{- end }}
+  {{- end }}
 Resources:
   {{template "vpc" .}}
   {{template "iam_policies" .}}
@@ -14,9 +32,11 @@ Resources:
   {{template "subnets" .}}
   {{template "internet_gateway" .}}
   {{template "nat_gateway" .}}
+  {{template "vpc_endpoints" .}}
+  {{template "vpc_flow_logs" .}}
   {{template "instance" .}}
   {{template "load_balancers" .}}
-  {{template "launch_configuration" .}}
+  {{template "launch_template" .}}
   {{template "lifecycle_hooks" .}}
   {{template "autoscaling_group" .}}
   {{template "record_sets" .}}
@@ -8,18 +8,26 @@ const NatGateway = `{{define "nat_gateway"}}
     DependsOn:
       - VPCGatewayAttachment
     Properties:
+      {{- if .AllocationID }}
+      AllocationId: {{ .AllocationID }}
+      {{- else }}
       AllocationId:
         Fn::GetAtt:
         - {{ .NATEIPName }}
         - AllocationId
+      {{- end }}
       SubnetId: !Ref {{ .PublicSubnetName }}
       Tags:
         - Key: Name
           Value: {{ .ClusterID }}
+  {{- if .NATEIPName }}
   {{ .NATEIPName }}:
     Type: AWS::EC2::EIP
     Properties:
       Domain: vpc
+  {{- end }}
+  {{- end }}
+  {{- range $v.Routes }}
   {{ .NATRouteName }}:
     Type: AWS::EC2::Route
     Properties:
@@ -27,5 +35,5 @@ const NatGateway = `{{define "nat_gateway"}}
       DestinationCidrBlock: 0.0.0.0/0
       NatGatewayId:
         Ref: "{{ .NATGWName }}"
-{{end}}
+  {{- end }}
 {{end}}`
@@ -5,10 +5,20 @@ const Outputs = `{{define "outputs"}}
 Outputs:
   DockerVolumeResourceName:
     Value: {{ $v.Master.DockerVolume.ResourceName }}
+  {{- if $v.EncryptionKeyID }}
+  EncryptionKeyID:
+    Value: {{ $v.EncryptionKeyID }}
+  {{- end }}
+  {{- if $v.VolumeEncryption.EtcdKeyARN }}
+  EtcdVolumeEncryptionKeyARN:
+    Value: {{ $v.VolumeEncryption.EtcdKeyARN }}
+  {{- end }}
   {{ if $v.Route53Enabled }}
   HostedZoneNameServers:
     Value: !Join [ ',', !GetAtt 'HostedZone.NameServers' ]
   {{ end }}
+  MasterCount:
+    Value: {{ $v.Master.Count }}
   MasterImageID:
     Value: {{ $v.Master.ImageID }}
   MasterInstanceResourceName:
@@ -17,6 +27,10 @@ Outputs:
     Value: {{ $v.Master.Instance.Type }}
   MasterCloudConfigVersion:
     Value: {{ $v.Master.CloudConfig.Version }}
+  {{- range $s := $v.Master.States }}
+  {{ $s.Key }}:
+    Value: {{ $s.Value }}
+  {{- end }}
   {{ $v.Worker.ASG.Key }}:
     Value: !Ref {{ $v.Worker.ASG.Ref }}
   WorkerCount:
@@ -27,9 +41,69 @@ Outputs:
     Value: {{ $v.Worker.ImageID }}
   WorkerInstanceType:
     Value: {{ $v.Worker.InstanceType }}
+  {{ $v.Worker.LaunchTemplate.Key }}:
+    Value: !Ref {{ $v.Worker.LaunchTemplate.Ref }}
+  {{ if $v.Worker.InstanceDistribution.Enabled }}
+  WorkerInstanceTypes:
+    Value: {{ $v.Worker.InstanceDistribution.InstanceTypes }}
+  WorkerOnDemandBaseCapacity:
+    Value: {{ $v.Worker.InstanceDistribution.OnDemandBaseCapacity }}
+  WorkerSpotAllocationStrategy:
+    Value: {{ $v.Worker.InstanceDistribution.SpotAllocationStrategy }}
+  WorkerSpotPercentage:
+    Value: {{ $v.Worker.InstanceDistribution.SpotPercentage }}
+  {{ end }}
   WorkerCloudConfigVersion:
     Value: {{ $v.Worker.CloudConfig.Version }}
+  {{- if $v.Worker.Scaling.Enabled }}
+  WorkerMaxSize:
+    Value: {{ $v.Worker.Scaling.MaxSize }}
+  WorkerMinSize:
+    Value: {{ $v.Worker.Scaling.MinSize }}
+  {{- end }}
+  {{- range $p := $v.NodePools }}
+  {{ $p.ASG.Key }}:
+    Value: !Ref {{ $p.ASG.Ref }}
+  {{ $p.CloudConfig.Key }}:
+    Value: {{ $p.CloudConfig.Value }}
+  {{ $p.DockerVolumeSizeGB.Key }}:
+    Value: {{ $p.DockerVolumeSizeGB.Value }}
+  {{ $p.InstanceType.Key }}:
+    Value: {{ $p.InstanceType.Value }}
+  {{ $p.MaxSize.Key }}:
+    Value: {{ $p.MaxSize.Value }}
+  {{ $p.MinSize.Key }}:
+    Value: {{ $p.MinSize.Value }}
+  {{- end }}
   VersionBundleVersion:
     Value:
       Ref: VersionBundleVersionParameter
+  {{- if $v.VolumeEncryption.KeyARN }}
+  VolumeEncryptionKeyARN:
+    Value: {{ $v.VolumeEncryption.KeyARN }}
+  {{- end }}
+  {{- if $v.VPCID }}
+  VPCID:
+    Value: {{ $v.VPCID }}
+  {{- end }}
+  {{- if $v.TransitGatewayID }}
+  TransitGatewayID:
+    Value: {{ $v.TransitGatewayID }}
+  {{- end }}
+  {{- if $v.NATGateway.Strategy }}
+  NATGatewayStrategy:
+    Value: {{ $v.NATGateway.Strategy }}
+  {{- end }}
+  {{- if $v.NATGateway.AllocationIDs }}
+  NATGatewayAllocationIDs:
+    Value: {{ $v.NATGateway.AllocationIDs }}
+  {{- end }}
+  {{- if $v.APIWhitelist.APICIDRs }}
+  APIWhitelistCIDRs:
+    Value: {{ $v.APIWhitelist.APICIDRs }}
+  {{- end }}
+  {{- if $v.APIWhitelist.IngressCIDRs }}
+  IngressWhitelistCIDRs:
+    Value: {{ $v.APIWhitelist.IngressCIDRs }}
+  {{- end }}
 {{end}}`
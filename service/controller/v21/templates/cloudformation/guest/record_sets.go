@@ -2,37 +2,64 @@ package guest
 
 const RecordSets = `{{define "record_sets"}}
 {{- $v := .Guest.RecordSets }}
+{{- $lb := .Guest.LoadBalancers }}
 {{ if $v.Route53Enabled }}
   HostedZone:
     Type: 'AWS::Route53::HostedZone'
     Properties:
       Name: '{{ $v.ClusterID }}.k8s.{{ $v.BaseDomain }}.'
+  {{- if $v.PrivateAPI }}
+  # The private hosted zone only covers the API domain. The host cluster VPC is
+  # associated with it by the hostedzone resource.
+  PrivateAPIHostedZone:
+    Type: 'AWS::Route53::HostedZone'
+    Properties:
+      Name: '{{ $v.PrivateAPIHostedZoneName }}.'
+      VPCs:
+        - VPCId: !Ref VPC
+          VPCRegion: '{{ $v.Region }}'
+  {{- end }}
   ApiRecordSet:
     Type: AWS::Route53::RecordSet
     Properties:
       AliasTarget:
-        DNSName: !GetAtt ApiLoadBalancer.DNSName
-        HostedZoneId: !GetAtt ApiLoadBalancer.CanonicalHostedZoneNameID
+        DNSName: !GetAtt {{ $lb.APIElbResourceName }}.DNSName
+        HostedZoneId: !GetAtt {{ $lb.APIElbResourceName }}.{{ $lb.APIElbHostedZoneIDAttribute }}
         EvaluateTargetHealth: false
       Name: 'api.{{ $v.ClusterID }}.k8s.{{ $v.BaseDomain }}.'
+      {{- if $v.PrivateAPI }}
+      HostedZoneId: !Ref 'PrivateAPIHostedZone'
+      {{- else }}
       HostedZoneId: !Ref 'HostedZone'
+      {{- end }}
       Type: A
   EtcdRecordSet:
     Type: AWS::Route53::RecordSet
     Properties:
       AliasTarget:
-        DNSName: !GetAtt EtcdLoadBalancer.DNSName
-        HostedZoneId: !GetAtt EtcdLoadBalancer.CanonicalHostedZoneNameID
+        DNSName: !GetAtt {{ $lb.EtcdElbResourceName }}.DNSName
+        HostedZoneId: !GetAtt {{ $lb.EtcdElbResourceName }}.{{ $lb.EtcdElbHostedZoneIDAttribute }}
         EvaluateTargetHealth: false
       Name: '{{ $v.EtcdDomain }}.'
       HostedZoneId: !Ref 'HostedZone'
       Type: A
+  {{- range $m := $v.Masters }}
+  {{ $m.RecordSetName }}:
+    Type: AWS::Route53::RecordSet
+    Properties:
+      Name: '{{ $m.EtcdMemberDomain }}.'
+      HostedZoneId: !Ref 'HostedZone'
+      TTL: '60'
+      Type: A
+      ResourceRecords:
+        - !GetAtt {{ $m.InstanceResourceName }}.PrivateIp
+  {{- end }}
   IngressRecordSet:
     Type: AWS::Route53::RecordSet
     Properties:
       AliasTarget:
-        DNSName: !GetAtt IngressLoadBalancer.DNSName
-        HostedZoneId: !GetAtt IngressLoadBalancer.CanonicalHostedZoneNameID
+        DNSName: !GetAtt {{ $lb.IngressElbResourceName }}.DNSName
+        HostedZoneId: !GetAtt {{ $lb.IngressElbResourceName }}.{{ $lb.IngressElbHostedZoneIDAttribute }}
         EvaluateTargetHealth: false
       Name: 'ingress.{{ $v.ClusterID }}.k8s.{{ $v.BaseDomain }}.'
       HostedZoneId: !Ref 'HostedZone'
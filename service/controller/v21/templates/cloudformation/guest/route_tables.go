@@ -2,6 +2,7 @@ package guest
 
 const RouteTables = `{{ define "route_tables" }}
 {{- $v := .Guest.RouteTables }}
+  {{- if $v.Managed }}
   {{ $v.PublicRouteTableName.ResourceName }}:
     Type: AWS::EC2::RouteTable
     Properties:
@@ -9,8 +10,10 @@ const RouteTables = `{{ define "route_tables" }}
       Tags:
       - Key: Name
         Value: {{ $v.PublicRouteTableName.TagName }}
+  {{- end }}
 
   {{- range $v.PrivateRouteTableNames }}
+  {{- if $v.Managed }}
   {{ .ResourceName }}:
     Type: AWS::EC2::RouteTable
     Properties:
@@ -18,13 +21,24 @@ const RouteTables = `{{ define "route_tables" }}
       Tags:
       - Key: Name
         Value: {{ .TagName }}
+  {{- end }}
 
+  {{- if $v.TransitGatewayID }}
+  {{ .TransitGatewayRouteName }}:
+    Type: AWS::EC2::Route
+    DependsOn: TransitGatewayAttachment
+    Properties:
+      RouteTableId: {{ if $v.Managed }}!Ref {{ .ResourceName }}{{ else }}{{ .ID }}{{ end }}
+      DestinationCidrBlock: {{ $v.HostClusterCIDR }}
+      TransitGatewayId: {{ $v.TransitGatewayID }}
+  {{- else }}
   {{ .VPCPeeringRouteName }}:
     Type: AWS::EC2::Route
     Properties:
-      RouteTableId: !Ref {{ .ResourceName }}
+      RouteTableId: {{ if $v.Managed }}!Ref {{ .ResourceName }}{{ else }}{{ .ID }}{{ end }}
       DestinationCidrBlock: {{ $v.HostClusterCIDR }}
       VpcPeeringConnectionId:
         Ref: "VPCPeeringConnection"
+  {{- end }}
   {{ end }}
 {{ end }}`
@@ -14,7 +14,11 @@ const SecurityGroups = `{{define "security_groups" }}
         IpProtocol: {{ .Protocol }}
         FromPort: {{ .Port }}
         ToPort: {{ .Port }}
+        {{- if .SourceIPv6CIDR }}
+        CidrIpv6: {{ .SourceIPv6CIDR }}
+        {{- else }}
         CidrIp: {{ .SourceCIDR }}
+        {{- end }}
       {{ end }}
       {{- if $v.APIWhitelistEnabled }}
       {{- $g := .Guest.NATGateway }}
@@ -24,7 +28,11 @@ const SecurityGroups = `{{define "security_groups" }}
         IpProtocol: tcp
         FromPort: 443
         ToPort: 443
+        {{- if .PublicIP }}
+        CidrIp: {{ .PublicIP }}/32
+        {{- else }}
         CidrIp: !Join [ "/", [ !Ref {{ .NATEIPName }}, "32" ] ]
+        {{- end }}
       {{- end}}
       {{- end }}
       Tags:
@@ -42,7 +50,9 @@ const SecurityGroups = `{{define "security_groups" }}
         IpProtocol: {{ .Protocol }}
         FromPort: {{ .Port }}
         ToPort: {{ .Port }}
-        {{ if .SourceCIDR }}
+        {{ if .SourceIPv6CIDR }}
+        CidrIpv6: {{ .SourceIPv6CIDR }}
+        {{ else if .SourceCIDR }}
         CidrIp: {{ .SourceCIDR }}
         {{ else }}
         SourceSecurityGroupId: !Ref {{ .SourceSecurityGroup }}
@@ -63,7 +73,11 @@ const SecurityGroups = `{{define "security_groups" }}
         IpProtocol: {{ .Protocol }}
         FromPort: {{ .Port }}
         ToPort: {{ .Port }}
+        {{- if .SourceIPv6CIDR }}
+        CidrIpv6: {{ .SourceIPv6CIDR }}
+        {{- else }}
         CidrIp: {{ .SourceCIDR }}
+        {{- end }}
       {{ end }}
       Tags:
         - Key: Name
@@ -80,7 +94,11 @@ const SecurityGroups = `{{define "security_groups" }}
         IpProtocol: {{ .Protocol }}
         FromPort: {{ .Port }}
         ToPort: {{ .Port }}
+        {{- if .SourceIPv6CIDR }}
+        CidrIpv6: {{ .SourceIPv6CIDR }}
+        {{- else }}
         CidrIp: {{ .SourceCIDR }}
+        {{- end }}
       {{ end }}
       Tags:
         - Key: Name
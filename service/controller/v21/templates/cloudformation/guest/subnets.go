@@ -2,12 +2,22 @@ package guest
 
 const Subnets = `{{ define "subnets" }}
 {{- $v := .Guest.Subnets }}
+  {{- if $v.Managed }}
   {{- range $v.PublicSubnets }}
   {{ .Name }}:
     Type: AWS::EC2::Subnet
+    {{- if $v.IPv6Enabled }}
+    DependsOn: VPCIPv6CidrBlock
+    {{- end }}
     Properties:
+      {{- if $v.IPv6Enabled }}
+      AssignIpv6AddressOnCreation: true
+      {{- end }}
       AvailabilityZone: {{ .AvailabilityZone }}
       CidrBlock: {{ .CIDR }}
+      {{- if $v.IPv6Enabled }}
+      Ipv6CidrBlock: !Select [ {{ .IPv6CIDRIndex }}, !Cidr [ !Select [ 0, !GetAtt VPC.Ipv6CidrBlocks ], {{ $v.IPv6SubnetCount }}, 64 ] ]
+      {{- end }}
       MapPublicIpOnLaunch: {{ .MapPublicIPOnLaunch }}
       Tags:
       - Key: Name
@@ -25,9 +35,18 @@ const Subnets = `{{ define "subnets" }}
   {{- range $v.PrivateSubnets }}
   {{ .Name }}:
     Type: AWS::EC2::Subnet
+    {{- if $v.IPv6Enabled }}
+    DependsOn: VPCIPv6CidrBlock
+    {{- end }}
     Properties:
+      {{- if $v.IPv6Enabled }}
+      AssignIpv6AddressOnCreation: true
+      {{- end }}
       AvailabilityZone: {{ .AvailabilityZone }}
       CidrBlock: {{ .CIDR }}
+      {{- if $v.IPv6Enabled }}
+      Ipv6CidrBlock: !Select [ {{ .IPv6CIDRIndex }}, !Cidr [ !Select [ 0, !GetAtt VPC.Ipv6CidrBlocks ], {{ $v.IPv6SubnetCount }}, 64 ] ]
+      {{- end }}
       MapPublicIpOnLaunch: {{ .MapPublicIPOnLaunch }}
       Tags:
       - Key: Name
@@ -40,4 +59,5 @@ const Subnets = `{{ define "subnets" }}
       RouteTableId: !Ref {{ .RouteTableAssociation.RouteTableName }}
       SubnetId: !Ref {{ .RouteTableAssociation.SubnetName }}
   {{ end }}
+  {{- end }}
 {{ end }}`
@@ -2,6 +2,7 @@ package guest
 
 const VPC = `{{define "vpc"}}
 {{- $v := .Guest.VPC }}
+  {{- if not $v.ID }}
   VPC:
     Type: AWS::EC2::VPC
     Properties:
@@ -13,6 +14,30 @@ const VPC = `{{define "vpc"}}
         Value: {{ $v.ClusterID }}
       - Key: Installation
         Value: {{ $v.InstallationName }}
+  {{- end }}
+  {{- if $v.IPv6Enabled }}
+  VPCIPv6CidrBlock:
+    Type: AWS::EC2::VPCCidrBlock
+    Properties:
+      AmazonProvidedIpv6CidrBlock: true
+      VpcId: !Ref VPC
+  {{- end }}
+  {{- if $v.TransitGatewayID }}
+  TransitGatewayAttachment:
+    Type: AWS::EC2::TransitGatewayAttachment
+    Properties:
+      TransitGatewayId: {{ $v.TransitGatewayID }}
+      VpcId: !Ref VPC
+      SubnetIds:
+      {{- range $.Guest.Subnets.PrivateSubnets }}
+        - !Ref {{ .Name }}
+      {{- end }}
+      Tags:
+        - Key: Name
+          Value: {{ $v.ClusterID }}
+        - Key: Installation
+          Value: {{ $v.InstallationName }}
+  {{- else }}
   VPCPeeringConnection:
     Type: 'AWS::EC2::VPCPeeringConnection'
     Properties:
@@ -23,4 +48,5 @@ const VPC = `{{define "vpc"}}
       Tags:
         - Key: Name
           Value: {{ $v.ClusterID }}
+  {{- end }}
 {{end}}`
@@ -0,0 +1,75 @@
+package guest
+
+const VPCEndpoints = `{{define "vpc_endpoints"}}
+{{- $v := .Guest.VPCEndpoints }}
+{{- if $v.Enabled }}
+  VPCEndpointSecurityGroup:
+    Type: AWS::EC2::SecurityGroup
+    Properties:
+      GroupDescription: {{ $v.SecurityGroupName }}
+      VpcId: !Ref VPC
+      SecurityGroupIngress:
+      -
+        Description: Allow HTTPS traffic from the guest cluster VPC.
+        IpProtocol: tcp
+        FromPort: 443
+        ToPort: 443
+        CidrIp: {{ $v.CIDRBlock }}
+      Tags:
+        - Key: Name
+          Value: {{ $v.SecurityGroupName }}
+  S3VPCEndpoint:
+    Type: AWS::EC2::VPCEndpoint
+    Properties:
+      PolicyDocument:
+        Version: "2012-10-17"
+        Statement:
+          - Effect: "Allow"
+            Principal: "*"
+            Action:
+              - "s3:GetBucketLocation"
+              - "s3:GetObject"
+              - "s3:ListBucket"
+              - "s3:PutObject"
+            Resource:
+              - "arn:{{ $v.RegionARN }}:s3:::{{ $v.S3Bucket }}"
+              - "arn:{{ $v.RegionARN }}:s3:::{{ $v.S3Bucket }}/*"
+          - Effect: "Allow"
+            Principal: "*"
+            Action: "s3:GetObject"
+            Resource: "arn:{{ $v.RegionARN }}:s3:::{{ $v.ECRLayerBucket }}/*"
+      RouteTableIds:
+      {{- range $.Guest.RouteTables.PrivateRouteTableNames }}
+        - {{ if $.Guest.RouteTables.Managed }}!Ref {{ .ResourceName }}{{ else }}{{ .ID }}{{ end }}
+      {{- end }}
+      ServiceName: {{ $v.S3ServiceName }}
+      VpcEndpointType: Gateway
+      VpcId: !Ref VPC
+  {{- range $v.InterfaceEndpoints }}
+  {{ .ResourceName }}:
+    Type: AWS::EC2::VPCEndpoint
+    Properties:
+      {{- if .KMSKeyARN }}
+      PolicyDocument:
+        Version: "2012-10-17"
+        Statement:
+          - Effect: "Allow"
+            Principal: "*"
+            Action:
+              - "kms:Decrypt"
+              - "kms:DescribeKey"
+            Resource: "{{ .KMSKeyARN }}"
+      {{- end }}
+      PrivateDnsEnabled: true
+      SecurityGroupIds:
+        - !Ref VPCEndpointSecurityGroup
+      ServiceName: {{ .ServiceName }}
+      SubnetIds:
+      {{- range $v.PrivateSubnets }}
+        - !Ref {{ . }}
+      {{- end }}
+      VpcEndpointType: Interface
+      VpcId: !Ref VPC
+  {{- end }}
+{{- end }}
+{{end}}`
@@ -0,0 +1,76 @@
+package guest
+
+const VPCFlowLogs = `{{define "vpc_flow_logs"}}
+{{- $v := .Guest.VPCFlowLogs }}
+{{- if eq $v.Destination "cloudwatch" }}
+  VPCFlowLogsLogGroup:
+    Type: AWS::Logs::LogGroup
+    Properties:
+      LogGroupName: {{ $v.LogGroupName }}
+  VPCFlowLogsRole:
+    Type: "AWS::IAM::Role"
+    Properties:
+      RoleName: {{ $v.RoleName }}
+      AssumeRolePolicyDocument:
+        Version: "2012-10-17"
+        Statement:
+          Effect: "Allow"
+          Principal:
+            Service: vpc-flow-logs.amazonaws.com
+          Action: "sts:AssumeRole"
+      Policies:
+        - PolicyName: {{ $v.RoleName }}
+          PolicyDocument:
+            Version: "2012-10-17"
+            Statement:
+              - Effect: "Allow"
+                Action:
+                  - "logs:CreateLogStream"
+                  - "logs:DescribeLogGroups"
+                  - "logs:DescribeLogStreams"
+                  - "logs:PutLogEvents"
+                Resource: !GetAtt VPCFlowLogsLogGroup.Arn
+  VPCFlowLog:
+    Type: AWS::EC2::FlowLog
+    Properties:
+      DeliverLogsPermissionArn: !GetAtt VPCFlowLogsRole.Arn
+      LogDestinationType: cloud-watch-logs
+      LogGroupName: !Ref VPCFlowLogsLogGroup
+      MaxAggregationInterval: {{ $v.MaxAggregationInterval }}
+      ResourceId: !Ref VPC
+      ResourceType: VPC
+      TrafficType: {{ $v.TrafficType }}
+{{- end }}
+{{- if eq $v.Destination "s3" }}
+  VPCFlowLogsBucketPolicy:
+    Type: AWS::S3::BucketPolicy
+    Properties:
+      Bucket: {{ $v.BucketName }}
+      PolicyDocument:
+        Version: "2012-10-17"
+        Statement:
+          - Effect: "Allow"
+            Principal:
+              Service: delivery.logs.amazonaws.com
+            Action: "s3:PutObject"
+            Resource: "{{ $v.LogsARN }}"
+            Condition:
+              StringEquals:
+                s3:x-amz-acl: bucket-owner-full-control
+          - Effect: "Allow"
+            Principal:
+              Service: delivery.logs.amazonaws.com
+            Action: "s3:GetBucketAcl"
+            Resource: "{{ $v.BucketARN }}"
+  VPCFlowLog:
+    Type: AWS::EC2::FlowLog
+    DependsOn: VPCFlowLogsBucketPolicy
+    Properties:
+      LogDestination: {{ $v.LogDestinationARN }}
+      LogDestinationType: s3
+      MaxAggregationInterval: {{ $v.MaxAggregationInterval }}
+      ResourceId: !Ref VPC
+      ResourceType: VPC
+      TrafficType: {{ $v.TrafficType }}
+{{- end }}
+{{end}}`
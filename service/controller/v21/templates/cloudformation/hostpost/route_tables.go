@@ -8,7 +8,11 @@ const RouteTables = `{{ define "route_tables" }}
     Properties:
       RouteTableId: {{$t.RouteTableID}}
       DestinationCidrBlock: {{$t.CidrBlock}}
+      {{- if $t.TransitGatewayID }}
+      TransitGatewayId: {{$t.TransitGatewayID}}
+      {{- else }}
       VpcPeeringConnectionId: {{$t.PeerConnectionID}}
+      {{- end }}
   {{end}}
 
   {{ range $i, $t := $v.PublicRoutes }}
@@ -17,7 +21,11 @@ const RouteTables = `{{ define "route_tables" }}
     Properties:
       RouteTableId: {{$t.RouteTableID}}
       DestinationCidrBlock: {{$t.CidrBlock}}
+      {{- if $t.TransitGatewayID }}
+      TransitGatewayId: {{$t.TransitGatewayID}}
+      {{- else }}
       VpcPeeringConnectionId: {{$t.PeerConnectionID}}
+      {{- end }}
   {{ end }}
 
 {{ end }}`
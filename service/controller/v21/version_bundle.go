@@ -12,6 +12,131 @@ func VersionBundle() versionbundle.Bundle {
 				Description: "Replace cloudinit with ignition.",
 				Kind:        versionbundle.KindAdded,
 			},
+			{
+				Component:   "aws-operator",
+				Description: "Add highly available control plane with three masters spread across availability zones, replaced one after another during updates once the etcd member of the previous master rejoined.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Add scheduled encrypted etcd snapshots to the cluster S3 bucket with restore on master replacement.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Launch worker nodes from versioned EC2 launch templates and migrate existing launch configurations in place. Optionally require session tokens for the instance metadata service of worker nodes (IMDSv2).",
+				Kind:        versionbundle.KindChanged,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Add mixed on-demand and spot worker nodes of several instance types.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Add named node pools with their own instance type, docker volume size, labels, taints and size.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Let the cluster autoscaler scale worker nodes within the scaling bounds of the cluster.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Add Network Load Balancers with static EIPs as an option for the Kubernetes API, etcd and ingress endpoints.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Add a private API endpoint mode only reachable from the tenant cluster VPC and the host cluster VPC.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Add an installation option to attach newly created tenant cluster VPCs to a Transit Gateway instead of peering them with the host cluster VPC.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Add dual-stack IPv6 tenant cluster VPCs and subnets.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Resolve Container Linux AMIs per region through an AMI catalog instead of a hardcoded map and allow installations to override them.",
+				Kind:        versionbundle.KindChanged,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Preview tenant cluster main stack updates with change sets and optionally block updates replacing the etcd volume until they are approved.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Detect drift of the guest, host-pre and host-post stacks periodically, report it with the Drifted condition of the CR status and as metrics and optionally re-apply the templates of drifted stacks.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Report the failing resources of tenant cluster main stacks in failed states and optionally recover them.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Apply tenant cluster updates only within the maintenance window configured in the CR and report it in the CR status.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Optionally replace outdated worker instances in batches only once the nodes of the previous batch are Ready in the tenant cluster and report paused rollouts in the CR status.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Support the Kubernetes and AppRole auth methods for Vault encryption and renew Vault tokens before they expire.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Add the ssm encrypter backend storing TLS assets and keys as SecureString parameters in the SSM Parameter Store.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Enable rotation of existing KMS keys and re-key guest clusters once their encryption key reaches the configured age.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Encrypt all EBS volumes of guest clusters with a per-cluster or installation supplied KMS key.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Deploy guest clusters into existing VPCs and subnets referenced by their AWSConfig.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Add optional VPC endpoints for S3, EC2, ELB, KMS, STS and ECR to guest cluster VPCs.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Add optional VPC flow logs delivered to the logging bucket or a CloudWatch log group of the guest cluster.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Add a single shared NAT gateway strategy and pre-allocated elastic IPs for NAT gateways of new guest clusters and publish the egress IPs of guest clusters in the AWSConfig status.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Add per cluster whitelists for the Kubernetes API and the ingress load balancer merged with the API whitelist of the installation and updated without replacing masters.",
+				Kind:        versionbundle.KindAdded,
+			},
 		},
 		Components: []versionbundle.Component{
 			{
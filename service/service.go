@@ -120,10 +120,18 @@ func New(config Config) (*Service, error) {
 				Enabled:    config.Viper.GetBool(config.Flag.Service.Installation.Guest.Kubernetes.API.Security.Whitelist.Enabled),
 				SubnetList: config.Viper.GetString(config.Flag.Service.Installation.Guest.Kubernetes.API.Security.Whitelist.SubnetList),
 			},
-			AccessLogsExpiration:  config.Viper.GetInt(config.Flag.Service.AWS.S3AccessLogsExpiration),
-			AdvancedMonitoringEC2: config.Viper.GetBool(config.Flag.Service.AWS.AdvancedMonitoringEC2),
-			DeleteLoggingBucket:   config.Viper.GetBool(config.Flag.Service.AWS.LoggingBucket.Delete),
-			EncrypterBackend:      config.Viper.GetString(config.Flag.Service.AWS.Encrypter),
+			AccessLogsExpiration:       config.Viper.GetInt(config.Flag.Service.AWS.S3AccessLogsExpiration),
+			AdvancedMonitoringEC2:      config.Viper.GetBool(config.Flag.Service.AWS.AdvancedMonitoringEC2),
+			AMIOverrides:               config.Viper.GetString(config.Flag.Service.AWS.AMI.Overrides),
+			AMIOwner:                   config.Viper.GetString(config.Flag.Service.AWS.AMI.Owner),
+			ChangeSetApprovalRequired:  config.Viper.GetBool(config.Flag.Service.AWS.ChangeSet.ApprovalRequired),
+			DeleteLoggingBucket:        config.Viper.GetBool(config.Flag.Service.AWS.LoggingBucket.Delete),
+			EBSEncryptionKeyARN:        config.Viper.GetString(config.Flag.Service.AWS.EBSEncryption.KeyARN),
+			EncrypterBackend:           config.Viper.GetString(config.Flag.Service.AWS.Encrypter),
+			EncryptionRekeyGracePeriod: config.Viper.GetDuration(config.Flag.Service.AWS.EncryptionRekey.GracePeriod),
+			EncryptionRekeyInterval:    config.Viper.GetDuration(config.Flag.Service.AWS.EncryptionRekey.Interval),
+			EtcdBackupInterval:         config.Viper.GetDuration(config.Flag.Service.AWS.EtcdBackup.Interval),
+			EtcdBackupRetention:        config.Viper.GetInt(config.Flag.Service.AWS.EtcdBackup.Retention),
 			GuestAWSConfig: controller.ClusterConfigAWSConfig{
 				AccessKeyID:       config.Viper.GetString(config.Flag.Service.AWS.AccessKey.ID),
 				AccessKeySecret:   config.Viper.GetString(config.Flag.Service.AWS.AccessKey.Secret),
@@ -142,10 +150,15 @@ func New(config Config) (*Service, error) {
 				SessionToken:      config.Viper.GetString(config.Flag.Service.AWS.HostAccessKey.Session),
 				Region:            config.Viper.GetString(config.Flag.Service.AWS.Region),
 			},
-			IgnitionPath:     config.Viper.GetString(config.Flag.Service.Guest.Ignition.Path),
-			IncludeTags:      config.Viper.GetBool(config.Flag.Service.AWS.IncludeTags),
-			InstallationName: config.Viper.GetString(config.Flag.Service.Installation.Name),
-			IPAMNetworkRange: *ipamNetworkRange,
+			IgnitionPath: config.Viper.GetString(config.Flag.Service.Guest.Ignition.Path),
+			IncludeTags:  config.Viper.GetBool(config.Flag.Service.AWS.IncludeTags),
+			IngressNLB: controller.ClusterConfigIngressNLB{
+				ProxyProtocol: config.Viper.GetBool(config.Flag.Service.AWS.IngressNLB.ProxyProtocol),
+				SourceCIDRs:   config.Viper.GetString(config.Flag.Service.AWS.IngressNLB.SourceCIDRs),
+			},
+			InstallationName:       config.Viper.GetString(config.Flag.Service.Installation.Name),
+			IPAMNetworkRange:       *ipamNetworkRange,
+			MetadataTokensRequired: config.Viper.GetBool(config.Flag.Service.AWS.InstanceMetadata.TokensRequired),
 			OIDC: controller.ClusterConfigOIDC{
 				ClientID:      config.Viper.GetString(config.Flag.Service.Installation.Guest.Kubernetes.API.Auth.Provider.OIDC.ClientID),
 				IssuerURL:     config.Viper.GetString(config.Flag.Service.Installation.Guest.Kubernetes.API.Auth.Provider.OIDC.IssuerURL),
@@ -153,14 +166,36 @@ func New(config Config) (*Service, error) {
 				GroupsClaim:   config.Viper.GetString(config.Flag.Service.Installation.Guest.Kubernetes.API.Auth.Provider.OIDC.GroupsClaim),
 			},
 
-			PodInfraContainerImage: config.Viper.GetString(config.Flag.Service.AWS.PodInfraContainerImage),
-			ProjectName:            config.ProjectName,
-			PubKeyFile:             config.Viper.GetString(config.Flag.Service.AWS.PubKeyFile),
-			PublicRouteTables:      config.Viper.GetString(config.Flag.Service.AWS.PublicRouteTables),
-			Route53Enabled:         config.Viper.GetBool(config.Flag.Service.AWS.Route53.Enabled),
-			RegistryDomain:         config.Viper.GetString(config.Flag.Service.RegistryDomain),
-			SSOPublicKey:           config.Viper.GetString(config.Flag.Service.Guest.SSH.SSOPublicKey),
-			VaultAddress:           config.Viper.GetString(config.Flag.Service.AWS.VaultAddress),
+			PodInfraContainerImage:      config.Viper.GetString(config.Flag.Service.AWS.PodInfraContainerImage),
+			ProjectName:                 config.ProjectName,
+			PubKeyFile:                  config.Viper.GetString(config.Flag.Service.AWS.PubKeyFile),
+			PublicRouteTables:           config.Viper.GetString(config.Flag.Service.AWS.PublicRouteTables),
+			RecoverDeleteFailed:         config.Viper.GetBool(config.Flag.Service.AWS.Recovery.DeleteFailed),
+			RecoverRollbackComplete:     config.Viper.GetBool(config.Flag.Service.AWS.Recovery.RollbackComplete),
+			RecoverUpdateRollbackFailed: config.Viper.GetBool(config.Flag.Service.AWS.Recovery.UpdateRollbackFailed),
+			Route53Enabled:              config.Viper.GetBool(config.Flag.Service.AWS.Route53.Enabled),
+			RegistryDomain:              config.Viper.GetString(config.Flag.Service.RegistryDomain),
+			SSOPublicKey:                config.Viper.GetString(config.Flag.Service.Guest.SSH.SSOPublicKey),
+			StackDriftInterval:          config.Viper.GetDuration(config.Flag.Service.AWS.StackDrift.Interval),
+			StackDriftRemediation:       config.Viper.GetBool(config.Flag.Service.AWS.StackDrift.Remediation),
+			TransitGatewayID:            config.Viper.GetString(config.Flag.Service.AWS.TransitGateway.ID),
+			VaultAddress:                config.Viper.GetString(config.Flag.Service.AWS.VaultAddress),
+			VaultAuth: controller.ClusterConfigVaultAuth{
+				AppRoleRoleID:       config.Viper.GetString(config.Flag.Service.AWS.VaultAuth.AppRoleRoleID),
+				AppRoleSecretIDPath: config.Viper.GetString(config.Flag.Service.AWS.VaultAuth.AppRoleSecretIDPath),
+				KubernetesTokenPath: config.Viper.GetString(config.Flag.Service.AWS.VaultAuth.KubernetesTokenPath),
+				Method:              config.Viper.GetString(config.Flag.Service.AWS.VaultAuth.Method),
+				Mount:               config.Viper.GetString(config.Flag.Service.AWS.VaultAuth.Mount),
+				Role:                config.Viper.GetString(config.Flag.Service.AWS.VaultAuth.Role),
+			},
+			VPCEndpointsEnabled: config.Viper.GetBool(config.Flag.Service.AWS.VPCEndpoints.Enabled),
+			VPCFlowLogs: controller.ClusterConfigVPCFlowLogs{
+				Destination:            config.Viper.GetString(config.Flag.Service.AWS.VPCFlowLogs.Destination),
+				MaxAggregationInterval: config.Viper.GetInt(config.Flag.Service.AWS.VPCFlowLogs.MaxAggregationInterval),
+				TrafficType:            config.Viper.GetString(config.Flag.Service.AWS.VPCFlowLogs.TrafficType),
+			},
+			WorkerRolloutBatchTimeout: config.Viper.GetDuration(config.Flag.Service.AWS.WorkerRollout.BatchTimeout),
+			WorkerRolloutHealthGated:  config.Viper.GetBool(config.Flag.Service.AWS.WorkerRollout.HealthGated),
 		}
 
 		clusterController, err = controller.NewCluster(c)
@@ -15,6 +15,8 @@ func commonViperSettings(f *flag.Flag, v *viper.Viper) {
 	v.Set(f.Service.AWS.AccessKey.Session, "session")
 	v.Set(f.Service.AWS.AvailabilityZones, []string{"eu-west-1a", "eu-west-1b", "eu-west-1c"})
 	v.Set(f.Service.AWS.Encrypter, "kms")
+	v.Set(f.Service.AWS.EtcdBackup.Interval, "1h")
+	v.Set(f.Service.AWS.EtcdBackup.Retention, 48)
 	v.Set(f.Service.AWS.HostAccessKey.ID, "accessKeyID")
 	v.Set(f.Service.AWS.HostAccessKey.Secret, "accessKeySecret")
 	v.Set(f.Service.AWS.HostAccessKey.Session, "session")